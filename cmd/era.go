@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/ethpandaops/checkpointz/pkg/beaconapi"
+	"github.com/spf13/cobra"
+)
+
+var (
+	eraOutputDir string
+	eraTimeout   time.Duration
+)
+
+// eraCmd downloads era files from a Checkpointz instance.
+var eraCmd = &cobra.Command{
+	Use:   "era [checkpointz url] [era number...]",
+	Short: "Download era files of the finalized history held by a Checkpointz instance",
+	Long: `Downloads the given eras from a Checkpointz instance running in full mode. Each era file holds every block
+within the era and the state at its closing boundary, so an era is only available while that state and all
+of its blocks are held.`,
+	Args: cobra.MinimumNArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		numbers := make([]uint64, 0, len(args)-1)
+
+		for _, arg := range args[1:] {
+			number, err := strconv.ParseUint(arg, 10, 64)
+			if err != nil {
+				return fmt.Errorf("invalid era number %q: %w", arg, err)
+			}
+
+			numbers = append(numbers, number)
+		}
+
+		cmd.SilenceUsage = true
+
+		if err := os.MkdirAll(eraOutputDir, 0o755); err != nil { //nolint:gosec // era files aren't secret
+			return err
+		}
+
+		client := beaconapi.NewClient(args[0], eraTimeout)
+
+		for _, number := range numbers {
+			ctx, cancel := context.WithTimeout(cmd.Context(), eraTimeout)
+			path, err := client.DownloadEra(ctx, number, eraOutputDir)
+
+			cancel()
+
+			if err != nil {
+				return fmt.Errorf("failed to download era %d: %w", number, err)
+			}
+
+			fmt.Fprintln(cmd.OutOrStdout(), path)
+		}
+
+		return nil
+	},
+}
+
+func init() {
+	eraCmd.Flags().StringVarP(&eraOutputDir, "output-dir", "d", ".", "directory to write the era files to")
+	eraCmd.Flags().DurationVar(&eraTimeout, "timeout", 10*time.Minute, "timeout for each era download")
+
+	rootCmd.AddCommand(eraCmd)
+}
@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/ethpandaops/checkpointz/pkg/beacon/ssz"
+	"github.com/ethpandaops/checkpointz/pkg/beaconapi"
+	"github.com/ethpandaops/checkpointz/pkg/bundle"
+	"github.com/spf13/cobra"
+)
+
+var (
+	fetchOutputDir    string
+	fetchState        bool
+	fetchBlobSidecars bool
+	fetchTimeout      time.Duration
+)
+
+// fetchCmd downloads a verified checkpoint bundle from Checkpointz providers.
+var fetchCmd = &cobra.Command{
+	Use:   "fetch [checkpointz url...]",
+	Short: "Download the finalized block, state and blob sidecars from one or more Checkpointz providers",
+	Long: `Downloads the finalized checkpoint that every given Checkpointz provider agrees on, verifies the block and
+state roots locally and writes them as SSZ files along with a manifest.json describing the bundle.
+
+Running it again against the same directory resumes interrupted downloads and skips files that are already
+verified.`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+
+		providers := make([]*beaconapi.Client, 0, len(args))
+		for _, url := range args {
+			providers = append(providers, beaconapi.NewClient(url, fetchTimeout))
+		}
+
+		ctx, cancel := context.WithTimeout(cmd.Context(), fetchTimeout*time.Duration(3+len(args)))
+		defer cancel()
+
+		manifest, err := bundle.Fetch(ctx, providers, ssz.NewEncoder(false), bundle.Options{
+			Dir:          fetchOutputDir,
+			State:        fetchState,
+			BlobSidecars: fetchBlobSidecars,
+		})
+		if err != nil {
+			return err
+		}
+
+		encoder := json.NewEncoder(cmd.OutOrStdout())
+		encoder.SetIndent("", "  ")
+
+		return encoder.Encode(manifest)
+	},
+}
+
+func init() {
+	fetchCmd.Flags().StringVarP(&fetchOutputDir, "output-dir", "d", "checkpoint", "directory to write the bundle to")
+	fetchCmd.Flags().BoolVar(&fetchState, "state", true, "download the beacon state (requires providers in full mode)")
+	fetchCmd.Flags().BoolVar(&fetchBlobSidecars, "blobs", true, "download the block's blob sidecars")
+	fetchCmd.Flags().DurationVar(&fetchTimeout, "timeout", 5*time.Minute, "timeout for each request")
+
+	rootCmd.AddCommand(fetchCmd)
+}
@@ -1,11 +1,13 @@
 package cmd
 
 import (
-	"context"
 	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/creasty/defaults"
 	"github.com/ethpandaops/checkpointz/pkg/checkpointz"
+	"github.com/ethpandaops/checkpointz/pkg/clock"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 	"gopkg.in/yaml.v2"
@@ -17,10 +19,16 @@ var rootCmd = &cobra.Command{
 	Short: "Checkpoint sync provider for Ethereum beacon nodes",
 	Run: func(cmd *cobra.Command, args []string) {
 		cfg := initCommon()
-		p := checkpointz.NewServer(log, cfg)
-		if err := p.Start(context.Background()); err != nil {
+
+		ctx, stop := signal.NotifyContext(cmd.Context(), syscall.SIGTERM, syscall.SIGINT)
+		defer stop()
+
+		p := checkpointz.NewServer(log, cfg, clock.New())
+		if err := p.Start(ctx); err != nil {
 			log.WithError(err).Fatal("failed to serve")
 		}
+
+		log.Info("Checkpointz stopped")
 	},
 }
 
@@ -61,7 +69,7 @@ func loadConfigFromFile(file string) (*checkpointz.Config, error) {
 
 	type plain checkpointz.Config
 
-	if err := yaml.Unmarshal(yamlFile, (*plain)(config)); err != nil {
+	if err := yaml.UnmarshalStrict(yamlFile, (*plain)(config)); err != nil {
 		return nil, err
 	}
 
@@ -0,0 +1,93 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/ethpandaops/checkpointz/pkg/beaconapi"
+	"github.com/ethpandaops/checkpointz/pkg/syncverify"
+	"github.com/spf13/cobra"
+)
+
+var (
+	verifyBeaconNode string
+	verifyEpochs     int
+	verifyOutput     string
+	verifyTimeout    time.Duration
+)
+
+// verifyCmd checks a local beacon node's finalized history against Checkpointz providers.
+var verifyCmd = &cobra.Command{
+	Use:   "verify [checkpointz url...]",
+	Short: "Verify a checkpoint synced beacon node against one or more Checkpointz providers",
+	Long: `Compares the blocks at the most recent finalized epoch boundaries of a beacon node with those served by
+one or more Checkpointz providers.
+
+Exits with status 1 if any provider has a different block, and 2 if nothing could be compared.`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		verdict, err := runVerify(cmd, args)
+		if err != nil {
+			return err
+		}
+
+		switch verdict {
+		case syncverify.VerdictMismatch:
+			os.Exit(1)
+		case syncverify.VerdictInconclusive:
+			os.Exit(2)
+		}
+
+		return nil
+	},
+}
+
+func runVerify(cmd *cobra.Command, args []string) (syncverify.Verdict, error) {
+	if verifyOutput != "text" && verifyOutput != "json" {
+		return "", fmt.Errorf("invalid output format %q: must be text or json", verifyOutput)
+	}
+
+	if verifyBeaconNode == "" {
+		return "", errors.New("--beacon-node is required")
+	}
+
+	// Anything failing from here on isn't a usage error.
+	cmd.SilenceUsage = true
+
+	providers := make([]*beaconapi.Client, 0, len(args))
+	for _, url := range args {
+		providers = append(providers, beaconapi.NewClient(url, verifyTimeout))
+	}
+
+	ctx, cancel := context.WithTimeout(cmd.Context(), verifyTimeout*time.Duration(2+len(args)*(1+2*verifyEpochs)))
+	defer cancel()
+
+	report, err := syncverify.Verify(ctx, beaconapi.NewClient(verifyBeaconNode, verifyTimeout), providers, verifyEpochs)
+	if err != nil {
+		return "", err
+	}
+
+	if verifyOutput == "json" {
+		encoder := json.NewEncoder(cmd.OutOrStdout())
+		encoder.SetIndent("", "  ")
+
+		err = encoder.Encode(report)
+	} else {
+		err = report.WriteText(cmd.OutOrStdout())
+	}
+
+	return report.Verdict, err
+}
+
+func init() {
+	verifyCmd.Flags().StringVar(&verifyBeaconNode, "beacon-node", "http://localhost:5052", "beacon API address of the node to verify")
+	verifyCmd.Flags().IntVar(&verifyEpochs, "epochs", 3, "number of recent finalized epoch boundaries to compare")
+	verifyCmd.Flags().StringVarP(&verifyOutput, "output", "o", "text", "output format (text or json)")
+	verifyCmd.Flags().DurationVar(&verifyTimeout, "timeout", 10*time.Second, "timeout for each request")
+
+	rootCmd.AddCommand(verifyCmd)
+}
@@ -0,0 +1,188 @@
+// Package access restricts groups of routes of the public HTTP API to clients presenting an API key or
+// connecting from an allowlisted network.
+package access
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethpandaops/checkpointz/pkg/clientip"
+	"github.com/ethpandaops/checkpointz/pkg/clock"
+	"github.com/ethpandaops/checkpointz/pkg/ratelimit"
+)
+
+var (
+	// ErrInvalidKey is returned when a request for a restricted route presents a key we don't know.
+	ErrInvalidKey = errors.New("invalid API key")
+	// ErrUnauthorized is returned when a request for a restricted route presents no key and isn't from an
+	// allowed network.
+	ErrUnauthorized = errors.New("an API key is required")
+	// ErrForbidden is returned when a request for a restricted route presents a key that may not access it.
+	ErrForbidden = errors.New("API key may not access this route")
+	// ErrQuotaExceeded is returned when a key has used up its quota.
+	ErrQuotaExceeded = errors.New("API key quota exceeded")
+)
+
+// Controller decides which clients may access which routes.
+type Controller struct {
+	clock clock.Clock
+
+	// routes maps a route's path to the group restricting it.
+	routes map[string]*group
+	// keys maps the SHA-256 digest of each key's secret to it, so that looking a key up doesn't leak its
+	// secret through timing.
+	keys map[[sha256.Size]byte]*key
+
+	// mu guards the keys' quotas.
+	mu sync.Mutex
+}
+
+type group struct {
+	name     string
+	networks []*net.IPNet
+}
+
+type key struct {
+	name   string
+	groups map[string]struct{}
+	quota  *ratelimit.Bucket
+}
+
+// New returns a Controller for config, which must be valid, loading its keys file.
+func New(config Config, clk clock.Clock) (*Controller, error) {
+	var keys []KeyConfig
+
+	if config.KeysFile != "" {
+		var err error
+
+		keys, err = LoadKeys(config.KeysFile)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return newWithKeys(config, keys, clk)
+}
+
+func newWithKeys(config Config, keys []KeyConfig, clk clock.Clock) (*Controller, error) {
+	c := &Controller{
+		clock:  clk,
+		routes: make(map[string]*group),
+		keys:   make(map[[sha256.Size]byte]*key, len(keys)),
+	}
+
+	groups := make(map[string]*group, len(config.Groups))
+
+	for _, g := range config.Groups {
+		networks, err := clientip.ParseNetworks(g.AllowedNetworks)
+		if err != nil {
+			return nil, err
+		}
+
+		groups[g.Name] = &group{name: g.Name, networks: networks}
+
+		for _, route := range g.Routes {
+			c.routes[route] = groups[g.Name]
+		}
+	}
+
+	names := make(map[string]struct{}, len(keys))
+
+	for _, k := range keys {
+		if err := k.validate(groups); err != nil {
+			return nil, err
+		}
+
+		if _, ok := names[k.Name]; ok {
+			return nil, fmt.Errorf("duplicate key name: %s", k.Name)
+		}
+
+		names[k.Name] = struct{}{}
+
+		digest := sha256.Sum256([]byte(k.Key))
+		if _, ok := c.keys[digest]; ok {
+			return nil, fmt.Errorf("key %s has the same secret as another key", k.Name)
+		}
+
+		allowed := make(map[string]struct{}, len(k.Groups))
+		for _, name := range k.Groups {
+			allowed[name] = struct{}{}
+		}
+
+		c.keys[digest] = &key{
+			name:   k.Name,
+			groups: allowed,
+			quota:  ratelimit.NewBucket(k.Quota, clk.Now()),
+		}
+	}
+
+	return c, nil
+}
+
+// Authorize decides whether a request for route may proceed, given the client's IP and the request's
+// Authorization header. It returns the name of the key the request presented, if any, and when a key's
+// quota is used up, how long until the request may be retried. Requests for routes that don't need a key
+// are anonymous unless they present one we know, so that credentials meant for something else, like a
+// proxy in front of us, don't get them turned away.
+func (c *Controller) Authorize(route, clientIP, authorization string) (string, time.Duration, error) {
+	k, err := c.key(authorization)
+
+	if g, restricted := c.routes[route]; restricted && !clientip.Contains(g.networks, clientIP) {
+		if err != nil {
+			return "", 0, err
+		}
+
+		if k == nil {
+			return "", 0, ErrUnauthorized
+		}
+
+		if _, ok := k.groups[g.name]; !ok {
+			return k.name, 0, ErrForbidden
+		}
+	}
+
+	if err != nil || k == nil {
+		return "", 0, nil
+	}
+
+	c.mu.Lock()
+	ok, retryAfter := k.quota.Take(c.clock.Now())
+	c.mu.Unlock()
+
+	if !ok {
+		return k.name, retryAfter, ErrQuotaExceeded
+	}
+
+	return k.name, 0, nil
+}
+
+// Restricted returns true if route is in a group.
+func (c *Controller) Restricted(route string) bool {
+	_, ok := c.routes[route]
+
+	return ok
+}
+
+// key returns the key presented as a bearer token in the Authorization header, or nil if there's none.
+func (c *Controller) key(authorization string) (*key, error) {
+	if authorization == "" {
+		return nil, nil //nolint:nilnil // presenting no key is fine
+	}
+
+	scheme, token, ok := strings.Cut(authorization, " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return nil, ErrInvalidKey
+	}
+
+	k, ok := c.keys[sha256.Sum256([]byte(strings.TrimSpace(token)))]
+	if !ok {
+		return nil, ErrInvalidKey
+	}
+
+	return k, nil
+}
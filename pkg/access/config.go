@@ -0,0 +1,124 @@
+package access
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ethpandaops/checkpointz/pkg/clientip"
+	"github.com/ethpandaops/checkpointz/pkg/ratelimit"
+	"gopkg.in/yaml.v2"
+)
+
+// Config configures access control of the public HTTP API.
+type Config struct {
+	Enabled bool `yaml:"enabled" default:"false"`
+	// KeysFile is the path to a YAML file of the API keys that may be presented as bearer tokens.
+	KeysFile string `yaml:"keysFile"`
+	// Groups restrict routes to API keys and allowlisted networks. Routes in no group are public.
+	Groups []GroupConfig `yaml:"groups"`
+}
+
+// GroupConfig is a group of routes only some clients may access.
+type GroupConfig struct {
+	// Name identifies the group to API keys.
+	Name string `yaml:"name"`
+	// Routes are the paths of the group's routes as they're registered, e.g.
+	// /eth/v2/debug/beacon/states/:state_id.
+	Routes []string `yaml:"routes"`
+	// AllowedNetworks are the addresses or CIDRs of clients that may access the group without an API key.
+	AllowedNetworks []string `yaml:"allowedNetworks"`
+}
+
+// KeyConfig is an API key, as it's written in the keys file.
+type KeyConfig struct {
+	// Name identifies the key in logs and metrics. The key itself is never exposed.
+	Name string `yaml:"name"`
+	// Key is the secret presented as a bearer token.
+	Key string `yaml:"key"`
+	// Groups are the names of the route groups the key may access. Every key may access public routes.
+	Groups []string `yaml:"groups"`
+	// Quota limits the key's requests across every route. A zero quota is unlimited.
+	Quota ratelimit.Limit `yaml:"quota"`
+}
+
+type keysFile struct {
+	Keys []KeyConfig `yaml:"keys"`
+}
+
+func (c *Config) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+
+	names := make(map[string]struct{})
+	routes := make(map[string]string)
+
+	for _, group := range c.Groups {
+		if group.Name == "" {
+			return errors.New("group name is required")
+		}
+
+		if _, ok := names[group.Name]; ok {
+			return fmt.Errorf("duplicate group: %s", group.Name)
+		}
+
+		names[group.Name] = struct{}{}
+
+		for _, route := range group.Routes {
+			if !strings.HasPrefix(route, "/") {
+				return fmt.Errorf("route path must start with /: %q", route)
+			}
+
+			if other, ok := routes[route]; ok {
+				return fmt.Errorf("route %s is in groups %s and %s", route, other, group.Name)
+			}
+
+			routes[route] = group.Name
+		}
+
+		if _, err := clientip.ParseNetworks(group.AllowedNetworks); err != nil {
+			return fmt.Errorf("invalid allowed network for group %s: %w", group.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// LoadKeys reads the API keys in the file at path.
+func LoadKeys(path string) ([]KeyConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var file keysFile
+	if err := yaml.UnmarshalStrict(data, &file); err != nil {
+		return nil, fmt.Errorf("invalid keys file: %w", err)
+	}
+
+	return file.Keys, nil
+}
+
+func (k *KeyConfig) validate(groups map[string]*group) error {
+	if k.Name == "" {
+		return errors.New("key name is required")
+	}
+
+	if k.Key == "" {
+		return fmt.Errorf("key %s has no secret", k.Name)
+	}
+
+	for _, name := range k.Groups {
+		if _, ok := groups[name]; !ok {
+			return fmt.Errorf("key %s refers to unknown group %s", k.Name, name)
+		}
+	}
+
+	if err := k.Quota.Validate(); err != nil {
+		return fmt.Errorf("invalid quota for key %s: %w", k.Name, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,49 @@
+package access_test
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethpandaops/checkpointz/pkg/access"
+	"github.com/ethpandaops/checkpointz/pkg/beacon"
+	"github.com/ethpandaops/checkpointz/pkg/checkpointz"
+	"github.com/ethpandaops/checkpointz/pkg/checkpointz/checkpointztest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestE2ERestrictsRoutesToAPIKeys(t *testing.T) {
+	chain := checkpointztest.NewChain(t)
+
+	keysFile := filepath.Join(t.TempDir(), "keys.yaml")
+	require.NoError(t, os.WriteFile(keysFile, []byte("keys: [{name: partner, key: partner-secret, groups: [states]}]"), 0o600))
+
+	server := checkpointztest.Start(t, beacon.OperatingModeFull, func(config *checkpointz.Config) {
+		config.GlobalConfig.Access = access.Config{
+			Enabled:  true,
+			KeysFile: keysFile,
+			Groups: []access.GroupConfig{
+				{Name: "states", Routes: []string{"/eth/v2/debug/beacon/states/:state_id"}},
+			},
+		}
+	}, checkpointztest.Upstream{Node: checkpointztest.NewNode(chain), DataProvider: true})
+
+	// Public routes stay public, even for credentials we don't know.
+	server.RequireServes(chain.Root(checkpointztest.FinalizedEpoch))
+
+	rsp, _ := server.Get("/eth/v1/beacon/blocks/finalized/root", "application/json", map[string]string{"Authorization": "Basic cHJveHk6c2VjcmV0"})
+	assert.Equal(t, http.StatusOK, rsp.StatusCode)
+
+	rsp, _ = server.Get("/eth/v2/debug/beacon/states/finalized", "application/octet-stream")
+	require.Equal(t, http.StatusUnauthorized, rsp.StatusCode)
+	assert.Equal(t, "Bearer", rsp.Header.Get("WWW-Authenticate"))
+
+	rsp, _ = server.Get("/eth/v2/debug/beacon/states/finalized", "application/octet-stream", map[string]string{"Authorization": "Bearer nonsense"})
+	assert.Equal(t, http.StatusUnauthorized, rsp.StatusCode)
+
+	rsp, _ = server.Get("/eth/v2/debug/beacon/states/finalized", "application/octet-stream", map[string]string{"Authorization": "Bearer partner-secret"})
+	require.Equal(t, http.StatusOK, rsp.StatusCode)
+	assert.Equal(t, "private", rsp.Header.Get("Cache-Control"))
+}
@@ -0,0 +1,32 @@
+package api
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/ethpandaops/checkpointz/pkg/compression"
+)
+
+// marshal marshals response as contentType. If the response's payload is immutable and the client accepts
+// a compressed encoding, it's served compressed from the compressor's cache, and that encoding is returned.
+func (h *Handler) marshal(ctx context.Context, r *http.Request, response *HTTPResponse, contentType ContentType) ([]byte, compression.Encoding, error) {
+	if h.compressor != nil && response.payloadKey != "" {
+		if encoding := h.compressor.Negotiate(r); encoding != compression.Identity {
+			compressed, err := h.compressor.Precompressed(ctx, response.payloadKey+"/"+contentType.String(), encoding, func() ([]byte, error) {
+				return response.MarshalAs(contentType)
+			})
+			if err != nil {
+				return nil, compression.Identity, err
+			}
+
+			// Payloads too small to compress are served as they are.
+			if compressed != nil {
+				return compressed, encoding, nil
+			}
+		}
+	}
+
+	data, err := response.MarshalAs(contentType)
+
+	return data, compression.Identity, err
+}
@@ -2,6 +2,8 @@ package api
 
 import (
 	"fmt"
+	"sort"
+	"strconv"
 	"strings"
 )
 
@@ -14,6 +16,20 @@ const (
 	ContentTypeSSZ
 )
 
+// ErrNotAcceptable is returned when none of the content types requested by the client can be produced.
+type ErrNotAcceptable struct {
+	Supported []ContentType
+}
+
+func (e *ErrNotAcceptable) Error() string {
+	supported := make([]string, 0, len(e.Supported))
+	for _, c := range e.Supported {
+		supported = append(supported, c.String())
+	}
+
+	return fmt.Sprintf("unsupported content-type requested, supported: %s", strings.Join(supported, ", "))
+}
+
 func (c ContentType) String() string {
 	switch c {
 	case ContentTypeJSON:
@@ -29,32 +45,173 @@ func (c ContentType) String() string {
 	return ""
 }
 
-func DeriveContentType(accept string) ContentType {
-	// Split the accept header by commas to handle multiple content types
-	acceptTypes := strings.Split(accept, ",")
-	for _, acceptType := range acceptTypes {
-		// Split each type by semicolon to handle q-values
-		parts := strings.Split(acceptType, ";")
-		contentType := strings.TrimSpace(parts[0])
-
-		switch contentType {
-		case "application/json":
-			return ContentTypeJSON
-		case "*/*":
-			return ContentTypeJSON
-		case "application/yaml":
-			return ContentTypeYAML
-		case "application/octet-stream":
-			return ContentTypeSSZ
+func contentTypeFromMediaType(mediaType string) ContentType {
+	switch mediaType {
+	case "application/json":
+		return ContentTypeJSON
+	case "application/yaml":
+		return ContentTypeYAML
+	case "application/octet-stream":
+		return ContentTypeSSZ
+	}
+
+	return ContentTypeUnknown
+}
+
+// mediaRange is a single entry of an Accept header.
+type mediaRange struct {
+	mediaType string
+	q         float64
+	index     int
+}
+
+// specificity ranks exact types above partial (application/*) and full (*/*) wildcards.
+func (m mediaRange) specificity() int {
+	switch {
+	case m.mediaType == "*/*":
+		return 0
+	case strings.HasSuffix(m.mediaType, "/*"):
+		return 1
+	default:
+		return 2
+	}
+}
+
+func (m mediaRange) matches(c ContentType) bool {
+	switch m.specificity() {
+	case 0:
+		return true
+	case 1:
+		return strings.HasPrefix(c.String(), strings.TrimSuffix(m.mediaType, "*"))
+	default:
+		return contentTypeFromMediaType(m.mediaType) == c
+	}
+}
+
+// parseAccept parses an Accept header into media ranges ordered by preference.
+func parseAccept(accept string) []mediaRange {
+	ranges := []mediaRange{}
+
+	for i, raw := range strings.Split(accept, ",") {
+		parts := strings.Split(raw, ";")
+
+		mediaType := strings.ToLower(strings.TrimSpace(parts[0]))
+		if mediaType == "" {
+			continue
+		}
+
+		q := 1.0
+
+		for _, param := range parts[1:] {
+			key, value, found := strings.Cut(strings.TrimSpace(param), "=")
+			if !found || strings.TrimSpace(key) != "q" {
+				continue
+			}
+
+			parsed, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+			if err != nil || parsed < 0 || parsed > 1 {
+				// Treat malformed weights as not acceptable rather than guessing.
+				parsed = 0
+			}
+
+			q = parsed
 		}
+
+		ranges = append(ranges, mediaRange{
+			mediaType: mediaType,
+			q:         q,
+			index:     i,
+		})
 	}
 
+	sort.SliceStable(ranges, func(i, j int) bool {
+		if ranges[i].q != ranges[j].q {
+			return ranges[i].q > ranges[j].q
+		}
+
+		if ranges[i].specificity() != ranges[j].specificity() {
+			return ranges[i].specificity() > ranges[j].specificity()
+		}
+
+		return ranges[i].index < ranges[j].index
+	})
+
+	return ranges
+}
+
+// qualityOf returns the weight the client assigned to the given content type, using the most
+// specific matching media range as per RFC 9110.
+func qualityOf(ranges []mediaRange, c ContentType) float64 {
+	best := -1
+	q := 0.0
+
+	for _, r := range ranges {
+		if !r.matches(c) {
+			continue
+		}
+
+		if r.specificity() > best {
+			best = r.specificity()
+			q = r.q
+		}
+	}
+
+	return q
+}
+
+// NegotiateContentType picks the content type to respond with given the client's Accept header
+// and the content types (in order of server preference) that can be produced.
+func NegotiateContentType(accept string, supported []ContentType) (ContentType, error) {
+	if len(supported) == 0 {
+		return ContentTypeUnknown, &ErrNotAcceptable{Supported: supported}
+	}
+
+	// Default to JSON if they don't care what they get.
+	if strings.TrimSpace(accept) == "" {
+		if DoesAccept(supported, ContentTypeJSON) {
+			return ContentTypeJSON, nil
+		}
+
+		return supported[0], nil
+	}
+
+	ranges := parseAccept(accept)
+
+	for _, r := range ranges {
+		if r.q == 0 {
+			break
+		}
+
+		for _, c := range supported {
+			if !r.matches(c) {
+				continue
+			}
+
+			// A more specific range may have excluded this type (e.g. "*/*, application/json;q=0").
+			if qualityOf(ranges, c) == 0 {
+				continue
+			}
+
+			return c, nil
+		}
+	}
+
+	return ContentTypeUnknown, &ErrNotAcceptable{Supported: supported}
+}
+
+// DeriveContentType returns the most preferred content type in the accept header that checkpointz knows about.
+func DeriveContentType(accept string) ContentType {
 	// Default to JSON if they don't care what they get.
 	if accept == "" {
 		return ContentTypeJSON
 	}
 
-	return ContentTypeUnknown
+	content, err := NegotiateContentType(accept, []ContentType{ContentTypeJSON, ContentTypeSSZ, ContentTypeYAML})
+	if err != nil {
+		return ContentTypeUnknown
+	}
+
+	return content
 }
 
 func ValidateContentType(contentType ContentType, accepting []ContentType) error {
@@ -23,6 +23,9 @@ func TestDeriveContentType(t *testing.T) {
 		{"QValue JSON", "application/json;q=0.8", api.ContentTypeJSON},
 		{"QValue YAML", "application/yaml;q=0.5", api.ContentTypeYAML},
 		{"QValue Multiple", "application/json;q=0.8, application/yaml;q=0.5", api.ContentTypeJSON},
+		{"QValue Reordered", "application/yaml;q=0.5, application/octet-stream;q=0.9", api.ContentTypeSSZ},
+		{"QValue Zero", "application/json;q=0", api.ContentTypeUnknown},
+		{"Partial Wildcard", "application/*", api.ContentTypeJSON},
 	}
 
 	for _, tt := range tests {
@@ -33,6 +36,50 @@ func TestDeriveContentType(t *testing.T) {
 	}
 }
 
+func TestNegotiateContentType(t *testing.T) {
+	jsonAndSSZ := []api.ContentType{api.ContentTypeJSON, api.ContentTypeSSZ, api.ContentTypeYAML}
+	sszOnly := []api.ContentType{api.ContentTypeSSZ}
+
+	tests := []struct {
+		name        string
+		accept      string
+		supported   []api.ContentType
+		expected    api.ContentType
+		expectError bool
+	}{
+		{"Empty prefers JSON", "", jsonAndSSZ, api.ContentTypeJSON, false},
+		{"Empty falls back to first supported", "", sszOnly, api.ContentTypeSSZ, false},
+		{"Exact match", "application/octet-stream", jsonAndSSZ, api.ContentTypeSSZ, false},
+		{"Highest q wins", "application/json;q=0.2, application/octet-stream;q=0.7", jsonAndSSZ, api.ContentTypeSSZ, false},
+		{"Equal q keeps header order", "application/yaml, application/json", jsonAndSSZ, api.ContentTypeYAML, false},
+		{"Specific beats wildcard at equal q", "*/*, application/octet-stream", jsonAndSSZ, api.ContentTypeSSZ, false},
+		{"Wildcard uses server preference", "*/*", jsonAndSSZ, api.ContentTypeJSON, false},
+		{"Wildcard with exclusion", "*/*, application/json;q=0", jsonAndSSZ, api.ContentTypeSSZ, false},
+		{"Unsupported falls through to lower q", "application/json, application/octet-stream;q=0.1", sszOnly, api.ContentTypeSSZ, false},
+		{"Nothing acceptable", "application/json", sszOnly, api.ContentTypeUnknown, true},
+		{"Only unknown types", "text/html", jsonAndSSZ, api.ContentTypeUnknown, true},
+		{"Malformed q is not acceptable", "application/octet-stream;q=abc", sszOnly, api.ContentTypeUnknown, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := api.NegotiateContentType(tt.accept, tt.supported)
+			if tt.expectError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestNotAcceptableListsSupported(t *testing.T) {
+	_, err := api.NegotiateContentType("text/html", []api.ContentType{api.ContentTypeJSON, api.ContentTypeSSZ})
+	assert.EqualError(t, err, "unsupported content-type requested, supported: application/json, application/octet-stream")
+}
+
 func TestValidateContentType(t *testing.T) {
 	tests := []struct {
 		name        string
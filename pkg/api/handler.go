@@ -10,12 +10,24 @@ import (
 	"strings"
 	"time"
 
+	"github.com/attestantio/go-eth2-client/spec/deneb"
+	"github.com/ethpandaops/checkpointz/pkg/access"
+	"github.com/ethpandaops/checkpointz/pkg/attestation"
 	"github.com/ethpandaops/checkpointz/pkg/beacon"
+	"github.com/ethpandaops/checkpointz/pkg/beacon/fulu"
 	"github.com/ethpandaops/checkpointz/pkg/beacon/ssz"
+	"github.com/ethpandaops/checkpointz/pkg/clientip"
+	"github.com/ethpandaops/checkpointz/pkg/compression"
+	"github.com/ethpandaops/checkpointz/pkg/ratelimit"
 	"github.com/ethpandaops/checkpointz/pkg/service/checkpointz"
 	"github.com/ethpandaops/checkpointz/pkg/service/eth"
+	"github.com/ethpandaops/checkpointz/pkg/tracing"
 	"github.com/julienschmidt/httprouter"
 	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // Handler is an API handler that is responsible for negotiating with a HTTP api.
@@ -26,6 +38,12 @@ type Handler struct {
 
 	eth           *eth.Handler
 	checkpointz   *checkpointz.Handler
+	provider      beacon.FinalityProvider
+	attestor      *attestation.Attestor
+	clientIPs     *clientip.Resolver
+	limiter       *ratelimit.Limiter
+	access        *access.Controller
+	compressor    *compression.Compressor
 	sszEncoder    *ssz.Encoder
 	publicURL     string
 	brandName     string
@@ -34,12 +52,21 @@ type Handler struct {
 	metrics Metrics
 }
 
-func NewHandler(log logrus.FieldLogger, beac beacon.FinalityProvider, config *beacon.Config) *Handler {
+// NewHandler returns a new Handler. attestor may be nil if attestations are disabled, limiter may be nil if
+// rate limiting is disabled, access may be nil if access control is disabled, and compressor may be nil if
+// compression is disabled.
+func NewHandler(log logrus.FieldLogger, beac beacon.FinalityProvider, config *beacon.Config, attestor *attestation.Attestor, clientIPs *clientip.Resolver, limiter *ratelimit.Limiter, access *access.Controller, compressor *compression.Compressor) *Handler {
 	return &Handler{
 		log: log.WithField("module", "api"),
 
 		eth:           eth.NewHandler(log, beac, "checkpointz"),
-		checkpointz:   checkpointz.NewHandler(log, beac),
+		checkpointz:   checkpointz.NewHandler(log, beac, attestor),
+		provider:      beac,
+		attestor:      attestor,
+		clientIPs:     clientIPs,
+		limiter:       limiter,
+		access:        access,
+		compressor:    compressor,
 		sszEncoder:    beac.SSZEncoder(),
 		publicURL:     config.Frontend.PublicURL,
 		brandName:     config.Frontend.BrandName,
@@ -53,8 +80,11 @@ func (h *Handler) Register(ctx context.Context, router *httprouter.Router) error
 	router.GET("/eth/v1/beacon/genesis", h.wrappedHandler(h.handleEthV1BeaconGenesis))
 	router.GET("/eth/v1/beacon/blocks/:block_id/root", h.wrappedHandler(h.handleEthV1BeaconBlocksRoot))
 	router.GET("/eth/v1/beacon/states/:state_id/finality_checkpoints", h.wrappedHandler(h.handleEthV1BeaconStatesFinalityCheckpoints))
-	router.GET("/eth/v1/beacon/deposit_snapshot", h.wrappedHandler(h.handleEthV1BeaconDepositSnapshot))
-	router.GET("/eth/v1/beacon/blob_sidecars/:block_id", h.wrappedHandler(h.handleEthV1BeaconBlobSidecars))
+	router.GET("/eth/v1/beacon/deposit_snapshot", h.wrappedHandler(h.handleEthV1BeaconDepositSnapshot, ContentTypeJSON, ContentTypeSSZ))
+	router.GET("/eth/v1/beacon/blob_sidecars/:block_id", h.wrappedHandler(h.handleEthV1BeaconBlobSidecars, ContentTypeJSON, ContentTypeSSZ))
+	router.GET("/eth/v1/beacon/blobs/:block_id", h.wrappedHandler(h.handleEthV1BeaconBlobs, ContentTypeJSON, ContentTypeSSZ))
+
+	router.GET("/eth/v1/debug/beacon/data_column_sidecars/:block_id", h.wrappedHandler(h.handleEthV1DebugBeaconDataColumnSidecars, ContentTypeJSON, ContentTypeSSZ))
 
 	router.GET("/eth/v1/config/spec", h.wrappedHandler(h.handleEthV1ConfigSpec))
 	router.GET("/eth/v1/config/deposit_contract", h.wrappedHandler(h.handleEthV1ConfigDepositContract))
@@ -65,14 +95,22 @@ func (h *Handler) Register(ctx context.Context, router *httprouter.Router) error
 	router.GET("/eth/v1/node/peers", h.wrappedHandler(h.handleEthV1NodePeers))
 	router.GET("/eth/v1/node/peer_count", h.wrappedHandler(h.handleEthV1NodePeerCount))
 
-	router.GET("/eth/v2/beacon/blocks/:block_id", h.wrappedHandler(h.handleEthV2BeaconBlocks))
+	router.GET("/eth/v2/beacon/blocks/:block_id", h.wrappedHandler(h.handleEthV2BeaconBlocks, ContentTypeJSON, ContentTypeSSZ))
 
-	router.GET("/eth/v2/debug/beacon/states/:state_id", h.wrappedHandler(h.handleEthV2DebugBeaconStates))
+	router.GET(stateDownloadsPath, h.wrappedHandler(h.handleEthV2DebugBeaconStates, ContentTypeJSON, ContentTypeSSZ))
 
 	router.GET("/checkpointz/v1/status", h.wrappedHandler(h.handleCheckpointzStatus))
 	router.GET("/checkpointz/v1/beacon/slots", h.wrappedHandler(h.handleCheckpointzBeaconSlots))
 	router.GET("/checkpointz/v1/beacon/slots/:slot", h.wrappedHandler(h.handleCheckpointzBeaconSlot))
 	router.GET("/checkpointz/v1/ready", h.wrappedHandler(h.handleCheckpointzReady))
+	router.GET("/checkpointz/v1/provenance/:root", h.wrappedHandler(h.handleCheckpointzProvenance))
+	router.GET(eraPath, h.wrappedHandler(h.handleCheckpointzEra, ContentTypeSSZ))
+
+	if h.attestor != nil {
+		h.provider.OnServingCheckpointUpdated(ctx, h.checkpointz.Attest)
+
+		router.GET("/checkpointz/v1/attestations", h.wrappedHandler(h.handleCheckpointzAttestations))
+	}
 
 	return nil
 }
@@ -86,19 +124,42 @@ func deriveRegisteredPath(request *http.Request, ps httprouter.Params) string {
 	return registeredPath
 }
 
-func (h *Handler) wrappedHandler(handler func(ctx context.Context, r *http.Request, p httprouter.Params, contentType ContentType) (*HTTPResponse, error)) httprouter.Handle {
+// wrappedHandler serves handler's responses as whichever of the content types its resolvers produce the client
+// accepts. Handlers without any only produce JSON.
+func (h *Handler) wrappedHandler(handler func(ctx context.Context, r *http.Request, p httprouter.Params) (*HTTPResponse, error), resolvable ...ContentType) httprouter.Handle {
+	if len(resolvable) == 0 {
+		resolvable = []ContentType{ContentTypeJSON}
+	}
+
+	supported := supportedContentTypes(func(c ContentType) bool { return DoesAccept(resolvable, c) })
+
 	return func(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
 		start := time.Now()
 
+		accept := r.Header.Get("Accept")
 		contentType := NewContentTypeFromRequest(r)
-		ctx := r.Context()
 		registeredPath := deriveRegisteredPath(r, p)
 
+		// Requests join the caller's trace if they carry one and came through a trusted proxy. Anyone else
+		// could otherwise have their requests sampled by setting the sampled flag, or attach them to another
+		// trace.
+		parent := r.Context()
+		if h.clientIPs.FromTrustedProxy(r) {
+			parent = otel.GetTextMapPropagator().Extract(parent, propagation.HeaderCarrier(r.Header))
+		}
+
+		ctx, span := tracing.Start(
+			parent,
+			r.Method+" "+registeredPath,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(semconv.HTTPRequestMethodKey.String(r.Method), semconv.HTTPRoute(registeredPath)),
+		)
+
 		h.log.WithFields(logrus.Fields{
 			"method":       r.Method,
 			"path":         r.URL.Path,
 			"content_type": contentType,
-			"accept":       r.Header.Get("Accept"),
+			"accept":       accept,
 		}).Trace("Handling request")
 
 		h.metrics.ObserveRequest(r.Method, registeredPath)
@@ -109,10 +170,40 @@ func (h *Handler) wrappedHandler(handler func(ctx context.Context, r *http.Reque
 
 		defer func() {
 			h.metrics.ObserveResponse(r.Method, registeredPath, fmt.Sprintf("%v", response.StatusCode), contentType.String(), time.Since(start))
+
+			span.SetAttributes(semconv.HTTPResponseStatusCode(response.StatusCode))
+
+			// Client errors are the client's problem, not a failure of the request's span.
+			if response.StatusCode < http.StatusInternalServerError {
+				tracing.End(span, nil)
+			} else {
+				tracing.End(span, err)
+			}
 		}()
 
-		response, err = handler(ctx, r, p, contentType)
+		// The representation varies by the Accept header, so caches must key on it.
+		w.Header().Add("Vary", "Accept")
+
+		var release func()
+
+		release, response, err = h.admit(ctx, r, registeredPath)
+		if err == nil {
+			defer release()
+
+			// Negotiate before handling, so that requests we can't satisfy don't load what they ask for.
+			contentType, err = NegotiateContentType(accept, supported)
+			if err != nil {
+				response = NewNotAcceptableResponse(nil)
+			} else {
+				response, err = handler(ctx, r, p)
+			}
+		}
+
 		if err != nil {
+			for header, value := range response.Headers {
+				w.Header().Set(header, value)
+			}
+
 			if writeErr := WriteErrorResponse(w, err.Error(), response.StatusCode); writeErr != nil {
 				h.log.WithError(writeErr).Error("Failed to write error response")
 			}
@@ -120,7 +211,17 @@ func (h *Handler) wrappedHandler(handler func(ctx context.Context, r *http.Reque
 			return
 		}
 
-		data, err := response.MarshalAs(contentType)
+		if response.StatusCode == http.StatusTemporaryRedirect {
+			for header, value := range response.Headers {
+				w.Header().Set(header, value)
+			}
+
+			w.WriteHeader(response.StatusCode)
+
+			return
+		}
+
+		data, encoding, err := h.marshal(ctx, r, response, contentType)
 		if err != nil {
 			if writeErr := WriteErrorResponse(w, err.Error(), http.StatusInternalServerError); writeErr != nil {
 				h.log.WithError(writeErr).Error("Failed to write error response")
@@ -133,17 +234,30 @@ func (h *Handler) wrappedHandler(handler func(ctx context.Context, r *http.Reque
 			w.Header().Set(header, value)
 		}
 
+		if h.access != nil && h.access.Restricted(registeredPath) {
+			// Shared caches mustn't serve restricted responses to clients that may not access them.
+			w.Header().Set("Cache-Control", "private")
+		}
+
+		if encoding != compression.Identity {
+			w.Header().Set("Content-Encoding", string(encoding))
+			compression.AddVary(w.Header())
+		}
+
+		// Immutable payloads can be requested in ranges, so that interrupted downloads can be resumed.
+		if response.payloadKey != "" && encoding == compression.Identity {
+			WriteRangedResponse(w, r, data, contentType)
+
+			return
+		}
+
 		if err := WriteContentAwareResponse(w, data, contentType); err != nil {
 			h.log.WithError(err).Error("Failed to write response")
 		}
 	}
 }
 
-func (h *Handler) handleEthV1BeaconGenesis(ctx context.Context, r *http.Request, p httprouter.Params, contentType ContentType) (*HTTPResponse, error) {
-	if err := ValidateContentType(contentType, []ContentType{ContentTypeJSON}); err != nil {
-		return NewUnsupportedMediaTypeResponse(nil), err
-	}
-
+func (h *Handler) handleEthV1BeaconGenesis(ctx context.Context, r *http.Request, p httprouter.Params) (*HTTPResponse, error) {
 	genesis, err := h.eth.BeaconGenesis(ctx)
 	if err != nil {
 		return NewInternalServerErrorResponse(nil), err
@@ -158,11 +272,7 @@ func (h *Handler) handleEthV1BeaconGenesis(ctx context.Context, r *http.Request,
 	return rsp, nil
 }
 
-func (h *Handler) handleEthV2BeaconBlocks(ctx context.Context, r *http.Request, p httprouter.Params, contentType ContentType) (*HTTPResponse, error) {
-	if err := ValidateContentType(contentType, []ContentType{ContentTypeJSON, ContentTypeSSZ}); err != nil {
-		return NewUnsupportedMediaTypeResponse(nil), err
-	}
-
+func (h *Handler) handleEthV2BeaconBlocks(ctx context.Context, r *http.Request, p httprouter.Params) (*HTTPResponse, error) {
 	blockID, err := eth.NewBlockIdentifier(p.ByName("block_id"))
 	if err != nil {
 		return NewBadRequestResponse(nil), err
@@ -175,10 +285,10 @@ func (h *Handler) handleEthV2BeaconBlocks(ctx context.Context, r *http.Request,
 
 	rsp := NewSuccessResponse(ContentTypeResolvers{
 		ContentTypeJSON: func() ([]byte, error) {
-			return h.sszEncoder.EncodeBlockJSON(block)
+			return h.sszEncoder.EncodeBlockJSON(ctx, block)
 		},
 		ContentTypeSSZ: func() ([]byte, error) {
-			return h.sszEncoder.EncodeBlockSSZ(block)
+			return h.sszEncoder.EncodeBlockSSZ(ctx, block)
 		},
 	})
 
@@ -186,6 +296,16 @@ func (h *Handler) handleEthV2BeaconBlocks(ctx context.Context, r *http.Request,
 	rsp.AddExtraData("execution_optimistic", false)
 	rsp.AddExtraData("finalized", true) // We only serve finalized data
 
+	rsp.SetEthConsensusVersion(block.Version.String())
+
+	if root, err := h.sszEncoder.GetBlockRoot(block); err == nil {
+		rsp.SetPayloadKey("block/" + root.String())
+	}
+
+	if provenance, err := h.eth.BlockProvenance(ctx, blockID); err == nil {
+		rsp.SetProvenance(provenance)
+	}
+
 	switch blockID.Type() {
 	case eth.BlockIDRoot, eth.BlockIDGenesis, eth.BlockIDSlot:
 		rsp.SetCacheControl("public, s-max-age=6000")
@@ -199,11 +319,7 @@ func (h *Handler) handleEthV2BeaconBlocks(ctx context.Context, r *http.Request,
 	return rsp, nil
 }
 
-func (h *Handler) handleEthV2DebugBeaconStates(ctx context.Context, r *http.Request, p httprouter.Params, contentType ContentType) (*HTTPResponse, error) {
-	if err := ValidateContentType(contentType, []ContentType{ContentTypeSSZ}); err != nil {
-		return NewUnsupportedMediaTypeResponse(nil), err
-	}
-
+func (h *Handler) handleEthV2DebugBeaconStates(ctx context.Context, r *http.Request, p httprouter.Params) (*HTTPResponse, error) {
 	id, err := eth.NewStateIdentifier(p.ByName("state_id"))
 	if err != nil {
 		return NewBadRequestResponse(nil), err
@@ -218,12 +334,30 @@ func (h *Handler) handleEthV2DebugBeaconStates(ctx context.Context, r *http.Requ
 		return NewInternalServerErrorResponse(nil), errors.New("state not found")
 	}
 
+	// SSZ downloads of states held in remote storage may be sent there instead of being served by us. The
+	// redirect carries the fork version, as the object's response only has it as x-amz-meta metadata.
+	if contentType, err := NegotiateContentType(r.Header.Get("Accept"), []ContentType{ContentTypeJSON, ContentTypeSSZ}); err == nil && contentType == ContentTypeSSZ {
+		if location, err := h.eth.StateURL(ctx, id); err == nil && location != "" {
+			rsp := NewRedirectResponse(location)
+			rsp.SetEthConsensusVersion(state.Version.String())
+
+			return rsp, nil
+		}
+	}
+
 	rsp := NewSuccessResponse(ContentTypeResolvers{
+		ContentTypeJSON: func() ([]byte, error) {
+			return h.sszEncoder.EncodeStateJSON(ctx, state)
+		},
 		ContentTypeSSZ: func() ([]byte, error) {
-			return h.sszEncoder.EncodeStateSSZ(state)
+			return h.sszEncoder.EncodeStateSSZ(ctx, state)
 		},
 	})
 
+	rsp.AddExtraData("version", state.Version.String())
+	rsp.AddExtraData("execution_optimistic", false)
+	rsp.AddExtraData("finalized", true) // We only serve finalized data
+
 	switch id.Type() {
 	case eth.StateIDSlot:
 		rsp.SetCacheControl("public, s-max-age=6000")
@@ -237,14 +371,18 @@ func (h *Handler) handleEthV2DebugBeaconStates(ctx context.Context, r *http.Requ
 
 	rsp.SetEthConsensusVersion(state.Version.String())
 
-	return rsp, nil
-}
+	if root, err := h.eth.StateRoot(ctx, id); err == nil {
+		rsp.SetPayloadKey("state/" + root.String())
+	}
 
-func (h *Handler) handleEthV1ConfigSpec(ctx context.Context, r *http.Request, p httprouter.Params, contentType ContentType) (*HTTPResponse, error) {
-	if err := ValidateContentType(contentType, []ContentType{ContentTypeJSON}); err != nil {
-		return NewUnsupportedMediaTypeResponse(nil), err
+	if provenance, err := h.eth.StateProvenance(ctx, id); err == nil {
+		rsp.SetProvenance(provenance)
 	}
 
+	return rsp, nil
+}
+
+func (h *Handler) handleEthV1ConfigSpec(ctx context.Context, r *http.Request, p httprouter.Params) (*HTTPResponse, error) {
 	sp, err := h.eth.ConfigSpec(ctx)
 	if err != nil {
 		return NewInternalServerErrorResponse(nil), err
@@ -261,11 +399,7 @@ func (h *Handler) handleEthV1ConfigSpec(ctx context.Context, r *http.Request, p
 	return rsp, nil
 }
 
-func (h *Handler) handleEthV1ConfigDepositContract(ctx context.Context, r *http.Request, p httprouter.Params, contentType ContentType) (*HTTPResponse, error) {
-	if err := ValidateContentType(contentType, []ContentType{ContentTypeJSON}); err != nil {
-		return NewUnsupportedMediaTypeResponse(nil), err
-	}
-
+func (h *Handler) handleEthV1ConfigDepositContract(ctx context.Context, r *http.Request, p httprouter.Params) (*HTTPResponse, error) {
 	contract, err := h.eth.DepositContract(ctx)
 	if err != nil {
 		return NewInternalServerErrorResponse(nil), err
@@ -282,11 +416,7 @@ func (h *Handler) handleEthV1ConfigDepositContract(ctx context.Context, r *http.
 	return rsp, nil
 }
 
-func (h *Handler) handleEthV1ConfigForkSchedule(ctx context.Context, r *http.Request, p httprouter.Params, contentType ContentType) (*HTTPResponse, error) {
-	if err := ValidateContentType(contentType, []ContentType{ContentTypeJSON}); err != nil {
-		return NewUnsupportedMediaTypeResponse(nil), err
-	}
-
+func (h *Handler) handleEthV1ConfigForkSchedule(ctx context.Context, r *http.Request, p httprouter.Params) (*HTTPResponse, error) {
 	forks, err := h.eth.ForkSchedule(ctx)
 	if err != nil {
 		return NewInternalServerErrorResponse(nil), err
@@ -303,11 +433,7 @@ func (h *Handler) handleEthV1ConfigForkSchedule(ctx context.Context, r *http.Req
 	return rsp, nil
 }
 
-func (h *Handler) handleEthV1NodeSyncing(ctx context.Context, r *http.Request, p httprouter.Params, contentType ContentType) (*HTTPResponse, error) {
-	if err := ValidateContentType(contentType, []ContentType{ContentTypeJSON}); err != nil {
-		return NewUnsupportedMediaTypeResponse(nil), err
-	}
-
+func (h *Handler) handleEthV1NodeSyncing(ctx context.Context, r *http.Request, p httprouter.Params) (*HTTPResponse, error) {
 	syncing, err := h.eth.NodeSyncing(ctx)
 	if err != nil {
 		return NewInternalServerErrorResponse(nil), err
@@ -324,11 +450,7 @@ func (h *Handler) handleEthV1NodeSyncing(ctx context.Context, r *http.Request, p
 	return rsp, nil
 }
 
-func (h *Handler) handleEthV1NodeVersion(ctx context.Context, r *http.Request, p httprouter.Params, contentType ContentType) (*HTTPResponse, error) {
-	if err := ValidateContentType(contentType, []ContentType{ContentTypeJSON}); err != nil {
-		return NewUnsupportedMediaTypeResponse(nil), err
-	}
-
+func (h *Handler) handleEthV1NodeVersion(ctx context.Context, r *http.Request, p httprouter.Params) (*HTTPResponse, error) {
 	version, err := h.eth.NodeVersion(ctx)
 	if err != nil {
 		return NewInternalServerErrorResponse(nil), err
@@ -349,11 +471,7 @@ func (h *Handler) handleEthV1NodeVersion(ctx context.Context, r *http.Request, p
 	return rsp, nil
 }
 
-func (h *Handler) handleEthV1NodePeerCount(ctx context.Context, r *http.Request, p httprouter.Params, contentType ContentType) (*HTTPResponse, error) {
-	if err := ValidateContentType(contentType, []ContentType{ContentTypeJSON}); err != nil {
-		return NewUnsupportedMediaTypeResponse(nil), err
-	}
-
+func (h *Handler) handleEthV1NodePeerCount(ctx context.Context, r *http.Request, p httprouter.Params) (*HTTPResponse, error) {
 	peers, err := h.eth.Peers(ctx)
 	if err != nil {
 		return NewInternalServerErrorResponse(nil), err
@@ -382,11 +500,7 @@ func (h *Handler) handleEthV1NodePeerCount(ctx context.Context, r *http.Request,
 	return rsp, nil
 }
 
-func (h *Handler) handleEthV1NodePeers(ctx context.Context, r *http.Request, p httprouter.Params, contentType ContentType) (*HTTPResponse, error) {
-	if err := ValidateContentType(contentType, []ContentType{ContentTypeJSON}); err != nil {
-		return NewUnsupportedMediaTypeResponse(nil), err
-	}
-
+func (h *Handler) handleEthV1NodePeers(ctx context.Context, r *http.Request, p httprouter.Params) (*HTTPResponse, error) {
 	peers, err := h.eth.Peers(ctx)
 	if err != nil {
 		return NewInternalServerErrorResponse(nil), err
@@ -403,11 +517,7 @@ func (h *Handler) handleEthV1NodePeers(ctx context.Context, r *http.Request, p h
 	return rsp, nil
 }
 
-func (h *Handler) handleCheckpointzStatus(ctx context.Context, r *http.Request, p httprouter.Params, contentType ContentType) (*HTTPResponse, error) {
-	if err := ValidateContentType(contentType, []ContentType{ContentTypeJSON}); err != nil {
-		return NewUnsupportedMediaTypeResponse(nil), err
-	}
-
+func (h *Handler) handleCheckpointzStatus(ctx context.Context, r *http.Request, p httprouter.Params) (*HTTPResponse, error) {
 	status, err := h.checkpointz.V1Status(ctx, checkpointz.NewStatusRequest())
 	if err != nil {
 		return NewInternalServerErrorResponse(nil), err
@@ -428,11 +538,7 @@ func (h *Handler) handleCheckpointzStatus(ctx context.Context, r *http.Request,
 	return rsp, nil
 }
 
-func (h *Handler) handleCheckpointzReady(ctx context.Context, r *http.Request, p httprouter.Params, contentType ContentType) (*HTTPResponse, error) {
-	if err := ValidateContentType(contentType, []ContentType{ContentTypeJSON}); err != nil {
-		return NewUnsupportedMediaTypeResponse(nil), err
-	}
-
+func (h *Handler) handleCheckpointzReady(ctx context.Context, r *http.Request, p httprouter.Params) (*HTTPResponse, error) {
 	status, err := h.checkpointz.V1Status(ctx, checkpointz.NewStatusRequest())
 	if err != nil {
 		return NewInternalServerErrorResponse(nil), err
@@ -451,11 +557,7 @@ func (h *Handler) handleCheckpointzReady(ctx context.Context, r *http.Request, p
 	return rsp, nil
 }
 
-func (h *Handler) handleCheckpointzBeaconSlots(ctx context.Context, r *http.Request, p httprouter.Params, contentType ContentType) (*HTTPResponse, error) {
-	if err := ValidateContentType(contentType, []ContentType{ContentTypeJSON}); err != nil {
-		return NewUnsupportedMediaTypeResponse(nil), err
-	}
-
+func (h *Handler) handleCheckpointzBeaconSlots(ctx context.Context, r *http.Request, p httprouter.Params) (*HTTPResponse, error) {
 	slots, err := h.checkpointz.V1BeaconSlots(ctx, checkpointz.NewBeaconSlotsRequest())
 	if err != nil {
 		return NewInternalServerErrorResponse(nil), err
@@ -472,11 +574,7 @@ func (h *Handler) handleCheckpointzBeaconSlots(ctx context.Context, r *http.Requ
 	return rsp, nil
 }
 
-func (h *Handler) handleCheckpointzBeaconSlot(ctx context.Context, r *http.Request, p httprouter.Params, contentType ContentType) (*HTTPResponse, error) {
-	if err := ValidateContentType(contentType, []ContentType{ContentTypeJSON}); err != nil {
-		return NewUnsupportedMediaTypeResponse(nil), err
-	}
-
+func (h *Handler) handleCheckpointzBeaconSlot(ctx context.Context, r *http.Request, p httprouter.Params) (*HTTPResponse, error) {
 	slot, err := eth.NewSlotFromString(p.ByName("slot"))
 	if err != nil {
 		return NewBadRequestResponse(nil), err
@@ -498,11 +596,74 @@ func (h *Handler) handleCheckpointzBeaconSlot(ctx context.Context, r *http.Reque
 	return rsp, nil
 }
 
-func (h *Handler) handleEthV1BeaconStatesFinalityCheckpoints(ctx context.Context, r *http.Request, p httprouter.Params, contentType ContentType) (*HTTPResponse, error) {
-	if err := ValidateContentType(contentType, []ContentType{ContentTypeJSON}); err != nil {
-		return NewUnsupportedMediaTypeResponse(nil), err
+func (h *Handler) handleCheckpointzProvenance(ctx context.Context, r *http.Request, p httprouter.Params) (*HTTPResponse, error) {
+	root, err := eth.NewRootFromString(p.ByName("root"))
+	if err != nil {
+		return NewBadRequestResponse(nil), err
+	}
+
+	provenance, err := h.checkpointz.V1Provenance(ctx, checkpointz.NewProvenanceRequest(root))
+	if err != nil {
+		return NewInternalServerErrorResponse(nil), err
+	}
+
+	rsp := NewSuccessResponse(ContentTypeResolvers{
+		ContentTypeJSON: func() ([]byte, error) {
+			return json.Marshal(provenance)
+		},
+	})
+
+	rsp.SetCacheControl("public, s-max-age=30")
+
+	return rsp, nil
+}
+
+func (h *Handler) handleCheckpointzEra(ctx context.Context, r *http.Request, p httprouter.Params) (*HTTPResponse, error) {
+	number, err := strconv.ParseUint(p.ByName("era"), 10, 64)
+	if err != nil {
+		return NewBadRequestResponse(nil), fmt.Errorf("invalid era: %w", err)
+	}
+
+	eraFile, err := h.checkpointz.V1Era(ctx, checkpointz.NewEraRequest(number))
+	if err != nil {
+		if errors.Is(err, checkpointz.ErrEraUnavailable) || errors.Is(err, eth.ErrRequiresFullMode) {
+			return NewNotFoundResponse(nil), err
+		}
+
+		return NewInternalServerErrorResponse(nil), err
 	}
 
+	rsp := NewSuccessResponse(ContentTypeResolvers{
+		ContentTypeSSZ: func() ([]byte, error) {
+			return eraFile.Data, nil
+		},
+	})
+
+	rsp.SetContentDisposition(eraFile.Name)
+	rsp.SetPayloadKey("era/" + eraFile.Name)
+	rsp.SetCacheControl("public, s-max-age=6000")
+
+	return rsp, nil
+}
+
+func (h *Handler) handleCheckpointzAttestations(ctx context.Context, r *http.Request, p httprouter.Params) (*HTTPResponse, error) {
+	attestations, err := h.checkpointz.V1Attestations(ctx, checkpointz.NewAttestationsRequest())
+	if err != nil {
+		return NewInternalServerErrorResponse(nil), err
+	}
+
+	rsp := NewSuccessResponse(ContentTypeResolvers{
+		ContentTypeJSON: func() ([]byte, error) {
+			return json.Marshal(attestations)
+		},
+	})
+
+	rsp.SetCacheControl("public, s-max-age=5")
+
+	return rsp, nil
+}
+
+func (h *Handler) handleEthV1BeaconStatesFinalityCheckpoints(ctx context.Context, r *http.Request, p httprouter.Params) (*HTTPResponse, error) {
 	id, err := eth.NewStateIdentifier(p.ByName("state_id"))
 	if err != nil {
 		return NewBadRequestResponse(nil), err
@@ -510,6 +671,10 @@ func (h *Handler) handleEthV1BeaconStatesFinalityCheckpoints(ctx context.Context
 
 	finality, err := h.eth.FinalityCheckpoints(ctx, id)
 	if err != nil {
+		if errors.Is(err, eth.ErrRequiresFullMode) {
+			return NewNotFoundResponse(nil), err
+		}
+
 		return NewInternalServerErrorResponse(nil), err
 	}
 
@@ -519,19 +684,21 @@ func (h *Handler) handleEthV1BeaconStatesFinalityCheckpoints(ctx context.Context
 		},
 	})
 
+	if provenance, err := h.eth.FinalityProvenance(ctx, id); err == nil {
+		rsp.SetProvenance(provenance)
+	}
+
 	switch id.Type() {
 	case eth.StateIDFinalized, eth.StateIDHead:
 		rsp.SetCacheControl("public, s-max-age=5")
+	case eth.StateIDGenesis, eth.StateIDSlot, eth.StateIDRoot:
+		rsp.SetCacheControl("public, s-max-age=6000")
 	}
 
 	return rsp, nil
 }
 
-func (h *Handler) handleEthV1BeaconBlocksRoot(ctx context.Context, r *http.Request, p httprouter.Params, contentType ContentType) (*HTTPResponse, error) {
-	if err := ValidateContentType(contentType, []ContentType{ContentTypeJSON}); err != nil {
-		return NewUnsupportedMediaTypeResponse(nil), err
-	}
-
+func (h *Handler) handleEthV1BeaconBlocksRoot(ctx context.Context, r *http.Request, p httprouter.Params) (*HTTPResponse, error) {
 	id, err := eth.NewBlockIdentifier(p.ByName("block_id"))
 	if err != nil {
 		return NewBadRequestResponse(nil), err
@@ -555,11 +722,7 @@ func (h *Handler) handleEthV1BeaconBlocksRoot(ctx context.Context, r *http.Reque
 	}), nil
 }
 
-func (h *Handler) handleEthV1BeaconDepositSnapshot(ctx context.Context, r *http.Request, p httprouter.Params, contentType ContentType) (*HTTPResponse, error) {
-	if err := ValidateContentType(contentType, []ContentType{ContentTypeJSON}); err != nil {
-		return NewUnsupportedMediaTypeResponse(nil), err
-	}
-
+func (h *Handler) handleEthV1BeaconDepositSnapshot(ctx context.Context, r *http.Request, p httprouter.Params) (*HTTPResponse, error) {
 	snapshot, err := h.eth.DepositSnapshot(ctx)
 	if err != nil {
 		return NewInternalServerErrorResponse(nil), err
@@ -569,14 +732,13 @@ func (h *Handler) handleEthV1BeaconDepositSnapshot(ctx context.Context, r *http.
 		ContentTypeJSON: func() ([]byte, error) {
 			return json.Marshal(snapshot)
 		},
+		ContentTypeSSZ: func() ([]byte, error) {
+			return h.sszEncoder.EncodeDepositSnapshotSSZ(snapshot)
+		},
 	}), nil
 }
 
-func (h *Handler) handleEthV1BeaconBlobSidecars(ctx context.Context, r *http.Request, p httprouter.Params, contentType ContentType) (*HTTPResponse, error) {
-	if err := ValidateContentType(contentType, []ContentType{ContentTypeJSON}); err != nil {
-		return NewUnsupportedMediaTypeResponse(nil), err
-	}
-
+func (h *Handler) handleEthV1BeaconBlobSidecars(ctx context.Context, r *http.Request, p httprouter.Params) (*HTTPResponse, error) {
 	id, err := eth.NewBlockIdentifier(p.ByName("block_id"))
 	if err != nil {
 		return NewBadRequestResponse(nil), err
@@ -605,6 +767,112 @@ func (h *Handler) handleEthV1BeaconBlobSidecars(ctx context.Context, r *http.Req
 		ContentTypeJSON: func() ([]byte, error) {
 			return json.Marshal(sidecars)
 		},
+		ContentTypeSSZ: func() ([]byte, error) {
+			return h.sszEncoder.EncodeBlobSidecarsSSZ(sidecars)
+		},
+	})
+
+	rsp.SetEthConsensusVersion(strings.ToLower(dataVersion.String()))
+
+	rsp.AddExtraData("version", strings.ToLower(dataVersion.String()))
+	rsp.AddExtraData("execution_optimistic", false)
+	rsp.AddExtraData("finalized", true) // We only serve finalized data
+
+	switch id.Type() {
+	case eth.BlockIDFinalized, eth.BlockIDRoot:
+		rsp.SetCacheControl("public, s-max-age=6000")
+	default:
+		rsp.SetCacheControl("public, s-max-age=15")
+	}
+
+	return rsp, nil
+}
+
+func (h *Handler) handleEthV1BeaconBlobs(ctx context.Context, r *http.Request, p httprouter.Params) (*HTTPResponse, error) {
+	id, err := eth.NewBlockIdentifier(p.ByName("block_id"))
+	if err != nil {
+		return NewBadRequestResponse(nil), err
+	}
+
+	hashesRaw := r.URL.Query()["versioned_hashes"]
+
+	versionedHashes := make([]deneb.VersionedHash, 0, len(hashesRaw))
+
+	for _, raw := range hashesRaw {
+		for _, hash := range strings.Split(raw, ",") {
+			var versionedHash deneb.VersionedHash
+			if errr := versionedHash.UnmarshalJSON([]byte(fmt.Sprintf("%q", hash))); errr != nil {
+				return NewBadRequestResponse(nil), errr
+			}
+
+			versionedHashes = append(versionedHashes, versionedHash)
+		}
+	}
+
+	blobs, err := h.eth.Blobs(ctx, id, versionedHashes)
+	if err != nil {
+		if errors.Is(err, fulu.ErrNotEnoughColumns) {
+			return NewNotFoundResponse(nil), err
+		}
+
+		return NewInternalServerErrorResponse(nil), err
+	}
+
+	rsp := NewSuccessResponse(ContentTypeResolvers{
+		ContentTypeJSON: func() ([]byte, error) {
+			return json.Marshal(blobs)
+		},
+		ContentTypeSSZ: func() ([]byte, error) {
+			return h.sszEncoder.EncodeBlobsSSZ(blobs)
+		},
+	})
+
+	rsp.AddExtraData("execution_optimistic", false)
+	rsp.AddExtraData("finalized", true) // We only serve finalized data
+
+	switch id.Type() {
+	case eth.BlockIDFinalized, eth.BlockIDRoot:
+		rsp.SetCacheControl("public, s-max-age=6000")
+	default:
+		rsp.SetCacheControl("public, s-max-age=15")
+	}
+
+	return rsp, nil
+}
+
+func (h *Handler) handleEthV1DebugBeaconDataColumnSidecars(ctx context.Context, r *http.Request, p httprouter.Params) (*HTTPResponse, error) {
+	id, err := eth.NewBlockIdentifier(p.ByName("block_id"))
+	if err != nil {
+		return NewBadRequestResponse(nil), err
+	}
+
+	indicesRaw := r.URL.Query()["indices"]
+
+	indices := make([]int, 0, len(indicesRaw))
+
+	for _, raw := range indicesRaw {
+		for _, index := range strings.Split(raw, ",") {
+			converted, errr := strconv.Atoi(index)
+			if errr != nil {
+				return NewBadRequestResponse(nil), errr
+			}
+
+			indices = append(indices, converted)
+		}
+	}
+
+	sidecars, dataVersion, err := h.eth.DataColumnSidecars(ctx, id, indices)
+	if err != nil {
+		return NewInternalServerErrorResponse(nil), err
+	}
+
+	rsp := NewSuccessResponse(ContentTypeResolvers{
+		ContentTypeJSON: func() ([]byte, error) {
+			return json.Marshal(sidecars)
+		},
+		ContentTypeSSZ: func() ([]byte, error) {
+			return h.sszEncoder.EncodeDataColumnSidecarsSSZ(sidecars)
+		},
 	})
 
 	rsp.SetEthConsensusVersion(strings.ToLower(dataVersion.String()))
@@ -0,0 +1,136 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ethpandaops/checkpointz/pkg/clientip"
+	"github.com/ethpandaops/checkpointz/pkg/tracing/tracingtest"
+	"github.com/julienschmidt/httprouter"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWrappedHandlerNegotiatesBeforeHandling(t *testing.T) {
+	clientIPs, err := clientip.New(nil)
+	require.NoError(t, err)
+
+	h := &Handler{
+		log:       logrus.New(),
+		clientIPs: clientIPs,
+		metrics:   NewMetrics("negotiation_test"),
+	}
+
+	calls := 0
+
+	handle := h.wrappedHandler(func(ctx context.Context, r *http.Request, p httprouter.Params) (*HTTPResponse, error) {
+		calls++
+
+		return NewSuccessResponse(ContentTypeResolvers{
+			ContentTypeSSZ: func() ([]byte, error) {
+				return []byte{0x01}, nil
+			},
+		}), nil
+	}, ContentTypeSSZ)
+
+	serve := func(accept string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/", http.NoBody)
+		req.Header.Set("Accept", accept)
+
+		rec := httptest.NewRecorder()
+		handle(rec, req, nil)
+
+		return rec
+	}
+
+	rec := serve("application/json")
+	assert.Equal(t, http.StatusNotAcceptable, rec.Code)
+	assert.Equal(t, 0, calls, "the handler isn't called for requests it can't satisfy")
+
+	rec = serve("application/octet-stream")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, []byte{0x01}, rec.Body.Bytes())
+	assert.Equal(t, 1, calls)
+}
+
+func TestWrappedHandlerServesRangesOfImmutablePayloads(t *testing.T) {
+	clientIPs, err := clientip.New(nil)
+	require.NoError(t, err)
+
+	h := &Handler{
+		log:       logrus.New(),
+		clientIPs: clientIPs,
+		metrics:   NewMetrics("ranges_test"),
+	}
+
+	handle := h.wrappedHandler(func(ctx context.Context, r *http.Request, p httprouter.Params) (*HTTPResponse, error) {
+		rsp := NewSuccessResponse(ContentTypeResolvers{
+			ContentTypeSSZ: func() ([]byte, error) {
+				return []byte{0x01, 0x02, 0x03, 0x04}, nil
+			},
+		})
+
+		rsp.SetPayloadKey("block/0x01")
+
+		return rsp, nil
+	}, ContentTypeSSZ)
+
+	req := httptest.NewRequest(http.MethodGet, "/", http.NoBody)
+	req.Header.Set("Accept", "application/octet-stream")
+	req.Header.Set("Range", "bytes=2-")
+
+	rec := httptest.NewRecorder()
+	handle(rec, req, nil)
+
+	assert.Equal(t, http.StatusPartialContent, rec.Code)
+	assert.Equal(t, "bytes 2-3/4", rec.Header().Get("Content-Range"))
+	assert.Equal(t, ContentTypeSSZ.String(), rec.Header().Get("Content-Type"))
+	assert.Equal(t, []byte{0x03, 0x04}, rec.Body.Bytes())
+}
+
+func TestWrappedHandlerOnlyJoinsTracesFromTrustedProxies(t *testing.T) {
+	recorder := tracingtest.Record(t)
+
+	clientIPs, err := clientip.New([]string{"10.0.0.1"})
+	require.NoError(t, err)
+
+	h := &Handler{
+		log:       logrus.New(),
+		clientIPs: clientIPs,
+		metrics:   NewMetrics("tracing_test"),
+	}
+
+	handle := h.wrappedHandler(func(ctx context.Context, r *http.Request, p httprouter.Params) (*HTTPResponse, error) {
+		return NewSuccessResponse(ContentTypeResolvers{
+			ContentTypeJSON: func() ([]byte, error) {
+				return []byte("{}"), nil
+			},
+		}), nil
+	}, ContentTypeJSON)
+
+	serve := func(remote string) {
+		req := httptest.NewRequest(http.MethodGet, "/", http.NoBody)
+		req.RemoteAddr = remote
+		req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+
+		rec := httptest.NewRecorder()
+		handle(rec, req, nil)
+
+		require.Equal(t, http.StatusOK, rec.Code)
+	}
+
+	serve("192.0.2.1:1234")
+	serve("10.0.0.1:1234")
+
+	spans := recorder.Spans()
+	require.Len(t, spans, 2)
+
+	assert.False(t, spans[0].Parent.IsValid(), "clients can't join their requests to a trace")
+	assert.NotEqual(t, "4bf92f3577b34da6a3ce929d0e0e4736", spans[0].SpanContext.TraceID().String())
+
+	assert.Equal(t, "00f067aa0ba902b7", spans[1].Parent.SpanID().String())
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", spans[1].SpanContext.TraceID().String())
+}
@@ -1,8 +1,10 @@
 package api
 
 import (
+	"bytes"
 	"encoding/json"
 	"net/http"
+	"time"
 )
 
 // WriteJSONResponse writes a JSON response to the given writer.
@@ -26,17 +28,36 @@ func WriteSSZResponse(w http.ResponseWriter, data []byte) error {
 	return nil
 }
 
+func WriteYAMLResponse(w http.ResponseWriter, data []byte) error {
+	w.Header().Set("Content-Type", ContentTypeYAML.String())
+
+	if _, err := w.Write(data); err != nil {
+		return err
+	}
+
+	return nil
+}
+
 func WriteContentAwareResponse(w http.ResponseWriter, data []byte, contentType ContentType) error {
 	switch contentType {
 	case ContentTypeJSON:
 		return WriteJSONResponse(w, data)
 	case ContentTypeSSZ:
 		return WriteSSZResponse(w, data)
+	case ContentTypeYAML:
+		return WriteYAMLResponse(w, data)
 	default:
 		return WriteJSONResponse(w, data)
 	}
 }
 
+// WriteRangedResponse writes data to the given writer, or the part of it that the request's Range header asks for.
+func WriteRangedResponse(w http.ResponseWriter, r *http.Request, data []byte, contentType ContentType) {
+	w.Header().Set("Content-Type", contentType.String())
+
+	http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(data))
+}
+
 func WriteErrorResponse(w http.ResponseWriter, msg string, statusCode int) error {
 	w.Header().Set("Content-Type", ContentTypeJSON.String())
 
@@ -0,0 +1,46 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteRangedResponse(t *testing.T) {
+	data := []byte{0x01, 0x02, 0x03, 0x04}
+
+	t.Run("whole", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		WriteRangedResponse(rec, httptest.NewRequest(http.MethodGet, "/", http.NoBody), data, ContentTypeSSZ)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "bytes", rec.Header().Get("Accept-Ranges"))
+		assert.Equal(t, ContentTypeSSZ.String(), rec.Header().Get("Content-Type"))
+		assert.Equal(t, data, rec.Body.Bytes())
+	})
+
+	t.Run("range", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/", http.NoBody)
+		req.Header.Set("Range", "bytes=2-")
+
+		rec := httptest.NewRecorder()
+		WriteRangedResponse(rec, req, data, ContentTypeSSZ)
+
+		assert.Equal(t, http.StatusPartialContent, rec.Code)
+		assert.Equal(t, "bytes 2-3/4", rec.Header().Get("Content-Range"))
+		assert.Equal(t, ContentTypeSSZ.String(), rec.Header().Get("Content-Type"))
+		assert.Equal(t, []byte{0x03, 0x04}, rec.Body.Bytes())
+	})
+
+	t.Run("unsatisfiable", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/", http.NoBody)
+		req.Header.Set("Range", "bytes=8-")
+
+		rec := httptest.NewRecorder()
+		WriteRangedResponse(rec, req, data, ContentTypeSSZ)
+
+		assert.Equal(t, http.StatusRequestedRangeNotSatisfiable, rec.Code)
+	})
+}
@@ -0,0 +1,98 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/ethpandaops/checkpointz/pkg/access"
+	"github.com/ethpandaops/checkpointz/pkg/ratelimit"
+)
+
+const (
+	// stateDownloadsPath is the route beacon states are downloaded from.
+	stateDownloadsPath = "/eth/v2/debug/beacon/states/:state_id"
+	// eraPath is the route era files, which each hold a beacon state, are downloaded from.
+	eraPath = "/checkpointz/v1/era/:era"
+)
+
+// queuesForStateDownloads returns true if requests for the route at path download beacon states. They're the
+// most expensive we serve, so they queue for a limited number of slots.
+func queuesForStateDownloads(path string) bool {
+	return path == stateDownloadsPath || path == eraPath
+}
+
+// admit applies access control, rate limiting and concurrency control to a request for the route at path.
+// If the request is turned away, it returns the response to send and the reason as an error. Otherwise the
+// returned func must be called once the response has been written.
+func (h *Handler) admit(ctx context.Context, r *http.Request, path string) (func(), *HTTPResponse, error) {
+	client := h.clientIPs.ClientIP(r)
+
+	keyed := false
+
+	if h.access != nil {
+		key, retryAfter, err := h.access.Authorize(path, client, r.Header.Get("Authorization"))
+		if key != "" {
+			keyed = true
+
+			h.metrics.ObserveKeyRequest(key, r.Method, path)
+		}
+
+		if err != nil {
+			var (
+				response *HTTPResponse
+				reason   string
+			)
+
+			switch {
+			case errors.Is(err, access.ErrQuotaExceeded):
+				response, reason = NewTooManyRequestsResponse(retryAfter), "key_quota"
+			case errors.Is(err, access.ErrForbidden):
+				response, reason = NewForbiddenResponse(), "forbidden"
+			default:
+				response, reason = NewUnauthorizedResponse(), "unauthorized"
+			}
+
+			h.metrics.ObserveRejection(r.Method, path, reason)
+
+			return nil, response, err
+		}
+	}
+
+	if h.limiter == nil {
+		return func() {}, nil, nil
+	}
+
+	// Requests made with an API key are limited by its quota rather than per client.
+	if !keyed {
+		if ok, retryAfter := h.limiter.Allow(path, client); !ok {
+			h.metrics.ObserveRejection(r.Method, path, "rate_limit")
+
+			return nil, NewTooManyRequestsResponse(retryAfter), errors.New("rate limit exceeded")
+		}
+	}
+
+	if !queuesForStateDownloads(path) {
+		return func() {}, nil, nil
+	}
+
+	queue := h.limiter.StateDownloads()
+
+	release, err := queue.Acquire(ctx)
+	if err != nil {
+		reason := "canceled"
+
+		switch {
+		case errors.Is(err, ratelimit.ErrQueueFull):
+			reason = "queue_full"
+		case errors.Is(err, ratelimit.ErrQueueTimeout):
+			reason = "queue_timeout"
+		}
+
+		h.metrics.ObserveRejection(r.Method, path, reason)
+
+		return nil, NewServiceUnavailableResponse(queue.RetryAfter()), errors.New("too many state downloads in progress")
+	}
+
+	return release, nil, nil
+}
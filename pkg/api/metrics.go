@@ -10,6 +10,8 @@ type Metrics struct {
 	requests        *prometheus.CounterVec
 	responses       *prometheus.CounterVec
 	requestDuration *prometheus.HistogramVec
+	rejections      *prometheus.CounterVec
+	keyRequests     *prometheus.CounterVec
 }
 
 func NewMetrics(namespace string) Metrics {
@@ -30,11 +32,23 @@ func NewMetrics(namespace string) Metrics {
 			Help:      "Request duration (in seconds.)",
 			Buckets:   []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10},
 		}, []string{"method", "path", "encoding"}),
+		rejections: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "rejected_request_count",
+			Help:      "Number of requests turned away by rate limiting or concurrency control",
+		}, []string{"method", "path", "reason"}),
+		keyRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "api_key_request_count",
+			Help:      "Number of requests made with each API key",
+		}, []string{"key", "method", "path"}),
 	}
 
 	prometheus.MustRegister(m.requests)
 	prometheus.MustRegister(m.responses)
 	prometheus.MustRegister(m.requestDuration)
+	prometheus.MustRegister(m.rejections)
+	prometheus.MustRegister(m.keyRequests)
 
 	return m
 }
@@ -43,6 +57,14 @@ func (m Metrics) ObserveRequest(method, path string) {
 	m.requests.WithLabelValues(method, path).Inc()
 }
 
+func (m Metrics) ObserveRejection(method, path, reason string) {
+	m.rejections.WithLabelValues(method, path, reason).Inc()
+}
+
+func (m Metrics) ObserveKeyRequest(key, method, path string) {
+	m.keyRequests.WithLabelValues(key, method, path).Inc()
+}
+
 func (m Metrics) ObserveResponse(method, path, code, encoding string, duration time.Duration) {
 	m.responses.WithLabelValues(method, path, code, encoding).Inc()
 	m.requestDuration.WithLabelValues(method, path, encoding).Observe(duration.Seconds())
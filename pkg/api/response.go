@@ -3,7 +3,15 @@ package api
 import (
 	"encoding/json"
 	"fmt"
+	"math"
+	"mime"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ethpandaops/checkpointz/pkg/beacon/store"
+	"gopkg.in/yaml.v2"
 )
 
 type ContentTypeResolver func() ([]byte, error)
@@ -14,6 +22,8 @@ type HTTPResponse struct {
 	StatusCode int               `json:"status_code"`
 	Headers    map[string]string `json:"headers"`
 	ExtraData  map[string]interface{}
+	// payloadKey identifies an immutable payload, whose compressed variants can be cached.
+	payloadKey string
 }
 type jsonResponse struct {
 	Data json.RawMessage `json:"data"`
@@ -22,16 +32,55 @@ type jsonResponse struct {
 	Version             string `json:"version,omitempty"`
 }
 
+// contentTypePreference is the order in which we prefer to respond when the client accepts several content types equally.
+var contentTypePreference = []ContentType{ContentTypeJSON, ContentTypeSSZ, ContentTypeYAML}
+
+// SupportedContentTypes returns the content types this response can be marshaled as, in order of preference.
+func (r HTTPResponse) SupportedContentTypes() []ContentType {
+	return supportedContentTypes(func(c ContentType) bool {
+		_, exists := r.resolvers[c]
+
+		return exists
+	})
+}
+
+// supportedContentTypes returns the content types that can be marshaled given which have resolvers, in order
+// of preference. YAML is always derivable from the JSON representation.
+func supportedContentTypes(resolvable func(ContentType) bool) []ContentType {
+	supported := []ContentType{}
+
+	for _, c := range contentTypePreference {
+		if resolvable(c) || (c == ContentTypeYAML && resolvable(ContentTypeJSON)) {
+			supported = append(supported, c)
+		}
+	}
+
+	return supported
+}
+
 func (r HTTPResponse) MarshalAs(contentType ContentType) ([]byte, error) {
-	if _, exists := r.resolvers[contentType]; !exists {
+	if !DoesAccept(r.SupportedContentTypes(), contentType) {
 		return nil, fmt.Errorf("unsupported content-type: %s", contentType.String())
 	}
 
-	if contentType != ContentTypeJSON {
+	switch contentType {
+	case ContentTypeJSON:
+		return r.buildWrappedJSONResponse()
+	case ContentTypeYAML:
+		if resolver, exists := r.resolvers[ContentTypeYAML]; exists {
+			return resolver()
+		}
+
+		return r.buildWrappedYAMLResponse()
+	default:
 		return r.resolvers[contentType]()
 	}
+}
 
-	return r.buildWrappedJSONResponse()
+// SetPayloadKey marks the response's payload as immutable, identified by key (e.g. a root), so that it's only
+// compressed once and can be requested in ranges.
+func (r *HTTPResponse) SetPayloadKey(key string) {
+	r.payloadKey = key
 }
 
 func (r HTTPResponse) SetEtag(etag string) {
@@ -46,6 +95,30 @@ func (r HTTPResponse) SetEthConsensusVersion(version string) {
 	r.Headers["Eth-Consensus-Version"] = version
 }
 
+// SetContentDisposition marks the response as a file download with the given name.
+func (r HTTPResponse) SetContentDisposition(filename string) {
+	r.Headers["Content-Disposition"] = mime.FormatMediaType("attachment", map[string]string{"filename": filename})
+}
+
+// SetProvenance sets headers describing which upstreams agreed on the finalized checkpoint the response's
+// data was served under, and which upstream supplied it.
+func (r HTTPResponse) SetProvenance(provenance *store.Provenance) {
+	if provenance == nil {
+		return
+	}
+
+	r.Headers["Checkpointz-Finalized-Epoch"] = strconv.FormatUint(uint64(provenance.FinalizedEpoch), 10)
+	r.Headers["Checkpointz-Finalized-Root"] = fmt.Sprintf("%#x", provenance.FinalizedRoot)
+	r.Headers["Checkpointz-Agreeing-Upstreams"] = strings.Join(provenance.AgreeingUpstreams, ",")
+	r.Headers["Checkpointz-Agreement"] = strconv.FormatFloat(provenance.Agreement, 'f', -1, 64)
+	r.Headers["Checkpointz-Source"] = provenance.Source
+	r.Headers["Checkpointz-Fetched-At"] = provenance.FetchedAt.UTC().Format(time.RFC3339)
+
+	if provenance.VerifiedAt != nil {
+		r.Headers["Checkpointz-Verified-At"] = provenance.VerifiedAt.UTC().Format(time.RFC3339)
+	}
+}
+
 func NewSuccessResponse(resolvers ContentTypeResolvers) *HTTPResponse {
 	return &HTTPResponse{
 		resolvers:  resolvers,
@@ -55,6 +128,19 @@ func NewSuccessResponse(resolvers ContentTypeResolvers) *HTTPResponse {
 	}
 }
 
+// NewRedirectResponse returns a response that sends the client to location instead of serving a body.
+func NewRedirectResponse(location string) *HTTPResponse {
+	return &HTTPResponse{
+		StatusCode: http.StatusTemporaryRedirect,
+		Headers: map[string]string{
+			"Location": location,
+			// The location may only be valid for a while, e.g. a presigned URL.
+			"Cache-Control": "no-store",
+		},
+		ExtraData: make(map[string]interface{}),
+	}
+}
+
 func NewInternalServerErrorResponse(resolvers ContentTypeResolvers) *HTTPResponse {
 	return &HTTPResponse{
 		resolvers:  resolvers,
@@ -73,7 +159,16 @@ func NewBadRequestResponse(resolvers ContentTypeResolvers) *HTTPResponse {
 	}
 }
 
-func NewUnsupportedMediaTypeResponse(resolvers ContentTypeResolvers) *HTTPResponse {
+func NewNotFoundResponse(resolvers ContentTypeResolvers) *HTTPResponse {
+	return &HTTPResponse{
+		resolvers:  resolvers,
+		StatusCode: http.StatusNotFound,
+		Headers:    make(map[string]string),
+		ExtraData:  make(map[string]interface{}),
+	}
+}
+
+func NewNotAcceptableResponse(resolvers ContentTypeResolvers) *HTTPResponse {
 	return &HTTPResponse{
 		resolvers:  resolvers,
 		StatusCode: http.StatusNotAcceptable,
@@ -82,6 +177,51 @@ func NewUnsupportedMediaTypeResponse(resolvers ContentTypeResolvers) *HTTPRespon
 	}
 }
 
+// NewUnauthorizedResponse returns a response asking the client to authenticate with a bearer token.
+func NewUnauthorizedResponse() *HTTPResponse {
+	return &HTTPResponse{
+		StatusCode: http.StatusUnauthorized,
+		Headers: map[string]string{
+			"WWW-Authenticate": "Bearer",
+		},
+		ExtraData: make(map[string]interface{}),
+	}
+}
+
+func NewForbiddenResponse() *HTTPResponse {
+	return &HTTPResponse{
+		StatusCode: http.StatusForbidden,
+		Headers:    make(map[string]string),
+		ExtraData:  make(map[string]interface{}),
+	}
+}
+
+// NewTooManyRequestsResponse returns a response turning away a client that has made too many requests,
+// telling it when to try again.
+func NewTooManyRequestsResponse(retryAfter time.Duration) *HTTPResponse {
+	return newRetryAfterResponse(http.StatusTooManyRequests, retryAfter)
+}
+
+// NewServiceUnavailableResponse returns a response turning away a request we're too busy to serve, telling
+// the client when to try again.
+func NewServiceUnavailableResponse(retryAfter time.Duration) *HTTPResponse {
+	return newRetryAfterResponse(http.StatusServiceUnavailable, retryAfter)
+}
+
+func newRetryAfterResponse(statusCode int, retryAfter time.Duration) *HTTPResponse {
+	// Retry-After is in whole seconds, so round up to not invite retries that will be turned away too.
+	seconds := max(int64(math.Ceil(retryAfter.Seconds())), 1)
+
+	return &HTTPResponse{
+		StatusCode: statusCode,
+		Headers: map[string]string{
+			"Retry-After":   strconv.FormatInt(seconds, 10),
+			"Cache-Control": "no-store",
+		},
+		ExtraData: make(map[string]interface{}),
+	}
+}
+
 func (r *HTTPResponse) AddExtraData(key string, value interface{}) {
 	r.ExtraData[key] = value
 }
@@ -110,3 +250,18 @@ func (r *HTTPResponse) buildWrappedJSONResponse() ([]byte, error) {
 
 	return json.Marshal(rsp)
 }
+
+func (r *HTTPResponse) buildWrappedYAMLResponse() ([]byte, error) {
+	data, err := r.buildWrappedJSONResponse()
+	if err != nil {
+		return nil, err
+	}
+
+	// JSON is valid YAML, so decoding into a MapSlice keeps the field order of the JSON response.
+	var wrapped yaml.MapSlice
+	if err := yaml.Unmarshal(data, &wrapped); err != nil {
+		return nil, err
+	}
+
+	return yaml.Marshal(wrapped)
+}
@@ -0,0 +1,86 @@
+package api_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/ethpandaops/checkpointz/pkg/api"
+	"github.com/ethpandaops/checkpointz/pkg/beacon/store"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSupportedContentTypes(t *testing.T) {
+	rsp := api.NewSuccessResponse(api.ContentTypeResolvers{
+		api.ContentTypeSSZ: func() ([]byte, error) {
+			return []byte{0x01}, nil
+		},
+	})
+
+	assert.Equal(t, []api.ContentType{api.ContentTypeSSZ}, rsp.SupportedContentTypes())
+
+	rsp = api.NewSuccessResponse(api.ContentTypeResolvers{
+		api.ContentTypeJSON: func() ([]byte, error) {
+			return []byte(`{}`), nil
+		},
+	})
+
+	assert.Equal(t, []api.ContentType{api.ContentTypeJSON, api.ContentTypeYAML}, rsp.SupportedContentTypes())
+}
+
+func TestMarshalAsYAML(t *testing.T) {
+	rsp := api.NewSuccessResponse(api.ContentTypeResolvers{
+		api.ContentTypeJSON: func() ([]byte, error) {
+			return []byte(`{"slot":"100","root":"0x01"}`), nil
+		},
+	})
+
+	rsp.AddExtraData("version", "deneb")
+
+	data, err := rsp.MarshalAs(api.ContentTypeYAML)
+	assert.NoError(t, err)
+	assert.Equal(t, "data:\n  slot: \"100\"\n  root: "+"\"0x01\"\nversion: deneb\n", string(data))
+
+	_, err = rsp.MarshalAs(api.ContentTypeSSZ)
+	assert.Error(t, err)
+}
+
+func TestSetProvenance(t *testing.T) {
+	fetchedAt := time.Date(2022, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	rsp := api.NewSuccessResponse(nil)
+	rsp.SetProvenance(&store.Provenance{
+		FinalizedEpoch:    10,
+		FinalizedRoot:     phase0.Root{0x01},
+		AgreeingUpstreams: []string{"lighthouse", "teku"},
+		Agreement:         2.0 / 3,
+		Source:            "teku",
+		FetchedAt:         fetchedAt,
+	})
+
+	assert.Equal(t, "10", rsp.Headers["Checkpointz-Finalized-Epoch"])
+	assert.Equal(t, "0x0100000000000000000000000000000000000000000000000000000000000000", rsp.Headers["Checkpointz-Finalized-Root"])
+	assert.Equal(t, "lighthouse,teku", rsp.Headers["Checkpointz-Agreeing-Upstreams"])
+	assert.Equal(t, "0.6666666666666666", rsp.Headers["Checkpointz-Agreement"])
+	assert.Equal(t, "teku", rsp.Headers["Checkpointz-Source"])
+	assert.Equal(t, "2022-06-01T12:00:00Z", rsp.Headers["Checkpointz-Fetched-At"])
+	assert.NotContains(t, rsp.Headers, "Checkpointz-Verified-At")
+
+	rsp = api.NewSuccessResponse(nil)
+	rsp.SetProvenance(nil)
+
+	assert.Empty(t, rsp.Headers)
+}
+
+func TestRetryAfterResponses(t *testing.T) {
+	rsp := api.NewTooManyRequestsResponse(1500 * time.Millisecond)
+
+	assert.Equal(t, http.StatusTooManyRequests, rsp.StatusCode)
+	assert.Equal(t, "2", rsp.Headers["Retry-After"], "rounds up to whole seconds")
+
+	rsp = api.NewServiceUnavailableResponse(0)
+
+	assert.Equal(t, http.StatusServiceUnavailable, rsp.StatusCode)
+	assert.Equal(t, "1", rsp.Headers["Retry-After"], "never invites an immediate retry")
+}
@@ -0,0 +1,104 @@
+// Package attestation signs the checkpoints Checkpointz serves with an operator key, so that users can
+// confirm a checkpoint came from a particular instance without relying on the transport.
+package attestation
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+)
+
+// signingDomain separates attestation signatures from anything else the key might sign.
+const signingDomain = "checkpointz-attestation-v1"
+
+var (
+	// ErrUnexpectedKey is returned when a statement was signed by a key other than the trusted one.
+	ErrUnexpectedKey = errors.New("statement was not signed by the trusted key")
+	// ErrInvalidSignature is returned when a statement's signature does not match its contents.
+	ErrInvalidSignature = errors.New("invalid signature")
+)
+
+// Checkpoint is the statement that a checkpoint is being served.
+type Checkpoint struct {
+	Epoch                 phase0.Epoch `json:"epoch,string"`
+	BlockRoot             phase0.Root  `json:"block_root"`
+	StateRoot             phase0.Root  `json:"state_root"`
+	GenesisValidatorsRoot phase0.Root  `json:"genesis_validators_root"`
+}
+
+// SigningRoot returns the digest that is signed for the checkpoint.
+func (c *Checkpoint) SigningRoot() [32]byte {
+	msg := make([]byte, 0, len(signingDomain)+8+3*len(phase0.Root{}))
+
+	msg = append(msg, signingDomain...)
+	msg = append(msg, c.GenesisValidatorsRoot[:]...)
+	msg = binary.LittleEndian.AppendUint64(msg, uint64(c.Epoch))
+	msg = append(msg, c.BlockRoot[:]...)
+	msg = append(msg, c.StateRoot[:]...)
+
+	return sha256.Sum256(msg)
+}
+
+// SignedCheckpoint is a checkpoint signed by an operator key.
+type SignedCheckpoint struct {
+	Message   Checkpoint `json:"message"`
+	PublicKey string     `json:"public_key"`
+	Signature string     `json:"signature"`
+}
+
+// Verify checks that signed was signed by trusted and that its signature matches its message.
+func Verify(signed *SignedCheckpoint, trusted ed25519.PublicKey) error {
+	if signed == nil {
+		return errors.New("signed checkpoint is nil")
+	}
+
+	publicKey, err := ParsePublicKey(signed.PublicKey)
+	if err != nil {
+		return err
+	}
+
+	if !publicKey.Equal(trusted) {
+		return ErrUnexpectedKey
+	}
+
+	signature, err := decodeHex(signed.Signature)
+	if err != nil {
+		return fmt.Errorf("invalid signature encoding: %w", err)
+	}
+
+	root := signed.Message.SigningRoot()
+
+	if !ed25519.Verify(publicKey, root[:], signature) {
+		return ErrInvalidSignature
+	}
+
+	return nil
+}
+
+// ParsePublicKey parses a hex encoded ed25519 public key, with or without a 0x prefix.
+func ParsePublicKey(s string) (ed25519.PublicKey, error) {
+	b, err := decodeHex(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid public key encoding: %w", err)
+	}
+
+	if len(b) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("invalid public key length: %d", len(b))
+	}
+
+	return ed25519.PublicKey(b), nil
+}
+
+func decodeHex(s string) ([]byte, error) {
+	return hex.DecodeString(strings.TrimPrefix(strings.TrimSpace(s), "0x"))
+}
+
+func encodeHex(b []byte) string {
+	return fmt.Sprintf("%#x", b)
+}
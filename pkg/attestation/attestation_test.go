@@ -0,0 +1,143 @@
+package attestation
+
+import (
+	"crypto/ed25519"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestSigner(t *testing.T, seed byte) *Signer {
+	t.Helper()
+
+	s := make([]byte, ed25519.SeedSize)
+	for i := range s {
+		s[i] = seed
+	}
+
+	return NewSigner(ed25519.NewKeyFromSeed(s))
+}
+
+func testCheckpoint(epoch phase0.Epoch) Checkpoint {
+	return Checkpoint{
+		Epoch:                 epoch,
+		BlockRoot:             phase0.Root{0x01},
+		StateRoot:             phase0.Root{0x02},
+		GenesisValidatorsRoot: phase0.Root{0x03},
+	}
+}
+
+func TestSignAndVerify(t *testing.T) {
+	signer := newTestSigner(t, 1)
+
+	signed := signer.Sign(testCheckpoint(10))
+
+	require.NoError(t, Verify(signed, signer.PublicKey()))
+
+	// Statements survive being published as JSON.
+	data, err := json.Marshal(signed)
+	require.NoError(t, err)
+
+	decoded := &SignedCheckpoint{}
+	require.NoError(t, json.Unmarshal(data, decoded))
+
+	assert.Equal(t, signed, decoded)
+	require.NoError(t, Verify(decoded, signer.PublicKey()))
+}
+
+func TestVerifyRejectsTamperedCheckpoint(t *testing.T) {
+	signer := newTestSigner(t, 1)
+
+	tests := map[string]func(c *Checkpoint){
+		"epoch":                   func(c *Checkpoint) { c.Epoch++ },
+		"block_root":              func(c *Checkpoint) { c.BlockRoot[0]++ },
+		"state_root":              func(c *Checkpoint) { c.StateRoot[0]++ },
+		"genesis_validators_root": func(c *Checkpoint) { c.GenesisValidatorsRoot[0]++ },
+	}
+
+	for name, tamper := range tests {
+		t.Run(name, func(t *testing.T) {
+			signed := signer.Sign(testCheckpoint(10))
+
+			tamper(&signed.Message)
+
+			assert.ErrorIs(t, Verify(signed, signer.PublicKey()), ErrInvalidSignature)
+		})
+	}
+}
+
+func TestVerifyRejectsUntrustedKey(t *testing.T) {
+	signed := newTestSigner(t, 1).Sign(testCheckpoint(10))
+
+	assert.ErrorIs(t, Verify(signed, newTestSigner(t, 2).PublicKey()), ErrUnexpectedKey)
+}
+
+func TestVerifyRejectsSubstitutedKey(t *testing.T) {
+	trusted := newTestSigner(t, 1)
+	signed := newTestSigner(t, 2).Sign(testCheckpoint(10))
+
+	// Claiming the trusted key doesn't help without its signature.
+	signed.PublicKey = encodeHex(trusted.PublicKey())
+
+	assert.ErrorIs(t, Verify(signed, trusted.PublicKey()), ErrInvalidSignature)
+}
+
+func TestLoadSigner(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "key")
+
+	require.NoError(t, os.WriteFile(path, []byte("0101010101010101010101010101010101010101010101010101010101010101\n"), 0o600))
+
+	signer, err := LoadSigner(path)
+	require.NoError(t, err)
+
+	assert.Equal(t, newTestSigner(t, 1).PublicKey(), signer.PublicKey())
+
+	require.NoError(t, os.WriteFile(path, []byte("0x0101"), 0o600))
+
+	_, err = LoadSigner(path)
+	assert.Error(t, err)
+}
+
+func TestParsePublicKey(t *testing.T) {
+	signer := newTestSigner(t, 1)
+
+	key, err := ParsePublicKey(encodeHex(signer.PublicKey()))
+	require.NoError(t, err)
+	assert.Equal(t, signer.PublicKey(), key)
+
+	_, err = ParsePublicKey("0x01")
+	assert.Error(t, err)
+
+	_, err = ParsePublicKey("zz")
+	assert.Error(t, err)
+}
+
+func TestAttestorHistory(t *testing.T) {
+	attestor := NewAttestor(newTestSigner(t, 1), 2)
+
+	assert.Nil(t, attestor.Latest())
+	assert.Empty(t, attestor.History())
+
+	attestor.Attest(testCheckpoint(1))
+	attestor.Attest(testCheckpoint(2))
+	attestor.Attest(testCheckpoint(3))
+
+	history := attestor.History()
+	require.Len(t, history, 2)
+	assert.Equal(t, phase0.Epoch(2), history[0].Message.Epoch)
+	assert.Equal(t, phase0.Epoch(3), history[1].Message.Epoch)
+	assert.Equal(t, phase0.Epoch(3), attestor.Latest().Message.Epoch)
+
+	// Re-attesting an epoch makes it the latest rather than duplicating it.
+	attestor.Attest(testCheckpoint(2))
+
+	history = attestor.History()
+	require.Len(t, history, 2)
+	assert.Equal(t, phase0.Epoch(3), history[0].Message.Epoch)
+	assert.Equal(t, phase0.Epoch(2), history[1].Message.Epoch)
+}
@@ -0,0 +1,73 @@
+package attestation
+
+import (
+	"crypto/ed25519"
+	"sync"
+)
+
+// Attestor signs served checkpoints and keeps the most recent signed checkpoints.
+type Attestor struct {
+	signer *Signer
+
+	mu       sync.Mutex
+	maxItems int
+	history  []*SignedCheckpoint
+}
+
+// NewAttestor returns an Attestor that signs with signer and keeps up to maxItems signed checkpoints.
+func NewAttestor(signer *Signer, maxItems int) *Attestor {
+	return &Attestor{
+		signer:   signer,
+		maxItems: maxItems,
+	}
+}
+
+// PublicKey returns the public key the attestor's statements are verified against.
+func (a *Attestor) PublicKey() ed25519.PublicKey {
+	return a.signer.PublicKey()
+}
+
+// Attest signs the checkpoint and records it, replacing any previous statement for the same epoch and
+// evicting the oldest once full.
+func (a *Attestor) Attest(checkpoint Checkpoint) *SignedCheckpoint {
+	signed := a.signer.Sign(checkpoint)
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	for i, existing := range a.history {
+		if existing.Message.Epoch == checkpoint.Epoch {
+			a.history = append(a.history[:i], a.history[i+1:]...)
+
+			break
+		}
+	}
+
+	a.history = append(a.history, signed)
+
+	if over := len(a.history) - a.maxItems; over > 0 {
+		a.history = append([]*SignedCheckpoint(nil), a.history[over:]...)
+	}
+
+	return signed
+}
+
+// Latest returns the most recently signed checkpoint, or nil if nothing has been signed yet.
+func (a *Attestor) Latest() *SignedCheckpoint {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if len(a.history) == 0 {
+		return nil
+	}
+
+	return a.history[len(a.history)-1]
+}
+
+// History returns every signed checkpoint held, oldest first.
+func (a *Attestor) History() []*SignedCheckpoint {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	return append([]*SignedCheckpoint(nil), a.history...)
+}
@@ -0,0 +1,66 @@
+package attestation_test
+
+import (
+	"crypto/ed25519"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/ethpandaops/checkpointz/pkg/attestation"
+	"github.com/ethpandaops/checkpointz/pkg/beacon"
+	"github.com/ethpandaops/checkpointz/pkg/checkpointz"
+	"github.com/ethpandaops/checkpointz/pkg/checkpointz/checkpointztest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestE2EPublishesAttestations(t *testing.T) {
+	chain := checkpointztest.NewChain(t)
+
+	keyFile := filepath.Join(t.TempDir(), "attestation.key")
+	require.NoError(t, os.WriteFile(keyFile, []byte(strings.Repeat("ab", ed25519.SeedSize)), 0o600))
+
+	signer, err := attestation.LoadSigner(keyFile)
+	require.NoError(t, err)
+
+	server := checkpointztest.Start(t, beacon.OperatingModeLight, func(config *checkpointz.Config) {
+		config.GlobalConfig.Attestations.KeyFile = keyFile
+	}, checkpointztest.Upstream{Node: checkpointztest.NewNode(chain), DataProvider: true})
+
+	server.RequireServes(chain.Root(checkpointztest.FinalizedEpoch))
+
+	var body struct {
+		Data struct {
+			PublicKey string                        `json:"public_key"`
+			Current   *attestation.SignedCheckpoint `json:"current"`
+		} `json:"data"`
+	}
+
+	server.Eventually(func() bool {
+		rsp, data := server.Get("/checkpointz/v1/attestations", "application/json")
+		if rsp.StatusCode != http.StatusOK {
+			return false
+		}
+
+		require.NoError(t, json.Unmarshal(data, &body))
+
+		return body.Data.Current != nil
+	})
+
+	assert.Equal(t, fmt.Sprintf("%#x", []byte(signer.PublicKey())), body.Data.PublicKey)
+	require.NoError(t, attestation.Verify(body.Data.Current, signer.PublicKey()))
+
+	expected, _ := chain.State(checkpointztest.FinalizedEpoch)
+	stateRoot, err := expected.Deneb.HashTreeRoot()
+	require.NoError(t, err)
+
+	assert.Equal(t, phase0.Epoch(checkpointztest.FinalizedEpoch), body.Data.Current.Message.Epoch)
+	assert.Equal(t, chain.Root(checkpointztest.FinalizedEpoch), body.Data.Current.Message.BlockRoot)
+	assert.Equal(t, phase0.Root(stateRoot), body.Data.Current.Message.StateRoot)
+	assert.Equal(t, expected.Deneb.GenesisValidatorsRoot, body.Data.Current.Message.GenesisValidatorsRoot)
+}
@@ -0,0 +1,53 @@
+package attestation
+
+import (
+	"crypto/ed25519"
+	"fmt"
+	"os"
+)
+
+// Signer signs checkpoints with an operator key.
+type Signer struct {
+	key ed25519.PrivateKey
+}
+
+// NewSigner returns a Signer for the given key.
+func NewSigner(key ed25519.PrivateKey) *Signer {
+	return &Signer{key: key}
+}
+
+// LoadSigner returns a Signer for the key in the file at path. The file holds a hex encoded 32 byte ed25519
+// seed, e.g. as generated by `openssl rand -hex 32`.
+func LoadSigner(path string) (*Signer, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read attestation key: %w", err)
+	}
+
+	seed, err := decodeHex(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("invalid attestation key encoding: %w", err)
+	}
+
+	if len(seed) != ed25519.SeedSize {
+		return nil, fmt.Errorf("invalid attestation key length: %d", len(seed))
+	}
+
+	return NewSigner(ed25519.NewKeyFromSeed(seed)), nil
+}
+
+// PublicKey returns the public key statements are verified against.
+func (s *Signer) PublicKey() ed25519.PublicKey {
+	return s.key.Public().(ed25519.PublicKey)
+}
+
+// Sign signs the checkpoint.
+func (s *Signer) Sign(checkpoint Checkpoint) *SignedCheckpoint {
+	root := checkpoint.SigningRoot()
+
+	return &SignedCheckpoint{
+		Message:   checkpoint,
+		PublicKey: encodeHex(s.PublicKey()),
+		Signature: encodeHex(ed25519.Sign(s.key, root[:])),
+	}
+}
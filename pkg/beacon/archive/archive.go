@@ -0,0 +1,299 @@
+// Package archive reads blocks and states from a local directory of files, for use as a read-only upstream.
+// The directory may hold:
+//   - era files (*.era), as written by the era command
+//   - block_<slot>.ssz and state_<slot>.ssz files
+//   - a checkpoint bundle written by the fetch command (manifest.json, block.ssz and state.ssz)
+//
+// Subdirectories are not read.
+package archive
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/attestantio/go-eth2-client/spec"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/ethpandaops/beacon/pkg/beacon/state"
+	"github.com/ethpandaops/checkpointz/pkg/beacon/ssz"
+	"github.com/ethpandaops/checkpointz/pkg/era"
+	"github.com/sirupsen/logrus"
+)
+
+// ErrNotFound is returned when the archive doesn't hold the requested block or state.
+var ErrNotFound = errors.New("not found in archive")
+
+// Archive is a read-only source of blocks and states backed by a directory. The directory is indexed on first
+// use and again whenever files are added to or removed from it.
+type Archive struct {
+	log     logrus.FieldLogger
+	config  Config
+	encoder *ssz.Encoder
+
+	mu      sync.Mutex
+	modTime time.Time
+	blocks  map[phase0.Slot]location
+	states  map[phase0.Slot]location
+}
+
+// location is where a block or state is stored.
+type location struct {
+	path string
+	// offset is the offset of the entry in an era file, or -1 for SSZ files.
+	offset int64
+	// version is the fork version of the contents, if the directory records it.
+	version string
+}
+
+// New returns a new Archive.
+func New(log logrus.FieldLogger, config Config, encoder *ssz.Encoder) *Archive {
+	return &Archive{
+		log:     log.WithField("archive", config.Name),
+		config:  config,
+		encoder: encoder,
+	}
+}
+
+// Name returns the archive's name.
+func (a *Archive) Name() string {
+	return a.config.Name
+}
+
+// Refresh indexes the directory if it has changed since it was last indexed, and returns how many blocks and
+// states it holds.
+func (a *Archive) Refresh() (blocks, states int, err error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if err := a.refresh(); err != nil {
+		return 0, 0, err
+	}
+
+	return len(a.blocks), len(a.states), nil
+}
+
+// Block returns the block at slot.
+func (a *Archive) Block(ctx context.Context, sp *state.Spec, slot phase0.Slot) (*spec.VersionedSignedBeaconBlock, error) {
+	loc, err := a.lookup(slot, func() map[phase0.Slot]location { return a.blocks })
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := read(loc, era.TypeCompressedSignedBeaconBlock)
+	if err != nil {
+		return nil, err
+	}
+
+	version, err := loc.versionAt(sp, slot)
+	if err != nil {
+		return nil, err
+	}
+
+	return a.encoder.DecodeBlockSSZ(ctx, version, data)
+}
+
+// State returns the beacon state at slot.
+func (a *Archive) State(ctx context.Context, sp *state.Spec, slot phase0.Slot) (*spec.VersionedBeaconState, error) {
+	loc, err := a.lookup(slot, func() map[phase0.Slot]location { return a.states })
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := read(loc, era.TypeCompressedBeaconState)
+	if err != nil {
+		return nil, err
+	}
+
+	version, err := loc.versionAt(sp, slot)
+	if err != nil {
+		return nil, err
+	}
+
+	return a.encoder.DecodeStateSSZ(ctx, version, data)
+}
+
+func (a *Archive) lookup(slot phase0.Slot, index func() map[phase0.Slot]location) (location, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if err := a.refresh(); err != nil {
+		return location{}, err
+	}
+
+	loc, ok := index()[slot]
+	if !ok {
+		return location{}, fmt.Errorf("slot %d: %w", slot, ErrNotFound)
+	}
+
+	return loc, nil
+}
+
+// refresh re-indexes the directory if its modification time has changed. a.mu must be held.
+func (a *Archive) refresh() error {
+	info, err := os.Stat(a.config.Path)
+	if err != nil {
+		return err
+	}
+
+	if a.blocks != nil && info.ModTime().Equal(a.modTime) {
+		return nil
+	}
+
+	entries, err := os.ReadDir(a.config.Path)
+	if err != nil {
+		return err
+	}
+
+	blocks := make(map[phase0.Slot]location)
+	states := make(map[phase0.Slot]location)
+
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+
+		path := filepath.Join(a.config.Path, entry.Name())
+
+		if err := index(path, entry.Name(), blocks, states); err != nil {
+			a.log.WithError(err).WithField("file", entry.Name()).Warn("Skipping unreadable archive file")
+		}
+	}
+
+	a.modTime = info.ModTime()
+	a.blocks = blocks
+	a.states = states
+
+	a.log.WithField("blocks", len(blocks)).WithField("states", len(states)).Debug("Indexed archive")
+
+	return nil
+}
+
+// index adds the blocks and states held by the file at path to the given indices.
+func index(path, name string, blocks, states map[phase0.Slot]location) error {
+	switch {
+	case filepath.Ext(name) == ".era":
+		return indexEra(path, blocks, states)
+	case name == "manifest.json":
+		return indexBundle(path, blocks, states)
+	case filepath.Ext(name) == ".ssz":
+		kind, slotStr, ok := strings.Cut(strings.TrimSuffix(name, ".ssz"), "_")
+		if !ok {
+			return nil
+		}
+
+		slot, err := strconv.ParseUint(slotStr, 10, 64)
+		if err != nil {
+			return nil //nolint:nilerr // not a file we read
+		}
+
+		switch kind {
+		case "block":
+			blocks[phase0.Slot(slot)] = location{path: path, offset: -1}
+		case "state":
+			states[phase0.Slot(slot)] = location{path: path, offset: -1}
+		}
+	}
+
+	return nil
+}
+
+func indexEra(path string, blocks, states map[phase0.Slot]location) error {
+	f, err := os.Open(path) //nolint:gosec // path is within the configured archive directory
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+
+	eraIndex, err := era.ReadIndex(f, info.Size())
+	if err != nil {
+		return err
+	}
+
+	for slot, offset := range eraIndex.Blocks {
+		blocks[slot] = location{path: path, offset: offset}
+	}
+
+	states[eraIndex.StateSlot] = location{path: path, offset: eraIndex.State}
+
+	return nil
+}
+
+func indexBundle(path string, blocks, states map[phase0.Slot]location) error {
+	data, err := os.ReadFile(path) //nolint:gosec // path is within the configured archive directory
+	if err != nil {
+		return err
+	}
+
+	// The parts of the manifest written by pkg/bundle that locate its files.
+	manifest := &struct {
+		Version string      `json:"version"`
+		Slot    phase0.Slot `json:"slot,string"`
+		Files   []struct {
+			Name string `json:"name"`
+		} `json:"files"`
+	}{}
+	if err := json.Unmarshal(data, manifest); err != nil {
+		return err
+	}
+
+	dir := filepath.Dir(path)
+
+	for _, file := range manifest.Files {
+		loc := location{path: filepath.Join(dir, file.Name), offset: -1, version: manifest.Version}
+
+		switch file.Name {
+		case "block.ssz":
+			blocks[manifest.Slot] = loc
+		case "state.ssz":
+			states[manifest.Slot] = loc
+		}
+	}
+
+	return nil
+}
+
+// read returns the SSZ encoded contents of loc.
+func read(loc location, typ era.EntryType) ([]byte, error) {
+	if loc.offset < 0 {
+		return os.ReadFile(loc.path)
+	}
+
+	f, err := os.Open(loc.path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return era.ReadCompressed(f, loc.offset, typ)
+}
+
+// versionAt returns the fork version of the contents, which is the fork active at slot unless the directory
+// recorded it.
+func (l location) versionAt(sp *state.Spec, slot phase0.Slot) (spec.DataVersion, error) {
+	if l.version != "" {
+		return spec.DataVersionFromString(l.version)
+	}
+
+	if sp == nil || sp.SlotsPerEpoch == 0 {
+		return 0, errors.New("chain spec is unknown")
+	}
+
+	fork, err := sp.ForkEpochs.CurrentFork(phase0.Epoch(slot / sp.SlotsPerEpoch))
+	if err != nil {
+		return 0, err
+	}
+
+	return fork.Name, nil
+}
@@ -0,0 +1,163 @@
+package archive
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/ethpandaops/beacon/pkg/beacon/state"
+	"github.com/ethpandaops/checkpointz/pkg/beacon/beacontest"
+	"github.com/ethpandaops/checkpointz/pkg/beacon/ssz"
+	"github.com/ethpandaops/checkpointz/pkg/era"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestSpec(chain *beacontest.Chain) *state.Spec {
+	data := make(map[string]interface{})
+	for k, v := range chain.Spec() {
+		data[k] = v
+	}
+
+	sp := state.NewSpec(data)
+
+	return &sp
+}
+
+func writeBlock(t *testing.T, path string, chain *beacontest.Chain, epoch phase0.Epoch) {
+	t.Helper()
+
+	block, _, ok := chain.Block(epoch)
+	require.True(t, ok)
+
+	data, err := block.Deneb.MarshalSSZ()
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(path, data, 0o600))
+}
+
+func writeState(t *testing.T, path string, chain *beacontest.Chain, epoch phase0.Epoch) {
+	t.Helper()
+
+	beaconState, ok := chain.State(epoch)
+	require.True(t, ok)
+
+	data, err := beaconState.Deneb.MarshalSSZ()
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(path, data, 0o600))
+}
+
+func requireBlock(t *testing.T, a *Archive, sp *state.Spec, chain *beacontest.Chain, epoch phase0.Epoch) {
+	t.Helper()
+
+	block, err := a.Block(context.Background(), sp, phase0.Slot(uint64(epoch)*beacontest.SlotsPerEpoch))
+	require.NoError(t, err)
+
+	root, err := ssz.NewEncoder(false).GetBlockRoot(block)
+	require.NoError(t, err)
+	assert.Equal(t, chain.Root(epoch), root)
+}
+
+func requireState(t *testing.T, a *Archive, sp *state.Spec, chain *beacontest.Chain, epoch phase0.Epoch) {
+	t.Helper()
+
+	beaconState, err := a.State(context.Background(), sp, phase0.Slot(uint64(epoch)*beacontest.SlotsPerEpoch))
+	require.NoError(t, err)
+
+	expected, _ := chain.State(epoch)
+	assert.Equal(t, expected.Deneb.Slot, beaconState.Deneb.Slot)
+
+	root, err := ssz.NewEncoder(false).GetStateRoot(context.Background(), beaconState)
+	require.NoError(t, err)
+
+	expectedRoot, err := expected.Deneb.HashTreeRoot()
+	require.NoError(t, err)
+	assert.Equal(t, phase0.Root(expectedRoot), root)
+}
+
+func TestArchive(t *testing.T) {
+	chain, err := beacontest.NewChain(6)
+	require.NoError(t, err)
+
+	sp := newTestSpec(chain)
+	dir := t.TempDir()
+
+	// An era covering epochs 0 and 1, with the state at epoch 2.
+	e := &era.Era{Number: 1, SlotsPerHistoricalRoot: 2 * beacontest.SlotsPerEpoch}
+
+	for epoch := phase0.Epoch(0); epoch < 2; epoch++ {
+		block, _, _ := chain.Block(epoch)
+
+		data, err := block.Deneb.MarshalSSZ()
+		require.NoError(t, err)
+
+		e.Blocks = append(e.Blocks, era.Block{Slot: phase0.Slot(uint64(epoch) * beacontest.SlotsPerEpoch), SSZ: data})
+	}
+
+	beaconState, _ := chain.State(2)
+	e.State, err = beaconState.Deneb.MarshalSSZ()
+	require.NoError(t, err)
+
+	f, err := os.Create(filepath.Join(dir, "beacontest-00001-00000000.era"))
+	require.NoError(t, err)
+	require.NoError(t, e.Write(f))
+	require.NoError(t, f.Close())
+
+	// Plain SSZ files named by slot.
+	writeBlock(t, filepath.Join(dir, fmt.Sprintf("block_%d.ssz", 3*beacontest.SlotsPerEpoch)), chain, 3)
+	writeState(t, filepath.Join(dir, fmt.Sprintf("state_%d.ssz", 3*beacontest.SlotsPerEpoch)), chain, 3)
+
+	// A bundle written by the fetch command.
+	writeBlock(t, filepath.Join(dir, "block.ssz"), chain, 4)
+	writeState(t, filepath.Join(dir, "state.ssz"), chain, 4)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "manifest.json"), fmt.Appendf(nil,
+		`{"version":"deneb","slot":"%d","files":[{"name":"block.ssz"},{"name":"state.ssz"}]}`, 4*beacontest.SlotsPerEpoch,
+	), 0o600))
+
+	// Files that aren't blocks or states are ignored.
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "README"), []byte("hello"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "broken.era"), []byte("nope"), 0o600))
+
+	a := New(logrus.New(), Config{Name: "archive", Path: dir}, ssz.NewEncoder(false))
+
+	blocks, states, err := a.Refresh()
+	require.NoError(t, err)
+	assert.Equal(t, 4, blocks)
+	assert.Equal(t, 3, states)
+
+	requireBlock(t, a, sp, chain, 0)
+	requireBlock(t, a, sp, chain, 1)
+	requireState(t, a, sp, chain, 2)
+	requireBlock(t, a, sp, chain, 3)
+	requireState(t, a, sp, chain, 3)
+	requireBlock(t, a, sp, chain, 4)
+	requireState(t, a, sp, chain, 4)
+
+	_, err = a.Block(context.Background(), sp, phase0.Slot(5*beacontest.SlotsPerEpoch))
+	require.ErrorIs(t, err, ErrNotFound)
+
+	_, err = a.State(context.Background(), sp, 0)
+	require.ErrorIs(t, err, ErrNotFound)
+
+	// Files added later are picked up.
+	time.Sleep(10 * time.Millisecond)
+	writeBlock(t, filepath.Join(dir, fmt.Sprintf("block_%d.ssz", 5*beacontest.SlotsPerEpoch)), chain, 5)
+	require.NoError(t, os.Chtimes(dir, time.Now(), time.Now()))
+
+	requireBlock(t, a, sp, chain, 5)
+}
+
+func TestArchiveMissingDirectory(t *testing.T) {
+	a := New(logrus.New(), Config{Name: "archive", Path: filepath.Join(t.TempDir(), "missing")}, ssz.NewEncoder(false))
+
+	_, _, err := a.Refresh()
+	require.Error(t, err)
+
+	_, err = a.Block(context.Background(), nil, 0)
+	require.Error(t, err)
+	assert.NotErrorIs(t, err, ErrNotFound)
+}
@@ -0,0 +1,23 @@
+package archive
+
+import "errors"
+
+// Config configures a local directory of era or SSZ files that blocks and states are read from.
+type Config struct {
+	// Name identifies the archive in provenance and logs.
+	Name string `yaml:"name"`
+	// Path is the directory holding the files.
+	Path string `yaml:"path"`
+}
+
+func (c *Config) Validate() error {
+	if c.Name == "" {
+		return errors.New("name is required")
+	}
+
+	if c.Path == "" {
+		return errors.New("path is required")
+	}
+
+	return nil
+}
@@ -0,0 +1,98 @@
+package archive_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/ethpandaops/checkpointz/pkg/beacon"
+	"github.com/ethpandaops/checkpointz/pkg/beacon/archive"
+	"github.com/ethpandaops/checkpointz/pkg/beacon/beacontest"
+	"github.com/ethpandaops/checkpointz/pkg/checkpointz"
+	"github.com/ethpandaops/checkpointz/pkg/checkpointz/checkpointztest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestE2EReadsFromArchives(t *testing.T) {
+	const finalizedEpoch = checkpointztest.FinalizedEpoch
+
+	chain := checkpointztest.NewChain(t)
+	node := checkpointztest.NewNode(chain)
+	dir := t.TempDir()
+
+	finalizedState, _ := chain.State(finalizedEpoch)
+	data, err := finalizedState.Deneb.MarshalSSZ()
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, fmt.Sprintf("state_%d.ssz", finalizedEpoch*beacontest.SlotsPerEpoch)), data, 0o600))
+
+	historicalBlock, _, _ := chain.Block(finalizedEpoch - 1)
+	data, err = historicalBlock.Deneb.MarshalSSZ()
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, fmt.Sprintf("block_%d.ssz", (finalizedEpoch-1)*beacontest.SlotsPerEpoch)), data, 0o600))
+
+	// A block from another chain is ignored.
+	fork, err := chain.Fork(finalizedEpoch - 3)
+	require.NoError(t, err)
+
+	forkedBlock, _, _ := fork.Block(finalizedEpoch - 2)
+	data, err = forkedBlock.Deneb.MarshalSSZ()
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, fmt.Sprintf("block_%d.ssz", (finalizedEpoch-2)*beacontest.SlotsPerEpoch)), data, 0o600))
+
+	server := checkpointztest.Start(t, beacon.OperatingModeFull, func(config *checkpointz.Config) {
+		config.BeaconConfig.Archives = []archive.Config{{Name: "archive", Path: dir}}
+	}, checkpointztest.Upstream{Node: node, DataProvider: true})
+
+	server.RequireServes(chain.Root(finalizedEpoch))
+
+	provenanceSources := func(root phase0.Root) (string, string) {
+		rsp, body := server.Get(fmt.Sprintf("/checkpointz/v1/provenance/%#x", root), "application/json")
+		if rsp.StatusCode != http.StatusOK {
+			return "", ""
+		}
+
+		var provenance struct {
+			Data struct {
+				Block struct {
+					Source string `json:"source"`
+				} `json:"block"`
+				State *struct {
+					Source string `json:"source"`
+				} `json:"state"`
+			} `json:"data"`
+		}
+
+		require.NoError(t, json.Unmarshal(body, &provenance))
+
+		if provenance.Data.State == nil {
+			return provenance.Data.Block.Source, ""
+		}
+
+		return provenance.Data.Block.Source, provenance.Data.State.Source
+	}
+
+	// The finalized block comes from the upstream, but its state from the archive.
+	blockSource, stateSource := provenanceSources(chain.Root(finalizedEpoch))
+	assert.Equal(t, "upstream-0", blockSource)
+	assert.Equal(t, "archive", stateSource)
+	assert.Zero(t, node.Requests(fmt.Sprintf("/eth/v2/debug/beacon/states/%d", finalizedEpoch*beacontest.SlotsPerEpoch)))
+
+	// Historical blocks are backfilled from the archive where it has them, and their roots match the
+	// serving state's.
+	server.Eventually(func() bool {
+		blockSource, _ := provenanceSources(chain.Root(finalizedEpoch - 1))
+
+		return blockSource == "archive"
+	})
+
+	server.Eventually(func() bool {
+		blockSource, _ := provenanceSources(chain.Root(finalizedEpoch - 2))
+
+		return blockSource == "upstream-0"
+	})
+}
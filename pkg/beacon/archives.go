@@ -0,0 +1,246 @@
+package beacon
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/attestantio/go-eth2-client/spec"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/ethpandaops/beacon/pkg/beacon/state"
+	"github.com/ethpandaops/checkpointz/pkg/beacon/archive"
+	"github.com/ethpandaops/checkpointz/pkg/eth"
+	"github.com/ethpandaops/checkpointz/pkg/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// archiveBlock returns the block at slot from the first archive that holds it, and that archive's name.
+func (d *Default) archiveBlock(ctx context.Context, slot phase0.Slot) (*spec.VersionedSignedBeaconBlock, string, error) {
+	sp, err := d.Spec()
+	if err != nil {
+		return nil, "", err
+	}
+
+	for _, a := range d.archives {
+		block, err := a.Block(ctx, sp, slot)
+		if err == nil {
+			err = checkArchivedFork(sp, slot, block.Version)
+		}
+
+		if err == nil {
+			return block, a.Name(), nil
+		}
+
+		if !errors.Is(err, archive.ErrNotFound) {
+			d.log.WithError(err).WithField("archive", a.Name()).WithField("slot", slot).Warn("Failed to read block from archive")
+		}
+	}
+
+	return nil, "", archive.ErrNotFound
+}
+
+// archiveState returns the beacon state at slot from the first archive that holds it, and that archive's name.
+func (d *Default) archiveState(ctx context.Context, slot phase0.Slot) (*spec.VersionedBeaconState, string, error) {
+	sp, err := d.Spec()
+	if err != nil {
+		return nil, "", err
+	}
+
+	for _, a := range d.archives {
+		beaconState, err := a.State(ctx, sp, slot)
+		if err == nil {
+			err = d.checkArchivedState(ctx, sp, slot, beaconState)
+		}
+
+		if err == nil {
+			return beaconState, a.Name(), nil
+		}
+
+		if !errors.Is(err, archive.ErrNotFound) {
+			d.log.WithError(err).WithField("archive", a.Name()).WithField("slot", slot).Warn("Failed to read state from archive")
+		}
+	}
+
+	return nil, "", archive.ErrNotFound
+}
+
+// archiveStateWithRoot returns the beacon state at slot from an archive, along with the archive's name, if an
+// archive holds it and it has the expected root. Otherwise the state is nil.
+func (d *Default) archiveStateWithRoot(ctx context.Context, slot phase0.Slot, expected phase0.Root) (*spec.VersionedBeaconState, string) {
+	beaconState, source, err := d.archiveState(ctx, slot)
+	if err != nil {
+		return nil, ""
+	}
+
+	root, err := d.sszEncoder.GetStateRoot(ctx, beaconState)
+	if err != nil || root != expected {
+		d.log.
+			WithField("archive", source).
+			WithField("slot", slot).
+			WithField("root", fmt.Sprintf("%#x", root)).
+			WithField("expected", fmt.Sprintf("%#x", expected)).
+			Warn("Ignoring archived state with an unexpected root")
+
+		return nil, ""
+	}
+
+	return beaconState, source
+}
+
+// archiveBlockWithRoot returns the block at slot from an archive, along with the archive's name, if an archive
+// holds it and its root matches the one recorded in the serving state or reported by upstream. If neither is
+// available to check it against, the archived block is only returned without an upstream to download it from
+// instead, and verified is false. Otherwise the block is nil.
+func (d *Default) archiveBlockWithRoot(ctx context.Context, slot phase0.Slot, upstream *Node) (_ *spec.VersionedSignedBeaconBlock, source string, verified bool) {
+	block, source, err := d.archiveBlock(ctx, slot)
+	if err != nil {
+		return nil, "", false
+	}
+
+	expected, ok := d.servingBlockRoot(slot)
+	if !ok && upstream != nil {
+		expected, err = d.fetchUpstreamBlockRoot(ctx, upstream, eth.SlotAsString(slot))
+		ok = err == nil
+	}
+
+	if !ok {
+		if upstream != nil {
+			return nil, "", false
+		}
+
+		return block, source, false
+	}
+
+	root, err := d.sszEncoder.GetBlockRoot(block)
+	if err != nil || root != expected {
+		d.log.
+			WithField("archive", source).
+			WithField("slot", slot).
+			WithField("root", fmt.Sprintf("%#x", root)).
+			WithField("expected", fmt.Sprintf("%#x", expected)).
+			Warn("Ignoring archived block with an unexpected root")
+
+		return nil, "", false
+	}
+
+	return block, source, true
+}
+
+// servingBlockRoot returns the root of the block at slot recorded in the serving checkpoint's state, if the
+// state is held and slot is recent enough to be recorded in it.
+func (d *Default) servingBlockRoot(slot phase0.Slot) (phase0.Root, bool) {
+	serving := d.servingBundle.Load()
+	if serving == nil || serving.Finalized == nil {
+		return phase0.Root{}, false
+	}
+
+	block, err := d.blocks.GetByRoot(serving.Finalized.Root)
+	if err != nil || block == nil {
+		return phase0.Root{}, false
+	}
+
+	stateRoot, err := block.StateRoot()
+	if err != nil {
+		return phase0.Root{}, false
+	}
+
+	beaconState, err := d.states.GetByStateRoot(stateRoot)
+	if err != nil || beaconState == nil {
+		return phase0.Root{}, false
+	}
+
+	stateSlot, err := beaconState.Slot()
+	if err != nil {
+		return phase0.Root{}, false
+	}
+
+	blockRoots, err := eth.BlockRootsFromState(beaconState)
+	if err != nil || len(blockRoots) == 0 {
+		return phase0.Root{}, false
+	}
+
+	if slot >= stateSlot || uint64(stateSlot-slot) > uint64(len(blockRoots)) {
+		return phase0.Root{}, false
+	}
+
+	return blockRoots[uint64(slot)%uint64(len(blockRoots))], true
+}
+
+// fetchUpstreamBlockRoot fetches the root of the block identified by blockID from upstream.
+func (d *Default) fetchUpstreamBlockRoot(ctx context.Context, upstream *Node, blockID string) (_ phase0.Root, err error) {
+	ctx, span := tracing.Start(ctx, "upstream.FetchBlockRoot", trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(
+		attribute.String("checkpointz.upstream", upstream.Config.Name),
+		attribute.String("eth.block_id", blockID),
+	))
+	defer func() { tracing.End(span, err) }()
+
+	root, err := upstream.Beacon.FetchBlockRoot(ctx, blockID)
+	if err != nil {
+		return phase0.Root{}, err
+	}
+
+	if root == nil {
+		return phase0.Root{}, errors.New("block root is nil")
+	}
+
+	return *root, nil
+}
+
+// checkArchivedFork returns an error unless data of the given version belongs at slot in the fork schedule.
+func checkArchivedFork(sp *state.Spec, slot phase0.Slot, version spec.DataVersion) error {
+	fork, err := sp.ForkEpochs.CurrentFork(phase0.Epoch(uint64(slot) / uint64(sp.SlotsPerEpoch)))
+	if err != nil {
+		return err
+	}
+
+	if fork.Name != version {
+		return fmt.Errorf("archived %s data at slot %d, where %s is scheduled", version, slot, fork.Name)
+	}
+
+	return nil
+}
+
+// checkArchivedState returns an error unless beaconState belongs to our chain: it must have the fork scheduled
+// at slot and our genesis validators root.
+func (d *Default) checkArchivedState(ctx context.Context, sp *state.Spec, slot phase0.Slot, beaconState *spec.VersionedBeaconState) error {
+	if err := checkArchivedFork(sp, slot, beaconState.Version); err != nil {
+		return err
+	}
+
+	// States may be needed before the genesis loop has fetched genesis.
+	if err := d.checkGenesisTime(ctx); err != nil {
+		return fmt.Errorf("genesis validators root is not known: %w", err)
+	}
+
+	genesis := d.genesis.Load()
+
+	_, genesisValidatorsRoot, err := eth.ForkFromState(beaconState)
+	if err != nil {
+		return err
+	}
+
+	if genesisValidatorsRoot != genesis.GenesisValidatorsRoot {
+		return fmt.Errorf("archived state has genesis validators root %#x, expected %#x", genesisValidatorsRoot, genesis.GenesisValidatorsRoot)
+	}
+
+	return nil
+}
+
+// indexArchives indexes every archive up front so that unreadable directories are reported at startup.
+func (d *Default) indexArchives() {
+	for _, a := range d.archives {
+		blocks, states, err := a.Refresh()
+		if err != nil {
+			d.log.WithError(err).WithField("archive", a.Name()).Error("Failed to index archive")
+
+			continue
+		}
+
+		d.log.
+			WithField("archive", a.Name()).
+			WithField("blocks", blocks).
+			WithField("states", states).
+			Info("Indexed archive")
+	}
+}
@@ -0,0 +1,326 @@
+// Package beacontest provides an in-process fake beacon node API for testing Checkpointz against scripted
+// upstream behaviour.
+package beacontest
+
+import (
+	"fmt"
+	"time"
+
+	v1 "github.com/attestantio/go-eth2-client/api/v1"
+	"github.com/attestantio/go-eth2-client/spec"
+	"github.com/attestantio/go-eth2-client/spec/altair"
+	"github.com/attestantio/go-eth2-client/spec/bellatrix"
+	"github.com/attestantio/go-eth2-client/spec/capella"
+	"github.com/attestantio/go-eth2-client/spec/deneb"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/ethpandaops/beacon/pkg/beacon/api/types"
+	"github.com/ethpandaops/checkpointz/pkg/beacon/verify"
+	"github.com/holiman/uint256"
+	blst "github.com/supranational/blst/bindings/go"
+)
+
+const (
+	// SlotsPerEpoch is the mainnet preset value used by the fake chain.
+	SlotsPerEpoch = 32
+	// SecondsPerSlot is the mainnet config value used by the fake chain.
+	SecondsPerSlot = 12
+
+	farFutureEpoch = "18446744073709551615"
+
+	slotsPerHistoricalRoot    = 8192
+	epochsPerHistoricalVector = 65536
+	epochsPerSlashingsVector  = 8192
+	syncCommitteeSize         = 512
+	blsSignatureDST           = "BLS_SIG_BLS12381G2_XMD:SHA-256_SSWU_RO_POP_"
+)
+
+var (
+	domainBeaconProposer = phase0.DomainType{0x00, 0x00, 0x00, 0x00}
+
+	capellaForkVersion = phase0.Version{0x03, 0x00, 0x00, 0x00}
+	denebForkVersion   = phase0.Version{0x04, 0x00, 0x00, 0x00}
+)
+
+// Chain is a deterministic, mainnet-preset Deneb chain with a signed block and a state at every epoch
+// boundary. It is shared by fake nodes that agree on history.
+type Chain struct {
+	genesisTime           time.Time
+	genesisValidatorsRoot phase0.Root
+
+	blocks []*spec.VersionedSignedBeaconBlock
+	roots  []phase0.Root
+	states []*spec.VersionedBeaconState
+}
+
+// NewChain returns a chain with epoch boundary blocks for epochs 0 to epochs inclusive. Genesis is far enough
+// in the past that every block is older than the wall clock head.
+func NewChain(epochs int) (*Chain, error) {
+	genesisTime := time.Now().Add(-time.Duration(epochs+2) * SlotsPerEpoch * SecondsPerSlot * time.Second).Truncate(time.Second)
+
+	return newChain(epochs, genesisTime, phase0.Root{0x01})
+}
+
+// Fork returns a chain that shares history with c up to and including epoch, and diverges after it.
+func (c *Chain) Fork(epoch phase0.Epoch) (*Chain, error) {
+	return newChain(len(c.blocks)-1, c.genesisTime, c.genesisValidatorsRoot, func(slot phase0.Slot, body *deneb.BeaconBlockBody) {
+		if slot > phase0.Slot(uint64(epoch)*SlotsPerEpoch) {
+			body.Graffiti = [32]byte{'f', 'o', 'r', 'k'}
+		}
+	})
+}
+
+func newChain(epochs int, genesisTime time.Time, genesisValidatorsRoot phase0.Root, mutators ...func(phase0.Slot, *deneb.BeaconBlockBody)) (*Chain, error) {
+	c := &Chain{
+		genesisTime:           genesisTime,
+		genesisValidatorsRoot: genesisValidatorsRoot,
+	}
+
+	sk := blst.KeyGen(make([]byte, 32))
+
+	var pubkey phase0.BLSPubKey
+
+	copy(pubkey[:], new(blst.P1Affine).From(sk).Compress())
+
+	parentRoot := phase0.Root{}
+
+	// blockRoots records the latest block at or before each slot. Only epoch boundaries have blocks.
+	blockRoots := make([]phase0.Root, slotsPerHistoricalRoot)
+
+	for epoch := 0; epoch <= epochs; epoch++ {
+		slot := phase0.Slot(uint64(epoch) * SlotsPerEpoch)
+
+		beaconState := c.newState(slot, pubkey, blockRoots)
+
+		stateRoot, err := beaconState.HashTreeRoot()
+		if err != nil {
+			return nil, fmt.Errorf("failed to compute state root for slot %d: %w", slot, err)
+		}
+
+		body := emptyBody()
+		for _, mutate := range mutators {
+			mutate(slot, body)
+		}
+
+		block := &deneb.BeaconBlock{
+			Slot:          slot,
+			ProposerIndex: 0,
+			ParentRoot:    parentRoot,
+			StateRoot:     stateRoot,
+			Body:          body,
+		}
+
+		root, err := block.HashTreeRoot()
+		if err != nil {
+			return nil, fmt.Errorf("failed to compute block root for slot %d: %w", slot, err)
+		}
+
+		signature, err := c.sign(sk, root, phase0.Epoch(epoch))
+		if err != nil {
+			return nil, err
+		}
+
+		c.blocks = append(c.blocks, &spec.VersionedSignedBeaconBlock{
+			Version: spec.DataVersionDeneb,
+			Deneb: &deneb.SignedBeaconBlock{
+				Message:   block,
+				Signature: signature,
+			},
+		})
+		c.roots = append(c.roots, root)
+		c.states = append(c.states, &spec.VersionedBeaconState{
+			Version: spec.DataVersionDeneb,
+			Deneb:   beaconState,
+		})
+
+		parentRoot = root
+
+		for s := uint64(slot); s < uint64(slot)+SlotsPerEpoch; s++ {
+			blockRoots[s%slotsPerHistoricalRoot] = root
+		}
+	}
+
+	return c, nil
+}
+
+func (c *Chain) sign(sk *blst.SecretKey, root phase0.Root, epoch phase0.Epoch) (phase0.BLSSignature, error) {
+	domain, err := verify.ComputeDomain(domainBeaconProposer, c.fork(), epoch, c.genesisValidatorsRoot)
+	if err != nil {
+		return phase0.BLSSignature{}, err
+	}
+
+	signingRoot, err := (&phase0.SigningData{ObjectRoot: root, Domain: domain}).HashTreeRoot()
+	if err != nil {
+		return phase0.BLSSignature{}, err
+	}
+
+	var signature phase0.BLSSignature
+
+	copy(signature[:], new(blst.P2Affine).Sign(sk, signingRoot[:], []byte(blsSignatureDST)).Compress())
+
+	return signature, nil
+}
+
+func (c *Chain) fork() *phase0.Fork {
+	return &phase0.Fork{
+		PreviousVersion: capellaForkVersion,
+		CurrentVersion:  denebForkVersion,
+		Epoch:           0,
+	}
+}
+
+func (c *Chain) newState(slot phase0.Slot, pubkey phase0.BLSPubKey, blockRoots []phase0.Root) *deneb.BeaconState {
+	return &deneb.BeaconState{
+		GenesisTime:           uint64(c.genesisTime.Unix()),
+		GenesisValidatorsRoot: c.genesisValidatorsRoot,
+		Slot:                  slot,
+		Fork:                  c.fork(),
+		LatestBlockHeader:     &phase0.BeaconBlockHeader{Slot: slot},
+		BlockRoots:            append([]phase0.Root(nil), blockRoots...),
+		StateRoots:            make([]phase0.Root, slotsPerHistoricalRoot),
+		HistoricalRoots:       []phase0.Root{},
+		ETH1Data:              &phase0.ETH1Data{BlockHash: make([]byte, 32)},
+		ETH1DataVotes:         []*phase0.ETH1Data{},
+		Validators: []*phase0.Validator{
+			{
+				PublicKey:             pubkey,
+				WithdrawalCredentials: make([]byte, 32),
+				EffectiveBalance:      32_000_000_000,
+				ExitEpoch:             phase0.Epoch(^uint64(0)),
+				WithdrawableEpoch:     phase0.Epoch(^uint64(0)),
+			},
+		},
+		Balances:                    []phase0.Gwei{32_000_000_000},
+		RANDAOMixes:                 make([]phase0.Root, epochsPerHistoricalVector),
+		Slashings:                   make([]phase0.Gwei, epochsPerSlashingsVector),
+		PreviousEpochParticipation:  []altair.ParticipationFlags{0},
+		CurrentEpochParticipation:   []altair.ParticipationFlags{0},
+		JustificationBits:           []byte{0},
+		PreviousJustifiedCheckpoint: &phase0.Checkpoint{},
+		CurrentJustifiedCheckpoint:  &phase0.Checkpoint{},
+		FinalizedCheckpoint:         &phase0.Checkpoint{},
+		InactivityScores:            []uint64{0},
+		CurrentSyncCommittee:        &altair.SyncCommittee{Pubkeys: make([]phase0.BLSPubKey, syncCommitteeSize)},
+		NextSyncCommittee:           &altair.SyncCommittee{Pubkeys: make([]phase0.BLSPubKey, syncCommitteeSize)},
+		LatestExecutionPayloadHeader: &deneb.ExecutionPayloadHeader{
+			ExtraData:     []byte{},
+			BaseFeePerGas: uint256.NewInt(0),
+		},
+		HistoricalSummaries: []*capella.HistoricalSummary{},
+	}
+}
+
+func emptyBody() *deneb.BeaconBlockBody {
+	return &deneb.BeaconBlockBody{
+		ETH1Data:          &phase0.ETH1Data{BlockHash: make([]byte, 32)},
+		ProposerSlashings: []*phase0.ProposerSlashing{},
+		AttesterSlashings: []*phase0.AttesterSlashing{},
+		Attestations:      []*phase0.Attestation{},
+		Deposits:          []*phase0.Deposit{},
+		VoluntaryExits:    []*phase0.SignedVoluntaryExit{},
+		SyncAggregate: &altair.SyncAggregate{
+			SyncCommitteeBits: make([]byte, syncCommitteeSize/8),
+		},
+		ExecutionPayload: &deneb.ExecutionPayload{
+			ExtraData:     []byte{},
+			BaseFeePerGas: uint256.NewInt(0),
+			Transactions:  []bellatrix.Transaction{},
+			Withdrawals:   []*capella.Withdrawal{},
+		},
+		BLSToExecutionChanges: []*capella.SignedBLSToExecutionChange{},
+		BlobKZGCommitments:    []deneb.KZGCommitment{},
+	}
+}
+
+// Epochs returns the number of the last epoch in the chain.
+func (c *Chain) Epochs() phase0.Epoch {
+	return phase0.Epoch(len(c.blocks) - 1)
+}
+
+// Block returns the block at the boundary of the given epoch and its root.
+func (c *Chain) Block(epoch phase0.Epoch) (*spec.VersionedSignedBeaconBlock, phase0.Root, bool) {
+	if int(epoch) >= len(c.blocks) {
+		return nil, phase0.Root{}, false
+	}
+
+	return c.blocks[epoch], c.roots[epoch], true
+}
+
+// State returns the state at the boundary of the given epoch.
+func (c *Chain) State(epoch phase0.Epoch) (*spec.VersionedBeaconState, bool) {
+	if int(epoch) >= len(c.states) {
+		return nil, false
+	}
+
+	return c.states[epoch], true
+}
+
+// Root returns the root of the block at the boundary of the given epoch.
+func (c *Chain) Root(epoch phase0.Epoch) phase0.Root {
+	_, root, _ := c.Block(epoch)
+
+	return root
+}
+
+// GenesisTime returns the time of the chain's first slot.
+func (c *Chain) GenesisTime() time.Time {
+	return c.genesisTime
+}
+
+// Finality returns the finality checkpoints of the chain when the given epoch is finalized.
+func (c *Chain) Finality(finalized phase0.Epoch) *v1.Finality {
+	return &v1.Finality{
+		Finalized: &phase0.Checkpoint{Epoch: finalized, Root: c.Root(finalized)},
+		Justified: &phase0.Checkpoint{Epoch: finalized + 1, Root: c.Root(finalized + 1)},
+		PreviousJustified: &phase0.Checkpoint{
+			Epoch: finalized,
+			Root:  c.Root(finalized),
+		},
+	}
+}
+
+// Genesis returns the chain's genesis details.
+func (c *Chain) Genesis() *v1.Genesis {
+	return &v1.Genesis{
+		GenesisTime:           c.genesisTime,
+		GenesisValidatorsRoot: c.genesisValidatorsRoot,
+		GenesisForkVersion:    phase0.Version{},
+	}
+}
+
+// DepositSnapshot returns an empty deposit snapshot.
+func (c *Chain) DepositSnapshot() *types.DepositSnapshot {
+	return &types.DepositSnapshot{
+		Finalized: []phase0.Root{},
+	}
+}
+
+// Spec returns the chain's config as served by /eth/v1/config/spec.
+func (c *Chain) Spec() map[string]string {
+	return map[string]string{
+		"CONFIG_NAME":                    "beacontest",
+		"PRESET_BASE":                    "mainnet",
+		"SECONDS_PER_SLOT":               fmt.Sprint(SecondsPerSlot),
+		"SLOTS_PER_EPOCH":                fmt.Sprint(SlotsPerEpoch),
+		"SLOTS_PER_HISTORICAL_ROOT":      fmt.Sprint(slotsPerHistoricalRoot),
+		"EPOCHS_PER_HISTORICAL_VECTOR":   fmt.Sprint(epochsPerHistoricalVector),
+		"EPOCHS_PER_SLASHINGS_VECTOR":    fmt.Sprint(epochsPerSlashingsVector),
+		"SYNC_COMMITTEE_SIZE":            fmt.Sprint(syncCommitteeSize),
+		"MAX_BLOB_COMMITMENTS_PER_BLOCK": "4096",
+		"DEPOSIT_CHAIN_ID":               "1",
+		"DEPOSIT_NETWORK_ID":             "1",
+		"DEPOSIT_CONTRACT_ADDRESS":       "0x00000000219ab540356cbb839cbe05303d7705fa",
+		"GENESIS_FORK_VERSION":           "0x00000000",
+		"ALTAIR_FORK_VERSION":            "0x01000000",
+		"ALTAIR_FORK_EPOCH":              "0",
+		"BELLATRIX_FORK_VERSION":         "0x02000000",
+		"BELLATRIX_FORK_EPOCH":           "0",
+		"CAPELLA_FORK_VERSION":           fmt.Sprintf("%#x", capellaForkVersion),
+		"CAPELLA_FORK_EPOCH":             "0",
+		"DENEB_FORK_VERSION":             fmt.Sprintf("%#x", denebForkVersion),
+		"DENEB_FORK_EPOCH":               "0",
+		"ELECTRA_FORK_VERSION":           "0x05000000",
+		"ELECTRA_FORK_EPOCH":             farFutureEpoch,
+		"FULU_FORK_VERSION":              "0x06000000",
+		"FULU_FORK_EPOCH":                farFutureEpoch,
+	}
+}
@@ -0,0 +1,503 @@
+package beacontest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/attestantio/go-eth2-client/spec"
+	"github.com/attestantio/go-eth2-client/spec/deneb"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/julienschmidt/httprouter"
+)
+
+// Scenario scripts how a fake node behaves.
+type Scenario struct {
+	// FinalizedEpoch is the epoch the node reports as finalized. Blocks and states after it are still served.
+	FinalizedEpoch phase0.Epoch
+	// Syncing makes the node report that it is syncing.
+	Syncing bool
+	// Delay is added before every response other than the event stream.
+	Delay time.Duration
+	// WrongBlocks makes the node serve the parent of any block requested by root, as if it had mixed up its roots.
+	WrongBlocks bool
+	// WrongStates makes the node serve the previous epoch's state for any state after genesis.
+	WrongStates bool
+	// Unavailable makes every request fail with a 503.
+	Unavailable bool
+}
+
+// Node is an in-process fake beacon node serving a Chain over the beacon API.
+type Node struct {
+	chain  *Chain
+	server *httptest.Server
+
+	mu       sync.RWMutex
+	scenario Scenario
+	requests map[string]int
+	streams  map[chan []byte]struct{}
+}
+
+// NewNode starts a fake beacon node serving the given chain. Close must be called to stop it.
+func NewNode(chain *Chain, scenario Scenario) *Node {
+	n := &Node{
+		chain:    chain,
+		scenario: scenario,
+		requests: make(map[string]int),
+		streams:  make(map[chan []byte]struct{}),
+	}
+
+	router := httprouter.New()
+
+	router.GET("/eth/v1/node/syncing", n.handleSyncing)
+	router.GET("/eth/v1/node/version", n.handleVersion)
+	router.GET("/eth/v1/node/peers", n.handlePeers)
+	router.GET("/eth/v1/node/peer_count", n.handlePeerCount)
+	router.GET("/eth/v1/beacon/genesis", n.handleGenesis)
+	router.GET("/eth/v1/config/spec", n.handleSpec)
+	router.GET("/eth/v1/beacon/states/:state_id/finality_checkpoints", n.handleFinality)
+	router.GET("/eth/v1/beacon/blocks/:block_id/root", n.handleBlockRoot)
+	router.GET("/eth/v2/beacon/blocks/:block_id", n.handleBlock)
+	router.GET("/eth/v2/debug/beacon/states/:state_id", n.handleState)
+	router.GET("/eth/v1/beacon/blob_sidecars/:block_id", n.handleBlobSidecars)
+	router.GET("/eth/v1/beacon/deposit_snapshot", n.handleDepositSnapshot)
+	router.GET("/eth/v1/events", n.handleEvents)
+
+	router.NotFound = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeError(w, http.StatusNotFound, "endpoint not supported by the fake beacon node")
+	})
+
+	n.server = httptest.NewServer(n.middleware(router))
+
+	return n
+}
+
+// URL returns the base URL of the node's beacon API.
+func (n *Node) URL() string {
+	return n.server.URL
+}
+
+// Close stops the node, ending any event streams.
+func (n *Node) Close() {
+	n.mu.Lock()
+
+	for stream := range n.streams {
+		close(stream)
+		delete(n.streams, stream)
+	}
+
+	n.mu.Unlock()
+
+	n.server.CloseClientConnections()
+	n.server.Close()
+}
+
+// Scenario returns the node's current scenario.
+func (n *Node) Scenario() Scenario {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+
+	return n.scenario
+}
+
+// SetScenario changes how the node behaves from its next request.
+func (n *Node) SetScenario(scenario Scenario) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	n.scenario = scenario
+}
+
+// Finalize advances the node's finalized epoch and publishes a finalized_checkpoint event.
+func (n *Node) Finalize(epoch phase0.Epoch) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	n.scenario.FinalizedEpoch = epoch
+
+	block, root, ok := n.chain.Block(epoch)
+	if !ok {
+		return
+	}
+
+	stateRoot, err := block.StateRoot()
+	if err != nil {
+		return
+	}
+
+	data, err := json.Marshal(map[string]any{
+		"block":                root.String(),
+		"state":                stateRoot.String(),
+		"epoch":                strconv.FormatUint(uint64(epoch), 10),
+		"execution_optimistic": false,
+	})
+	if err != nil {
+		return
+	}
+
+	event := []byte(fmt.Sprintf("event: finalized_checkpoint\ndata: %s\n\n", data))
+
+	for stream := range n.streams {
+		select {
+		case stream <- event:
+		default:
+		}
+	}
+}
+
+// Requests returns how many requests the node has received for paths starting with prefix.
+func (n *Node) Requests(prefix string) int {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+
+	count := 0
+
+	for path, c := range n.requests {
+		if strings.HasPrefix(path, prefix) {
+			count += c
+		}
+	}
+
+	return count
+}
+
+func (n *Node) middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n.mu.Lock()
+		n.requests[r.URL.Path]++
+		scenario := n.scenario
+		n.mu.Unlock()
+
+		if scenario.Unavailable {
+			writeError(w, http.StatusServiceUnavailable, "node is unavailable")
+
+			return
+		}
+
+		if scenario.Delay > 0 && r.URL.Path != "/eth/v1/events" {
+			select {
+			case <-time.After(scenario.Delay):
+			case <-r.Context().Done():
+				return
+			}
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (n *Node) handleSyncing(w http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
+	scenario := n.Scenario()
+
+	writeJSON(w, map[string]any{
+		"data": map[string]any{
+			"head_slot":     strconv.FormatUint(uint64(n.chain.Epochs())*SlotsPerEpoch, 10),
+			"sync_distance": "0",
+			"is_syncing":    scenario.Syncing,
+			"is_optimistic": false,
+			"el_offline":    false,
+		},
+	})
+}
+
+func (n *Node) handleVersion(w http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
+	writeJSON(w, map[string]any{
+		"data": map[string]string{"version": "beacontest/v0.0.0"},
+	})
+}
+
+func (n *Node) handlePeers(w http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
+	writeJSON(w, map[string]any{
+		"data": []any{},
+		"meta": map[string]string{"count": "0"},
+	})
+}
+
+func (n *Node) handlePeerCount(w http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
+	writeJSON(w, map[string]any{
+		"data": map[string]string{
+			"disconnected":  "0",
+			"connecting":    "0",
+			"connected":     "0",
+			"disconnecting": "0",
+		},
+	})
+}
+
+func (n *Node) handleGenesis(w http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
+	writeJSON(w, map[string]any{"data": n.chain.Genesis()})
+}
+
+func (n *Node) handleSpec(w http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
+	writeJSON(w, map[string]any{"data": n.chain.Spec()})
+}
+
+func (n *Node) handleFinality(w http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
+	finality := n.chain.Finality(n.Scenario().FinalizedEpoch)
+
+	checkpoint := func(cp *phase0.Checkpoint) map[string]string {
+		return map[string]string{
+			"epoch": strconv.FormatUint(uint64(cp.Epoch), 10),
+			"root":  cp.Root.String(),
+		}
+	}
+
+	writeJSON(w, map[string]any{
+		"execution_optimistic": false,
+		"finalized":            false,
+		"data": map[string]any{
+			"previous_justified": checkpoint(finality.PreviousJustified),
+			"current_justified":  checkpoint(finality.Justified),
+			"finalized":          checkpoint(finality.Finalized),
+		},
+	})
+}
+
+func (n *Node) handleBlockRoot(w http.ResponseWriter, _ *http.Request, p httprouter.Params) {
+	_, root, ok := n.resolveBlock(p.ByName("block_id"))
+	if !ok {
+		writeError(w, http.StatusNotFound, "block not found")
+
+		return
+	}
+
+	writeJSON(w, map[string]any{
+		"data": map[string]string{"root": root.String()},
+	})
+}
+
+func (n *Node) handleBlock(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+	block, _, ok := n.resolveBlock(p.ByName("block_id"))
+	if !ok {
+		writeError(w, http.StatusNotFound, "block not found")
+
+		return
+	}
+
+	if acceptsSSZ(r) {
+		data, err := block.Deneb.MarshalSSZ()
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+
+			return
+		}
+
+		writeSSZ(w, block.Version, data)
+
+		return
+	}
+
+	writeVersionedJSON(w, block.Version, block.Deneb)
+}
+
+func (n *Node) handleState(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+	beaconState, ok := n.resolveState(p.ByName("state_id"))
+	if !ok {
+		writeError(w, http.StatusNotFound, "state not found")
+
+		return
+	}
+
+	if acceptsSSZ(r) {
+		data, err := beaconState.Deneb.MarshalSSZ()
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+
+			return
+		}
+
+		writeSSZ(w, beaconState.Version, data)
+
+		return
+	}
+
+	writeVersionedJSON(w, beaconState.Version, beaconState.Deneb)
+}
+
+func (n *Node) handleBlobSidecars(w http.ResponseWriter, _ *http.Request, p httprouter.Params) {
+	if _, _, ok := n.resolveBlock(p.ByName("block_id")); !ok {
+		writeError(w, http.StatusNotFound, "block not found")
+
+		return
+	}
+
+	// Blocks on the fake chain carry no blobs.
+	writeJSON(w, map[string]any{"data": []*deneb.BlobSidecar{}})
+}
+
+func (n *Node) handleDepositSnapshot(w http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
+	writeJSON(w, map[string]any{"data": n.chain.DepositSnapshot()})
+}
+
+func (n *Node) handleEvents(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, "streaming unsupported")
+
+		return
+	}
+
+	stream := make(chan []byte, 16)
+
+	n.mu.Lock()
+	n.streams[stream] = struct{}{}
+	n.mu.Unlock()
+
+	defer func() {
+		n.mu.Lock()
+		delete(n.streams, stream)
+		n.mu.Unlock()
+	}()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	for {
+		select {
+		case event, ok := <-stream:
+			if !ok {
+				return
+			}
+
+			if _, err := w.Write(event); err != nil {
+				return
+			}
+
+			flusher.Flush()
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+// resolveBlock returns the block for a block id, honouring the WrongBlocks scenario.
+func (n *Node) resolveBlock(id string) (*spec.VersionedSignedBeaconBlock, phase0.Root, bool) {
+	scenario := n.Scenario()
+
+	epoch, ok := n.resolveEpoch(id, scenario, func(root phase0.Root) (phase0.Epoch, bool) {
+		for epoch := phase0.Epoch(0); epoch <= n.chain.Epochs(); epoch++ {
+			if n.chain.Root(epoch) == root {
+				if scenario.WrongBlocks && epoch > 0 {
+					return epoch - 1, true
+				}
+
+				return epoch, true
+			}
+		}
+
+		return 0, false
+	})
+	if !ok {
+		return nil, phase0.Root{}, false
+	}
+
+	return n.chain.Block(epoch)
+}
+
+// resolveState returns the state for a state id, honouring the WrongStates scenario.
+func (n *Node) resolveState(id string) (*spec.VersionedBeaconState, bool) {
+	scenario := n.Scenario()
+
+	epoch, ok := n.resolveEpoch(id, scenario, func(root phase0.Root) (phase0.Epoch, bool) {
+		for epoch := phase0.Epoch(0); epoch <= n.chain.Epochs(); epoch++ {
+			block, _, _ := n.chain.Block(epoch)
+
+			if stateRoot, err := block.StateRoot(); err == nil && stateRoot == root {
+				return epoch, true
+			}
+		}
+
+		return 0, false
+	})
+	if !ok {
+		return nil, false
+	}
+
+	if scenario.WrongStates && epoch > 0 {
+		epoch--
+	}
+
+	return n.chain.State(epoch)
+}
+
+// resolveEpoch maps a block or state id to the epoch boundary it refers to. Only epoch boundary slots have
+// blocks; every other slot is empty.
+func (n *Node) resolveEpoch(id string, scenario Scenario, byRoot func(phase0.Root) (phase0.Epoch, bool)) (phase0.Epoch, bool) {
+	switch id {
+	case "head":
+		return n.chain.Epochs(), true
+	case "finalized":
+		return scenario.FinalizedEpoch, true
+	case "genesis":
+		return 0, true
+	}
+
+	if strings.HasPrefix(id, "0x") {
+		var root phase0.Root
+
+		if err := root.UnmarshalJSON([]byte(strconv.Quote(id))); err != nil {
+			return 0, false
+		}
+
+		return byRoot(root)
+	}
+
+	slot, err := strconv.ParseUint(id, 10, 64)
+	if err != nil || slot%SlotsPerEpoch != 0 {
+		return 0, false
+	}
+
+	epoch := phase0.Epoch(slot / SlotsPerEpoch)
+	if epoch > n.chain.Epochs() {
+		return 0, false
+	}
+
+	return epoch, true
+}
+
+func acceptsSSZ(r *http.Request) bool {
+	return strings.Contains(r.Header.Get("Accept"), "application/octet-stream")
+}
+
+func writeSSZ(w http.ResponseWriter, version spec.DataVersion, data []byte) {
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("Eth-Consensus-Version", version.String())
+	w.WriteHeader(http.StatusOK)
+
+	_, _ = w.Write(data)
+}
+
+func writeVersionedJSON(w http.ResponseWriter, version spec.DataVersion, data any) {
+	w.Header().Set("Eth-Consensus-Version", version.String())
+
+	writeJSON(w, map[string]any{
+		"version":              version.String(),
+		"execution_optimistic": false,
+		"finalized":            true,
+		"data":                 data,
+	})
+}
+
+func writeJSON(w http.ResponseWriter, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+func writeError(w http.ResponseWriter, code int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"code":    code,
+		"message": message,
+	})
+}
@@ -0,0 +1,131 @@
+package beacontest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/attestantio/go-eth2-client/api"
+	ehttp "github.com/attestantio/go-eth2-client/http"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newClient(t *testing.T, node *Node) *ehttp.Service {
+	t.Helper()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	client, err := ehttp.New(ctx, ehttp.WithAddress(node.URL()), ehttp.WithLogLevel(zerolog.Disabled))
+	require.NoError(t, err)
+
+	service, ok := client.(*ehttp.Service)
+	require.True(t, ok)
+
+	return service
+}
+
+func TestNodeServesChain(t *testing.T) {
+	chain, err := NewChain(4)
+	require.NoError(t, err)
+
+	node := NewNode(chain, Scenario{FinalizedEpoch: 2})
+	defer node.Close()
+
+	client := newClient(t, node)
+	ctx := context.Background()
+
+	finality, err := client.Finality(ctx, &api.FinalityOpts{State: "head"})
+	require.NoError(t, err)
+	assert.Equal(t, phase0.Epoch(2), finality.Data.Finalized.Epoch)
+	assert.Equal(t, chain.Root(2), finality.Data.Finalized.Root)
+
+	block, err := client.SignedBeaconBlock(ctx, &api.SignedBeaconBlockOpts{Block: chain.Root(2).String()})
+	require.NoError(t, err)
+
+	root, err := block.Data.Root()
+	require.NoError(t, err)
+	assert.Equal(t, chain.Root(2), root)
+
+	stateRoot, err := block.Data.StateRoot()
+	require.NoError(t, err)
+
+	beaconState, err := client.BeaconState(ctx, &api.BeaconStateOpts{State: "64"})
+	require.NoError(t, err)
+
+	actualStateRoot, err := beaconState.Data.HashTreeRoot()
+	require.NoError(t, err)
+	assert.Equal(t, stateRoot, phase0.Root(actualStateRoot))
+
+	genesis, err := client.Genesis(ctx, &api.GenesisOpts{})
+	require.NoError(t, err)
+	assert.Equal(t, chain.GenesisTime().Unix(), genesis.Data.GenesisTime.Unix())
+
+	_, err = client.SignedBeaconBlock(ctx, &api.SignedBeaconBlockOpts{Block: "65"})
+	assert.Error(t, err, "non epoch boundary slots are empty")
+}
+
+func TestNodeWrongBlocks(t *testing.T) {
+	chain, err := NewChain(4)
+	require.NoError(t, err)
+
+	node := NewNode(chain, Scenario{FinalizedEpoch: 2, WrongBlocks: true})
+	defer node.Close()
+
+	block, err := newClient(t, node).SignedBeaconBlock(context.Background(), &api.SignedBeaconBlockOpts{Block: chain.Root(2).String()})
+	require.NoError(t, err)
+
+	root, err := block.Data.Root()
+	require.NoError(t, err)
+	assert.Equal(t, chain.Root(1), root)
+}
+
+func TestNodeWrongStates(t *testing.T) {
+	chain, err := NewChain(4)
+	require.NoError(t, err)
+
+	node := NewNode(chain, Scenario{FinalizedEpoch: 2, WrongStates: true})
+	defer node.Close()
+
+	beaconState, err := newClient(t, node).BeaconState(context.Background(), &api.BeaconStateOpts{State: "64"})
+	require.NoError(t, err)
+
+	slot, err := beaconState.Data.Slot()
+	require.NoError(t, err)
+	assert.Equal(t, phase0.Slot(SlotsPerEpoch), slot)
+}
+
+func TestChainFork(t *testing.T) {
+	chain, err := NewChain(4)
+	require.NoError(t, err)
+
+	fork, err := chain.Fork(2)
+	require.NoError(t, err)
+
+	assert.Equal(t, chain.Root(2), fork.Root(2))
+	assert.NotEqual(t, chain.Root(3), fork.Root(3))
+}
+
+func TestNodeDelayAndUnavailable(t *testing.T) {
+	chain, err := NewChain(1)
+	require.NoError(t, err)
+
+	node := NewNode(chain, Scenario{Delay: 100 * time.Millisecond})
+	defer node.Close()
+
+	client := newClient(t, node)
+
+	start := time.Now()
+	_, err = client.Genesis(context.Background(), &api.GenesisOpts{})
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, time.Since(start), 100*time.Millisecond)
+
+	node.SetScenario(Scenario{Unavailable: true})
+
+	_, err = client.Finality(context.Background(), &api.FinalityOpts{State: "head"})
+	assert.Error(t, err)
+	assert.Positive(t, node.Requests("/eth/v1/beacon/states/"))
+}
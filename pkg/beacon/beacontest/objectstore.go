@@ -0,0 +1,213 @@
+package beacontest
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/md5" //nolint:gosec // ETags are MD5 digests.
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ObjectStore is an in-process stand-in for S3 compatible object storage such as MinIO. It serves GET, HEAD,
+// DELETE and conditional PUT of whole objects addressed by path (/<bucket>/<key>), and ignores
+// authentication.
+type ObjectStore struct {
+	server *httptest.Server
+
+	mu       sync.RWMutex
+	objects  map[string]storedObject
+	requests map[string]int
+}
+
+type storedObject struct {
+	data     []byte
+	header   http.Header
+	modified time.Time
+}
+
+// NewObjectStore starts a fake object store. Close must be called to stop it.
+func NewObjectStore() *ObjectStore {
+	s := &ObjectStore{
+		objects:  make(map[string]storedObject),
+		requests: make(map[string]int),
+	}
+
+	s.server = httptest.NewServer(http.HandlerFunc(s.handle))
+
+	return s
+}
+
+// URL returns the base URL of the object store.
+func (s *ObjectStore) URL() string {
+	return s.server.URL
+}
+
+// Close stops the object store.
+func (s *ObjectStore) Close() {
+	s.server.Close()
+}
+
+// Object returns the contents of the object with the given key in bucket.
+func (s *ObjectStore) Object(bucket, key string) ([]byte, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	object, ok := s.objects[bucket+"/"+key]
+
+	return object.data, ok
+}
+
+// Requests returns how many requests with the given method have been made.
+func (s *ObjectStore) Requests(method string) int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.requests[method]
+}
+
+func (s *ObjectStore) handle(w http.ResponseWriter, r *http.Request) {
+	name := strings.TrimPrefix(r.URL.Path, "/")
+
+	s.mu.Lock()
+	s.requests[r.Method]++
+	s.mu.Unlock()
+
+	switch r.Method {
+	case http.MethodPut:
+		s.put(w, r, name)
+	case http.MethodGet, http.MethodHead:
+		s.get(w, r, name)
+	case http.MethodDelete:
+		s.mu.Lock()
+		delete(s.objects, name)
+		s.mu.Unlock()
+
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func (s *ObjectStore) put(w http.ResponseWriter, r *http.Request, name string) {
+	var (
+		data []byte
+		err  error
+	)
+
+	// Clients sign or checksum uploads over plain HTTP in the aws-chunked encoding.
+	if strings.HasPrefix(r.Header.Get("X-Amz-Content-Sha256"), "STREAMING-") {
+		data, err = decodeAWSChunked(r.Body)
+	} else {
+		data, err = io.ReadAll(r.Body)
+	}
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	header := http.Header{}
+
+	for key, values := range r.Header {
+		if strings.HasPrefix(strings.ToLower(key), "x-amz-meta-") || key == "Content-Type" {
+			header[key] = values
+		}
+	}
+
+	sum := md5.Sum(data) //nolint:gosec // ETags are MD5 digests.
+	header.Set("ETag", `"`+hex.EncodeToString(sum[:])+`"`)
+
+	s.mu.Lock()
+
+	existing, exists := s.objects[name]
+
+	ifMatch, ifNoneMatch := r.Header.Get("If-Match"), r.Header.Get("If-None-Match")
+	if (ifMatch != "" && (!exists || (ifMatch != "*" && ifMatch != existing.header.Get("ETag")))) ||
+		(ifNoneMatch != "" && exists && (ifNoneMatch == "*" || ifNoneMatch == existing.header.Get("ETag"))) {
+		s.mu.Unlock()
+
+		w.Header().Set("Content-Type", "application/xml")
+		w.WriteHeader(http.StatusPreconditionFailed)
+		fmt.Fprintf(w, `<?xml version="1.0" encoding="UTF-8"?><Error><Code>PreconditionFailed</Code><Message>At least one of the pre-conditions you specified did not hold</Message><Key>%s</Key></Error>`, name)
+
+		return
+	}
+
+	s.objects[name] = storedObject{data: data, header: header, modified: time.Now()}
+	s.mu.Unlock()
+
+	w.Header().Set("ETag", header.Get("ETag"))
+	w.WriteHeader(http.StatusOK)
+}
+
+func (s *ObjectStore) get(w http.ResponseWriter, r *http.Request, name string) {
+	s.mu.RLock()
+	object, ok := s.objects[name]
+	s.mu.RUnlock()
+
+	if !ok {
+		w.Header().Set("Content-Type", "application/xml")
+		w.WriteHeader(http.StatusNotFound)
+
+		if r.Method == http.MethodGet {
+			fmt.Fprintf(w, `<?xml version="1.0" encoding="UTF-8"?><Error><Code>NoSuchKey</Code><Message>The specified key does not exist.</Message><Key>%s</Key></Error>`, name)
+		}
+
+		return
+	}
+
+	for key, values := range object.header {
+		w.Header()[key] = values
+	}
+
+	w.Header().Set("Content-Length", strconv.Itoa(len(object.data)))
+	w.Header().Set("Last-Modified", object.modified.UTC().Format(http.TimeFormat))
+	w.WriteHeader(http.StatusOK)
+
+	if r.Method == http.MethodGet {
+		_, _ = w.Write(object.data)
+	}
+}
+
+// decodeAWSChunked returns the payload of a body in the aws-chunked encoding: hex sized chunks, each with an
+// optional signature, ending with an empty chunk and optional trailers.
+func decodeAWSChunked(body io.Reader) ([]byte, error) {
+	var data bytes.Buffer
+
+	reader := bufio.NewReader(body)
+
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+
+		sizeHex, _, _ := strings.Cut(strings.TrimSpace(line), ";")
+
+		size, err := strconv.ParseInt(sizeHex, 16, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid chunk size: %w", err)
+		}
+
+		if size == 0 {
+			return data.Bytes(), nil
+		}
+
+		if _, err := io.CopyN(&data, reader, size); err != nil {
+			return nil, err
+		}
+
+		if crlf, err := reader.ReadString('\n'); err != nil || crlf != "\r\n" {
+			return nil, errors.New("chunk isn't terminated by CRLF")
+		}
+	}
+}
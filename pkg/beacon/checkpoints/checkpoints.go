@@ -6,7 +6,8 @@ import (
 )
 
 type Decider interface {
-	Decide(checkpoints []*v1.Finality) (*v1.Finality, error)
+	// Decide returns the agreed upon finality and the fraction of checkpoints that agreed on it.
+	Decide(checkpoints []*v1.Finality) (*v1.Finality, float64, error)
 }
 
 var _ Decider = (*majority.Decider)(nil)
@@ -17,7 +17,9 @@ func New() *Decider {
 	return &Decider{}
 }
 
-func (m *Decider) Decide(checkpoints []*v1.Finality) (*v1.Finality, error) {
+// Decide returns the finality reported by more than half of the checkpoints, along with the fraction of
+// checkpoints that reported it.
+func (m *Decider) Decide(checkpoints []*v1.Finality) (*v1.Finality, float64, error) {
 	common := make(map[string]struct {
 		Finality *v1.Finality
 		Count    int
@@ -47,9 +49,9 @@ func (m *Decider) Decide(checkpoints []*v1.Finality) (*v1.Finality, error) {
 
 	for _, v := range common {
 		if v.Count > len(checkpoints)/2 {
-			return v.Finality, nil
+			return v.Finality, float64(v.Count) / float64(len(checkpoints)), nil
 		}
 	}
 
-	return nil, ErrNoMajorityFound
+	return nil, 0, ErrNoMajorityFound
 }
@@ -54,7 +54,7 @@ func TestBasicMajority(t *testing.T) {
 		finalityA,
 	}
 
-	finality, err := majority.Decide(payload)
+	finality, agreement, err := majority.Decide(payload)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -62,6 +62,10 @@ func TestBasicMajority(t *testing.T) {
 	if finality.Finalized.Root != finalityA.Finalized.Root {
 		t.Errorf("Expected %v, got %v", finalityA, finality)
 	}
+
+	if agreement != 2.0/3.0 {
+		t.Errorf("Expected agreement of %v, got %v", 2.0/3.0, agreement)
+	}
 }
 
 func TestNonMajority(t *testing.T) {
@@ -71,7 +75,7 @@ func TestNonMajority(t *testing.T) {
 		finalityC,
 	}
 
-	_, err := majority.Decide(payload)
+	_, _, err := majority.Decide(payload)
 	if err != ErrNoMajorityFound {
 		t.Errorf("Expected %v, got %v", ErrNoMajorityFound, err)
 	}
@@ -83,7 +87,7 @@ func TestSplitMajority(t *testing.T) {
 		finalityB,
 	}
 
-	_, err := majority.Decide(payload)
+	_, _, err := majority.Decide(payload)
 	if err != ErrNoMajorityFound {
 		t.Errorf("Expected %v, got %v", ErrNoMajorityFound, err)
 	}
@@ -3,8 +3,13 @@ package beacon
 import (
 	"errors"
 	"fmt"
+	"time"
 
+	"github.com/ethpandaops/checkpointz/pkg/beacon/leader"
 	"github.com/ethpandaops/checkpointz/pkg/beacon/store"
+	"github.com/ethpandaops/checkpointz/pkg/beacon/store/remote"
+	"github.com/ethpandaops/checkpointz/pkg/beacon/witness"
+	"github.com/ethpandaops/checkpointz/pkg/cache"
 )
 
 // Config holds configuration for running a FinalityProvider config
@@ -16,15 +21,40 @@ type Config struct {
 	// Cache holds configuration for the caches.
 	Caches CacheConfig `yaml:"caches"`
 
+	// VerifyBlockSignatures enables BLS verification of block signatures on ingest when a state is available.
+	VerifyBlockSignatures bool `yaml:"verify_block_signatures" default:"false"`
+
 	// HistoricalEpochCount determines how many historical epochs the provider will cache.
 	HistoricalEpochCount int `yaml:"historical_epoch_count" default:"20"`
 
+	// Witnesses holds configuration for comparing our checkpoints with other providers.
+	Witnesses WitnessesConfig `yaml:"witnesses"`
+
+	// RemoteStorage holds configuration for the object storage beacon states are shared through.
+	RemoteStorage remote.Config `yaml:"remote_storage"`
+
+	// Coordination holds configuration for electing a single replica to download bundles from upstreams.
+	Coordination leader.Config `yaml:"coordination"`
+
 	// Cache holds configuration for the caches.
 	Frontend FrontendConfig `yaml:"frontend"`
 }
 
+// WitnessesConfig configures the external witnesses our serving checkpoint is compared with.
+type WitnessesConfig struct {
+	// Endpoints are the other Checkpointz instances or beacon nodes to compare with.
+	Endpoints []witness.Config `yaml:"endpoints"`
+	// PollInterval is how often the serving checkpoint is compared with the witnesses.
+	PollInterval time.Duration `yaml:"poll_interval" default:"1m"`
+	// BlockOnDisagreement refuses to serve a new checkpoint that any witness disagrees with.
+	BlockOnDisagreement bool `yaml:"block_on_disagreement" default:"false"`
+}
+
 // Cache configuration holds configuration for the caches.
 type CacheConfig struct {
+	// MemoryBudget is the combined size of the blocks, states, deposit snapshots and sidecars that can be cached,
+	// e.g. "4GiB". Items are evicted across all caches to stay within it. 0 means unlimited.
+	MemoryBudget cache.ByteSize `yaml:"memory_budget" default:"0"`
 	// Blocks holds the block cache configuration.
 	Blocks store.Config `yaml:"blocks" default:"{\"MaxItems\": 30}"`
 	// States holds the state cache configuration.
@@ -33,6 +63,8 @@ type CacheConfig struct {
 	DepositSnapshots store.Config `yaml:"deposit_snapshots" default:"{\"MaxItems\": 30}"`
 	// BlobSidecars holds the blob sidecar cache configuration.
 	BlobSidecars store.Config `yaml:"blob_sidecars" default:"{\"MaxItems\": 30}"`
+	// DataColumnSidecars holds the data column sidecar cache configuration.
+	DataColumnSidecars store.Config `yaml:"data_column_sidecars" default:"{\"MaxItems\": 30}"`
 }
 
 type FrontendConfig struct {
@@ -66,6 +98,44 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("historical_epoch_count (%d) cannot be higher than 200", c.HistoricalEpochCount)
 	}
 
+	if err := c.Witnesses.Validate(); err != nil {
+		return fmt.Errorf("invalid witnesses config: %s", err)
+	}
+
+	if err := c.RemoteStorage.Validate(); err != nil {
+		return fmt.Errorf("invalid remote_storage config: %s", err)
+	}
+
+	if err := c.Coordination.Validate(); err != nil {
+		return fmt.Errorf("invalid coordination config: %s", err)
+	}
+
+	if c.Coordination.Enabled && c.Coordination.Lock.Type == leader.LockTypeObject && !c.RemoteStorage.Enabled {
+		return errors.New("coordination object locks are held in remote_storage, which must be enabled")
+	}
+
+	return nil
+}
+
+func (c *WitnessesConfig) Validate() error {
+	names := make(map[string]struct{})
+
+	for _, w := range c.Endpoints {
+		if err := w.Validate(); err != nil {
+			return fmt.Errorf("invalid witness %s: %s", w.Name, err)
+		}
+
+		if _, ok := names[w.Name]; ok {
+			return fmt.Errorf("there's a duplicate witness with the same name: %s", w.Name)
+		}
+
+		names[w.Name] = struct{}{}
+	}
+
+	if len(c.Endpoints) > 0 && c.PollInterval <= 0 {
+		return errors.New("poll_interval must be positive")
+	}
+
 	return nil
 }
 
@@ -82,6 +152,10 @@ func (c *CacheConfig) Validate() error {
 		return errors.New("blocks.max_items must be at least 3")
 	}
 
+	if c.MemoryBudget < 0 {
+		return errors.New("memory_budget must not be negative")
+	}
+
 	if c.States.MaxItems < 3 {
 		return errors.New("states.max_items must be at least 3")
 	}
@@ -0,0 +1,52 @@
+package beacon
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/ethpandaops/checkpointz/pkg/beacon/fulu"
+)
+
+// FetchDataColumnSidecars fetches the data column sidecars for the given block id from the upstream.
+// The underlying beacon client does not support the endpoint yet, so the request is made directly.
+func (n *Node) FetchDataColumnSidecars(ctx context.Context, blockID string) ([]*fulu.DataColumnSidecar, error) {
+	url := fmt.Sprintf("%s/eth/v1/debug/beacon/data_column_sidecars/%s", strings.TrimRight(n.Config.Address, "/"), blockID)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, http.NoBody)
+	if err != nil {
+		return nil, err
+	}
+
+	for header, value := range n.Config.Headers {
+		req.Header.Set(header, value)
+	}
+
+	req.Header.Set("Accept", "application/json")
+
+	rsp, err := n.HTTP.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rsp.Body.Close()
+
+	if rsp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(rsp.Body, 1024))
+
+		return nil, fmt.Errorf("unexpected status code %d fetching data column sidecars: %s", rsp.StatusCode, string(body))
+	}
+
+	var wrapped struct {
+		Data []*fulu.DataColumnSidecar `json:"data"`
+	}
+
+	if err := json.NewDecoder(rsp.Body).Decode(&wrapped); err != nil {
+		return nil, fmt.Errorf("failed to decode data column sidecars: %w", err)
+	}
+
+	return wrapped.Data, nil
+}
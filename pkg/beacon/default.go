@@ -4,7 +4,9 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sort"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	v1 "github.com/attestantio/go-eth2-client/api/v1"
@@ -15,13 +17,19 @@ import (
 	"github.com/ethpandaops/beacon/pkg/beacon"
 	"github.com/ethpandaops/beacon/pkg/beacon/api/types"
 	"github.com/ethpandaops/beacon/pkg/beacon/state"
+	"github.com/ethpandaops/checkpointz/pkg/beacon/archive"
 	"github.com/ethpandaops/checkpointz/pkg/beacon/checkpoints"
+	"github.com/ethpandaops/checkpointz/pkg/beacon/fulu"
+	"github.com/ethpandaops/checkpointz/pkg/beacon/leader"
 	"github.com/ethpandaops/checkpointz/pkg/beacon/node"
 	"github.com/ethpandaops/checkpointz/pkg/beacon/ssz"
 	"github.com/ethpandaops/checkpointz/pkg/beacon/store"
+	"github.com/ethpandaops/checkpointz/pkg/beacon/store/remote"
+	"github.com/ethpandaops/checkpointz/pkg/beacon/verify"
+	"github.com/ethpandaops/checkpointz/pkg/beacon/witness"
+	"github.com/ethpandaops/checkpointz/pkg/cache"
+	"github.com/ethpandaops/checkpointz/pkg/clock"
 	"github.com/ethpandaops/checkpointz/pkg/eth"
-	"github.com/ethpandaops/ethwallclock"
-	"github.com/go-co-op/gocron"
 	perrors "github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 )
@@ -35,17 +43,20 @@ type Default struct {
 	broker      *emission.Emitter
 	sszEncoder  *ssz.Encoder
 
-	head          *v1.Finality
-	servingBundle *v1.Finality
+	// head is the finality the upstreams agree on and servingBundle the one being served. They're read by
+	// the API while the loops replace them.
+	head          atomic.Pointer[v1.Finality]
+	servingBundle atomic.Pointer[v1.Finality]
 
-	blocks           *store.Block
-	states           *store.BeaconState
-	depositSnapshots *store.DepositSnapshot
-	blobSidecars     *store.BlobSidecar
+	blocks             *store.Block
+	states             *store.BeaconState
+	depositSnapshots   *store.DepositSnapshot
+	blobSidecars       *store.BlobSidecar
+	dataColumnSidecars *store.DataColumnSidecar
 
 	specMutex sync.Mutex
 	spec      *state.Spec
-	genesis   *v1.Genesis
+	genesis   atomic.Pointer[v1.Genesis]
 
 	historicalSlotFailures map[phase0.Slot]int
 
@@ -54,12 +65,48 @@ type Default struct {
 	majorityMutex   sync.Mutex
 
 	metrics *Metrics
+
+	// agreement is the outcome of the latest majority decision. It's recorded in the provenance of everything
+	// fetched under its checkpoint.
+	agreement atomic.Pointer[store.Provenance]
+
+	// witnesses are the external providers our serving checkpoint is compared with. witnessReports holds
+	// their latest reports, guarded by witnessMutex.
+	witnesses      []*witness.Witness
+	witnessMutex   sync.Mutex
+	witnessReports map[string]*witness.Report
+
+	// archives are local directories of blocks and states that are read before asking an upstream. They
+	// don't take part in the finality vote.
+	archives []*archive.Archive
+
+	// remote is object storage that beacon states are shared through with other replicas, if configured.
+	// It's read before asking an upstream for a state, and states fetched elsewhere are uploaded to it.
+	remote *remote.Store
+
+	// elector decides which replica downloads bundles from upstreams, if coordination is enabled. While
+	// another replica leads, bundles are synced from leader, a node for its API guarded by leaderMutex.
+	elector     *leader.Elector
+	leaderMutex sync.Mutex
+	leader      *Node
+	namespace   string
+
+	// clock drives every timer, poll and expiry in the provider.
+	clock clock.Clock
+
+	// lifecycleMu guards cancel and stopped. wg tracks the provider's loops so that Stop can wait for them
+	// to finish.
+	lifecycleMu sync.Mutex
+	cancel      context.CancelFunc
+	stopped     bool
+	wg          sync.WaitGroup
 }
 
 var _ FinalityProvider = (*Default)(nil)
 
 var (
-	topicFinalityHeadUpdated = "finality_head_updated"
+	topicFinalityHeadUpdated      = "finality_head_updated"
+	topicServingCheckpointUpdated = "serving_checkpoint_updated"
 )
 
 const (
@@ -68,24 +115,59 @@ const (
 	FinalityHaltedServingPeriod = 14 * 24 * time.Hour
 )
 
-func NewDefaultProvider(namespace string, log logrus.FieldLogger, nodes []node.Config, config *Config) FinalityProvider {
-	return &Default{
+func NewDefaultProvider(namespace string, log logrus.FieldLogger, nodes []node.Config, archives []archive.Config, config *Config, clk clock.Clock) FinalityProvider {
+	budget := cache.NewBudget(int64(config.Caches.MemoryBudget), namespace)
+	budget.EnableMetrics()
+
+	witnesses := make([]*witness.Witness, 0, len(config.Witnesses.Endpoints))
+	for _, w := range config.Witnesses.Endpoints {
+		witnesses = append(witnesses, witness.New(w, clk))
+	}
+
+	sszEncoder := ssz.NewEncoder(config.CustomPreset)
+
+	archiveSources := make([]*archive.Archive, 0, len(archives))
+	for _, a := range archives {
+		archiveSources = append(archiveSources, archive.New(log.WithField("module", "beacon/archive"), a, sszEncoder))
+	}
+
+	var remoteStore *remote.Store
+
+	if config.RemoteStorage.Enabled {
+		var err error
+
+		remoteStore, err = remote.New(config.RemoteStorage)
+		if err != nil {
+			log.WithError(err).Error("Failed to create remote storage client, continuing without it")
+		}
+	}
+
+	var elector *leader.Elector
+
+	if config.Coordination.Enabled {
+		var err error
+
+		elector, err = newElector(log, config, clk)
+		if err != nil {
+			log.WithError(err).Error("Failed to create leader elector, continuing without coordination")
+		}
+	}
+
+	d := &Default{
 		nodeConfigs: nodes,
 		log:         log.WithField("module", "beacon/default"),
-		nodes:       NewNodesFromConfig(log, nodes, namespace, config.CustomPreset),
+		nodes:       NewNodesFromConfig(log, nodes, namespace, config.CustomPreset, clk),
 		config:      config,
 
-		head:          &v1.Finality{},
-		servingBundle: &v1.Finality{},
-
 		historicalSlotFailures: make(map[phase0.Slot]int),
 
-		broker:           emission.NewEmitter(),
-		sszEncoder:       ssz.NewEncoder(config.CustomPreset),
-		blocks:           store.NewBlock(log, config.Caches.Blocks, namespace),
-		states:           store.NewBeaconState(log, config.Caches.States, namespace),
-		depositSnapshots: store.NewDepositSnapshot(log, config.Caches.DepositSnapshots, namespace),
-		blobSidecars:     store.NewBlobSidecar(log, config.Caches.BlobSidecars, namespace),
+		broker:             emission.NewEmitter(),
+		sszEncoder:         sszEncoder,
+		blocks:             store.NewBlock(log, config.Caches.Blocks, namespace, budget, clk),
+		states:             store.NewBeaconState(log, config.Caches.States, namespace, budget, clk),
+		depositSnapshots:   store.NewDepositSnapshot(log, config.Caches.DepositSnapshots, namespace, budget, clk),
+		blobSidecars:       store.NewBlobSidecar(log, config.Caches.BlobSidecars, namespace, budget, clk),
+		dataColumnSidecars: store.NewDataColumnSidecar(log, config.Caches.DataColumnSidecars, namespace, budget, clk),
 
 		servingMutex:    sync.Mutex{},
 		historicalMutex: sync.Mutex{},
@@ -93,54 +175,85 @@ func NewDefaultProvider(namespace string, log logrus.FieldLogger, nodes []node.C
 		specMutex:       sync.Mutex{},
 
 		metrics: NewMetrics(namespace + "_beacon"),
+
+		witnesses:      witnesses,
+		witnessReports: make(map[string]*witness.Report),
+
+		archives: archiveSources,
+		remote:   remoteStore,
+
+		elector:   elector,
+		namespace: namespace,
+
+		clock: clk,
+	}
+
+	d.head.Store(&v1.Finality{})
+	d.servingBundle.Store(&v1.Finality{})
+
+	if elector != nil {
+		elector.OnRoleChanged(d.onRoleChanged)
 	}
+
+	return d
 }
 
 func (d *Default) Start(ctx context.Context) error {
 	d.log.Infof("Starting Finality provider in %s mode", d.OperatingMode())
 
+	d.lifecycleMu.Lock()
+
+	if d.stopped {
+		d.lifecycleMu.Unlock()
+
+		return errors.New("provider has been stopped")
+	}
+
+	ctx, d.cancel = context.WithCancel(ctx)
+
+	d.lifecycleMu.Unlock()
+
 	d.metrics.ObserveOperatingMode(d.OperatingMode())
 
+	d.indexArchives()
+
+	if d.elector != nil {
+		d.metrics.ObserveCoordinationRole(d.elector.Role())
+		d.runLoop(ctx, "leader_election", d.elector.Run)
+	}
+
 	if err := d.nodes.StartAll(ctx); err != nil {
 		return err
 	}
 
-	go func() {
+	d.runLoop(ctx, "startup", func(ctx context.Context) error {
 		for {
 			// Wait until we have a single healthy node.
-			nd, err := d.nodes.Healthy(ctx).NotSyncing(ctx).RandomNode(ctx)
+			_, err := d.nodes.Healthy(ctx).NotSyncing(ctx).RandomNode(ctx)
 			if err != nil {
 				d.log.WithError(err).Error("Waiting for a healthy, non-syncing node before beginning..")
-				time.Sleep(time.Second * 5)
 
-				continue
-			}
-
-			nd.Beacon.Wallclock().OnEpochChanged(func(epoch ethwallclock.Epoch) {
-				// Refresh the spec on epoch change.
-				// This will intentionally use any node (not the one that triggered the event) to fetch the spec.
-				if err := d.refreshSpec(ctx); err != nil {
-					d.log.WithError(err).Error("Failed to refresh spec")
+				select {
+				case <-d.clock.After(time.Second * 5):
+				case <-ctx.Done():
+					return ctx.Err()
 				}
-			})
 
-			if err := d.startCrons(ctx); err != nil {
-				d.log.WithError(err).Fatal("Failed to start crons")
+				continue
 			}
 
-			go func() {
-				if err := d.startGenesisLoop(ctx); err != nil {
-					d.log.WithError(err).Fatal("Failed to start genesis loop")
-				}
-			}()
+			d.startCrons(ctx)
+
+			d.runLoop(ctx, "genesis", d.startGenesisLoop)
+			d.runLoop(ctx, "epoch", d.startEpochLoop)
 
 			if err := d.fetchUpstreamRequirements(ctx); err != nil {
 				d.log.WithError(err).Error("Failed to fetch upstream requirements")
 			}
 
-			break
+			return nil
 		}
-	}()
+	})
 
 	// Subscribe to the nodes' finality updates.
 	for _, node := range d.nodes {
@@ -152,6 +265,9 @@ func (d *Default) Start(ctx context.Context) error {
 		})
 
 		n.Beacon.OnFinalityCheckpointUpdated(ctx, func(ctx context.Context, event *beacon.FinalityCheckpointUpdated) error {
+			// Listeners are called concurrently, so the node's own listener may not have recorded it yet.
+			n.setFinality(event.Finality)
+
 			logCtx.WithFields(logrus.Fields{
 				"epoch": event.Finality.Finalized.Epoch,
 				"root":  fmt.Sprintf("%#x", event.Finality.Finalized.Root),
@@ -172,75 +288,148 @@ func (d *Default) Start(ctx context.Context) error {
 
 			return nil
 		})
-
-		n.Beacon.OnReady(ctx, func(ctx context.Context, _ *beacon.ReadyEvent) error {
-			n.Beacon.Wallclock().OnEpochChanged(func(epoch ethwallclock.Epoch) {
-				time.Sleep(time.Second * 5)
-
-				if _, err := node.Beacon.FetchFinality(ctx, "head"); err != nil {
-					logCtx.WithError(err).Error("Failed to fetch finality after epoch transition")
-				}
-
-				if err := d.checkFinality(ctx); err != nil {
-					logCtx.WithError(err).Error("Failed to check finality")
-				}
-
-				if err := d.checkForNewServingCheckpoint(ctx); err != nil {
-					logCtx.WithError(err).Error("Failed to check for new serving checkpoint after epoch change")
-				}
-			})
-
-			return nil
-		})
 	}
 
 	return nil
 }
 
-func (d *Default) startCrons(ctx context.Context) error {
-	s := gocron.NewScheduler(time.Local)
-
-	if _, err := s.Every("30s").Do(func() {
+func (d *Default) startCrons(ctx context.Context) {
+	d.runLoop(ctx, "finality_check", d.every(time.Second*30, func(ctx context.Context) {
 		if err := d.checkFinality(ctx); err != nil {
 			d.log.WithError(err).Error("Failed to check finality")
 		}
-	}); err != nil {
-		return err
-	}
+	}))
 
-	if _, err := s.Every("10s").Do(func() {
+	d.runLoop(ctx, "spec_check", d.every(time.Second*10, func(ctx context.Context) {
 		if err := d.checkBeaconSpec(ctx); err != nil {
 			d.log.WithError(err).Error("Failed to check beacon chain spec")
 		}
-	}); err != nil {
-		return err
-	}
+	}))
 
-	if _, err := s.Every("3m").Do(func() {
+	d.runLoop(ctx, "finality_poll", d.every(time.Minute*3, func(ctx context.Context) {
 		for _, node := range d.nodes.Healthy(ctx) {
-			if _, err := node.Beacon.FetchFinality(ctx, "head"); err != nil {
+			if _, err := node.FetchFinality(ctx); err != nil {
 				d.log.WithError(err).Error("Failed to fetch finality when polling")
 			}
 		}
-	}); err != nil {
-		return err
+
+		if err := d.checkFinality(ctx); err != nil {
+			d.log.WithError(err).Error("Failed to check finality")
+		}
+	}))
+
+	if len(d.witnesses) > 0 {
+		d.runLoop(ctx, "witness_check", d.every(d.config.Witnesses.PollInterval, func(ctx context.Context) {
+			if err := d.checkWitnesses(ctx); err != nil {
+				d.log.WithError(err).Debug("Failed to check witnesses")
+			}
+		}))
+
+		// Compare each new serving checkpoint straight away rather than on the next poll. When disagreement
+		// blocks serving, that's already been done before it was served.
+		if !d.config.Witnesses.BlockOnDisagreement {
+			d.OnServingCheckpointUpdated(ctx, func(ctx context.Context, _ *v1.Finality) error {
+				return d.checkWitnesses(ctx)
+			})
+		}
 	}
 
+	d.runLoop(ctx, "serving", d.startServingLoop)
+	d.runLoop(ctx, "historical", d.startHistoricalLoop)
+}
+
+// every returns a loop that runs job straight away and then on every interval of the provider's clock.
+func (d *Default) every(interval time.Duration, job func(ctx context.Context)) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		ticker := d.clock.NewTicker(interval)
+		defer ticker.Stop()
+
+		job(ctx)
+
+		for {
+			select {
+			case <-ticker.C():
+				job(ctx)
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+	}
+}
+
+// runLoop runs loop in a goroutine that Stop waits for. Loops should return once ctx is done.
+func (d *Default) runLoop(ctx context.Context, name string, loop func(ctx context.Context) error) {
+	d.lifecycleMu.Lock()
+	defer d.lifecycleMu.Unlock()
+
+	if d.stopped {
+		return
+	}
+
+	d.wg.Add(1)
+
 	go func() {
-		if err := d.startServingLoop(ctx); err != nil {
-			d.log.WithError(err).Fatal("Failed to start serving loop")
+		defer d.wg.Done()
+
+		if err := loop(ctx); err != nil && !errors.Is(err, context.Canceled) {
+			d.log.WithError(err).WithField("loop", name).Error("Loop exited unexpectedly")
 		}
 	}()
+}
 
-	go func() {
-		if err := d.startHistoricalLoop(ctx); err != nil {
-			d.log.WithError(err).Fatal("Failed to start historical loop")
+// Stop stops the provider's loops and upstream nodes, then waits for in-flight work to finish or for
+// ctx to be done before stopping the caches.
+func (d *Default) Stop(ctx context.Context) error {
+	d.lifecycleMu.Lock()
+
+	if d.stopped {
+		d.lifecycleMu.Unlock()
+
+		return nil
+	}
+
+	d.stopped = true
+
+	if d.cancel != nil {
+		d.cancel()
+	}
+
+	d.lifecycleMu.Unlock()
+
+	d.log.Info("Stopping Finality provider")
+
+	var errs []error
+
+	for _, node := range d.nodes {
+		if err := node.Beacon.Stop(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("failed to stop upstream %s: %w", node.Config.Name, err))
 		}
+	}
+
+	done := make(chan struct{})
+
+	go func() {
+		d.wg.Wait()
+		close(done)
 	}()
 
-	s.StartAsync()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		errs = append(errs, fmt.Errorf("timed out waiting for the provider's loops to stop: %w", ctx.Err()))
+	}
 
-	return nil
+	d.leaderMutex.Lock()
+	d.stopLeaderNode(ctx)
+	d.leaderMutex.Unlock()
+
+	d.blocks.Stop()
+	d.states.Stop()
+	d.depositSnapshots.Stop()
+	d.blobSidecars.Stop()
+	d.dataColumnSidecars.Stop()
+
+	return errors.Join(errs...)
 }
 
 func (d *Default) fetchUpstreamRequirements(ctx context.Context) error {
@@ -270,7 +459,7 @@ func (d *Default) startGenesisLoop(ctx context.Context) error {
 
 	for {
 		select {
-		case <-time.After(time.Second * 15):
+		case <-d.clock.After(time.Second * 15):
 			if err := d.checkGenesisTime(ctx); err != nil {
 				d.log.WithError(err).Error("Failed to check genesis time")
 			}
@@ -284,15 +473,88 @@ func (d *Default) startGenesisLoop(ctx context.Context) error {
 	}
 }
 
+// startEpochLoop refreshes the spec and re-checks finality at the start of every epoch. Upstreams are given a
+// few seconds into the epoch to process the transition before their finality is fetched.
+func (d *Default) startEpochLoop(ctx context.Context) error {
+	for {
+		next, err := d.nextEpochStart()
+		if err != nil {
+			// The spec or genesis time isn't known yet.
+			select {
+			case <-d.clock.After(time.Second * 5):
+				continue
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+
+		select {
+		case <-d.clock.After(next.Sub(d.clock.Now())):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+
+		if err := d.refreshSpec(ctx); err != nil {
+			d.log.WithError(err).Error("Failed to refresh spec")
+		}
+
+		select {
+		case <-d.clock.After(time.Second * 5):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+
+		for _, node := range d.nodes.Ready(ctx) {
+			if _, err := node.FetchFinality(ctx); err != nil {
+				d.log.WithError(err).WithField("node", node.Config.Name).Error("Failed to fetch finality after epoch transition")
+			}
+		}
+
+		if err := d.checkFinality(ctx); err != nil {
+			d.log.WithError(err).Error("Failed to check finality")
+		}
+
+		if err := d.checkForNewServingCheckpoint(ctx); err != nil {
+			d.log.WithError(err).Error("Failed to check for new serving checkpoint after epoch change")
+		}
+	}
+}
+
+// nextEpochStart returns the start time of the epoch after the current one.
+func (d *Default) nextEpochStart() (time.Time, error) {
+	sp, err := d.Spec()
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	genesis := d.genesis.Load()
+	if genesis == nil {
+		return time.Time{}, errors.New("genesis time is unknown")
+	}
+
+	epochDuration := sp.SecondsPerSlot.AsDuration() * time.Duration(sp.SlotsPerEpoch)
+	if epochDuration <= 0 {
+		return time.Time{}, errors.New("invalid epoch duration")
+	}
+
+	sinceGenesis := d.clock.Now().Sub(genesis.GenesisTime)
+	if sinceGenesis < 0 {
+		return genesis.GenesisTime, nil
+	}
+
+	return genesis.GenesisTime.Add((sinceGenesis/epochDuration + 1) * epochDuration), nil
+}
+
 func (d *Default) startHistoricalLoop(ctx context.Context) error {
 	for {
 		select {
-		case <-time.After(time.Second * 15):
-			if d.head == nil || d.head.Finalized == nil {
+		case <-d.clock.After(time.Second * 15):
+			head := d.head.Load()
+			if head == nil || head.Finalized == nil {
 				continue
 			}
 
-			if err := d.fetchHistoricalCheckpoints(ctx, d.head); err != nil {
+			if err := d.fetchHistoricalCheckpoints(ctx, head); err != nil {
 				d.log.WithError(err).Error("Failed to fetch historical checkpoints")
 			}
 		case <-ctx.Done():
@@ -308,11 +570,15 @@ func (d *Default) startServingLoop(ctx context.Context) error {
 
 	for {
 		select {
-		case <-time.After(time.Second * 5):
+		case <-d.clock.After(time.Second * 5):
 			if err := d.checkForNewServingCheckpoint(ctx); err != nil {
 				d.log.WithError(err).Error("Failed to check for new serving checkpoint")
 
-				time.Sleep(time.Second * 15)
+				select {
+				case <-d.clock.After(time.Second * 15):
+				case <-ctx.Done():
+					return ctx.Err()
+				}
 			}
 		case <-ctx.Done():
 			return ctx.Err()
@@ -324,41 +590,45 @@ func (d *Default) checkForNewServingCheckpoint(ctx context.Context) error {
 	d.servingMutex.Lock()
 	defer d.servingMutex.Unlock()
 
+	head := d.head.Load()
+
 	// Don't bother checking if we don't know the head yet.
-	if d.head == nil {
+	if head == nil {
 		return errors.New("head finality is unknown")
 	}
 
-	if d.head.Finalized == nil {
+	if head.Finalized == nil {
 		return errors.New("head finalized checkpoint is unknown")
 	}
 
 	logCtx := d.log.WithFields(logrus.Fields{
-		"head_epoch": d.head.Finalized.Epoch,
-		"head_root":  fmt.Sprintf("%#x", d.head.Finalized.Root),
+		"head_epoch": head.Finalized.Epoch,
+		"head_root":  fmt.Sprintf("%#x", head.Finalized.Root),
 	})
 
+	servingBundle := d.servingBundle.Load()
+
 	// If we don't have a serving bundle already, download one.
-	if d.servingBundle == nil {
+	if servingBundle == nil {
 		logCtx.Info("No serving bundle available, downloading")
 
-		return d.downloadServingCheckpoint(ctx, d.head)
+		return d.downloadServingCheckpoint(ctx, head)
 	}
 
-	if d.servingBundle.Finalized == nil {
+	if servingBundle.Finalized == nil {
 		logCtx.Info("Serving bundle is unknown, downloading")
 
-		return d.downloadServingCheckpoint(ctx, d.head)
+		return d.downloadServingCheckpoint(ctx, head)
 	}
 
 	// If the head has moved on, download a new serving bundle.
-	if d.servingBundle.Finalized.Epoch != d.head.Finalized.Epoch {
+	if servingBundle.Finalized.Epoch != head.Finalized.Epoch {
 		logCtx.
-			WithField("serving_epoch", d.servingBundle.Finalized.Epoch).
-			WithField("serving_root", fmt.Sprintf("%#x", d.servingBundle.Finalized.Root)).
+			WithField("serving_epoch", servingBundle.Finalized.Epoch).
+			WithField("serving_root", fmt.Sprintf("%#x", servingBundle.Finalized.Root)).
 			Info("Head finality has advanced, downloading new serving bundle")
 
-		return d.downloadServingCheckpoint(ctx, d.head)
+		return d.downloadServingCheckpoint(ctx, head)
 	}
 
 	return nil
@@ -410,31 +680,32 @@ func (d *Default) Syncing(ctx context.Context) (*v1.SyncState, error) {
 		return syncState, errors.New("spec unknown")
 	}
 
-	if d.head != nil && d.head.Finalized != nil {
-		syncState.HeadSlot = phase0.Slot(d.head.Finalized.Epoch) * sp.SlotsPerEpoch
+	if head := d.head.Load(); head != nil && head.Finalized != nil {
+		syncState.HeadSlot = phase0.Slot(head.Finalized.Epoch) * sp.SlotsPerEpoch
 	}
 
-	if d.servingBundle != nil && d.servingBundle.Finalized != nil {
-		syncState.SyncDistance = syncState.HeadSlot - phase0.Slot(d.servingBundle.Finalized.Epoch)*sp.SlotsPerEpoch
+	if servingBundle := d.servingBundle.Load(); servingBundle != nil && servingBundle.Finalized != nil {
+		syncState.SyncDistance = syncState.HeadSlot - phase0.Slot(servingBundle.Finalized.Epoch)*sp.SlotsPerEpoch
 	}
 
 	return syncState, nil
 }
 
 func (d *Default) Finalized(ctx context.Context) (*v1.Finality, error) {
-	return d.servingBundle, nil
+	return d.servingBundle.Load(), nil
 }
 
 func (d *Default) Head(ctx context.Context) (*v1.Finality, error) {
-	return d.head, nil
+	return d.head.Load(), nil
 }
 
 func (d *Default) Genesis(ctx context.Context) (*v1.Genesis, error) {
-	if d.genesis == nil {
+	genesis := d.genesis.Load()
+	if genesis == nil {
 		return nil, errors.New("genesis bundle not yet available")
 	}
 
-	return d.genesis, nil
+	return genesis, nil
 }
 
 func (d *Default) setSpec(s *state.Spec) {
@@ -476,9 +747,10 @@ func (d *Default) checkFinality(ctx context.Context) error {
 
 	aggFinality := []*v1.Finality{}
 	readyNodes := d.nodes.Ready(ctx)
+	reporters := make([]string, 0, len(readyNodes))
 
 	for _, node := range readyNodes {
-		finality, err := node.Beacon.Finality()
+		finality, err := node.Finality()
 		if err != nil {
 			d.log.Infof("Failed to get finality from node %s", node.Config.Name)
 
@@ -486,15 +758,33 @@ func (d *Default) checkFinality(ctx context.Context) error {
 		}
 
 		aggFinality = append(aggFinality, finality)
+		reporters = append(reporters, node.Config.Name)
 	}
 
-	majority, err := checkpoints.NewMajorityDecider().Decide(aggFinality)
+	majority, agreement, err := checkpoints.NewMajorityDecider().Decide(aggFinality)
 	if err != nil {
 		return perrors.Wrap(err, "failed to decide majority finality")
 	}
 
-	if d.head == nil || d.head.Finalized == nil || d.head.Finalized.Root != majority.Finalized.Root {
-		d.head = majority
+	agreeing := []string{}
+
+	for i, finality := range aggFinality {
+		if finality.Finalized != nil && finality.Finalized.Root == majority.Finalized.Root {
+			agreeing = append(agreeing, reporters[i])
+		}
+	}
+
+	sort.Strings(agreeing)
+
+	d.agreement.Store(&store.Provenance{
+		FinalizedEpoch:    majority.Finalized.Epoch,
+		FinalizedRoot:     majority.Finalized.Root,
+		AgreeingUpstreams: agreeing,
+		Agreement:         agreement,
+	})
+
+	if head := d.head.Load(); head == nil || head.Finalized == nil || head.Finalized.Root != majority.Finalized.Root {
+		d.head.Store(majority)
 
 		d.publishFinalityCheckpointHeadUpdated(ctx, majority)
 
@@ -542,7 +832,7 @@ func (d *Default) checkBeaconSpec(ctx context.Context) error {
 
 func (d *Default) checkGenesisTime(ctx context.Context) error {
 	// No-Op if we already have a genesis time
-	if d.genesis != nil {
+	if d.genesis.Load() != nil {
 		return nil
 	}
 
@@ -559,7 +849,7 @@ func (d *Default) checkGenesisTime(ctx context.Context) error {
 	}
 
 	// store the genesis time
-	d.genesis = g
+	d.genesis.Store(g)
 
 	d.log.Info("Fetched genesis time")
 
@@ -578,6 +868,19 @@ func (d *Default) publishFinalityCheckpointHeadUpdated(_ context.Context, checkp
 	d.broker.Emit(topicFinalityHeadUpdated, checkpoint)
 }
 
+// OnServingCheckpointUpdated registers a callback for when a new finalized checkpoint starts being served.
+func (d *Default) OnServingCheckpointUpdated(ctx context.Context, cb func(ctx context.Context, checkpoint *v1.Finality) error) {
+	d.broker.On(topicServingCheckpointUpdated, func(checkpoint *v1.Finality) {
+		if err := cb(ctx, checkpoint); err != nil {
+			d.log.WithError(err).Error("Failed to handle serving checkpoint updated")
+		}
+	})
+}
+
+func (d *Default) publishServingCheckpointUpdated(_ context.Context, checkpoint *v1.Finality) {
+	d.broker.Emit(topicServingCheckpointUpdated, checkpoint)
+}
+
 func (d *Default) GetBlockBySlot(ctx context.Context, slot phase0.Slot) (*spec.VersionedSignedBeaconBlock, error) {
 	block, err := d.blocks.GetBySlot(slot)
 	if err != nil {
@@ -591,6 +894,20 @@ func (d *Default) GetBlockBySlot(ctx context.Context, slot phase0.Slot) (*spec.V
 	return block, nil
 }
 
+func (d *Default) GetBlockSignatureStatusBySlot(ctx context.Context, slot phase0.Slot) (verify.Status, error) {
+	block, err := d.GetBlockBySlot(ctx, slot)
+	if err != nil {
+		return "", err
+	}
+
+	root, err := d.sszEncoder.GetBlockRoot(block)
+	if err != nil {
+		return "", err
+	}
+
+	return d.blocks.GetSignatureStatus(root)
+}
+
 func (d *Default) GetBlockByRoot(ctx context.Context, root phase0.Root) (*spec.VersionedSignedBeaconBlock, error) {
 	block, err := d.blocks.GetByRoot(root)
 	if err != nil {
@@ -617,10 +934,46 @@ func (d *Default) GetBlockByStateRoot(ctx context.Context, stateRoot phase0.Root
 	return block, nil
 }
 
+func (d *Default) GetBlockProvenance(ctx context.Context, root phase0.Root) (*store.Provenance, error) {
+	return d.blocks.GetProvenance(root)
+}
+
+func (d *Default) GetBeaconStateProvenance(ctx context.Context, stateRoot phase0.Root) (*store.Provenance, error) {
+	return d.states.GetProvenance(stateRoot)
+}
+
+// newProvenance returns the provenance of an entry fetched from source now, under checkpoint. The majority
+// decision's agreement is only recorded if it was reached on the same checkpoint. checkpoint is nil for entries
+// that aren't fetched under one, like the genesis bundle.
+func (d *Default) newProvenance(source string, checkpoint *v1.Finality) *store.Provenance {
+	provenance := &store.Provenance{
+		Source:    source,
+		FetchedAt: d.clock.Now(),
+	}
+
+	if checkpoint == nil || checkpoint.Finalized == nil {
+		return provenance
+	}
+
+	provenance.FinalizedEpoch = checkpoint.Finalized.Epoch
+	provenance.FinalizedRoot = checkpoint.Finalized.Root
+
+	if agreement := d.agreement.Load(); agreement != nil && agreement.FinalizedRoot == checkpoint.Finalized.Root {
+		provenance.AgreeingUpstreams = append([]string(nil), agreement.AgreeingUpstreams...)
+		provenance.Agreement = agreement.Agreement
+	}
+
+	return provenance
+}
+
 func (d *Default) GetBlobSidecarsBySlot(ctx context.Context, slot phase0.Slot) ([]*deneb.BlobSidecar, error) {
 	return d.blobSidecars.GetBySlot(slot)
 }
 
+func (d *Default) GetDataColumnSidecarsBySlot(ctx context.Context, slot phase0.Slot) ([]*fulu.DataColumnSidecar, error) {
+	return d.dataColumnSidecars.GetBySlot(slot)
+}
+
 func (d *Default) GetBeaconStateBySlot(ctx context.Context, slot phase0.Slot) (*spec.VersionedBeaconState, error) {
 	block, err := d.GetBlockBySlot(ctx, slot)
 	if err != nil {
@@ -653,13 +1006,14 @@ func (d *Default) GetBeaconStateByRoot(ctx context.Context, root phase0.Root) (*
 	return d.states.GetByStateRoot(stateRoot)
 }
 
-func (d *Default) storeBlock(_ context.Context, block *spec.VersionedSignedBeaconBlock) error {
-	_, err := d.Spec()
+// storeBlock verifies and stores the block. The provenance is only recorded if the block wasn't already stored.
+func (d *Default) storeBlock(_ context.Context, block *spec.VersionedSignedBeaconBlock, provenance *store.Provenance) error {
+	sp, err := d.Spec()
 	if err != nil {
 		return err
 	}
 
-	if d.genesis == nil {
+	if d.genesis.Load() == nil {
 		return errors.New("genesis time is unknown")
 	}
 
@@ -672,27 +1026,84 @@ func (d *Default) storeBlock(_ context.Context, block *spec.VersionedSignedBeaco
 		return err
 	}
 
+	slot, err := block.Slot()
+	if err != nil {
+		return err
+	}
+
 	exists, err := d.blocks.GetByRoot(root)
 	if err == nil && exists != nil {
+		// A block stored before its state was available can be verified now.
+		if status, err := d.blocks.GetSignatureStatus(root); err == nil && status == verify.StatusUnverified {
+			status, err = d.verifyBlockSignature(exists, root, slot, uint64(sp.SlotsPerEpoch))
+			if err != nil {
+				d.blocks.Delete(root)
+
+				return err
+			}
+
+			return d.blocks.SetSignatureStatus(root, status)
+		}
+
 		return nil
 	}
 
-	slot, err := block.Slot()
+	expiresAt := d.clock.Now().Add(FinalityHaltedServingPeriod)
+
+	if slot == phase0.Slot(0) {
+		expiresAt = d.clock.Now().Add(999999 * time.Hour)
+	}
+
+	status, err := d.verifyBlockSignature(block, root, slot, uint64(sp.SlotsPerEpoch))
 	if err != nil {
 		return err
 	}
 
-	expiresAt := time.Now().Add(FinalityHaltedServingPeriod)
+	if err := d.blocks.Add(root, block, expiresAt); err != nil {
+		return err
+	}
 
+	if err := d.blocks.SetProvenance(root, provenance); err != nil {
+		return err
+	}
+
+	return d.blocks.SetSignatureStatus(root, status)
+}
+
+// verifyBlockSignature verifies the block's proposer signature if enabled and the block's post-state is
+// available and was checked against its root. An error is only returned if the signature was checked and
+// found to be invalid.
+func (d *Default) verifyBlockSignature(block *spec.VersionedSignedBeaconBlock, root phase0.Root, slot phase0.Slot, slotsPerEpoch uint64) (verify.Status, error) {
+	if !d.config.VerifyBlockSignatures {
+		return verify.StatusDisabled, nil
+	}
+
+	// The genesis block is not signed.
 	if slot == phase0.Slot(0) {
-		expiresAt = time.Now().Add(999999 * time.Hour)
+		return verify.StatusUnverified, nil
 	}
 
-	if err := d.blocks.Add(root, block, expiresAt); err != nil {
-		return err
+	stateRoot, err := block.StateRoot()
+	if err != nil {
+		return "", err
 	}
 
-	return nil
+	beaconState, err := d.states.GetByStateRoot(stateRoot)
+	if err != nil || beaconState == nil {
+		return verify.StatusUnverified, nil
+	}
+
+	// The proposer's key is read from the state, so it must be the state the block committed to.
+	provenance, err := d.states.GetProvenance(stateRoot)
+	if err != nil || provenance.VerifiedAt == nil {
+		return verify.StatusUnverified, nil
+	}
+
+	if err := verify.BlockSignature(block, root, beaconState, slotsPerEpoch); err != nil {
+		return "", fmt.Errorf("failed to verify block signature: %w", err)
+	}
+
+	return verify.StatusVerified, nil
 }
 
 func (d *Default) UpstreamsStatus(ctx context.Context) (map[string]*UpstreamStatus, error) {
@@ -716,7 +1127,7 @@ func (d *Default) UpstreamsStatus(ctx context.Context) (map[string]*UpstreamStat
 			rsp[node.Config.Name].NetworkName = network
 		}
 
-		finality, err := node.Beacon.Finality()
+		finality, err := node.Finality()
 		if err != nil {
 			continue
 		}
@@ -778,11 +1189,12 @@ func (d *Default) GetSlotTime(ctx context.Context, slot phase0.Slot) (eth.SlotTi
 		return SlotTime, errors.New("no upstream beacon state spec available")
 	}
 
-	if d.genesis == nil {
+	genesis := d.genesis.Load()
+	if genesis == nil {
 		return SlotTime, errors.New("genesis time is unknown")
 	}
 
-	return eth.CalculateSlotTime(slot, d.genesis.GenesisTime, d.spec.SecondsPerSlot.AsDuration()), nil
+	return eth.CalculateSlotTime(slot, genesis.GenesisTime, d.spec.SecondsPerSlot.AsDuration()), nil
 }
 
 func (d *Default) GetDepositSnapshot(ctx context.Context, epoch phase0.Epoch) (*types.DepositSnapshot, error) {
@@ -0,0 +1,240 @@
+package beacon
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	v1 "github.com/attestantio/go-eth2-client/api/v1"
+	"github.com/attestantio/go-eth2-client/spec"
+	"github.com/attestantio/go-eth2-client/spec/altair"
+	"github.com/attestantio/go-eth2-client/spec/deneb"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/creasty/defaults"
+	"github.com/ethpandaops/beacon/pkg/beacon/state"
+	"github.com/ethpandaops/checkpointz/pkg/beacon/archive"
+	"github.com/ethpandaops/checkpointz/pkg/beacon/beacontest"
+	"github.com/ethpandaops/checkpointz/pkg/beacon/verify"
+	"github.com/ethpandaops/checkpointz/pkg/clock"
+	"github.com/holiman/uint256"
+	"github.com/sirupsen/logrus"
+	logtest "github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDefaultStopWaitsForLoops(t *testing.T) {
+	config := &Config{}
+	require.NoError(t, defaults.Set(config))
+
+	provider := NewDefaultProvider("test_default_stop", logrus.New(), nil, nil, config, clock.New())
+
+	require.NoError(t, provider.Start(context.Background()))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	require.NoError(t, provider.Stop(ctx))
+	require.NoError(t, provider.Stop(ctx), "stopping twice should be a no-op")
+
+	assert.Error(t, provider.Start(context.Background()), "a stopped provider can't be restarted")
+}
+
+// newSimulatedProvider returns a provider with no upstreams whose time is driven by the returned mock clock.
+func newSimulatedProvider(t *testing.T, namespace string, genesis time.Time) (*Default, *clock.Mock) {
+	t.Helper()
+
+	config := &Config{}
+	require.NoError(t, defaults.Set(config))
+
+	mock := clock.NewMock(genesis)
+
+	provider, ok := NewDefaultProvider(namespace, logrus.New(), nil, nil, config, mock).(*Default)
+	require.True(t, ok)
+
+	provider.spec = &state.Spec{
+		SlotsPerEpoch:  32,
+		SecondsPerSlot: state.StringerDuration(12 * time.Second),
+	}
+	provider.genesis.Store(&v1.Genesis{GenesisTime: genesis})
+
+	t.Cleanup(func() {
+		require.NoError(t, provider.Stop(context.Background()))
+	})
+
+	return provider, mock
+}
+
+func TestStoredBlockExpiresAfterFinalityHaltedServingPeriod(t *testing.T) {
+	genesis := time.Date(2020, 12, 1, 12, 0, 23, 0, time.UTC)
+
+	provider, mock := newSimulatedProvider(t, "test_default_expiry", genesis)
+
+	block := &spec.VersionedSignedBeaconBlock{
+		Version: spec.DataVersionDeneb,
+		Deneb: &deneb.SignedBeaconBlock{
+			Message: &deneb.BeaconBlock{
+				Slot: 64,
+				Body: &deneb.BeaconBlockBody{
+					ETH1Data:         &phase0.ETH1Data{BlockHash: make([]byte, 32)},
+					SyncAggregate:    &altair.SyncAggregate{SyncCommitteeBits: make([]byte, 64)},
+					ExecutionPayload: &deneb.ExecutionPayload{BaseFeePerGas: uint256.NewInt(0)},
+				},
+			},
+		},
+	}
+
+	require.NoError(t, provider.storeBlock(context.Background(), block, provider.newProvenance("test", nil)))
+
+	// Wait for every store's expiry loop to start ticking.
+	mock.BlockUntil(5)
+
+	mock.Advance(FinalityHaltedServingPeriod - time.Minute)
+
+	_, err := provider.GetBlockBySlot(context.Background(), 64)
+	require.NoError(t, err, "block should be served until the finality halted serving period has passed")
+
+	mock.Advance(2 * time.Minute)
+
+	assert.Eventually(t, func() bool {
+		block, err := provider.GetBlockBySlot(context.Background(), 64)
+
+		return err != nil || block == nil
+	}, 5*time.Second, 10*time.Millisecond, "block should expire after the finality halted serving period")
+}
+
+func TestEveryRunsOnTheProviderClock(t *testing.T) {
+	provider, mock := newSimulatedProvider(t, "test_default_every", time.Date(2020, 12, 1, 12, 0, 23, 0, time.UTC))
+
+	runs := make(chan time.Time, 10)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	provider.runLoop(ctx, "test", provider.every(time.Minute, func(ctx context.Context) {
+		runs <- mock.Now()
+	}))
+
+	start := <-runs
+
+	for i := 1; i <= 3; i++ {
+		// The 5 store tickers plus the job's own.
+		mock.BlockUntil(6)
+		mock.Advance(time.Minute)
+
+		select {
+		case at := <-runs:
+			assert.Equal(t, start.Add(time.Duration(i)*time.Minute), at)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("job did not run on tick %d", i)
+		}
+	}
+}
+
+func TestNextEpochStart(t *testing.T) {
+	genesis := time.Date(2020, 12, 1, 12, 0, 23, 0, time.UTC)
+	epoch := 32 * 12 * time.Second
+
+	provider, mock := newSimulatedProvider(t, "test_default_next_epoch", genesis.Add(-time.Minute))
+
+	provider.genesis.Store(&v1.Genesis{GenesisTime: genesis})
+
+	next, err := provider.nextEpochStart()
+	require.NoError(t, err)
+	assert.Equal(t, genesis, next, "the first epoch starts at genesis")
+
+	mock.Set(genesis)
+
+	next, err = provider.nextEpochStart()
+	require.NoError(t, err)
+	assert.Equal(t, genesis.Add(epoch), next, "an epoch boundary waits for the following epoch")
+
+	mock.Advance(3*epoch + time.Second)
+
+	next, err = provider.nextEpochStart()
+	require.NoError(t, err)
+	assert.Equal(t, genesis.Add(4*epoch), next)
+}
+
+func TestHistoricalBlockIsAbandonedAfterRepeatedFailures(t *testing.T) {
+	genesis := time.Date(2020, 12, 1, 12, 0, 23, 0, time.UTC)
+
+	provider, mock := newSimulatedProvider(t, "test_default_historical_failures", genesis)
+
+	log, hook := logtest.NewNullLogger()
+	provider.log = log
+
+	// With nothing in the archive and no upstreams, every attempt at the genesis block fails.
+	provider.archives = []*archive.Archive{archive.New(log, archive.Config{Name: "empty", Path: t.TempDir()}, provider.sszEncoder)}
+	provider.head.Store(&v1.Finality{Finalized: &phase0.Checkpoint{Epoch: 0}})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	provider.runLoop(ctx, "historical", provider.startHistoricalLoop)
+
+	count := func(message string) int {
+		n := 0
+
+		for _, entry := range hook.AllEntries() {
+			if entry.Message == message {
+				n++
+			}
+		}
+
+		return n
+	}
+
+	require.Eventually(t, func() bool {
+		mock.Advance(time.Second)
+
+		return count("Failed to download historical block") >= historicalFailureLimit
+	}, 5*time.Second, time.Millisecond)
+
+	// Let a few more rounds of the historical loop go by.
+	for range 5 * 15 {
+		mock.Advance(time.Second)
+		time.Sleep(time.Millisecond)
+	}
+
+	assert.Equal(t, historicalFailureLimit, count("Failed to download historical block"), "the slot should no longer be attempted")
+	assert.Equal(t, 1, count("No longer attempting to download historical block - too many failures"))
+}
+
+func TestBlockSignatureIsOnlyVerifiedAgainstCheckedStates(t *testing.T) {
+	chain, err := beacontest.NewChain(2)
+	require.NoError(t, err)
+
+	provider, mock := newSimulatedProvider(t, "test_default_signature_states", chain.GenesisTime())
+	provider.config.VerifyBlockSignatures = true
+
+	block, root, ok := chain.Block(1)
+	require.True(t, ok)
+
+	beaconState, ok := chain.State(1)
+	require.True(t, ok)
+
+	stateRoot, err := block.StateRoot()
+	require.NoError(t, err)
+
+	slot, err := block.Slot()
+	require.NoError(t, err)
+
+	provenance := provider.newProvenance("upstream", nil)
+
+	require.NoError(t, provider.states.Add(stateRoot, beaconState, mock.Now().Add(time.Hour), slot))
+	require.NoError(t, provider.states.SetProvenance(stateRoot, provenance))
+
+	status, err := provider.verifyBlockSignature(block, root, slot, 32)
+	require.NoError(t, err)
+	assert.Equal(t, verify.StatusUnverified, status, "the state wasn't checked against its root")
+
+	verifiedAt := mock.Now()
+	provenance.VerifiedAt = &verifiedAt
+
+	require.NoError(t, provider.states.SetProvenance(stateRoot, provenance))
+
+	status, err = provider.verifyBlockSignature(block, root, slot, 32)
+	require.NoError(t, err)
+	assert.Equal(t, verify.StatusVerified, status)
+}
@@ -4,14 +4,22 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strconv"
 	"time"
 
 	v1 "github.com/attestantio/go-eth2-client/api/v1"
 	"github.com/attestantio/go-eth2-client/spec"
+	"github.com/attestantio/go-eth2-client/spec/deneb"
 	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/ethpandaops/beacon/pkg/beacon/state"
+	"github.com/ethpandaops/checkpointz/pkg/beacon/fulu"
+	"github.com/ethpandaops/checkpointz/pkg/beacon/verify"
 	"github.com/ethpandaops/checkpointz/pkg/eth"
+	"github.com/ethpandaops/checkpointz/pkg/tracing"
 	perrors "github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 func (d *Default) downloadServingCheckpoint(ctx context.Context, checkpoint *v1.Finality) error {
@@ -38,16 +46,12 @@ func (d *Default) downloadServingCheckpoint(ctx context.Context, checkpoint *v1.
 		WithField("fork_name", fork.Name).
 		Info("Downloading serving checkpoint")
 
-	upstream, err := d.nodes.
-		Ready(ctx).
-		DataProviders(ctx).
-		PastFinalizedCheckpoint(ctx, checkpoint). // Ensure we attempt to fetch the bundle from a node that knows about the checkpoint.
-		RandomNode(ctx)
+	upstream, err := d.dataProvider(ctx, checkpoint)
 	if err != nil {
 		return perrors.Wrap(err, "no data provider node available")
 	}
 
-	block, err := d.fetchBundle(ctx, checkpoint.Finalized.Root, upstream)
+	block, err := d.fetchBundle(ctx, checkpoint.Finalized.Root, checkpoint, upstream)
 	if err != nil {
 		return perrors.Wrap(err, "failed to fetch bundle")
 	}
@@ -63,7 +67,15 @@ func (d *Default) downloadServingCheckpoint(ctx context.Context, checkpoint *v1.
 		return fmt.Errorf("block slot is not aligned from an epoch boundary: %d", blockSlot)
 	}
 
-	d.servingBundle = checkpoint
+	if d.config.Witnesses.BlockOnDisagreement {
+		if err := d.verifyWithWitnesses(ctx, checkpoint.Finalized, blockSlot); err != nil {
+			return fmt.Errorf("refusing to serve checkpoint: %w", err)
+		}
+	}
+
+	d.pinServingBundle(d.servingBundle.Load(), checkpoint)
+
+	d.servingBundle.Store(checkpoint)
 	d.metrics.ObserveServingEpoch(checkpoint.Finalized.Epoch)
 
 	d.log.WithFields(
@@ -73,6 +85,8 @@ func (d *Default) downloadServingCheckpoint(ctx context.Context, checkpoint *v1.
 		},
 	).Info("Serving a new finalized checkpoint bundle")
 
+	d.publishServingCheckpointUpdated(ctx, checkpoint)
+
 	return nil
 }
 
@@ -95,18 +109,27 @@ func (d *Default) checkGenesis(ctx context.Context) error {
 
 	d.log.Debug("Fetching genesis state")
 
-	readyNodes := d.nodes.Ready(ctx)
-	if len(readyNodes) == 0 {
-		return errors.New("no nodes ready")
-	}
-
-	// Grab the genesis root
-	randomNode, err := readyNodes.RandomNode(ctx)
+	upstream, err := d.dataProvider(ctx, nil)
 	if err != nil {
 		return err
 	}
 
-	genesisBlock, err := randomNode.Beacon.FetchBlock(ctx, "genesis")
+	// Grab the genesis root from any ready node, unless we follow a leader that has it.
+	source := upstream
+
+	if !d.following() {
+		readyNodes := d.nodes.Ready(ctx)
+		if len(readyNodes) == 0 {
+			return errors.New("no nodes ready")
+		}
+
+		source, err = readyNodes.RandomNode(ctx)
+		if err != nil {
+			return err
+		}
+	}
+
+	genesisBlock, err := source.Beacon.FetchBlock(ctx, "genesis")
 	if err != nil {
 		return err
 	}
@@ -120,13 +143,8 @@ func (d *Default) checkGenesis(ctx context.Context) error {
 		return err
 	}
 
-	upstream, err := d.nodes.Ready(ctx).DataProviders(ctx).RandomNode(ctx)
-	if err != nil {
-		return err
-	}
-
 	// Fetch the bundle
-	if _, err := d.fetchBundle(ctx, genesisBlockRoot, upstream); err != nil {
+	if _, err := d.fetchBundle(ctx, genesisBlockRoot, nil, upstream); err != nil {
 		return err
 	}
 
@@ -137,6 +155,10 @@ func (d *Default) checkGenesis(ctx context.Context) error {
 	return nil
 }
 
+// historicalFailureLimit is the amount of times we'll try to download a block
+// before we permanently give up.
+const historicalFailureLimit = 5
+
 func (d *Default) fetchHistoricalCheckpoints(ctx context.Context, checkpoint *v1.Finality) error {
 	d.historicalMutex.Lock()
 	defer d.historicalMutex.Unlock()
@@ -146,18 +168,21 @@ func (d *Default) fetchHistoricalCheckpoints(ctx context.Context, checkpoint *v1
 		return errors.New("chain spec unavailable")
 	}
 
-	if d.genesis == nil {
+	if d.genesis.Load() == nil {
 		return errors.New("genesis time unavailable")
 	}
 
-	// Download the previous n epochs worth of epoch boundaries if they don't already exist
-	upstream, err := d.nodes.
-		Ready(ctx).
-		DataProviders(ctx).
-		PastFinalizedCheckpoint(ctx, checkpoint).
-		RandomNode(ctx)
+	// Download the previous n epochs worth of epoch boundaries if they don't already exist. Archives can
+	// provide them without a data provider node.
+	following := d.following()
+
+	upstream, err := d.dataProvider(ctx, checkpoint)
 	if err != nil {
-		return errors.New("no data provider node available")
+		if len(d.archives) == 0 {
+			return errors.New("no data provider node available")
+		}
+
+		upstream = nil
 	}
 
 	slotsInScope := make(map[phase0.Slot]struct{})
@@ -165,10 +190,6 @@ func (d *Default) fetchHistoricalCheckpoints(ctx context.Context, checkpoint *v1
 	// We always care about the genesis slot.
 	slotsInScope[0] = struct{}{}
 
-	// historicalFailureLimit is the amount of times we'll try to download a block
-	// before we permanently give up.
-	historicalFailureLimit := 5
-
 	// Calculate the epoch boundaries we need to fetch
 	// We'll derive the current finalized slot and then work back in intervals of SLOTS_PER_EPOCH.
 	currentSlot := uint64(checkpoint.Finalized.Epoch) * uint64(sp.SlotsPerEpoch)
@@ -196,8 +217,11 @@ func (d *Default) fetchHistoricalCheckpoints(ctx context.Context, checkpoint *v1
 			continue
 		}
 
-		if _, err := d.downloadBlock(ctx, slot, upstream); err != nil {
-			failureCount++
+		if _, err := d.downloadBlock(ctx, slot, checkpoint, upstream); err != nil {
+			// The leader may not have downloaded the block yet, so followers don't give up on it.
+			if !following {
+				failureCount++
+			}
 
 			d.log.WithError(err).
 				WithField("slot", eth.SlotAsString(slot)).
@@ -213,7 +237,11 @@ func (d *Default) fetchHistoricalCheckpoints(ctx context.Context, checkpoint *v1
 
 		d.historicalSlotFailures[slot] = failureCount
 
-		time.Sleep(50 * time.Millisecond)
+		select {
+		case <-d.clock.After(50 * time.Millisecond):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
 	}
 
 	// Cleanup any banned slots that we don't care about anymore to prevent leaking memory.
@@ -226,15 +254,18 @@ func (d *Default) fetchHistoricalCheckpoints(ctx context.Context, checkpoint *v1
 	return nil
 }
 
-func (d *Default) downloadBlock(ctx context.Context, slot phase0.Slot, upstream *Node) (*spec.VersionedSignedBeaconBlock, error) {
+func (d *Default) downloadBlock(ctx context.Context, slot phase0.Slot, checkpoint *v1.Finality, upstream *Node) (_ *spec.VersionedSignedBeaconBlock, err error) {
+	ctx, span := tracing.Start(ctx, "beacon.downloadBlock", trace.WithAttributes(attribute.Int64("eth.slot", int64(slot))))
+	defer func() { tracing.End(span, err) }()
+
 	// If we don't know genesis time yet, don't bother fetching blocks as
 	// we won't be able to calculate an expiry.
-	if d.genesis == nil {
+	if d.genesis.Load() == nil {
 		return nil, errors.New("genesis time not known")
 	}
 
 	// Same thing with the chain spec.
-	_, err := d.Spec()
+	_, err = d.Spec()
 	if err != nil {
 		return nil, errors.New("chain spec not known")
 	}
@@ -245,12 +276,24 @@ func (d *Default) downloadBlock(ctx context.Context, slot phase0.Slot, upstream
 		return bl, nil
 	}
 
-	// Download the block from our upstream.
-	block, err := upstream.Beacon.FetchBlock(ctx, eth.SlotAsString(slot))
-	if err != nil {
-		return nil, err
+	// Read the block from an archive if one holds it and its root checks out, otherwise download it from our
+	// upstream.
+	block, source, verified := d.archiveBlockWithRoot(ctx, slot, upstream)
+	if block == nil {
+		if upstream == nil {
+			return nil, fmt.Errorf("no archive holds the block at slot %d and no data provider node is available", slot)
+		}
+
+		block, err = d.fetchUpstreamBlock(ctx, upstream, eth.SlotAsString(slot))
+		if err != nil {
+			return nil, err
+		}
+
+		source = upstream.Config.Name
 	}
 
+	span.SetAttributes(attribute.String("checkpointz.source", source))
+
 	if block == nil {
 		return nil, errors.New("invalid block")
 	}
@@ -265,7 +308,17 @@ func (d *Default) downloadBlock(ctx context.Context, slot phase0.Slot, upstream
 		return nil, err
 	}
 
-	if err := d.storeBlock(ctx, block); err != nil {
+	// Blocks downloaded by slot can't be checked against a root.
+	provenance := d.newProvenance(source, checkpoint)
+
+	if verified {
+		verifiedAt := d.clock.Now()
+		provenance.VerifiedAt = &verifiedAt
+	}
+
+	if err := d.storeBlock(ctx, block, provenance); err != nil {
+		d.observeBlockSignatureFailure(err, source)
+
 		return nil, err
 	}
 
@@ -274,29 +327,46 @@ func (d *Default) downloadBlock(ctx context.Context, slot phase0.Slot, upstream
 			"slot":       slot,
 			"root":       eth.RootAsString(root),
 			"state_root": eth.RootAsString(stateRoot),
-			"node":       upstream.Config.Name,
+			"node":       source,
 		}).
 		Infof("Downloaded and stored block for slot %d", slot)
 
 	return block, nil
 }
 
-func (d *Default) fetchBundle(ctx context.Context, root phase0.Root, upstream *Node) (*spec.VersionedSignedBeaconBlock, error) {
+func (d *Default) fetchBundle(ctx context.Context, root phase0.Root, checkpoint *v1.Finality, upstream *Node) (_ *spec.VersionedSignedBeaconBlock, err error) {
+	ctx, span := tracing.Start(ctx, "beacon.fetchBundle", trace.WithAttributes(
+		attribute.String("eth.block_root", eth.RootAsString(root)),
+		attribute.String("checkpointz.upstream", upstream.Config.Name),
+	))
+	defer func() { tracing.End(span, err) }()
+
 	d.log.Infof("Fetching bundle from node %s with root %#x", upstream.Config.Name, root)
 
+	source := upstream.Config.Name
+
 	block, err := d.blocks.GetByRoot(root)
 	if err != nil || block == nil {
-		// Download the block.
-		block, err = upstream.Beacon.FetchBlock(ctx, fmt.Sprintf("%#x", root))
-		if err != nil {
-			return nil, err
-		}
+		// Read the block from remote storage if another replica shared it, otherwise download it.
+		var shared string
+
+		block, shared = d.remoteBlockWithRoot(ctx, root)
+		if block != nil {
+			source = shared
+		} else {
+			block, err = d.fetchUpstreamBlock(ctx, upstream, fmt.Sprintf("%#x", root))
+			if err != nil {
+				return nil, err
+			}
 
-		if block == nil {
-			return nil, errors.New("block is nil")
+			if block == nil {
+				return nil, errors.New("block is nil")
+			}
 		}
 	}
 
+	provenance := d.newProvenance(source, checkpoint)
+
 	stateRoot, err := block.StateRoot()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get state root from block: %w", err)
@@ -311,27 +381,39 @@ func (d *Default) fetchBundle(ctx context.Context, root phase0.Root, upstream *N
 		return nil, fmt.Errorf("block root does not match: %#x != %#x", blockRoot, root)
 	}
 
+	verifiedAt := d.clock.Now()
+	provenance.VerifiedAt = &verifiedAt
+
 	slot, err := block.Slot()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get slot from block: %w", err)
 	}
 
+	span.SetAttributes(attribute.Int64("eth.slot", int64(slot)))
+
 	d.log.
 		WithField("slot", slot).
 		WithField("root", fmt.Sprintf("%#x", blockRoot)).
 		WithField("state_root", fmt.Sprintf("%#x", stateRoot)).
 		Info("Fetched beacon block")
 
-	err = d.storeBlock(ctx, block)
+	if d.shouldDownloadStates() {
+		// Download and store beacon state. This happens before the block is stored so that the
+		// block's signature can be verified against it.
+		if err = d.downloadAndStoreBeaconState(ctx, stateRoot, slot, checkpoint, upstream); err != nil {
+			return nil, fmt.Errorf("failed to download and store beacon state: %w", err)
+		}
+	}
+
+	err = d.storeBlock(ctx, block, provenance)
 	if err != nil {
+		d.observeBlockSignatureFailure(err, source)
+
 		return nil, fmt.Errorf("failed to store block: %w", err)
 	}
 
-	if d.shouldDownloadStates() {
-		// Download and store beacon state
-		if err = d.downloadAndStoreBeaconState(ctx, stateRoot, slot, upstream); err != nil {
-			return nil, fmt.Errorf("failed to download and store beacon state: %w", err)
-		}
+	if d.remote != nil && source != d.remote.Name() {
+		d.shareBlock(ctx, blockRoot, block)
 	}
 
 	sp, err := d.Spec()
@@ -350,18 +432,10 @@ func (d *Default) fetchBundle(ctx context.Context, root phase0.Root, upstream *N
 	}
 
 	denebFork, err := sp.ForkEpochs.GetByName("deneb")
-	if err == nil && denebFork != nil {
-		if denebFork.Active(epoch) {
-			// Check if Fulu is active - if so, don't fetch blobs as they're no longer in blocks
-			fuluFork, fuluErr := sp.ForkEpochs.GetByName("fulu")
-			if fuluErr == nil && fuluFork != nil && fuluFork.Active(epoch) {
-				d.log.WithField("epoch", epoch).Debug("Skipping blob sidecar download - Fulu fork active")
-			} else {
-				// Download and store blob sidecars
-				if err := d.downloadAndStoreBlobSidecars(ctx, slot, upstream); err != nil {
-					return nil, fmt.Errorf("failed to download and store blob sidecars: %w", err)
-				}
-			}
+	if err == nil && denebFork != nil && denebFork.Active(epoch) {
+		// Download and store blob sidecars (or data column sidecars once Fulu is active)
+		if err := d.downloadAndStoreBlobSidecars(ctx, slot, upstream); err != nil {
+			return nil, fmt.Errorf("failed to download and store blob sidecars: %w", err)
 		}
 	}
 
@@ -373,54 +447,134 @@ func (d *Default) fetchBundle(ctx context.Context, root phase0.Root, upstream *N
 	return block, nil
 }
 
-func (d *Default) downloadAndStoreBeaconState(ctx context.Context, stateRoot phase0.Root, slot phase0.Slot, node *Node) error {
+func (d *Default) downloadAndStoreBeaconState(ctx context.Context, stateRoot phase0.Root, slot phase0.Slot, checkpoint *v1.Finality, node *Node) (err error) {
+	ctx, span := tracing.Start(ctx, "beacon.downloadAndStoreBeaconState", trace.WithAttributes(
+		attribute.String("eth.state_root", eth.RootAsString(stateRoot)),
+		attribute.Int64("eth.slot", int64(slot)),
+	))
+	defer func() { tracing.End(span, err) }()
+
 	// If the state already exists, don't bother downloading it again.
 	existingState, err := d.states.GetByStateRoot(stateRoot)
 	if err == nil && existingState != nil {
+		span.SetAttributes(attribute.Bool("checkpointz.cached", true))
+
 		return nil
 	}
 
-	beaconState, err := node.Beacon.FetchBeaconState(ctx, eth.SlotAsString(slot))
-	if err != nil {
-		return fmt.Errorf("failed to fetch beacon state: %w", err)
+	// States are only used if they have the expected root, wherever they come from.
+	var remoteETag string
+
+	beaconState, source := d.archiveStateWithRoot(ctx, slot, stateRoot)
+	if beaconState == nil {
+		beaconState, source, remoteETag = d.remoteStateWithRoot(ctx, stateRoot)
 	}
 
 	if beaconState == nil {
-		return errors.New("beacon state is nil")
+		beaconState, err = d.fetchUpstreamState(ctx, node, eth.SlotAsString(slot))
+		if err != nil {
+			return fmt.Errorf("failed to fetch beacon state: %w", err)
+		}
+
+		if beaconState == nil {
+			return errors.New("beacon state is nil")
+		}
+
+		root, err := d.sszEncoder.GetStateRoot(ctx, beaconState)
+		if err != nil {
+			return fmt.Errorf("failed to get state root: %w", err)
+		}
+
+		if root != stateRoot {
+			return fmt.Errorf("state root does not match: %#x != %#x", root, stateRoot)
+		}
+
+		source = node.Config.Name
 	}
 
-	expiresAt := time.Now().Add(FinalityHaltedServingPeriod)
+	provenance := d.newProvenance(source, checkpoint)
+
+	provenance.RemoteETag = remoteETag
+
+	verifiedAt := d.clock.Now()
+	provenance.VerifiedAt = &verifiedAt
+
+	span.SetAttributes(attribute.String("checkpointz.source", source))
+
+	expiresAt := d.clock.Now().Add(FinalityHaltedServingPeriod)
 	if slot == phase0.Slot(0) {
-		expiresAt = time.Now().Add(999999 * time.Hour)
+		expiresAt = d.clock.Now().Add(999999 * time.Hour)
 	}
 
 	if err := d.states.Add(stateRoot, beaconState, expiresAt, slot); err != nil {
 		return fmt.Errorf("failed to store beacon state: %w", err)
 	}
 
+	if err := d.states.SetProvenance(stateRoot, provenance); err != nil {
+		return fmt.Errorf("failed to store beacon state provenance: %w", err)
+	}
+
+	if d.remote != nil && source != d.remote.Name() {
+		d.shareState(ctx, stateRoot, beaconState)
+	}
+
 	return nil
 }
 
+// fetchUpstreamBlock fetches the block identified by blockID from upstream.
+func (d *Default) fetchUpstreamBlock(ctx context.Context, upstream *Node, blockID string) (block *spec.VersionedSignedBeaconBlock, err error) {
+	ctx, span := tracing.Start(ctx, "upstream.FetchBlock", trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(
+		attribute.String("checkpointz.upstream", upstream.Config.Name),
+		attribute.String("eth.block_id", blockID),
+	))
+	defer func() { tracing.End(span, err) }()
+
+	return upstream.Beacon.FetchBlock(ctx, blockID)
+}
+
+// fetchUpstreamState fetches the beacon state identified by stateID from upstream.
+func (d *Default) fetchUpstreamState(ctx context.Context, upstream *Node, stateID string) (beaconState *spec.VersionedBeaconState, err error) {
+	ctx, span := tracing.Start(ctx, "upstream.FetchBeaconState", trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(
+		attribute.String("checkpointz.upstream", upstream.Config.Name),
+		attribute.String("eth.state_id", stateID),
+	))
+	defer func() { tracing.End(span, err) }()
+
+	return upstream.Beacon.FetchBeaconState(ctx, stateID)
+}
+
 func (d *Default) downloadAndStoreDepositSnapshot(ctx context.Context, epoch phase0.Epoch, node *Node) error {
 	// Check if we already have the deposit snapshot.
 	if _, err := d.depositSnapshots.GetByEpoch(epoch); err == nil {
 		return nil
 	}
 
-	// Download the deposit snapshot from our upstream.
-	depositSnapshot, err := node.Beacon.FetchDepositSnapshot(ctx)
-	if err != nil {
-		return err
-	}
+	source := node.Config.Name
+
+	// Read the deposit snapshot from remote storage if another replica shared it, otherwise download it from
+	// our upstream.
+	depositSnapshot := d.remoteDepositSnapshot(ctx, epoch)
+	if depositSnapshot != nil {
+		source = d.remote.Name()
+	} else {
+		var err error
+
+		depositSnapshot, err = node.Beacon.FetchDepositSnapshot(ctx)
+		if err != nil {
+			return err
+		}
+
+		if depositSnapshot == nil {
+			return errors.New("invalid deposit snapshot")
+		}
 
-	if depositSnapshot == nil {
-		return errors.New("invalid deposit snapshot")
+		d.shareDepositSnapshot(ctx, epoch, depositSnapshot)
 	}
 
 	// These are small so store them for a month. Max items will most likely purge it before then.
 	// Mostly just guarding against periods of non-finality; we won't have new items to purge the old ones which
 	// is a good thing here.
-	expiresAt := time.Now().Add(672 * time.Hour)
+	expiresAt := d.clock.Now().Add(672 * time.Hour)
 
 	if err := d.depositSnapshots.Add(epoch, depositSnapshot, expiresAt); err != nil {
 		return fmt.Errorf("failed to store deposit snapshot: %w", err)
@@ -429,7 +583,7 @@ func (d *Default) downloadAndStoreDepositSnapshot(ctx context.Context, epoch pha
 	d.log.
 		WithFields(logrus.Fields{
 			"epoch": epoch,
-			"node":  node.Config.Name,
+			"node":  source,
 		}).
 		Infof("Downloaded and stored deposit snapshot for epoch %d", epoch)
 
@@ -437,35 +591,299 @@ func (d *Default) downloadAndStoreDepositSnapshot(ctx context.Context, epoch pha
 }
 
 func (d *Default) downloadAndStoreBlobSidecars(ctx context.Context, slot phase0.Slot, node *Node) error {
+	sp, err := d.Spec()
+	if err != nil {
+		return fmt.Errorf("failed to fetch spec: %w", err)
+	}
+
+	// Blobs are no longer gossiped as sidecars once PeerDAS (Fulu) is active - they're
+	// distributed as columns of the extended blob matrix instead. Upstreams only custody some of the columns,
+	// and may not serve them at all, so the bundle is served without them rather than held back.
+	fuluFork, err := sp.ForkEpochs.GetByName("fulu")
+	if err == nil && fuluFork != nil && fuluFork.Active(phase0.Epoch(slot/sp.SlotsPerEpoch)) {
+		if err := d.downloadAndStoreDataColumnSidecars(ctx, slot, node); err != nil {
+			d.log.
+				WithError(err).
+				WithField("slot", slot).
+				Warn("Serving bundle without data column sidecars")
+		}
+
+		return nil
+	}
+
 	// Check if we already have the blob sidecars.
 	if _, err := d.blobSidecars.GetBySlot(slot); err == nil {
 		return nil
 	}
 
-	// Download the blob sidecars from our upstream.
-	blobSidecars, err := node.Beacon.FetchBeaconBlockBlobs(ctx, eth.SlotAsString(slot))
+	block, err := d.blocks.GetBySlot(slot)
+	if err != nil || block == nil {
+		return fmt.Errorf("block for slot %d is required to verify blob sidecars", slot)
+	}
+
+	blockRoot, err := d.sszEncoder.GetBlockRoot(block)
 	if err != nil {
-		return err
+		return fmt.Errorf("failed to get block root for slot %d: %w", slot, err)
+	}
+
+	maxCommitments := maxBlobCommitmentsPerBlock(sp)
+
+	// Sidecars another replica shared are used if they verify against the block.
+	if blobSidecars := d.remoteBlobSidecars(ctx, blockRoot); blobSidecars != nil {
+		if err := verify.BlobSidecars(block, blobSidecars, maxCommitments); err == nil {
+			if err := d.blobSidecars.Add(slot, blobSidecars, d.clock.Now().Add(FinalityHaltedServingPeriod)); err != nil {
+				return fmt.Errorf("failed to store blob sidecars: %w", err)
+			}
+
+			d.log.WithField("slot", slot).WithField("store", d.remote.Name()).Infof("Read blob sidecars for slot %d from remote storage", slot)
+
+			return nil
+		}
+
+		d.log.WithField("slot", slot).WithField("store", d.remote.Name()).Warn("Ignoring blob sidecars in remote storage that failed verification")
+	}
+
+	// Try the given node first, falling back to our other data providers if its sidecars don't verify.
+	upstreams := Nodes{node}
+	upstreams = append(upstreams, d.nodes.Ready(ctx).DataProviders(ctx).Filter(ctx, func(n *Node) bool {
+		return n != node
+	})...)
+
+	var lastErr error
+
+	for _, upstream := range upstreams {
+		blobSidecars, err := d.fetchVerifiedBlobSidecars(ctx, slot, block, upstream, maxCommitments)
+		if err != nil {
+			lastErr = err
+
+			continue
+		}
+
+		// Store for the FinalityHaltedServingPeriod to ensure we have them in case of non-finality.
+		// We'll let the store handle purging old items.
+		expiresAt := d.clock.Now().Add(FinalityHaltedServingPeriod)
+
+		if err := d.blobSidecars.Add(slot, blobSidecars, expiresAt); err != nil {
+			return fmt.Errorf("failed to store blob sidecars: %w", err)
+		}
+
+		d.shareBlobSidecars(ctx, blockRoot, blobSidecars)
+
+		d.log.
+			WithFields(logrus.Fields{
+				"slot": slot,
+				"node": upstream.Config.Name,
+			}).
+			Infof("Downloaded and stored blob sidecar for slot %d", slot)
+
+		return nil
+	}
+
+	return fmt.Errorf("no upstream provided valid blob sidecars for slot %d: %w", slot, lastErr)
+}
+
+// fetchVerifiedBlobSidecars downloads the blob sidecars for a slot from the given upstream and verifies them
+// against the block. Verification failures are counted against the upstream.
+func (d *Default) fetchVerifiedBlobSidecars(ctx context.Context, slot phase0.Slot, block *spec.VersionedSignedBeaconBlock, upstream *Node, maxCommitments uint64) ([]*deneb.BlobSidecar, error) {
+	blobSidecars, err := upstream.Beacon.FetchBeaconBlockBlobs(ctx, eth.SlotAsString(slot))
+	if err != nil {
+		return nil, err
 	}
 
 	if blobSidecars == nil {
-		return errors.New("invalid blob sidecars")
+		return nil, errors.New("invalid blob sidecars")
 	}
 
-	// Store for the FinalityHaltedServingPeriod to ensure we have them in case of non-finality.
-	// We'll let the store handle purging old items.
-	expiresAt := time.Now().Add(FinalityHaltedServingPeriod)
+	if err := verify.BlobSidecars(block, blobSidecars, maxCommitments); err != nil {
+		d.metrics.ObserveBlobSidecarVerificationFailure(upstream.Config.Name)
+
+		d.log.
+			WithError(err).
+			WithFields(logrus.Fields{
+				"slot": slot,
+				"node": upstream.Config.Name,
+			}).
+			Warn("Blob sidecars failed verification")
 
-	if err := d.blobSidecars.Add(slot, blobSidecars, expiresAt); err != nil {
-		return fmt.Errorf("failed to store blob sidecars: %w", err)
+		return nil, fmt.Errorf("blob sidecars from %s failed verification: %w", upstream.Config.Name, err)
 	}
 
+	return blobSidecars, nil
+}
+
+// pinServingBundle pins the items of the new serving bundle so they are never expired or evicted while being
+// served, and unpins the previous bundle. The genesis bundle stays pinned.
+func (d *Default) pinServingBundle(previous, next *v1.Finality) {
+	if previous != nil && previous.Finalized != nil && previous.Finalized.Root != next.Finalized.Root {
+		d.setBundlePinned(previous.Finalized.Root, false)
+	}
+
+	d.setBundlePinned(next.Finalized.Root, true)
+}
+
+func (d *Default) setBundlePinned(root phase0.Root, pinned bool) {
+	block, err := d.blocks.GetByRoot(root)
+	if err != nil || block == nil {
+		return
+	}
+
+	slot, err := block.Slot()
+	if err != nil || (slot == phase0.Slot(0) && !pinned) {
+		return
+	}
+
+	stateRoot, err := block.StateRoot()
+	if err != nil {
+		return
+	}
+
+	// Not every item of a bundle is necessarily cached (e.g. states in light mode), so errors are ignored.
+	if pinned {
+		_ = d.blocks.Pin(root)
+		_ = d.states.Pin(stateRoot)
+		_ = d.blobSidecars.Pin(slot)
+		_ = d.dataColumnSidecars.Pin(slot)
+
+		return
+	}
+
+	_ = d.blocks.Unpin(root)
+	_ = d.states.Unpin(stateRoot)
+	_ = d.blobSidecars.Unpin(slot)
+	_ = d.dataColumnSidecars.Unpin(slot)
+}
+
+// observeBlockSignatureFailure counts the error against the upstream or archive the block came from if it was
+// caused by an invalid block signature.
+func (d *Default) observeBlockSignatureFailure(err error, source string) {
+	if !errors.Is(err, verify.ErrBlockSignatureInvalid) {
+		return
+	}
+
+	d.metrics.ObserveBlockSignatureVerificationFailure(source)
+
 	d.log.
-		WithFields(logrus.Fields{
-			"slot": slot,
-			"node": node.Config.Name,
-		}).
-		Infof("Downloaded and stored blob sidecar for slot %d", slot)
+		WithError(err).
+		WithField("node", source).
+		Warn("Block failed signature verification")
+}
 
-	return nil
+// maxBlobCommitmentsPerBlock returns MAX_BLOB_COMMITMENTS_PER_BLOCK from the spec, or 0 if unknown.
+func maxBlobCommitmentsPerBlock(sp *state.Spec) uint64 {
+	raw, exists := sp.FullSpec["MAX_BLOB_COMMITMENTS_PER_BLOCK"]
+	if !exists {
+		return 0
+	}
+
+	value, err := strconv.ParseUint(fmt.Sprint(raw), 10, 64)
+	if err != nil {
+		return 0
+	}
+
+	return value
+}
+
+func (d *Default) downloadAndStoreDataColumnSidecars(ctx context.Context, slot phase0.Slot, node *Node) error {
+	// Check if we already have the data column sidecars.
+	if _, err := d.dataColumnSidecars.GetBySlot(slot); err == nil {
+		return nil
+	}
+
+	sp, err := d.Spec()
+	if err != nil {
+		return fmt.Errorf("failed to fetch spec: %w", err)
+	}
+
+	block, err := d.blocks.GetBySlot(slot)
+	if err != nil || block == nil {
+		return fmt.Errorf("block for slot %d is required to verify data column sidecars", slot)
+	}
+
+	blockRoot, err := d.sszEncoder.GetBlockRoot(block)
+	if err != nil {
+		return fmt.Errorf("failed to get block root for slot %d: %w", slot, err)
+	}
+
+	maxCommitments := maxBlobCommitmentsPerBlock(sp)
+
+	// Columns another replica shared are used if they verify against the block.
+	if sidecars := d.remoteDataColumnSidecars(ctx, blockRoot); sidecars != nil {
+		if err := verify.DataColumnSidecars(block, sidecars, maxCommitments); err == nil {
+			if err := d.dataColumnSidecars.Add(slot, sidecars, d.clock.Now().Add(FinalityHaltedServingPeriod)); err != nil {
+				return fmt.Errorf("failed to store data column sidecars: %w", err)
+			}
+
+			d.log.WithField("slot", slot).WithField("store", d.remote.Name()).Infof("Read data column sidecars for slot %d from remote storage", slot)
+
+			return nil
+		}
+
+		d.log.WithField("slot", slot).WithField("store", d.remote.Name()).Warn("Ignoring data column sidecars in remote storage that failed verification")
+	}
+
+	// Try the given node first, falling back to our other data providers if its columns don't verify.
+	upstreams := Nodes{node}
+	upstreams = append(upstreams, d.nodes.Ready(ctx).DataProviders(ctx).Filter(ctx, func(n *Node) bool {
+		return n != node
+	})...)
+
+	var lastErr error
+
+	for _, upstream := range upstreams {
+		sidecars, err := d.fetchVerifiedDataColumnSidecars(ctx, slot, block, upstream, maxCommitments)
+		if err != nil {
+			lastErr = err
+
+			continue
+		}
+
+		// Store for the FinalityHaltedServingPeriod to ensure we have them in case of non-finality.
+		// We'll let the store handle purging old items.
+		expiresAt := d.clock.Now().Add(FinalityHaltedServingPeriod)
+
+		if err := d.dataColumnSidecars.Add(slot, sidecars, expiresAt); err != nil {
+			return fmt.Errorf("failed to store data column sidecars: %w", err)
+		}
+
+		d.shareDataColumnSidecars(ctx, blockRoot, sidecars)
+
+		d.log.
+			WithFields(logrus.Fields{
+				"slot":    slot,
+				"node":    upstream.Config.Name,
+				"columns": len(sidecars),
+			}).
+			Infof("Downloaded and stored data column sidecars for slot %d", slot)
+
+		return nil
+	}
+
+	return fmt.Errorf("no upstream provided valid data column sidecars for slot %d: %w", slot, lastErr)
+}
+
+func (d *Default) fetchVerifiedDataColumnSidecars(ctx context.Context, slot phase0.Slot, block *spec.VersionedSignedBeaconBlock, upstream *Node, maxCommitments uint64) ([]*fulu.DataColumnSidecar, error) {
+	sidecars, err := upstream.FetchDataColumnSidecars(ctx, eth.SlotAsString(slot))
+	if err != nil {
+		return nil, err
+	}
+
+	if sidecars == nil {
+		return nil, errors.New("invalid data column sidecars")
+	}
+
+	if err := verify.DataColumnSidecars(block, sidecars, maxCommitments); err != nil {
+		d.metrics.ObserveDataColumnSidecarVerificationFailure(upstream.Config.Name)
+
+		d.log.
+			WithError(err).
+			WithFields(logrus.Fields{
+				"slot": slot,
+				"node": upstream.Config.Name,
+			}).
+			Warn("Data column sidecars failed verification")
+
+		return nil, fmt.Errorf("data column sidecars from %s failed verification: %w", upstream.Config.Name, err)
+	}
+
+	return sidecars, nil
 }
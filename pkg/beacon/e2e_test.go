@@ -0,0 +1,39 @@
+package beacon_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/ethpandaops/checkpointz/pkg/beacon"
+	"github.com/ethpandaops/checkpointz/pkg/beacon/beacontest"
+	"github.com/ethpandaops/checkpointz/pkg/checkpointz/checkpointztest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestE2ERejectsStatesWithWrongRoots(t *testing.T) {
+	chain := checkpointztest.NewChain(t)
+	root := chain.Root(checkpointztest.FinalizedEpoch)
+
+	wrong := beacontest.NewNode(chain, beacontest.Scenario{FinalizedEpoch: checkpointztest.FinalizedEpoch, WrongStates: true})
+
+	server := checkpointztest.Start(t, beacon.OperatingModeFull, nil,
+		checkpointztest.Upstream{Node: wrong, DataProvider: true},
+	)
+
+	// The only data provider serves states that don't match the finalized block's state root, so the bundle
+	// can't be completed.
+	server.Eventually(func() bool {
+		return wrong.Requests(fmt.Sprintf("/eth/v2/debug/beacon/states/%d", uint64(checkpointztest.FinalizedEpoch)*beacontest.SlotsPerEpoch)) > 0
+	})
+
+	// Give the download a chance to finish before checking nothing was served.
+	server.Wait(10 * time.Second)
+
+	_, served := server.ServedRoot()
+	assert.False(t, served, "a state with the wrong root must not be served")
+
+	wrong.SetScenario(beacontest.Scenario{FinalizedEpoch: checkpointztest.FinalizedEpoch})
+
+	server.RequireServes(root)
+}
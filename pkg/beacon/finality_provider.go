@@ -9,7 +9,12 @@ import (
 	"github.com/attestantio/go-eth2-client/spec/phase0"
 	"github.com/ethpandaops/beacon/pkg/beacon/api/types"
 	"github.com/ethpandaops/beacon/pkg/beacon/state"
+	"github.com/ethpandaops/checkpointz/pkg/beacon/fulu"
+	"github.com/ethpandaops/checkpointz/pkg/beacon/leader"
 	"github.com/ethpandaops/checkpointz/pkg/beacon/ssz"
+	"github.com/ethpandaops/checkpointz/pkg/beacon/store"
+	"github.com/ethpandaops/checkpointz/pkg/beacon/verify"
+	"github.com/ethpandaops/checkpointz/pkg/beacon/witness"
 	"github.com/ethpandaops/checkpointz/pkg/eth"
 )
 
@@ -19,6 +24,8 @@ type FinalityProvider interface {
 	Start(ctx context.Context) error
 	// StartAsync starts the provider in a goroutine.
 	StartAsync(ctx context.Context)
+	// Stop stops the provider, waiting for in-flight work to finish until ctx is done.
+	Stop(ctx context.Context) error
 	// Healthy returns true if the provider is healthy.
 	Healthy(ctx context.Context) (bool, error)
 	// Peers returns the peers the provider is connected to).
@@ -39,20 +46,35 @@ type FinalityProvider interface {
 	SSZEncoder() *ssz.Encoder
 	// UpstreamsStatus returns the status of all the upstreams.
 	UpstreamsStatus(ctx context.Context) (map[string]*UpstreamStatus, error)
+	// WitnessesStatus returns the latest report from each witness on the serving checkpoint.
+	WitnessesStatus(ctx context.Context) (map[string]*witness.Report, error)
+	// CoordinationStatus returns this instance's part in leader election, or nil if it isn't enabled.
+	CoordinationStatus(ctx context.Context) (*leader.Status, error)
 	// GetBlockBySlot returns the block at the given slot.
 	GetBlockBySlot(ctx context.Context, slot phase0.Slot) (*spec.VersionedSignedBeaconBlock, error)
+	// GetBlockSignatureStatusBySlot returns how the signature of the block at the given slot was verified.
+	GetBlockSignatureStatusBySlot(ctx context.Context, slot phase0.Slot) (verify.Status, error)
 	// GetBlockByRoot returns the block with the given root.
 	GetBlockByRoot(ctx context.Context, root phase0.Root) (*spec.VersionedSignedBeaconBlock, error)
 	// GetBlockByStateRoot returns the block with the given root.
 	GetBlockByStateRoot(ctx context.Context, root phase0.Root) (*spec.VersionedSignedBeaconBlock, error)
+	// GetBlockProvenance returns where the block with the given root came from.
+	GetBlockProvenance(ctx context.Context, root phase0.Root) (*store.Provenance, error)
 	// GetBeaconStateBySlot returns the beacon sate with the given slot.
 	GetBeaconStateBySlot(ctx context.Context, slot phase0.Slot) (*spec.VersionedBeaconState, error)
 	// GetBeaconStateByStateRoot returns the beacon sate with the given state root.
 	GetBeaconStateByStateRoot(ctx context.Context, root phase0.Root) (*spec.VersionedBeaconState, error)
 	// GetBeaconStateByRoot returns the beacon sate with the given root.
 	GetBeaconStateByRoot(ctx context.Context, root phase0.Root) (*spec.VersionedBeaconState, error)
+	// GetBeaconStateProvenance returns where the beacon state with the given state root came from.
+	GetBeaconStateProvenance(ctx context.Context, stateRoot phase0.Root) (*store.Provenance, error)
+	// GetBeaconStateURL returns the URL downloads of the beacon state with the given state root should be
+	// redirected to, or an empty string if it's served directly.
+	GetBeaconStateURL(ctx context.Context, stateRoot phase0.Root) (string, error)
 	// GetBlobSidecarsBySlot returns the blob sidecars for the given slot.
 	GetBlobSidecarsBySlot(ctx context.Context, slot phase0.Slot) ([]*deneb.BlobSidecar, error)
+	// GetDataColumnSidecarsBySlot returns the data column sidecars for the given slot.
+	GetDataColumnSidecarsBySlot(ctx context.Context, slot phase0.Slot) ([]*fulu.DataColumnSidecar, error)
 	// ListFinalizedSlots returns a slice of finalized slots.
 	ListFinalizedSlots(ctx context.Context) ([]phase0.Slot, error)
 	// GetEpochBySlot returns the epoch for the given slot.
@@ -61,6 +83,8 @@ type FinalityProvider interface {
 	OperatingMode() OperatingMode
 	// GetSlotTime returns the wall clock for the given slot.
 	GetSlotTime(ctx context.Context, slot phase0.Slot) (eth.SlotTime, error)
+	// OnServingCheckpointUpdated registers a callback for when a new finalized checkpoint starts being served.
+	OnServingCheckpointUpdated(ctx context.Context, cb func(ctx context.Context, checkpoint *v1.Finality) error)
 	// GetDepositSnapshot returns the deposit snapshot at the given epoch.
 	GetDepositSnapshot(ctx context.Context, epoch phase0.Epoch) (*types.DepositSnapshot, error)
 }
@@ -0,0 +1,169 @@
+package fulu
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/attestantio/go-eth2-client/spec/deneb"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/pkg/errors"
+)
+
+const (
+	// BytesPerCell is FIELD_ELEMENTS_PER_CELL * BYTES_PER_FIELD_ELEMENT.
+	BytesPerCell = 2048
+	// NumberOfColumns is NUMBER_OF_COLUMNS, the number of columns in the extended data matrix.
+	NumberOfColumns = 128
+	// KZGCommitmentsInclusionProofDepth is KZG_COMMITMENTS_INCLUSION_PROOF_DEPTH.
+	KZGCommitmentsInclusionProofDepth = 4
+)
+
+// ErrNotEnoughColumns is returned when blobs can't be reconstructed from the columns held.
+var ErrNotEnoughColumns = errors.New("not enough data columns to reconstruct blobs")
+
+// Cell is a single cell of a column in the extended blob matrix.
+type Cell [BytesPerCell]byte
+
+// DataColumnSidecar is a column of the extended blob matrix, as introduced by PeerDAS in Fulu.
+type DataColumnSidecar struct {
+	Index                        uint64
+	Column                       []Cell                `ssz-max:"4096" dynssz-max:"MAX_BLOB_COMMITMENTS_PER_BLOCK"`
+	KZGCommitments               []deneb.KZGCommitment `ssz-max:"4096" dynssz-max:"MAX_BLOB_COMMITMENTS_PER_BLOCK"`
+	KZGProofs                    []deneb.KZGProof      `ssz-max:"4096" dynssz-max:"MAX_BLOB_COMMITMENTS_PER_BLOCK"`
+	SignedBlockHeader            *phase0.SignedBeaconBlockHeader
+	KZGCommitmentsInclusionProof [KZGCommitmentsInclusionProofDepth]phase0.Root
+}
+
+// dataColumnSidecarFixedSize is the size of the fixed part of an SSZ encoded DataColumnSidecar: the index,
+// three list offsets, the signed block header and the inclusion proof.
+const dataColumnSidecarFixedSize = 8 + 3*4 + 208 + KZGCommitmentsInclusionProofDepth*32
+
+// SizeSSZ returns the size of the SSZ encoded sidecar.
+func (d *DataColumnSidecar) SizeSSZ() int {
+	return dataColumnSidecarFixedSize + len(d.Column)*BytesPerCell + len(d.KZGCommitments)*48 + len(d.KZGProofs)*48
+}
+
+// dataColumnSidecarJSON is the beacon API representation of the struct.
+type dataColumnSidecarJSON struct {
+	Index                        string                                         `json:"index"`
+	Column                       []string                                       `json:"column"`
+	KZGCommitments               []deneb.KZGCommitment                          `json:"kzg_commitments"`
+	KZGProofs                    []deneb.KZGProof                               `json:"kzg_proofs"`
+	SignedBlockHeader            *phase0.SignedBeaconBlockHeader                `json:"signed_block_header"`
+	KZGCommitmentsInclusionProof [KZGCommitmentsInclusionProofDepth]phase0.Root `json:"kzg_commitments_inclusion_proof"`
+}
+
+// MarshalJSON implements json.Marshaler.
+func (d *DataColumnSidecar) MarshalJSON() ([]byte, error) {
+	column := make([]string, len(d.Column))
+	for i := range d.Column {
+		column[i] = fmt.Sprintf("%#x", d.Column[i][:])
+	}
+
+	return json.Marshal(&dataColumnSidecarJSON{
+		Index:                        fmt.Sprintf("%d", d.Index),
+		Column:                       column,
+		KZGCommitments:               d.KZGCommitments,
+		KZGProofs:                    d.KZGProofs,
+		SignedBlockHeader:            d.SignedBlockHeader,
+		KZGCommitmentsInclusionProof: d.KZGCommitmentsInclusionProof,
+	})
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (d *DataColumnSidecar) UnmarshalJSON(input []byte) error {
+	var data dataColumnSidecarJSON
+	if err := json.Unmarshal(input, &data); err != nil {
+		return errors.Wrap(err, "invalid JSON")
+	}
+
+	index, err := strconv.ParseUint(data.Index, 10, 64)
+	if err != nil {
+		return errors.Wrap(err, "index")
+	}
+
+	if data.SignedBlockHeader == nil {
+		return errors.New("signed_block_header missing")
+	}
+
+	column := make([]Cell, len(data.Column))
+
+	for i, raw := range data.Column {
+		decoded, err := hex.DecodeString(strings.TrimPrefix(raw, "0x"))
+		if err != nil {
+			return errors.Wrapf(err, "column[%d]", i)
+		}
+
+		if len(decoded) != BytesPerCell {
+			return fmt.Errorf("column[%d]: incorrect length %d", i, len(decoded))
+		}
+
+		copy(column[i][:], decoded)
+	}
+
+	if len(column) != len(data.KZGCommitments) || len(column) != len(data.KZGProofs) {
+		return errors.New("column, kzg_commitments and kzg_proofs lengths differ")
+	}
+
+	d.Index = index
+	d.Column = column
+	d.KZGCommitments = data.KZGCommitments
+	d.KZGProofs = data.KZGProofs
+	d.SignedBlockHeader = data.SignedBlockHeader
+	d.KZGCommitmentsInclusionProof = data.KZGCommitmentsInclusionProof
+
+	return nil
+}
+
+// BlobsFromColumns reconstructs the original blobs from the data column sidecars of a block.
+// The extension is systematic, so the first half of the columns hold the blob data verbatim. Only upstreams
+// custodying all of them (supernodes) provide enough columns; ErrNotEnoughColumns is returned otherwise.
+func BlobsFromColumns(columns []*DataColumnSidecar) ([]*deneb.Blob, error) {
+	const cellsPerBlob = NumberOfColumns / 2
+
+	byIndex := make(map[uint64]*DataColumnSidecar, len(columns))
+	held := 0
+
+	for _, column := range columns {
+		byIndex[column.Index] = column
+
+		if column.Index < cellsPerBlob {
+			held++
+		}
+	}
+
+	if held < cellsPerBlob {
+		return nil, fmt.Errorf("%w: %d of the first %d columns are held", ErrNotEnoughColumns, held, cellsPerBlob)
+	}
+
+	blobCount := -1
+
+	for i := uint64(0); i < cellsPerBlob; i++ {
+		column := byIndex[i]
+
+		if blobCount == -1 {
+			blobCount = len(column.Column)
+		}
+
+		if len(column.Column) != blobCount {
+			return nil, fmt.Errorf("column %d has %d cells, expected %d", i, len(column.Column), blobCount)
+		}
+	}
+
+	blobs := make([]*deneb.Blob, blobCount)
+
+	for b := 0; b < blobCount; b++ {
+		blob := &deneb.Blob{}
+
+		for i := uint64(0); i < cellsPerBlob; i++ {
+			copy(blob[i*BytesPerCell:(i+1)*BytesPerCell], byIndex[i].Column[b][:])
+		}
+
+		blobs[b] = blob
+	}
+
+	return blobs, nil
+}
@@ -0,0 +1,89 @@
+package fulu
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/attestantio/go-eth2-client/spec/deneb"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	dynssz "github.com/pk910/dynamic-ssz"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestSidecar(index uint64, blobs int) *DataColumnSidecar {
+	sidecar := &DataColumnSidecar{
+		Index:          index,
+		Column:         make([]Cell, blobs),
+		KZGCommitments: make([]deneb.KZGCommitment, blobs),
+		KZGProofs:      make([]deneb.KZGProof, blobs),
+		SignedBlockHeader: &phase0.SignedBeaconBlockHeader{
+			Message: &phase0.BeaconBlockHeader{
+				Slot: 100,
+			},
+		},
+	}
+
+	for b := 0; b < blobs; b++ {
+		sidecar.Column[b][0] = byte(index)
+		sidecar.Column[b][1] = byte(b)
+	}
+
+	return sidecar
+}
+
+func TestDataColumnSidecarJSONRoundTrip(t *testing.T) {
+	sidecar := newTestSidecar(7, 2)
+	sidecar.KZGCommitmentsInclusionProof[3] = phase0.Root{0xaa}
+
+	data, err := json.Marshal(sidecar)
+	require.NoError(t, err)
+
+	decoded := &DataColumnSidecar{}
+	require.NoError(t, json.Unmarshal(data, decoded))
+
+	assert.Equal(t, sidecar, decoded)
+}
+
+func TestDataColumnSidecarSSZ(t *testing.T) {
+	sidecar := newTestSidecar(1, 3)
+
+	ds := dynssz.NewDynSsz(nil)
+
+	data, err := ds.MarshalSSZ(sidecar)
+	require.NoError(t, err)
+
+	// index + column offset + commitments offset + proofs offset + signed header + inclusion proof + variable parts
+	assert.Len(t, data, 8+4+4+4+208+4*32+3*(BytesPerCell+48+48))
+	assert.Equal(t, len(data), sidecar.SizeSSZ())
+
+	decoded := &DataColumnSidecar{}
+	require.NoError(t, ds.UnmarshalSSZ(decoded, data))
+
+	assert.Equal(t, sidecar, decoded)
+}
+
+func TestBlobsFromColumns(t *testing.T) {
+	columns := make([]*DataColumnSidecar, 0, NumberOfColumns)
+	for i := uint64(0); i < NumberOfColumns; i++ {
+		columns = append(columns, newTestSidecar(i, 2))
+	}
+
+	blobs, err := BlobsFromColumns(columns)
+	require.NoError(t, err)
+	require.Len(t, blobs, 2)
+
+	for b, blob := range blobs {
+		for i := 0; i < NumberOfColumns/2; i++ {
+			assert.Equal(t, byte(i), blob[i*BytesPerCell])
+			assert.Equal(t, byte(b), blob[i*BytesPerCell+1])
+		}
+	}
+}
+
+func TestBlobsFromColumnsMissingColumn(t *testing.T) {
+	columns := []*DataColumnSidecar{newTestSidecar(0, 1), newTestSidecar(2, 1)}
+
+	_, err := BlobsFromColumns(columns)
+	assert.ErrorIs(t, err, ErrNotEnoughColumns)
+}
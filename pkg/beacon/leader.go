@@ -0,0 +1,142 @@
+package beacon
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+
+	v1 "github.com/attestantio/go-eth2-client/api/v1"
+	"github.com/creasty/defaults"
+	"github.com/ethpandaops/checkpointz/pkg/beacon/leader"
+	"github.com/ethpandaops/checkpointz/pkg/beacon/node"
+	"github.com/ethpandaops/checkpointz/pkg/beacon/store/remote"
+	"github.com/ethpandaops/checkpointz/pkg/clock"
+	"github.com/sirupsen/logrus"
+)
+
+// newElector returns an elector competing for the lease in the lock the config describes.
+func newElector(log logrus.FieldLogger, config *Config, clk clock.Clock) (*leader.Elector, error) {
+	var lock leader.Lock
+
+	switch config.Coordination.Lock.Type {
+	case leader.LockTypeFile:
+		lock = leader.NewFileLock(config.Coordination.Lock.Path, clk)
+	case leader.LockTypeObject:
+		client, err := remote.NewClient(config.RemoteStorage)
+		if err != nil {
+			return nil, err
+		}
+
+		key := path.Join(config.RemoteStorage.Prefix, config.Coordination.Lock.Key)
+		lock = leader.NewObjectLock(client, config.RemoteStorage.Bucket, key)
+	default:
+		return nil, fmt.Errorf("invalid lock type: %s", config.Coordination.Lock.Type)
+	}
+
+	return leader.NewElector(log, config.Coordination, lock, clk), nil
+}
+
+// following returns true if another replica leads and we sync bundles from it.
+func (d *Default) following() bool {
+	return d.elector != nil && d.elector.Role() == leader.RoleFollower
+}
+
+// dataProvider returns the node to fetch bundles from: the leader if we follow one, otherwise a random ready
+// data provider upstream that knows about checkpoint, if one is given.
+func (d *Default) dataProvider(ctx context.Context, checkpoint *v1.Finality) (*Node, error) {
+	if d.following() {
+		return d.leaderNode(ctx)
+	}
+
+	nodes := d.nodes.Ready(ctx).DataProviders(ctx)
+
+	if checkpoint != nil {
+		// Ensure we attempt to fetch the bundle from a node that knows about the checkpoint.
+		nodes = nodes.PastFinalizedCheckpoint(ctx, checkpoint)
+	}
+
+	return nodes.RandomNode(ctx)
+}
+
+// leaderNode returns a node for the leader's API, replacing the previous one if leadership has moved.
+func (d *Default) leaderNode(ctx context.Context) (*Node, error) {
+	lease := d.elector.Leader()
+	if lease == nil {
+		return nil, errors.New("leader is unknown")
+	}
+
+	d.leaderMutex.Lock()
+	defer d.leaderMutex.Unlock()
+
+	if d.leader == nil || d.leader.Config.Address != lease.URL {
+		d.stopLeaderNode(ctx)
+
+		config := node.Config{}
+		if err := defaults.Set(&config); err != nil {
+			return nil, err
+		}
+
+		config.Name = "leader/" + lease.Holder
+		config.Address = lease.URL
+		config.DataProvider = true
+
+		if d.config.Coordination.APIKey != "" {
+			config.Headers = map[string]string{"Authorization": "Bearer " + d.config.Coordination.APIKey}
+		}
+
+		httpClient := node.NewHTTPClient(config, d.clock)
+		opts := nodeOptions(config, httpClient, d.config.CustomPreset)
+
+		// The leader isn't an upstream: it doesn't vote on finality, and it comes and goes, so it mustn't
+		// register metrics.
+		opts.PrometheusMetrics = false
+
+		d.leader = newNode(d.log, config, d.namespace, httpClient, opts)
+		d.leader.Beacon.StartAsync(ctx)
+
+		d.log.WithField("leader", lease.Holder).WithField("address", lease.URL).Info("Following a new leader")
+	}
+
+	if !d.leader.Beacon.Status().Healthy() {
+		return nil, fmt.Errorf("leader %s isn't healthy yet", lease.Holder)
+	}
+
+	return d.leader, nil
+}
+
+// stopLeaderNode stops the node for the leader's API, if there is one. leaderMutex must be held.
+func (d *Default) stopLeaderNode(ctx context.Context) {
+	if d.leader == nil {
+		return
+	}
+
+	if err := d.leader.Beacon.Stop(ctx); err != nil {
+		d.log.WithError(err).WithField("leader", d.leader.Config.Name).Warn("Failed to stop leader node")
+	}
+
+	d.leader = nil
+}
+
+// onRoleChanged reacts to this replica's leader election role changing.
+func (d *Default) onRoleChanged(role leader.Role) {
+	d.metrics.ObserveCoordinationRole(role)
+
+	if role == leader.RoleFollower {
+		return
+	}
+
+	d.leaderMutex.Lock()
+	defer d.leaderMutex.Unlock()
+
+	d.stopLeaderNode(context.Background())
+}
+
+// CoordinationStatus returns this replica's part in leader election, or nil if it isn't enabled.
+func (d *Default) CoordinationStatus(ctx context.Context) (*leader.Status, error) {
+	if d.elector == nil {
+		return nil, nil //nolint:nilnil // leader election is optional
+	}
+
+	return d.elector.Status(), nil
+}
@@ -0,0 +1,86 @@
+package leader
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// LockType is the backend the leader lease is held in.
+type LockType string
+
+const (
+	// LockTypeFile holds the lease in a file on a filesystem shared by every replica.
+	LockTypeFile LockType = "file"
+	// LockTypeObject holds the lease in an object in the remote storage bucket.
+	LockTypeObject LockType = "object"
+)
+
+// Config configures leader election between replicas, so that only the leader downloads bundles from its
+// upstreams and the followers sync them from it.
+type Config struct {
+	// Enabled enables leader election.
+	Enabled bool `yaml:"enabled" default:"false"`
+	// ID identifies this replica. It defaults to the host name.
+	ID string `yaml:"id"`
+	// AdvertiseURL is the base URL followers reach this replica's API at while it leads.
+	AdvertiseURL string `yaml:"advertise_url"`
+	// LeaseDuration is how long a lease lasts without being renewed, and so how long it takes another
+	// replica to take over from a leader that has gone away.
+	LeaseDuration time.Duration `yaml:"lease_duration" default:"30s"`
+	// RenewInterval is how often the lease is renewed or, by followers, checked.
+	RenewInterval time.Duration `yaml:"renew_interval" default:"10s"`
+	// Lock configures where the lease is held.
+	Lock LockConfig `yaml:"lock"`
+	// APIKey is presented as a bearer token when syncing from the leader, for when the routes followers
+	// sync through are restricted to API keys.
+	APIKey string `yaml:"api_key"`
+}
+
+// LockConfig configures where the leader lease is held.
+type LockConfig struct {
+	// Type is the lock backend.
+	Type LockType `yaml:"type" default:"file"`
+	// Path is the lease file, for LockTypeFile.
+	Path string `yaml:"path"`
+	// Key is the lease object's key under the remote storage prefix, for LockTypeObject.
+	Key string `yaml:"key" default:"leader.json"`
+}
+
+func (c *Config) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+
+	if c.AdvertiseURL == "" {
+		return errors.New("advertise_url is required")
+	}
+
+	if u, err := url.Parse(c.AdvertiseURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return errors.New("advertise_url must be an http or https URL")
+	}
+
+	if c.RenewInterval <= 0 {
+		return errors.New("renew_interval must be positive")
+	}
+
+	if c.LeaseDuration <= c.RenewInterval {
+		return fmt.Errorf("lease_duration (%s) must be longer than renew_interval (%s)", c.LeaseDuration, c.RenewInterval)
+	}
+
+	switch c.Lock.Type {
+	case LockTypeFile:
+		if c.Lock.Path == "" {
+			return errors.New("lock.path is required for file locks")
+		}
+	case LockTypeObject:
+		if c.Lock.Key == "" {
+			return errors.New("lock.key is required for object locks")
+		}
+	default:
+		return fmt.Errorf("invalid lock.type: %s", c.Lock.Type)
+	}
+
+	return nil
+}
@@ -0,0 +1,139 @@
+package leader_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ethpandaops/checkpointz/pkg/access"
+	"github.com/ethpandaops/checkpointz/pkg/beacon"
+	"github.com/ethpandaops/checkpointz/pkg/beacon/leader"
+	"github.com/ethpandaops/checkpointz/pkg/checkpointz"
+	"github.com/ethpandaops/checkpointz/pkg/checkpointz/checkpointztest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestE2EFollowersSyncBundlesFromTheLeader(t *testing.T) {
+	chain := checkpointztest.NewChain(t)
+	lockPath := filepath.Join(t.TempDir(), "leader.json")
+
+	withCoordination := func(id string) func(*checkpointz.Config) {
+		return func(config *checkpointz.Config) {
+			config.Checkpointz.Coordination = leader.Config{
+				Enabled:       true,
+				ID:            id,
+				AdvertiseURL:  "http://" + config.GlobalConfig.ListenAddr,
+				LeaseDuration: 30 * time.Second,
+				RenewInterval: time.Second,
+				Lock:          leader.LockConfig{Type: leader.LockTypeFile, Path: lockPath},
+			}
+		}
+	}
+
+	coordination := func(s *checkpointztest.Server) *leader.Status {
+		rsp, body := s.Get("/checkpointz/v1/status", "application/json")
+		require.Equal(t, http.StatusOK, rsp.StatusCode)
+
+		var status struct {
+			Data struct {
+				Coordination *leader.Status `json:"coordination"`
+			} `json:"data"`
+		}
+
+		require.NoError(t, json.Unmarshal(body, &status))
+
+		return status.Data.Coordination
+	}
+
+	// The first replica takes the lease and downloads from its upstream.
+	first := checkpointztest.Start(t, beacon.OperatingModeFull, withCoordination("first"),
+		checkpointztest.Upstream{Node: checkpointztest.NewNode(chain), DataProvider: true},
+	)
+
+	first.RequireServes(chain.Root(checkpointztest.FinalizedEpoch))
+
+	status := coordination(first)
+	require.NotNil(t, status)
+	assert.Equal(t, leader.RoleLeader, status.Role)
+
+	// The second follows it, and syncs the bundle from the leader rather than its own upstream.
+	node := checkpointztest.NewNode(chain)
+
+	second := checkpointztest.Start(t, beacon.OperatingModeFull, withCoordination("second"),
+		checkpointztest.Upstream{Node: node, DataProvider: true},
+	)
+
+	second.RequireServes(chain.Root(checkpointztest.FinalizedEpoch))
+
+	status = coordination(second)
+	require.NotNil(t, status)
+	assert.Equal(t, leader.RoleFollower, status.Role)
+	assert.Equal(t, "first", status.Leader)
+
+	assert.Zero(t, node.Requests(fmt.Sprintf("/eth/v2/beacon/blocks/%s", chain.Root(checkpointztest.FinalizedEpoch))))
+	assert.Zero(t, node.Requests("/eth/v2/beacon/blocks/genesis"))
+	assert.Zero(t, node.Requests("/eth/v2/debug/beacon/states/"))
+
+	rsp, _ := second.Get("/eth/v2/debug/beacon/states/finalized", "application/octet-stream")
+	assert.Equal(t, http.StatusOK, rsp.StatusCode)
+
+	// Once the leader shuts down, it hands the lease over.
+	first.Stop()
+
+	second.Eventually(func() bool {
+		status := coordination(second)
+
+		return status != nil && status.Role == leader.RoleLeader
+	})
+}
+
+func TestE2EFollowersPresentTheirAPIKeyToTheLeader(t *testing.T) {
+	chain := checkpointztest.NewChain(t)
+	lockPath := filepath.Join(t.TempDir(), "leader.json")
+
+	keysFile := filepath.Join(t.TempDir(), "keys.yaml")
+	require.NoError(t, os.WriteFile(keysFile, []byte("keys: [{name: follower, key: follower-secret, groups: [states]}]"), 0o600))
+
+	withRestrictedCoordination := func(id string) func(*checkpointz.Config) {
+		return func(config *checkpointz.Config) {
+			config.GlobalConfig.Access = access.Config{
+				Enabled:  true,
+				KeysFile: keysFile,
+				Groups: []access.GroupConfig{
+					{Name: "states", Routes: []string{"/eth/v2/debug/beacon/states/:state_id"}},
+				},
+			}
+			config.Checkpointz.Coordination = leader.Config{
+				Enabled:       true,
+				ID:            id,
+				AdvertiseURL:  "http://" + config.GlobalConfig.ListenAddr,
+				LeaseDuration: 30 * time.Second,
+				RenewInterval: time.Second,
+				Lock:          leader.LockConfig{Type: leader.LockTypeFile, Path: lockPath},
+				APIKey:        "follower-secret",
+			}
+		}
+	}
+
+	first := checkpointztest.Start(t, beacon.OperatingModeFull, withRestrictedCoordination("first"),
+		checkpointztest.Upstream{Node: checkpointztest.NewNode(chain), DataProvider: true},
+	)
+
+	first.RequireServes(chain.Root(checkpointztest.FinalizedEpoch))
+
+	// The follower's state downloads from the leader need its key.
+	node := checkpointztest.NewNode(chain)
+
+	second := checkpointztest.Start(t, beacon.OperatingModeFull, withRestrictedCoordination("second"),
+		checkpointztest.Upstream{Node: node, DataProvider: true},
+	)
+
+	second.RequireServes(chain.Root(checkpointztest.FinalizedEpoch))
+
+	assert.Zero(t, node.Requests("/eth/v2/debug/beacon/states/"))
+}
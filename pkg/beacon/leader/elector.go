@@ -0,0 +1,219 @@
+package leader
+
+import (
+	"context"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/ethpandaops/checkpointz/pkg/clock"
+	"github.com/sirupsen/logrus"
+)
+
+// Role is a replica's part in leader election.
+type Role string
+
+const (
+	// RoleLeader downloads bundles from its upstreams.
+	RoleLeader Role = "leader"
+	// RoleFollower syncs bundles from the leader.
+	RoleFollower Role = "follower"
+	// RoleCandidate doesn't know who leads, e.g. because the lock is unreachable, so it downloads bundles
+	// from its upstreams as if there were no election.
+	RoleCandidate Role = "candidate"
+)
+
+// releaseTimeout bounds how long giving up the lease on shutdown can take.
+const releaseTimeout = 5 * time.Second
+
+// Status describes a replica's part in leader election.
+type Status struct {
+	// ID identifies this replica.
+	ID string `json:"id"`
+	// Role is this replica's role.
+	Role Role `json:"role"`
+	// Leader is the ID of the replica that leads, if known.
+	Leader string `json:"leader,omitempty"`
+	// LeaseExpiresAt is when the leader's lease lapses unless it's renewed.
+	LeaseExpiresAt *time.Time `json:"lease_expires_at,omitempty"`
+}
+
+// Elector campaigns for the lease on behalf of this replica and tracks who holds it.
+type Elector struct {
+	log    logrus.FieldLogger
+	config Config
+	lock   Lock
+	clock  clock.Clock
+	id     string
+
+	mu        sync.RWMutex
+	role      Role
+	lease     *Lease
+	callbacks []func(Role)
+}
+
+// NewElector returns a new Elector competing for the lease in lock.
+func NewElector(log logrus.FieldLogger, config Config, lock Lock, clk clock.Clock) *Elector {
+	id := config.ID
+	if id == "" {
+		id, _ = os.Hostname()
+	}
+
+	return &Elector{
+		log:    log.WithField("module", "beacon/leader").WithField("id", id),
+		config: config,
+		lock:   lock,
+		clock:  clk,
+		id:     id,
+		role:   RoleCandidate,
+	}
+}
+
+// OnRoleChanged registers a callback for when this replica's role changes. It must be called before Run.
+func (e *Elector) OnRoleChanged(cb func(Role)) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.callbacks = append(e.callbacks, cb)
+}
+
+// Run campaigns for the lease every renew interval until ctx is done, then gives it up if it's held.
+func (e *Elector) Run(ctx context.Context) error {
+	for {
+		e.campaign(ctx)
+
+		select {
+		case <-e.clock.After(e.config.RenewInterval):
+		case <-ctx.Done():
+			e.resign()
+
+			return ctx.Err()
+		}
+	}
+}
+
+// campaign takes or renews the lease if it's available, and updates our role from whoever holds it.
+func (e *Elector) campaign(ctx context.Context) {
+	now := e.clock.Now()
+
+	lease, err := e.lock.Acquire(ctx, Lease{
+		Holder:    e.id,
+		URL:       e.config.AdvertiseURL,
+		ExpiresAt: now.Add(e.config.LeaseDuration),
+	}, now)
+
+	e.mu.Lock()
+
+	if err != nil {
+		e.log.WithError(err).Warn("Failed to acquire leader lease")
+
+		// The last lease we saw still stands until it expires, as nobody can take it before then.
+		if e.lease != nil && now.Before(e.lease.ExpiresAt) {
+			e.mu.Unlock()
+
+			return
+		}
+
+		lease = nil
+	}
+
+	role := RoleCandidate
+
+	if lease != nil {
+		role = RoleFollower
+
+		if lease.Holder == e.id {
+			role = RoleLeader
+		}
+	}
+
+	previous := e.role
+	e.role = role
+	e.lease = lease
+	callbacks := e.callbacks
+
+	e.mu.Unlock()
+
+	if role == previous {
+		return
+	}
+
+	logCtx := e.log.WithField("role", role)
+	if lease != nil {
+		logCtx = logCtx.WithField("leader", lease.Holder)
+	}
+
+	logCtx.Info("Leader election role changed")
+
+	for _, cb := range callbacks {
+		cb(role)
+	}
+}
+
+// resign gives up the lease if we hold it, so that another replica can take over straight away.
+func (e *Elector) resign() {
+	e.mu.Lock()
+	previous := e.role
+	e.role = RoleCandidate
+	e.lease = nil
+	callbacks := e.callbacks
+	e.mu.Unlock()
+
+	if previous != RoleCandidate {
+		for _, cb := range callbacks {
+			cb(RoleCandidate)
+		}
+	}
+
+	if previous != RoleLeader {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), releaseTimeout)
+	defer cancel()
+
+	if err := e.lock.Release(ctx, e.id); err != nil {
+		e.log.WithError(err).Warn("Failed to release leader lease")
+	}
+}
+
+// Role returns this replica's role.
+func (e *Elector) Role() Role {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	return e.role
+}
+
+// Leader returns the lease of the replica we follow, or nil if we aren't following.
+func (e *Elector) Leader() *Lease {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	if e.role != RoleFollower || e.lease == nil {
+		return nil
+	}
+
+	lease := *e.lease
+
+	return &lease
+}
+
+// Status returns this replica's part in leader election.
+func (e *Elector) Status() *Status {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	status := &Status{
+		ID:   e.id,
+		Role: e.role,
+	}
+
+	if e.lease != nil {
+		expiresAt := e.lease.ExpiresAt
+		status.Leader = e.lease.Holder
+		status.LeaseExpiresAt = &expiresAt
+	}
+
+	return status
+}
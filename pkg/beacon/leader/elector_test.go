@@ -0,0 +1,161 @@
+package leader
+
+import (
+	"context"
+	"io"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ethpandaops/checkpointz/pkg/clock"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestElector(t *testing.T, id string, lock Lock, clk clock.Clock) *Elector {
+	t.Helper()
+
+	log := logrus.New()
+	log.SetOutput(io.Discard)
+
+	config := Config{
+		Enabled:       true,
+		ID:            id,
+		AdvertiseURL:  "http://" + id + ":5555",
+		LeaseDuration: 30 * time.Second,
+		RenewInterval: 10 * time.Second,
+		Lock:          LockConfig{Type: LockTypeFile, Path: "unused"},
+	}
+
+	require.NoError(t, config.Validate())
+
+	return NewElector(log, config, lock, clk)
+}
+
+func TestElectorFailsOver(t *testing.T) {
+	ctx := context.Background()
+	clk := clock.NewMock(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+	lock := NewFileLock(filepath.Join(t.TempDir(), "leader.json"), clk)
+
+	a := newTestElector(t, "a", lock, clk)
+	b := newTestElector(t, "b", lock, clk)
+
+	var roles []Role
+
+	b.OnRoleChanged(func(role Role) {
+		roles = append(roles, role)
+	})
+
+	assert.Equal(t, RoleCandidate, a.Role())
+	assert.Nil(t, a.Leader())
+
+	a.campaign(ctx)
+	b.campaign(ctx)
+
+	assert.Equal(t, RoleLeader, a.Role())
+	assert.Equal(t, RoleFollower, b.Role())
+	assert.Nil(t, a.Leader())
+	require.NotNil(t, b.Leader())
+	assert.Equal(t, "http://a:5555", b.Leader().URL)
+
+	// While the leader renews its lease, it keeps it.
+	for range 5 {
+		clk.Advance(10 * time.Second)
+		a.campaign(ctx)
+		b.campaign(ctx)
+	}
+
+	assert.Equal(t, RoleLeader, a.Role())
+	assert.Equal(t, RoleFollower, b.Role())
+
+	// Once it stops renewing, the lease expires and a follower takes over.
+	clk.Advance(20 * time.Second)
+	b.campaign(ctx)
+	assert.Equal(t, RoleFollower, b.Role(), "the lease hasn't expired yet")
+
+	clk.Advance(10 * time.Second)
+	b.campaign(ctx)
+	assert.Equal(t, RoleLeader, b.Role())
+
+	a.campaign(ctx)
+	assert.Equal(t, RoleFollower, a.Role())
+
+	// A leader that shuts down hands over straight away.
+	b.resign()
+	assert.Equal(t, RoleCandidate, b.Role())
+
+	a.campaign(ctx)
+	assert.Equal(t, RoleLeader, a.Role())
+
+	assert.Equal(t, []Role{RoleFollower, RoleLeader, RoleCandidate}, roles)
+
+	status := a.Status()
+	assert.Equal(t, "a", status.ID)
+	assert.Equal(t, RoleLeader, status.Role)
+	assert.Equal(t, "a", status.Leader)
+	require.NotNil(t, status.LeaseExpiresAt)
+	assert.Equal(t, clk.Now().Add(30*time.Second), *status.LeaseExpiresAt)
+}
+
+type failingLock struct{}
+
+func (failingLock) Acquire(context.Context, Lease, time.Time) (*Lease, error) {
+	return nil, assert.AnError
+}
+
+func (failingLock) Release(context.Context, string) error {
+	return assert.AnError
+}
+
+func TestElectorKeepsItsRoleUntilTheLeaseExpires(t *testing.T) {
+	ctx := context.Background()
+	clk := clock.NewMock(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+	path := filepath.Join(t.TempDir(), "leader.json")
+
+	e := newTestElector(t, "a", NewFileLock(path, clk), clk)
+	e.campaign(ctx)
+	require.Equal(t, RoleLeader, e.Role())
+
+	// The lock becomes unreachable.
+	e.lock = failingLock{}
+
+	clk.Advance(20 * time.Second)
+	e.campaign(ctx)
+	assert.Equal(t, RoleLeader, e.Role(), "nobody else can take the lease before it expires")
+
+	clk.Advance(10 * time.Second)
+	e.campaign(ctx)
+	assert.Equal(t, RoleCandidate, e.Role(), "once it expires, we can't know who leads")
+	assert.Nil(t, e.Status().LeaseExpiresAt)
+}
+
+func TestConfigValidate(t *testing.T) {
+	valid := Config{
+		Enabled:       true,
+		AdvertiseURL:  "http://checkpointz-0:5555",
+		LeaseDuration: 30 * time.Second,
+		RenewInterval: 10 * time.Second,
+		Lock:          LockConfig{Type: LockTypeFile, Path: "/shared/leader.json"},
+	}
+
+	require.NoError(t, valid.Validate())
+	require.NoError(t, (&Config{}).Validate(), "disabled election isn't validated")
+
+	for name, mutate := range map[string]func(*Config){
+		"no advertise url":         func(c *Config) { c.AdvertiseURL = "" },
+		"advertise url no scheme":  func(c *Config) { c.AdvertiseURL = "checkpointz-0:5555" },
+		"lease shorter than renew": func(c *Config) { c.LeaseDuration = 5 * time.Second },
+		"no renew interval":        func(c *Config) { c.RenewInterval = 0 },
+		"file lock without path":   func(c *Config) { c.Lock.Path = "" },
+		"object lock without key":  func(c *Config) { c.Lock = LockConfig{Type: LockTypeObject} },
+		"unknown lock":             func(c *Config) { c.Lock.Type = "etcd" },
+	} {
+		t.Run(name, func(t *testing.T) {
+			config := valid
+			mutate(&config)
+
+			assert.Error(t, config.Validate())
+		})
+	}
+}
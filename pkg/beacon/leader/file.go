@@ -0,0 +1,184 @@
+package leader
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/ethpandaops/checkpointz/pkg/clock"
+)
+
+const (
+	// fileMutexTimeout bounds how long Acquire and Release wait for another replica to finish with the
+	// lease file.
+	fileMutexTimeout = 5 * time.Second
+	// fileMutexStaleAfter is how old a mutex file must be before it's assumed to have been left behind by a
+	// replica that crashed while holding it.
+	fileMutexStaleAfter = 30 * time.Second
+)
+
+// FileLock holds the lease in a JSON file. Replicas must share the filesystem the file is on. Updates are
+// serialised with a mutex file next to it, created exclusively, so the lock works on any platform. The mutex
+// file records when it was taken by the clock, which decides when it's stale.
+type FileLock struct {
+	path  string
+	clock clock.Clock
+}
+
+var _ Lock = (*FileLock)(nil)
+
+// NewFileLock returns a lock holding the lease in the file at path.
+func NewFileLock(path string, clk clock.Clock) *FileLock {
+	return &FileLock{path: path, clock: clk}
+}
+
+func (l *FileLock) Acquire(ctx context.Context, candidate Lease, now time.Time) (*Lease, error) {
+	unlock, err := l.lock(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	current, err := l.read()
+	if err != nil {
+		return nil, err
+	}
+
+	if !available(current, candidate, now) {
+		return current, nil
+	}
+
+	if err := l.write(candidate); err != nil {
+		return nil, err
+	}
+
+	return &candidate, nil
+}
+
+func (l *FileLock) Release(ctx context.Context, holder string) error {
+	unlock, err := l.lock(ctx)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	current, err := l.read()
+	if err != nil || current == nil || current.Holder != holder {
+		return err
+	}
+
+	return os.Remove(l.path)
+}
+
+// lock takes the mutex file, returning a function that releases it.
+func (l *FileLock) lock(ctx context.Context) (func(), error) {
+	mutex := l.path + ".lock"
+	deadline := l.clock.Now().Add(fileMutexTimeout)
+
+	for {
+		f, err := os.OpenFile(mutex, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600) //nolint:gosec // the path is the operator's lease file
+		if err == nil {
+			_, err = f.WriteString(l.clock.Now().Format(time.RFC3339Nano))
+			f.Close()
+
+			if err != nil {
+				os.Remove(mutex)
+
+				return nil, err
+			}
+
+			return func() { os.Remove(mutex) }, nil
+		}
+
+		if !errors.Is(err, os.ErrExist) {
+			return nil, err
+		}
+
+		if takenAt, err := l.mutexTakenAt(mutex); err == nil && l.clock.Now().Sub(takenAt) > fileMutexStaleAfter {
+			os.Remove(mutex)
+
+			continue
+		}
+
+		if !l.clock.Now().Before(deadline) {
+			return nil, fmt.Errorf("timed out waiting for %s", mutex)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("gave up waiting for %s: %w", mutex, ctx.Err())
+		case <-l.clock.After(25 * time.Millisecond):
+		}
+	}
+}
+
+// mutexTakenAt returns when the mutex file was taken. A replica that crashed before recording it leaves an
+// empty file, which is dated by its modification time instead.
+func (l *FileLock) mutexTakenAt(mutex string) (time.Time, error) {
+	data, err := os.ReadFile(mutex) //nolint:gosec // the path is the operator's lease file
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	if takenAt, err := time.Parse(time.RFC3339Nano, string(data)); err == nil {
+		return takenAt, nil
+	}
+
+	info, err := os.Stat(mutex)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return info.ModTime(), nil
+}
+
+// read returns the lease in the file, or nil if there's none.
+func (l *FileLock) read() (*Lease, error) {
+	data, err := os.ReadFile(l.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil //nolint:nilnil // no lease is held
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	lease := &Lease{}
+	if err := json.Unmarshal(data, lease); err != nil {
+		return nil, fmt.Errorf("invalid lease file: %w", err)
+	}
+
+	return lease, nil
+}
+
+// write replaces the lease in the file.
+func (l *FileLock) write(lease Lease) error {
+	data, err := json.Marshal(lease)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(l.path), filepath.Base(l.path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+
+		return err
+	}
+
+	return os.Rename(tmp.Name(), l.path)
+}
@@ -0,0 +1,33 @@
+// Package leader elects one replica of a fleet to download bundles from upstreams. Replicas compete for a
+// lease held in a shared Lock, and the holder leads until it stops renewing it.
+package leader
+
+import (
+	"context"
+	"time"
+)
+
+// Lease is a claim to leadership.
+type Lease struct {
+	// Holder is the ID of the replica holding the lease.
+	Holder string `json:"holder"`
+	// URL is the base URL of the holder's API.
+	URL string `json:"url"`
+	// ExpiresAt is when the lease lapses unless it's renewed.
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// Lock holds the lease that replicas compete for.
+type Lock interface {
+	// Acquire takes or renews the lease for the candidate if it's free, has expired by now or is already
+	// held by the candidate. It returns the lease as it stands afterwards, which is the candidate's if it
+	// was taken.
+	Acquire(ctx context.Context, candidate Lease, now time.Time) (*Lease, error)
+	// Release gives up the lease if it's held by holder.
+	Release(ctx context.Context, holder string) error
+}
+
+// available returns true if candidate may take the current lease.
+func available(current *Lease, candidate Lease, now time.Time) bool {
+	return current == nil || current.Holder == candidate.Holder || !now.Before(current.ExpiresAt)
+}
@@ -0,0 +1,164 @@
+package leader
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethpandaops/checkpointz/pkg/beacon/beacontest"
+	"github.com/ethpandaops/checkpointz/pkg/beacon/store/remote"
+	"github.com/ethpandaops/checkpointz/pkg/clock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testLocks returns a fresh lock of every type.
+func testLocks(t *testing.T) map[string]Lock {
+	t.Helper()
+
+	objects := beacontest.NewObjectStore()
+	t.Cleanup(objects.Close)
+
+	client, err := remote.NewClient(remote.Config{
+		Endpoint:        objects.URL(),
+		Region:          "us-east-1",
+		AccessKeyID:     "access",
+		SecretAccessKey: "secret",
+		PathStyle:       true,
+	})
+	require.NoError(t, err)
+
+	return map[string]Lock{
+		"file":   NewFileLock(filepath.Join(t.TempDir(), "leader.json"), clock.New()),
+		"object": NewObjectLock(client, "checkpointz", "e2e/leader.json"),
+	}
+}
+
+func TestLocks(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	a := Lease{Holder: "a", URL: "http://a:5555", ExpiresAt: now.Add(30 * time.Second)}
+	b := Lease{Holder: "b", URL: "http://b:5555", ExpiresAt: now.Add(30 * time.Second)}
+
+	for name, lock := range testLocks(t) {
+		t.Run(name, func(t *testing.T) {
+			// The first candidate takes the free lease.
+			lease, err := lock.Acquire(ctx, a, now)
+			require.NoError(t, err)
+			assert.Equal(t, a, *lease)
+
+			// Others see it held until it expires.
+			lease, err = lock.Acquire(ctx, b, now.Add(10*time.Second))
+			require.NoError(t, err)
+			assert.Equal(t, a, *lease)
+
+			// The holder renews it.
+			renewed := a
+			renewed.ExpiresAt = now.Add(40 * time.Second)
+
+			lease, err = lock.Acquire(ctx, renewed, now.Add(10*time.Second))
+			require.NoError(t, err)
+			assert.Equal(t, renewed, *lease)
+
+			// Once it expires, another candidate takes it.
+			taken := b
+			taken.ExpiresAt = now.Add(70 * time.Second)
+
+			lease, err = lock.Acquire(ctx, taken, now.Add(40*time.Second))
+			require.NoError(t, err)
+			assert.Equal(t, taken, *lease)
+
+			// Only the holder can release it.
+			require.NoError(t, lock.Release(ctx, "a"))
+
+			lease, err = lock.Acquire(ctx, a, now.Add(41*time.Second))
+			require.NoError(t, err)
+			assert.Equal(t, "b", lease.Holder)
+
+			require.NoError(t, lock.Release(ctx, "b"))
+
+			lease, err = lock.Acquire(ctx, a, now.Add(42*time.Second))
+			require.NoError(t, err)
+			assert.Equal(t, "a", lease.Holder)
+		})
+	}
+}
+
+func TestLocksGrantOneHolderAtATime(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	for name, lock := range testLocks(t) {
+		t.Run(name, func(t *testing.T) {
+			var (
+				wg      sync.WaitGroup
+				mu      sync.Mutex
+				holders = make(map[string]struct{})
+			)
+
+			for _, holder := range []string{"a", "b", "c", "d"} {
+				wg.Add(1)
+
+				go func() {
+					defer wg.Done()
+
+					lease, err := lock.Acquire(context.Background(), Lease{Holder: holder, ExpiresAt: now.Add(time.Minute)}, now)
+					if !assert.NoError(t, err) {
+						return
+					}
+
+					mu.Lock()
+					defer mu.Unlock()
+
+					holders[lease.Holder] = struct{}{}
+				}()
+			}
+
+			wg.Wait()
+
+			assert.Len(t, holders, 1, "every candidate should agree on a single holder")
+		})
+	}
+}
+
+func TestFileLockBreaksStaleMutex(t *testing.T) {
+	clk := clock.NewMock(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+	path := filepath.Join(t.TempDir(), "leader.json")
+	lock := NewFileLock(path, clk)
+
+	// A replica crashed while holding the mutex.
+	require.NoError(t, os.WriteFile(path+".lock", []byte(clk.Now().Format(time.RFC3339Nano)), 0o600))
+
+	candidate := Lease{Holder: "a", URL: "http://a:5555", ExpiresAt: clk.Now().Add(time.Minute)}
+
+	done := make(chan error, 1)
+
+	go func() {
+		_, err := lock.Acquire(context.Background(), candidate, clk.Now())
+		done <- err
+	}()
+
+	// While the mutex is fresh, it's waited on until the timeout by the lock's clock.
+	clk.BlockUntil(1)
+	clk.Advance(fileMutexTimeout)
+
+	select {
+	case err := <-done:
+		require.Error(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("waiting for the mutex did not time out")
+	}
+
+	// Once it's stale, it's broken.
+	clk.Advance(fileMutexStaleAfter)
+
+	lease, err := lock.Acquire(context.Background(), candidate, clk.Now())
+	require.NoError(t, err)
+	assert.Equal(t, candidate, *lease)
+
+	_, err = os.Stat(path + ".lock")
+	assert.ErrorIs(t, err, os.ErrNotExist, "the mutex is released after acquiring")
+}
@@ -0,0 +1,116 @@
+package leader
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+)
+
+// ObjectLock holds the lease in an object in S3 compatible storage. Updates are conditional on the object
+// not having changed since it was read, so two replicas can't both take the lease.
+type ObjectLock struct {
+	client *minio.Client
+	bucket string
+	key    string
+}
+
+var _ Lock = (*ObjectLock)(nil)
+
+// NewObjectLock returns a lock holding the lease in the object with the given key.
+func NewObjectLock(client *minio.Client, bucket, key string) *ObjectLock {
+	return &ObjectLock{
+		client: client,
+		bucket: bucket,
+		key:    key,
+	}
+}
+
+func (l *ObjectLock) Acquire(ctx context.Context, candidate Lease, now time.Time) (*Lease, error) {
+	current, etag, err := l.read(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if !available(current, candidate, now) {
+		return current, nil
+	}
+
+	data, err := json.Marshal(candidate)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := minio.PutObjectOptions{ContentType: "application/json"}
+
+	if current == nil {
+		opts.SetMatchETagExcept("*")
+	} else {
+		opts.SetMatchETag(etag)
+	}
+
+	_, err = l.client.PutObject(ctx, l.bucket, l.key, bytes.NewReader(data), int64(len(data)), opts)
+	if err == nil {
+		return &candidate, nil
+	}
+
+	// Another replica changed the lease since we read it, so it holds it now.
+	if minio.ToErrorResponse(err).Code == minio.PreconditionFailed {
+		current, _, err = l.read(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		if current == nil {
+			return nil, errors.New("lease changed while being acquired")
+		}
+
+		return current, nil
+	}
+
+	return nil, err
+}
+
+func (l *ObjectLock) Release(ctx context.Context, holder string) error {
+	current, _, err := l.read(ctx)
+	if err != nil || current == nil || current.Holder != holder {
+		return err
+	}
+
+	return l.client.RemoveObject(ctx, l.bucket, l.key, minio.RemoveObjectOptions{})
+}
+
+// read returns the lease in the object and the object's ETag, or nil if there's none.
+func (l *ObjectLock) read(ctx context.Context) (*Lease, string, error) {
+	object, err := l.client.GetObject(ctx, l.bucket, l.key, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, "", err
+	}
+	defer object.Close()
+
+	info, err := object.Stat()
+	if minio.ToErrorResponse(err).Code == minio.NoSuchKey {
+		return nil, "", nil
+	}
+
+	if err != nil {
+		return nil, "", err
+	}
+
+	data, err := io.ReadAll(object)
+	if err != nil {
+		return nil, "", err
+	}
+
+	lease := &Lease{}
+	if err := json.Unmarshal(data, lease); err != nil {
+		return nil, "", fmt.Errorf("invalid lease object: %w", err)
+	}
+
+	return lease, info.ETag, nil
+}
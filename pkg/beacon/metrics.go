@@ -2,6 +2,8 @@ package beacon
 
 import (
 	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/ethpandaops/checkpointz/pkg/beacon/leader"
+	"github.com/ethpandaops/checkpointz/pkg/beacon/witness"
 	"github.com/prometheus/client_golang/prometheus"
 )
 
@@ -9,6 +11,16 @@ type Metrics struct {
 	servingEpoch  prometheus.Gauge
 	headEpoch     prometheus.Gauge
 	operatingMode prometheus.GaugeVec
+
+	blobSidecarVerificationFailures       *prometheus.CounterVec
+	dataColumnSidecarVerificationFailures *prometheus.CounterVec
+	blockSignatureVerificationFailures    *prometheus.CounterVec
+
+	witnessVerdict            *prometheus.GaugeVec
+	witnessDisagreements      *prometheus.CounterVec
+	servingCheckpointsBlocked prometheus.Counter
+
+	coordinationRole *prometheus.GaugeVec
 }
 
 func NewMetrics(namespace string) *Metrics {
@@ -29,11 +41,59 @@ func NewMetrics(namespace string) *Metrics {
 				Name:      "operating_mode",
 				Help:      "The current operating mode",
 			}, []string{"mode"}),
+		blobSidecarVerificationFailures: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Name:      "blob_sidecar_verification_failures_total",
+				Help:      "The number of blob sidecar sets that failed verification, by upstream",
+			}, []string{"upstream"}),
+		dataColumnSidecarVerificationFailures: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Name:      "data_column_sidecar_verification_failures_total",
+				Help:      "The number of data column sidecar sets that failed verification, by upstream",
+			}, []string{"upstream"}),
+		blockSignatureVerificationFailures: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Name:      "block_signature_verification_failures_total",
+				Help:      "The number of blocks that failed signature verification, by upstream",
+			}, []string{"upstream"}),
+		witnessVerdict: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Name:      "witness_verdict",
+				Help:      "The latest verdict of each witness on the serving checkpoint",
+			}, []string{"witness", "verdict"}),
+		witnessDisagreements: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Name:      "witness_disagreements_total",
+				Help:      "The number of checks in which a witness disagreed with our checkpoint, by witness",
+			}, []string{"witness"}),
+		servingCheckpointsBlocked: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "serving_checkpoints_blocked_total",
+			Help:      "The number of new serving checkpoints that were refused because a witness disagreed",
+		}),
+		coordinationRole: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Name:      "coordination_role",
+				Help:      "This instance's current leader election role",
+			}, []string{"role"}),
 	}
 
 	prometheus.MustRegister(m.servingEpoch)
 	prometheus.MustRegister(m.headEpoch)
 	prometheus.MustRegister(m.operatingMode)
+	prometheus.MustRegister(m.blobSidecarVerificationFailures)
+	prometheus.MustRegister(m.dataColumnSidecarVerificationFailures)
+	prometheus.MustRegister(m.blockSignatureVerificationFailures)
+	prometheus.MustRegister(m.witnessVerdict)
+	prometheus.MustRegister(m.witnessDisagreements)
+	prometheus.MustRegister(m.servingCheckpointsBlocked)
+	prometheus.MustRegister(m.coordinationRole)
 
 	return m
 }
@@ -50,3 +110,39 @@ func (m *Metrics) ObserveOperatingMode(mode OperatingMode) {
 	m.operatingMode.Reset()
 	m.operatingMode.WithLabelValues(string(mode)).Set(1)
 }
+
+func (m *Metrics) ObserveBlobSidecarVerificationFailure(upstream string) {
+	m.blobSidecarVerificationFailures.WithLabelValues(upstream).Inc()
+}
+
+func (m *Metrics) ObserveDataColumnSidecarVerificationFailure(upstream string) {
+	m.dataColumnSidecarVerificationFailures.WithLabelValues(upstream).Inc()
+}
+
+func (m *Metrics) ObserveBlockSignatureVerificationFailure(upstream string) {
+	m.blockSignatureVerificationFailures.WithLabelValues(upstream).Inc()
+}
+
+func (m *Metrics) ObserveWitnessVerdict(name string, verdict witness.Verdict) {
+	for _, v := range witness.Verdicts {
+		value := 0.0
+		if v == verdict {
+			value = 1
+		}
+
+		m.witnessVerdict.WithLabelValues(name, string(v)).Set(value)
+	}
+
+	if verdict == witness.VerdictDisagree {
+		m.witnessDisagreements.WithLabelValues(name).Inc()
+	}
+}
+
+func (m *Metrics) ObserveServingCheckpointBlocked() {
+	m.servingCheckpointsBlocked.Inc()
+}
+
+func (m *Metrics) ObserveCoordinationRole(role leader.Role) {
+	m.coordinationRole.Reset()
+	m.coordinationRole.WithLabelValues(string(role)).Set(1)
+}
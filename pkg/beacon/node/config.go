@@ -1,8 +1,122 @@
 package node
 
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/creasty/defaults"
+)
+
 type Config struct {
 	Name         string            `yaml:"name"`
 	Address      string            `yaml:"address"`
 	DataProvider bool              `yaml:"dataProvider"`
 	Headers      map[string]string `yaml:"headers"`
+
+	// Timeout bounds each attempt at a request to the upstream, other than beacon state downloads.
+	Timeout time.Duration `yaml:"timeout" default:"30s"`
+	// TimeoutSeconds is the deprecated form of Timeout, still accepted so that older configs load.
+	TimeoutSeconds int `yaml:"timeoutSeconds"`
+	// StateTimeout bounds each attempt at a beacon state download, which can be several hundred megabytes.
+	StateTimeout time.Duration `yaml:"stateTimeout" default:"10m"`
+	// Retry configures how failed requests to the upstream are retried.
+	Retry RetryConfig `yaml:"retry"`
+	// MaxConcurrentRequests limits the number of requests in flight to the upstream. 0 is unlimited.
+	MaxConcurrentRequests int `yaml:"maxConcurrentRequests" default:"8"`
+}
+
+// RetryConfig configures how failed requests to an upstream are retried.
+type RetryConfig struct {
+	// MaxAttempts is the number of times a request is attempted, including the first. 1 disables retries.
+	MaxAttempts int `yaml:"maxAttempts" default:"3"`
+	// InitialBackoff is the delay before the first retry. It doubles on every retry after that.
+	InitialBackoff time.Duration `yaml:"initialBackoff" default:"1s"`
+	// MaxBackoff caps the delay between retries.
+	MaxBackoff time.Duration `yaml:"maxBackoff" default:"30s"`
+}
+
+// UnmarshalYAML applies the defaults before decoding, as defaults aren't set on slice elements.
+func (c *Config) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	if err := defaults.Set(c); err != nil {
+		return err
+	}
+
+	type plain Config
+
+	if err := unmarshal((*plain)(c)); err != nil {
+		return err
+	}
+
+	if c.TimeoutSeconds == 0 {
+		return nil
+	}
+
+	// The keys are decoded again to tell an explicit timeout apart from the default.
+	var keys map[string]interface{}
+	if err := unmarshal(&keys); err != nil {
+		return err
+	}
+
+	if _, ok := keys["timeout"]; ok {
+		return errors.New("timeout and the deprecated timeoutSeconds can't both be set")
+	}
+
+	c.Timeout = time.Duration(c.TimeoutSeconds) * time.Second
+
+	return nil
+}
+
+// TimeoutFor returns the timeout for a request to the given path.
+func (c *Config) TimeoutFor(path string) time.Duration {
+	if strings.HasPrefix(path, stateEndpointPrefix) {
+		return c.StateTimeout
+	}
+
+	return c.Timeout
+}
+
+// MaxTimeout returns the longest timeout of any request to the upstream.
+func (c *Config) MaxTimeout() time.Duration {
+	return max(c.Timeout, c.StateTimeout)
+}
+
+func (c *Config) Validate() error {
+	if c.Name == "" {
+		return errors.New("name is required")
+	}
+
+	if c.Address == "" {
+		return errors.New("address is required")
+	}
+
+	if c.Timeout <= 0 {
+		return errors.New("timeout must be positive")
+	}
+
+	if c.StateTimeout <= 0 {
+		return errors.New("stateTimeout must be positive")
+	}
+
+	if c.MaxConcurrentRequests < 0 {
+		return errors.New("maxConcurrentRequests must not be negative")
+	}
+
+	return c.Retry.Validate()
+}
+
+func (c *RetryConfig) Validate() error {
+	if c.MaxAttempts < 1 {
+		return errors.New("retry.maxAttempts must be at least 1")
+	}
+
+	if c.InitialBackoff < 0 {
+		return errors.New("retry.initialBackoff must not be negative")
+	}
+
+	if c.MaxBackoff < c.InitialBackoff {
+		return errors.New("retry.maxBackoff must not be less than retry.initialBackoff")
+	}
+
+	return nil
 }
@@ -0,0 +1,111 @@
+package node
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v2"
+)
+
+func TestConfigUnmarshalAppliesDefaults(t *testing.T) {
+	var configs []Config
+
+	err := yaml.UnmarshalStrict([]byte(`
+- name: a
+  address: http://localhost:5052
+- name: b
+  address: http://localhost:5053
+  timeout: 5s
+  retry:
+    maxAttempts: 1
+`), &configs)
+	require.NoError(t, err)
+	require.Len(t, configs, 2)
+
+	assert.Equal(t, 30*time.Second, configs[0].Timeout)
+	assert.Equal(t, 10*time.Minute, configs[0].StateTimeout)
+	assert.Equal(t, 3, configs[0].Retry.MaxAttempts)
+	assert.Equal(t, time.Second, configs[0].Retry.InitialBackoff)
+	assert.Equal(t, 30*time.Second, configs[0].Retry.MaxBackoff)
+	assert.Equal(t, 8, configs[0].MaxConcurrentRequests)
+
+	assert.Equal(t, 5*time.Second, configs[1].Timeout)
+	assert.Equal(t, 1, configs[1].Retry.MaxAttempts)
+	assert.Equal(t, time.Second, configs[1].Retry.InitialBackoff)
+
+	for _, config := range configs {
+		assert.NoError(t, config.Validate())
+	}
+}
+
+func TestConfigUnmarshalRejectsUnknownKeys(t *testing.T) {
+	var configs []Config
+
+	err := yaml.UnmarshalStrict([]byte(`
+- name: a
+  address: http://localhost:5052
+  timeoutMinutes: 30
+`), &configs)
+	assert.Error(t, err)
+}
+
+func TestConfigUnmarshalAcceptsDeprecatedTimeoutSeconds(t *testing.T) {
+	var configs []Config
+
+	err := yaml.UnmarshalStrict([]byte(`
+- name: a
+  address: http://localhost:5052
+  timeoutSeconds: 5
+`), &configs)
+	require.NoError(t, err)
+	require.Len(t, configs, 1)
+
+	assert.Equal(t, 5*time.Second, configs[0].Timeout)
+	assert.NoError(t, configs[0].Validate())
+
+	err = yaml.UnmarshalStrict([]byte(`
+- name: a
+  address: http://localhost:5052
+  timeout: 10s
+  timeoutSeconds: 5
+`), &configs)
+	assert.Error(t, err, "the deprecated key can't be mixed with its replacement")
+}
+
+func TestConfigTimeoutFor(t *testing.T) {
+	config := Config{Timeout: time.Second, StateTimeout: time.Minute}
+
+	assert.Equal(t, time.Minute, config.TimeoutFor("/eth/v2/debug/beacon/states/finalized"))
+	assert.Equal(t, time.Second, config.TimeoutFor("/eth/v2/beacon/blocks/finalized"))
+	assert.Equal(t, time.Minute, config.MaxTimeout())
+}
+
+func TestConfigValidate(t *testing.T) {
+	valid := Config{
+		Name:         "a",
+		Address:      "http://localhost:5052",
+		Timeout:      time.Second,
+		StateTimeout: time.Minute,
+		Retry:        RetryConfig{MaxAttempts: 1},
+	}
+
+	require.NoError(t, valid.Validate())
+
+	for name, mutate := range map[string]func(*Config){
+		"no name":          func(c *Config) { c.Name = "" },
+		"no timeout":       func(c *Config) { c.Timeout = 0 },
+		"no state timeout": func(c *Config) { c.StateTimeout = 0 },
+		"no attempts":      func(c *Config) { c.Retry.MaxAttempts = 0 },
+		"negative limit":   func(c *Config) { c.MaxConcurrentRequests = -1 },
+		"backoff inverted": func(c *Config) { c.Retry.InitialBackoff = time.Second },
+	} {
+		t.Run(name, func(t *testing.T) {
+			config := valid
+			mutate(&config)
+
+			assert.Error(t, config.Validate())
+		})
+	}
+}
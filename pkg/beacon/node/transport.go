@@ -0,0 +1,188 @@
+package node
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/ethpandaops/checkpointz/pkg/clock"
+)
+
+// stateEndpointPrefix is the path prefix of beacon state downloads, which get the longer state timeout.
+const stateEndpointPrefix = "/eth/v2/debug/beacon/states/"
+
+// Transport is an http.RoundTripper that applies an upstream's timeouts, retry policy and concurrency limit.
+type Transport struct {
+	config Config
+	next   http.RoundTripper
+	clock  clock.Clock
+	slots  chan struct{}
+}
+
+// NewTransport wraps next with the timeouts, retries and concurrency limit of the given upstream config.
+// Retries are backed off on clk.
+func NewTransport(config Config, next http.RoundTripper, clk clock.Clock) *Transport {
+	t := &Transport{
+		config: config,
+		next:   next,
+		clock:  clk,
+	}
+
+	if config.MaxConcurrentRequests > 0 {
+		t.slots = make(chan struct{}, config.MaxConcurrentRequests)
+	}
+
+	return t
+}
+
+// NewHTTPClient returns an HTTP client for the upstream. Requests are bounded by the transport rather than
+// the client, so that beacon states get a longer timeout than everything else.
+func NewHTTPClient(config Config, clk clock.Clock) *http.Client {
+	return &http.Client{
+		Transport: NewTransport(config, http.DefaultTransport.(*http.Transport).Clone(), clk),
+	}
+}
+
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	timeout := t.config.TimeoutFor(req.URL.Path)
+
+	// Waiting for a slot is bounded by the timeout too, so that a stuck upstream doesn't queue requests forever.
+	acquireCtx, cancel := context.WithTimeout(req.Context(), timeout)
+	err := t.acquire(acquireCtx)
+
+	cancel()
+
+	if err != nil {
+		return nil, err
+	}
+
+	rsp, cancel, err := t.roundTrip(req, timeout)
+	if err != nil {
+		t.release()
+
+		return nil, err
+	}
+
+	// The timeout and concurrency slot cover reading the body too, so are only released once it's closed.
+	rsp.Body = &releasingBody{
+		ReadCloser: rsp.Body,
+		release: func() {
+			t.release()
+			cancel()
+		},
+	}
+
+	return rsp, nil
+}
+
+// roundTrip sends the request, retrying it if allowed. Each attempt gets its own timeout, so a slow attempt
+// doesn't eat into the next. The returned cancel func releases the timeout of the attempt that succeeded.
+func (t *Transport) roundTrip(req *http.Request, timeout time.Duration) (*http.Response, context.CancelFunc, error) {
+	attempts := t.config.Retry.MaxAttempts
+	if !retryable(req) {
+		attempts = 1
+	}
+
+	backoff := t.config.Retry.InitialBackoff
+
+	for attempt := 1; ; attempt++ {
+		if attempt > 1 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, nil, err
+			}
+
+			req.Body = body
+		}
+
+		ctx, cancel := context.WithTimeout(req.Context(), timeout)
+
+		rsp, err := t.next.RoundTrip(req.WithContext(ctx))
+
+		// An attempt that ran out of time is retried, unless the caller has given up.
+		timedOut := err != nil && ctx.Err() != nil
+
+		if attempt >= attempts || !(timedOut || shouldRetry(rsp, err)) || req.Context().Err() != nil {
+			if err != nil {
+				cancel()
+
+				return nil, nil, err
+			}
+
+			return rsp, cancel, nil
+		}
+
+		if rsp != nil {
+			// Drain a little of the body so the connection can be reused.
+			_, _ = io.CopyN(io.Discard, rsp.Body, 4096)
+			rsp.Body.Close()
+		}
+
+		cancel()
+
+		select {
+		case <-req.Context().Done():
+			return nil, nil, req.Context().Err()
+		case <-t.clock.After(backoff):
+		}
+
+		backoff = min(backoff*2, t.config.Retry.MaxBackoff)
+	}
+}
+
+func (t *Transport) acquire(ctx context.Context) error {
+	if t.slots == nil {
+		return nil
+	}
+
+	select {
+	case t.slots <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (t *Transport) release() {
+	if t.slots == nil {
+		return
+	}
+
+	<-t.slots
+}
+
+// retryable returns true if the request is idempotent and can be replayed.
+func retryable(req *http.Request) bool {
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		return false
+	}
+
+	return req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+}
+
+// shouldRetry returns true if the attempt failed in a way that may succeed if tried again.
+func shouldRetry(rsp *http.Response, err error) bool {
+	if err != nil {
+		return !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+	}
+
+	return rsp.StatusCode == http.StatusTooManyRequests || rsp.StatusCode >= http.StatusInternalServerError
+}
+
+// releasingBody calls release once when the body is closed.
+type releasingBody struct {
+	io.ReadCloser
+	release func()
+	once    sync.Once
+}
+
+func (b *releasingBody) Close() error {
+	err := b.ReadCloser.Close()
+
+	b.once.Do(b.release)
+
+	return err
+}
@@ -0,0 +1,230 @@
+package node
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ethpandaops/checkpointz/pkg/clock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testConfig() Config {
+	return Config{
+		Timeout:      time.Second,
+		StateTimeout: time.Second,
+		Retry: RetryConfig{
+			MaxAttempts:    3,
+			InitialBackoff: time.Millisecond,
+			MaxBackoff:     time.Millisecond,
+		},
+	}
+}
+
+func TestTransportRetriesServerErrors(t *testing.T) {
+	var calls atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+
+			return
+		}
+
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	rsp, err := NewHTTPClient(testConfig(), clock.New()).Get(server.URL)
+	require.NoError(t, err)
+
+	defer rsp.Body.Close()
+
+	body, err := io.ReadAll(rsp.Body)
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusOK, rsp.StatusCode)
+	assert.Equal(t, "ok", string(body))
+	assert.Equal(t, int32(3), calls.Load())
+}
+
+func TestTransportGivesUpAfterMaxAttempts(t *testing.T) {
+	var calls atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	rsp, err := NewHTTPClient(testConfig(), clock.New()).Get(server.URL)
+	require.NoError(t, err)
+
+	rsp.Body.Close()
+
+	assert.Equal(t, http.StatusTooManyRequests, rsp.StatusCode)
+	assert.Equal(t, int32(3), calls.Load())
+}
+
+func TestTransportDoesNotRetryClientErrorsOrPosts(t *testing.T) {
+	var calls atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+
+		if r.Method == http.MethodPost {
+			w.WriteHeader(http.StatusInternalServerError)
+
+			return
+		}
+
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	client := NewHTTPClient(testConfig(), clock.New())
+
+	rsp, err := client.Get(server.URL)
+	require.NoError(t, err)
+	rsp.Body.Close()
+
+	rsp, err = client.Post(server.URL, "application/json", http.NoBody)
+	require.NoError(t, err)
+	rsp.Body.Close()
+
+	assert.Equal(t, int32(2), calls.Load())
+}
+
+func TestTransportAppliesStateTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(100 * time.Millisecond)
+
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	config := testConfig()
+	config.Timeout = 20 * time.Millisecond
+	config.StateTimeout = time.Second
+	config.Retry.MaxAttempts = 1
+
+	client := NewHTTPClient(config, clock.New())
+
+	_, err := client.Get(server.URL + "/eth/v2/beacon/blocks/finalized")
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	rsp, err := client.Get(server.URL + "/eth/v2/debug/beacon/states/finalized")
+	require.NoError(t, err)
+	rsp.Body.Close()
+}
+
+func TestTransportTimesOutEachAttempt(t *testing.T) {
+	var calls atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			time.Sleep(100 * time.Millisecond)
+		}
+
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	config := testConfig()
+	config.Timeout = 50 * time.Millisecond
+
+	rsp, err := NewHTTPClient(config, clock.New()).Get(server.URL)
+	require.NoError(t, err, "the retry should get a timeout of its own")
+	rsp.Body.Close()
+
+	assert.Equal(t, int32(2), calls.Load())
+}
+
+func TestTransportBacksOffOnTheClock(t *testing.T) {
+	var calls atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+
+			return
+		}
+
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	config := testConfig()
+	config.Retry.InitialBackoff = time.Hour
+	config.Retry.MaxBackoff = time.Hour
+
+	mock := clock.NewMock(time.Now())
+	done := make(chan error, 1)
+
+	go func() {
+		rsp, err := NewHTTPClient(config, mock).Get(server.URL)
+		if err == nil {
+			rsp.Body.Close()
+		}
+
+		done <- err
+	}()
+
+	mock.BlockUntil(1)
+	assert.Equal(t, int32(1), calls.Load())
+
+	mock.Advance(time.Hour)
+
+	require.NoError(t, <-done)
+	assert.Equal(t, int32(2), calls.Load())
+}
+
+func TestTransportLimitsConcurrency(t *testing.T) {
+	var inFlight, peak atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		current := inFlight.Add(1)
+		defer inFlight.Add(-1)
+
+		for {
+			previous := peak.Load()
+			if current <= previous || peak.CompareAndSwap(previous, current) {
+				break
+			}
+		}
+
+		time.Sleep(20 * time.Millisecond)
+	}))
+	defer server.Close()
+
+	config := testConfig()
+	config.MaxConcurrentRequests = 2
+
+	client := NewHTTPClient(config, clock.New())
+
+	done := make(chan struct{})
+
+	for range 6 {
+		go func() {
+			defer func() { done <- struct{}{} }()
+
+			rsp, err := client.Get(server.URL)
+			if err != nil {
+				return
+			}
+
+			rsp.Body.Close()
+		}()
+	}
+
+	for range 6 {
+		<-done
+	}
+
+	assert.Equal(t, int32(2), peak.Load())
+}
@@ -4,57 +4,130 @@ import (
 	"context"
 	"errors"
 	"math/rand"
+	"net/http"
 	"strings"
+	"sync/atomic"
 	"time"
 
+	eth2client "github.com/attestantio/go-eth2-client"
+	"github.com/attestantio/go-eth2-client/api"
 	v1 "github.com/attestantio/go-eth2-client/api/v1"
 	ehttp "github.com/attestantio/go-eth2-client/http"
 	sbeacon "github.com/ethpandaops/beacon/pkg/beacon"
 	"github.com/ethpandaops/checkpointz/pkg/beacon/node"
+	"github.com/ethpandaops/checkpointz/pkg/clock"
 	"github.com/sirupsen/logrus"
 )
 
 type Node struct {
 	Config node.Config
 	Beacon sbeacon.Node
+	// HTTP is the client for requests the beacon client doesn't support. It applies the upstream's timeouts,
+	// retries and concurrency limit, and shares them with the beacon client.
+	HTTP *http.Client
+
+	// finality is the node's latest head finality. The beacon client replaces its own copy without
+	// synchronisation, so it's read from the update events instead.
+	finality atomic.Pointer[v1.Finality]
 }
 
 type Nodes []*Node
 
-func NewNodesFromConfig(log logrus.FieldLogger, configs []node.Config, namespace string, customPreset bool) Nodes {
+func NewNodesFromConfig(log logrus.FieldLogger, configs []node.Config, namespace string, customPreset bool, clk clock.Clock) Nodes {
 	nodes := make(Nodes, len(configs))
 
 	for i, config := range configs {
-		sconfig := &sbeacon.Config{
-			Name:    config.Name,
-			Addr:    strings.TrimRight(config.Address, "/"),
-			Headers: config.Headers,
+		httpClient := node.NewHTTPClient(config, clk)
+		opts := nodeOptions(config, httpClient, customPreset)
+
+		// The node keeps its own copy of the options, so the subscription has to be configured beforehand.
+		opts.BeaconSubscription.Enabled = true
+		opts.BeaconSubscription.Topics = sbeacon.EventTopics{
+			"finalized_checkpoint",
 		}
 
-		opts := *sbeacon.DefaultOptions()
+		nodes[i] = newNode(log, config, namespace, httpClient, opts)
+	}
 
-		opts.HealthCheck.Interval.Duration = time.Second * 5
-		opts.HealthCheck.SuccessfulResponses = 2
+	return nodes
+}
 
-		if customPreset {
-			opts.GoEth2ClientParams = append(opts.GoEth2ClientParams, ehttp.WithCustomSpecSupport(true))
-		}
+// nodeOptions returns the beacon client options for the node in config.
+func nodeOptions(config node.Config, httpClient *http.Client, customPreset bool) sbeacon.Options {
+	opts := *sbeacon.DefaultOptions()
 
-		snode := sbeacon.NewNode(log.WithField("upstream", config.Name), sconfig, namespace, opts)
+	opts.HealthCheck.Interval.Duration = time.Second * 5
+	opts.HealthCheck.SuccessfulResponses = 2
 
-		snode.Options().BeaconSubscription.Enabled = true
+	// The transport applies the per-request timeouts; the client's own timeout only needs to not cut them short.
+	opts.GoEth2ClientParams = append(opts.GoEth2ClientParams,
+		ehttp.WithHTTPClient(httpClient),
+		ehttp.WithTimeout(config.MaxTimeout()),
+	)
 
-		opts.BeaconSubscription.Topics = sbeacon.EventTopics{
-			"finalized_checkpoint",
-		}
+	if customPreset {
+		opts.GoEth2ClientParams = append(opts.GoEth2ClientParams, ehttp.WithCustomSpecSupport(true))
+	}
 
-		nodes[i] = &Node{
-			Config: config,
-			Beacon: snode,
-		}
+	return opts
+}
+
+func newNode(log logrus.FieldLogger, config node.Config, namespace string, httpClient *http.Client, opts sbeacon.Options) *Node {
+	sconfig := &sbeacon.Config{
+		Name:    config.Name,
+		Addr:    strings.TrimRight(config.Address, "/"),
+		Headers: config.Headers,
 	}
 
-	return nodes
+	n := &Node{
+		Config: config,
+		Beacon: sbeacon.NewNode(log.WithField("upstream", config.Name), sconfig, namespace, opts),
+		HTTP:   httpClient,
+	}
+
+	n.Beacon.OnFinalityCheckpointUpdated(context.Background(), func(_ context.Context, event *sbeacon.FinalityCheckpointUpdated) error {
+		n.setFinality(event.Finality)
+
+		return nil
+	})
+
+	return n
+}
+
+// Finality returns the node's latest head finality.
+func (n *Node) Finality() (*v1.Finality, error) {
+	finality := n.finality.Load()
+	if finality == nil {
+		return nil, errors.New("finality not available")
+	}
+
+	return finality, nil
+}
+
+// FetchFinality fetches the node's head finality and records it. It's fetched through the node's client
+// rather than the beacon client, which mustn't be asked for it while it may be doing so itself.
+func (n *Node) FetchFinality(ctx context.Context) (*v1.Finality, error) {
+	provider, ok := n.Beacon.Service().(eth2client.FinalityProvider)
+	if !ok {
+		return nil, errors.New("client does not implement eth2client.FinalityProvider")
+	}
+
+	rsp, err := provider.Finality(ctx, &api.FinalityOpts{State: "head"})
+	if err != nil {
+		return nil, err
+	}
+
+	n.setFinality(rsp.Data)
+
+	return rsp.Data, nil
+}
+
+func (n *Node) setFinality(finality *v1.Finality) {
+	if finality == nil {
+		return
+	}
+
+	n.finality.Store(finality)
 }
 
 func (n Nodes) StartAll(ctx context.Context) error {
@@ -174,7 +247,7 @@ func (n Nodes) Filter(ctx context.Context, f func(*Node) bool) Nodes {
 
 func (n Nodes) PastFinalizedCheckpoint(ctx context.Context, checkpoint *v1.Finality) Nodes {
 	return n.Filter(ctx, func(node *Node) bool {
-		finality, err := node.Beacon.Finality()
+		finality, err := node.Finality()
 		if err != nil {
 			return false
 		}
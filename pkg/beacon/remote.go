@@ -0,0 +1,265 @@
+package beacon
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/attestantio/go-eth2-client/spec"
+	"github.com/attestantio/go-eth2-client/spec/deneb"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/ethpandaops/beacon/pkg/beacon/api/types"
+	"github.com/ethpandaops/checkpointz/pkg/beacon/fulu"
+	"github.com/ethpandaops/checkpointz/pkg/beacon/store/remote"
+	"github.com/sirupsen/logrus"
+)
+
+// remoteObject returns the object and what's recorded about it from remote storage, or nil data if remote
+// storage isn't enabled or doesn't hold it.
+func (d *Default) remoteObject(ctx context.Context, object remote.Object, logCtx logrus.FieldLogger) (remote.Info, []byte) {
+	if d.remote == nil {
+		return remote.Info{}, nil
+	}
+
+	info, data, err := d.remote.Get(ctx, object)
+	if err != nil {
+		if !errors.Is(err, remote.ErrNotFound) {
+			logCtx.WithError(err).Warnf("Failed to read %s from remote storage", object.Kind())
+		}
+
+		return remote.Info{}, nil
+	}
+
+	return info, data
+}
+
+// remoteStateWithRoot returns the beacon state with the given root from remote storage, along with the
+// store's name and the ETag of the object it was read from, if it holds it and the state has that root.
+// Otherwise the state is nil.
+func (d *Default) remoteStateWithRoot(ctx context.Context, stateRoot phase0.Root) (*spec.VersionedBeaconState, string, string) {
+	logCtx := d.log.WithField("state_root", fmt.Sprintf("%#x", stateRoot))
+
+	info, data := d.remoteObject(ctx, remote.State(stateRoot), logCtx)
+	if data == nil {
+		return nil, "", ""
+	}
+
+	logCtx = logCtx.WithField("store", d.remote.Name())
+
+	beaconState, err := d.sszEncoder.DecodeStateSSZ(ctx, info.Version, data)
+	if err != nil {
+		logCtx.WithError(err).Warn("Ignoring undecodable beacon state in remote storage")
+
+		return nil, "", ""
+	}
+
+	root, err := d.sszEncoder.GetStateRoot(ctx, beaconState)
+	if err != nil || root != stateRoot {
+		logCtx.WithField("root", fmt.Sprintf("%#x", root)).Warn("Ignoring beacon state in remote storage with an unexpected root")
+
+		return nil, "", ""
+	}
+
+	return beaconState, d.remote.Name(), info.ETag
+}
+
+// remoteBlockWithRoot returns the block with the given root from remote storage, along with the store's
+// name, if it holds it and the block has that root. Otherwise the block is nil.
+func (d *Default) remoteBlockWithRoot(ctx context.Context, blockRoot phase0.Root) (*spec.VersionedSignedBeaconBlock, string) {
+	logCtx := d.log.WithField("root", fmt.Sprintf("%#x", blockRoot))
+
+	info, data := d.remoteObject(ctx, remote.Block(blockRoot), logCtx)
+	if data == nil {
+		return nil, ""
+	}
+
+	logCtx = logCtx.WithField("store", d.remote.Name())
+
+	block, err := d.sszEncoder.DecodeBlockSSZ(ctx, info.Version, data)
+	if err != nil {
+		logCtx.WithError(err).Warn("Ignoring undecodable block in remote storage")
+
+		return nil, ""
+	}
+
+	root, err := d.sszEncoder.GetBlockRoot(block)
+	if err != nil || root != blockRoot {
+		logCtx.WithField("actual_root", fmt.Sprintf("%#x", root)).Warn("Ignoring block in remote storage with an unexpected root")
+
+		return nil, ""
+	}
+
+	return block, d.remote.Name()
+}
+
+// remoteBlobSidecars returns the blob sidecars of the block with the given root from remote storage, or nil.
+// They still have to be verified against the block.
+func (d *Default) remoteBlobSidecars(ctx context.Context, blockRoot phase0.Root) []*deneb.BlobSidecar {
+	logCtx := d.log.WithField("root", fmt.Sprintf("%#x", blockRoot))
+
+	_, data := d.remoteObject(ctx, remote.BlobSidecars(blockRoot), logCtx)
+	if data == nil {
+		return nil
+	}
+
+	sidecars, err := d.sszEncoder.DecodeBlobSidecarsSSZ(data)
+	if err != nil {
+		logCtx.WithError(err).WithField("store", d.remote.Name()).Warn("Ignoring undecodable blob sidecars in remote storage")
+
+		return nil
+	}
+
+	return sidecars
+}
+
+// remoteDataColumnSidecars returns the data column sidecars of the block with the given root from remote
+// storage, or nil. They still have to be verified against the block.
+func (d *Default) remoteDataColumnSidecars(ctx context.Context, blockRoot phase0.Root) []*fulu.DataColumnSidecar {
+	logCtx := d.log.WithField("root", fmt.Sprintf("%#x", blockRoot))
+
+	_, data := d.remoteObject(ctx, remote.DataColumnSidecars(blockRoot), logCtx)
+	if data == nil {
+		return nil
+	}
+
+	sidecars, err := d.sszEncoder.DecodeDataColumnSidecarsSSZ(data)
+	if err != nil {
+		logCtx.WithError(err).WithField("store", d.remote.Name()).Warn("Ignoring undecodable data column sidecars in remote storage")
+
+		return nil
+	}
+
+	return sidecars
+}
+
+// remoteDepositSnapshot returns the deposit snapshot for the given epoch from remote storage, or nil.
+func (d *Default) remoteDepositSnapshot(ctx context.Context, epoch phase0.Epoch) *types.DepositSnapshot {
+	logCtx := d.log.WithField("epoch", epoch)
+
+	_, data := d.remoteObject(ctx, remote.DepositSnapshot(epoch), logCtx)
+	if data == nil {
+		return nil
+	}
+
+	snapshot, err := d.sszEncoder.DecodeDepositSnapshotSSZ(data)
+	if err != nil {
+		logCtx.WithError(err).WithField("store", d.remote.Name()).Warn("Ignoring undecodable deposit snapshot in remote storage")
+
+		return nil
+	}
+
+	return snapshot
+}
+
+// share uploads the object to remote storage in the background, unless it's already there, so that other
+// replicas don't have to fetch it from their upstreams. encode is only called if the object is uploaded, and
+// uploaded, if not nil, is called with the uploaded object's ETag.
+func (d *Default) share(ctx context.Context, object remote.Object, version spec.DataVersion, encode func() ([]byte, error), uploaded func(etag string)) {
+	if d.remote == nil {
+		return
+	}
+
+	d.runLoop(ctx, "remote_upload", func(ctx context.Context) error {
+		logCtx := d.log.WithField("store", d.remote.Name()).WithField("kind", object.Kind())
+
+		exists, err := d.remote.Has(ctx, object)
+		if err != nil {
+			logCtx.WithError(err).Warn("Failed to check remote storage")
+
+			return nil
+		}
+
+		if exists {
+			return nil
+		}
+
+		data, err := encode()
+		if err != nil {
+			return fmt.Errorf("failed to encode %s: %w", object.Kind(), err)
+		}
+
+		etag, err := d.remote.Put(ctx, object, version, data)
+		if err != nil {
+			logCtx.WithError(err).Warn("Failed to upload to remote storage")
+
+			return nil
+		}
+
+		if uploaded != nil {
+			uploaded(etag)
+		}
+
+		logCtx.WithField("bytes", len(data)).Info("Uploaded to remote storage")
+
+		return nil
+	})
+}
+
+// shareState shares the beacon state with the given root through remote storage.
+func (d *Default) shareState(ctx context.Context, stateRoot phase0.Root, beaconState *spec.VersionedBeaconState) {
+	d.share(ctx, remote.State(stateRoot), beaconState.Version, func() ([]byte, error) {
+		return d.sszEncoder.EncodeStateSSZ(ctx, beaconState)
+	}, func(etag string) {
+		d.recordStateETag(stateRoot, etag)
+	})
+}
+
+// recordStateETag records the ETag of the copy of the state in remote storage, which is known to have the
+// state's root, so that downloads can be redirected to it.
+func (d *Default) recordStateETag(stateRoot phase0.Root, etag string) {
+	provenance, err := d.states.GetProvenance(stateRoot)
+	if err != nil {
+		return
+	}
+
+	provenance.RemoteETag = etag
+
+	if err := d.states.SetProvenance(stateRoot, provenance); err != nil {
+		d.log.WithError(err).Debug("Failed to record remote storage ETag of beacon state")
+	}
+}
+
+// GetBeaconStateURL returns the URL downloads of the beacon state with the given state root should be
+// redirected to, or an empty string if we should serve it ourselves. Only states we hold are redirected, and
+// only to the copy in remote storage that was checked against the root.
+func (d *Default) GetBeaconStateURL(ctx context.Context, stateRoot phase0.Root) (string, error) {
+	if d.remote == nil {
+		return "", nil
+	}
+
+	provenance, err := d.states.GetProvenance(stateRoot)
+	if err != nil {
+		return "", err
+	}
+
+	return d.remote.StateURL(ctx, stateRoot, provenance.RemoteETag)
+}
+
+// shareBlock shares the block with the given root through remote storage.
+func (d *Default) shareBlock(ctx context.Context, blockRoot phase0.Root, block *spec.VersionedSignedBeaconBlock) {
+	d.share(ctx, remote.Block(blockRoot), block.Version, func() ([]byte, error) {
+		return d.sszEncoder.EncodeBlockSSZ(ctx, block)
+	}, nil)
+}
+
+// shareBlobSidecars shares the blob sidecars of the block with the given root through remote storage.
+func (d *Default) shareBlobSidecars(ctx context.Context, blockRoot phase0.Root, sidecars []*deneb.BlobSidecar) {
+	d.share(ctx, remote.BlobSidecars(blockRoot), spec.DataVersionUnknown, func() ([]byte, error) {
+		return d.sszEncoder.EncodeBlobSidecarsSSZ(sidecars)
+	}, nil)
+}
+
+// shareDataColumnSidecars shares the data column sidecars of the block with the given root through remote
+// storage.
+func (d *Default) shareDataColumnSidecars(ctx context.Context, blockRoot phase0.Root, sidecars []*fulu.DataColumnSidecar) {
+	d.share(ctx, remote.DataColumnSidecars(blockRoot), spec.DataVersionUnknown, func() ([]byte, error) {
+		return d.sszEncoder.EncodeDataColumnSidecarsSSZ(sidecars)
+	}, nil)
+}
+
+// shareDepositSnapshot shares the deposit snapshot for the given epoch through remote storage.
+func (d *Default) shareDepositSnapshot(ctx context.Context, epoch phase0.Epoch, snapshot *types.DepositSnapshot) {
+	d.share(ctx, remote.DepositSnapshot(epoch), spec.DataVersionUnknown, func() ([]byte, error) {
+		return d.sszEncoder.EncodeDepositSnapshotSSZ(snapshot)
+	}, nil)
+}
@@ -0,0 +1,224 @@
+package ssz
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/attestantio/go-eth2-client/spec"
+	"github.com/attestantio/go-eth2-client/spec/altair"
+	"github.com/attestantio/go-eth2-client/spec/bellatrix"
+	"github.com/attestantio/go-eth2-client/spec/capella"
+	"github.com/attestantio/go-eth2-client/spec/deneb"
+	"github.com/attestantio/go-eth2-client/spec/electra"
+	ethfulu "github.com/attestantio/go-eth2-client/spec/fulu"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/ethpandaops/beacon/pkg/beacon/api/types"
+	"github.com/ethpandaops/checkpointz/pkg/beacon/fulu"
+	"github.com/pk910/dynamic-ssz/sszutils"
+)
+
+// DecodeBlockSSZ decodes a signed beacon block of the given fork.
+func (e *Encoder) DecodeBlockSSZ(ctx context.Context, version spec.DataVersion, data []byte) (_ *spec.VersionedSignedBeaconBlock, err error) {
+	span := startSpan(ctx, "DecodeBlockSSZ", version)
+	defer func() { endSpan(span, len(data), err) }()
+
+	block := &spec.VersionedSignedBeaconBlock{Version: version}
+
+	var blockObj sszutils.FastsszUnmarshaler
+
+	switch version {
+	case spec.DataVersionPhase0:
+		block.Phase0 = &phase0.SignedBeaconBlock{}
+		blockObj = block.Phase0
+	case spec.DataVersionAltair:
+		block.Altair = &altair.SignedBeaconBlock{}
+		blockObj = block.Altair
+	case spec.DataVersionBellatrix:
+		block.Bellatrix = &bellatrix.SignedBeaconBlock{}
+		blockObj = block.Bellatrix
+	case spec.DataVersionCapella:
+		block.Capella = &capella.SignedBeaconBlock{}
+		blockObj = block.Capella
+	case spec.DataVersionDeneb:
+		block.Deneb = &deneb.SignedBeaconBlock{}
+		blockObj = block.Deneb
+	case spec.DataVersionElectra:
+		block.Electra = &electra.SignedBeaconBlock{}
+		blockObj = block.Electra
+	case spec.DataVersionFulu:
+		block.Fulu = &electra.SignedBeaconBlock{}
+		blockObj = block.Fulu
+	default:
+		return nil, errors.New("unknown block version")
+	}
+
+	if err := e.unmarshal(blockObj, data); err != nil {
+		return nil, fmt.Errorf("failed to decode %s block: %w", version, err)
+	}
+
+	return block, nil
+}
+
+// DecodeStateSSZ decodes a beacon state of the given fork.
+func (e *Encoder) DecodeStateSSZ(ctx context.Context, version spec.DataVersion, data []byte) (_ *spec.VersionedBeaconState, err error) {
+	span := startSpan(ctx, "DecodeStateSSZ", version)
+	defer func() { endSpan(span, len(data), err) }()
+
+	beaconState := &spec.VersionedBeaconState{Version: version}
+
+	var stateObj sszutils.FastsszUnmarshaler
+
+	switch version {
+	case spec.DataVersionPhase0:
+		beaconState.Phase0 = &phase0.BeaconState{}
+		stateObj = beaconState.Phase0
+	case spec.DataVersionAltair:
+		beaconState.Altair = &altair.BeaconState{}
+		stateObj = beaconState.Altair
+	case spec.DataVersionBellatrix:
+		beaconState.Bellatrix = &bellatrix.BeaconState{}
+		stateObj = beaconState.Bellatrix
+	case spec.DataVersionCapella:
+		beaconState.Capella = &capella.BeaconState{}
+		stateObj = beaconState.Capella
+	case spec.DataVersionDeneb:
+		beaconState.Deneb = &deneb.BeaconState{}
+		stateObj = beaconState.Deneb
+	case spec.DataVersionElectra:
+		beaconState.Electra = &electra.BeaconState{}
+		stateObj = beaconState.Electra
+	case spec.DataVersionFulu:
+		beaconState.Fulu = &ethfulu.BeaconState{}
+		stateObj = beaconState.Fulu
+	default:
+		return nil, errors.New("unknown state version")
+	}
+
+	if err := e.unmarshal(stateObj, data); err != nil {
+		return nil, fmt.Errorf("failed to decode %s state: %w", version, err)
+	}
+
+	return beaconState, nil
+}
+
+// DecodeBlobSidecarsSSZ decodes an SSZ List[BlobSidecar, MAX_BLOB_COMMITMENTS_PER_BLOCK], as encoded by
+// EncodeBlobSidecarsSSZ.
+func (e *Encoder) DecodeBlobSidecarsSSZ(data []byte) ([]*deneb.BlobSidecar, error) {
+	size, err := e.blobSidecarSize()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(data)%size != 0 {
+		return nil, fmt.Errorf("blob sidecars length %d is not a multiple of %d", len(data), size)
+	}
+
+	sidecars := make([]*deneb.BlobSidecar, 0, len(data)/size)
+
+	for offset := 0; offset < len(data); offset += size {
+		sidecar := &deneb.BlobSidecar{}
+
+		if err := e.unmarshal(sidecar, data[offset:offset+size]); err != nil {
+			return nil, fmt.Errorf("failed to decode blob sidecar %d: %w", len(sidecars), err)
+		}
+
+		sidecars = append(sidecars, sidecar)
+	}
+
+	return sidecars, nil
+}
+
+// DecodeDepositSnapshotSSZ decodes an EIP-4881 DepositTreeSnapshot, as encoded by EncodeDepositSnapshotSSZ.
+func (e *Encoder) DecodeDepositSnapshotSSZ(data []byte) (*types.DepositSnapshot, error) {
+	// finalized (offset) + deposit_root + deposit_count + execution_block_hash + execution_block_height
+	const fixedSize = 4 + 32 + 8 + 32 + 8
+
+	if len(data) < fixedSize {
+		return nil, fmt.Errorf("deposit snapshot is too short: %d bytes", len(data))
+	}
+
+	if offset := binary.LittleEndian.Uint32(data); offset != fixedSize {
+		return nil, fmt.Errorf("invalid deposit snapshot finalized offset: %d", offset)
+	}
+
+	finalized := data[fixedSize:]
+	if len(finalized)%32 != 0 || len(finalized)/32 > depositContractTreeDepth {
+		return nil, fmt.Errorf("invalid deposit snapshot finalized roots length: %d", len(finalized))
+	}
+
+	snapshot := &types.DepositSnapshot{
+		Finalized:            make([]phase0.Root, len(finalized)/32),
+		DepositCount:         binary.LittleEndian.Uint64(data[36:44]),
+		ExecutionBlockHeight: binary.LittleEndian.Uint64(data[76:84]),
+	}
+
+	copy(snapshot.DepositRoot[:], data[4:36])
+	copy(snapshot.ExecutionBlockHash[:], data[44:76])
+
+	for i := range snapshot.Finalized {
+		copy(snapshot.Finalized[i][:], finalized[i*32:])
+	}
+
+	return snapshot, nil
+}
+
+// DecodeDataColumnSidecarsSSZ decodes an SSZ List[DataColumnSidecar, NUMBER_OF_COLUMNS], as encoded by
+// EncodeDataColumnSidecarsSSZ.
+func (e *Encoder) DecodeDataColumnSidecarsSSZ(data []byte) ([]*fulu.DataColumnSidecar, error) {
+	if len(data) == 0 {
+		return []*fulu.DataColumnSidecar{}, nil
+	}
+
+	if len(data) < 4 {
+		return nil, fmt.Errorf("data column sidecars are too short: %d bytes", len(data))
+	}
+
+	first := binary.LittleEndian.Uint32(data)
+	if first%4 != 0 || first == 0 || int(first) > len(data) {
+		return nil, fmt.Errorf("invalid data column sidecars offset: %d", first)
+	}
+
+	offsets := make([]int, 0, first/4+1)
+	for i := 0; i < int(first); i += 4 {
+		offsets = append(offsets, int(binary.LittleEndian.Uint32(data[i:])))
+	}
+
+	offsets = append(offsets, len(data))
+
+	sidecars := make([]*fulu.DataColumnSidecar, 0, len(offsets)-1)
+
+	for i := 0; i < len(offsets)-1; i++ {
+		start, end := offsets[i], offsets[i+1]
+		if start > end || end > len(data) {
+			return nil, fmt.Errorf("invalid data column sidecar %d offsets: %d-%d", i, start, end)
+		}
+
+		// There are no generated SSZ methods for data column sidecars, so always use dynamic SSZ.
+		sidecar := &fulu.DataColumnSidecar{}
+		if err := e.getDynamicSSZ().UnmarshalSSZ(sidecar, data[start:end]); err != nil {
+			return nil, fmt.Errorf("failed to decode data column sidecar %d: %w", i, err)
+		}
+
+		sidecars = append(sidecars, sidecar)
+	}
+
+	return sidecars, nil
+}
+
+func (e *Encoder) blobSidecarSize() (int, error) {
+	if e.customPreset {
+		return e.getDynamicSSZ().SizeSSZ(&deneb.BlobSidecar{})
+	}
+
+	return (&deneb.BlobSidecar{}).SizeSSZ(), nil
+}
+
+func (e *Encoder) unmarshal(obj sszutils.FastsszUnmarshaler, data []byte) error {
+	if e.customPreset {
+		return e.getDynamicSSZ().UnmarshalSSZ(obj, data)
+	}
+
+	return obj.UnmarshalSSZ(data)
+}
@@ -1,18 +1,29 @@
 package ssz
 
 import (
+	"context"
+	"encoding/binary"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"sync"
 
+	v1 "github.com/attestantio/go-eth2-client/api/v1"
 	"github.com/attestantio/go-eth2-client/spec"
+	"github.com/attestantio/go-eth2-client/spec/deneb"
 	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/ethpandaops/beacon/pkg/beacon/api/types"
 	"github.com/ethpandaops/beacon/pkg/beacon/state"
+	"github.com/ethpandaops/checkpointz/pkg/beacon/fulu"
+	"github.com/ethpandaops/checkpointz/pkg/tracing"
 
 	dynssz "github.com/pk910/dynamic-ssz"
 	"github.com/pk910/dynamic-ssz/sszutils"
 )
 
+// depositContractTreeDepth is DEPOSIT_CONTRACT_TREE_DEPTH, the maximum length of a deposit snapshot's finalized roots.
+const depositContractTreeDepth = 32
+
 type Encoder struct {
 	customPreset bool
 	dynssz       *dynssz.DynSsz
@@ -80,7 +91,10 @@ func (e *Encoder) GetBlockRoot(block *spec.VersionedSignedBeaconBlock) (root pha
 	return root, nil
 }
 
-func (e *Encoder) EncodeBlockSSZ(block *spec.VersionedSignedBeaconBlock) (ssz []byte, err error) {
+func (e *Encoder) EncodeBlockSSZ(ctx context.Context, block *spec.VersionedSignedBeaconBlock) (ssz []byte, err error) {
+	span := startSpan(ctx, "EncodeBlockSSZ", block.Version)
+	defer func() { endSpan(span, len(ssz), err) }()
+
 	var blockObj sszutils.FastsszMarshaler
 
 	switch block.Version {
@@ -115,7 +129,10 @@ func (e *Encoder) EncodeBlockSSZ(block *spec.VersionedSignedBeaconBlock) (ssz []
 	return ssz, nil
 }
 
-func (e *Encoder) EncodeBlockJSON(block *spec.VersionedSignedBeaconBlock) ([]byte, error) {
+func (e *Encoder) EncodeBlockJSON(ctx context.Context, block *spec.VersionedSignedBeaconBlock) (data []byte, err error) {
+	span := startSpan(ctx, "EncodeBlockJSON", block.Version)
+	defer func() { endSpan(span, len(data), err) }()
+
 	var blockObj json.Marshaler
 
 	switch block.Version {
@@ -137,15 +154,13 @@ func (e *Encoder) EncodeBlockJSON(block *spec.VersionedSignedBeaconBlock) ([]byt
 		return nil, errors.New("unknown block version")
 	}
 
-	ssz, err := blockObj.MarshalJSON()
-	if err != nil {
-		return nil, err
-	}
-
-	return ssz, nil
+	return blockObj.MarshalJSON()
 }
 
-func (e *Encoder) GetStateRoot(beaconState *spec.VersionedBeaconState) (root phase0.Root, err error) {
+func (e *Encoder) GetStateRoot(ctx context.Context, beaconState *spec.VersionedBeaconState) (root phase0.Root, err error) {
+	span := startSpan(ctx, "GetStateRoot", beaconState.Version)
+	defer func() { tracing.End(span, err) }()
+
 	var stateObj sszutils.FastsszHashRoot
 
 	switch beaconState.Version {
@@ -179,7 +194,11 @@ func (e *Encoder) GetStateRoot(beaconState *spec.VersionedBeaconState) (root pha
 
 	return root, nil
 }
-func (e *Encoder) EncodeStateSSZ(beaconState *spec.VersionedBeaconState) (ssz []byte, err error) {
+
+func (e *Encoder) EncodeStateSSZ(ctx context.Context, beaconState *spec.VersionedBeaconState) (ssz []byte, err error) {
+	span := startSpan(ctx, "EncodeStateSSZ", beaconState.Version)
+	defer func() { endSpan(span, len(ssz), err) }()
+
 	var stateObj sszutils.FastsszMarshaler
 
 	switch beaconState.Version {
@@ -213,3 +232,126 @@ func (e *Encoder) EncodeStateSSZ(beaconState *spec.VersionedBeaconState) (ssz []
 
 	return ssz, nil
 }
+
+func (e *Encoder) EncodeStateJSON(ctx context.Context, beaconState *spec.VersionedBeaconState) (data []byte, err error) {
+	span := startSpan(ctx, "EncodeStateJSON", beaconState.Version)
+	defer func() { endSpan(span, len(data), err) }()
+
+	var stateObj json.Marshaler
+
+	switch beaconState.Version {
+	case spec.DataVersionPhase0:
+		stateObj = beaconState.Phase0
+	case spec.DataVersionAltair:
+		stateObj = beaconState.Altair
+	case spec.DataVersionBellatrix:
+		stateObj = beaconState.Bellatrix
+	case spec.DataVersionCapella:
+		stateObj = beaconState.Capella
+	case spec.DataVersionDeneb:
+		stateObj = beaconState.Deneb
+	case spec.DataVersionElectra:
+		stateObj = beaconState.Electra
+	case spec.DataVersionFulu:
+		stateObj = beaconState.Fulu
+	default:
+		return nil, errors.New("unknown state version")
+	}
+
+	return stateObj.MarshalJSON()
+}
+
+// EncodeBlobSidecarsSSZ encodes the sidecars as an SSZ List[BlobSidecar, MAX_BLOB_COMMITMENTS_PER_BLOCK].
+// BlobSidecar is a fixed-size container, so the list is the concatenation of the encoded sidecars.
+func (e *Encoder) EncodeBlobSidecarsSSZ(sidecars []*deneb.BlobSidecar) ([]byte, error) {
+	ssz := []byte{}
+
+	for _, sidecar := range sidecars {
+		var (
+			encoded []byte
+			err     error
+		)
+
+		if e.customPreset {
+			encoded, err = e.getDynamicSSZ().MarshalSSZ(sidecar)
+		} else {
+			encoded, err = sidecar.MarshalSSZ()
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		ssz = append(ssz, encoded...)
+	}
+
+	return ssz, nil
+}
+
+// EncodeDepositSnapshotSSZ encodes an EIP-4881 DepositTreeSnapshot.
+func (e *Encoder) EncodeDepositSnapshotSSZ(snapshot *types.DepositSnapshot) ([]byte, error) {
+	if snapshot == nil {
+		return nil, errors.New("deposit snapshot is nil")
+	}
+
+	if len(snapshot.Finalized) > depositContractTreeDepth {
+		return nil, fmt.Errorf("deposit snapshot has too many finalized roots: %d", len(snapshot.Finalized))
+	}
+
+	// finalized (offset) + deposit_root + deposit_count + execution_block_hash + execution_block_height
+	const fixedSize = 4 + 32 + 8 + 32 + 8
+
+	ssz := make([]byte, 0, fixedSize+len(snapshot.Finalized)*32)
+
+	ssz = binary.LittleEndian.AppendUint32(ssz, fixedSize)
+	ssz = append(ssz, snapshot.DepositRoot[:]...)
+	ssz = binary.LittleEndian.AppendUint64(ssz, snapshot.DepositCount)
+	ssz = append(ssz, snapshot.ExecutionBlockHash[:]...)
+	ssz = binary.LittleEndian.AppendUint64(ssz, snapshot.ExecutionBlockHeight)
+
+	for _, root := range snapshot.Finalized {
+		ssz = append(ssz, root[:]...)
+	}
+
+	return ssz, nil
+}
+
+// EncodeDataColumnSidecarsSSZ encodes the sidecars as an SSZ List[DataColumnSidecar, NUMBER_OF_COLUMNS].
+// DataColumnSidecar is variable-size, so each element is preceded by an offset.
+func (e *Encoder) EncodeDataColumnSidecarsSSZ(sidecars []*fulu.DataColumnSidecar) ([]byte, error) {
+	encoded := make([][]byte, 0, len(sidecars))
+	size := 4 * len(sidecars)
+
+	for _, sidecar := range sidecars {
+		// There are no generated SSZ methods for data column sidecars, so always use dynamic SSZ.
+		data, err := e.getDynamicSSZ().MarshalSSZ(sidecar)
+		if err != nil {
+			return nil, err
+		}
+
+		encoded = append(encoded, data)
+		size += len(data)
+	}
+
+	ssz := make([]byte, 0, size)
+	offset := 4 * len(sidecars)
+
+	for _, data := range encoded {
+		ssz = binary.LittleEndian.AppendUint32(ssz, uint32(offset)) //nolint:gosec // bounded by NUMBER_OF_COLUMNS sidecars
+		offset += len(data)
+	}
+
+	for _, data := range encoded {
+		ssz = append(ssz, data...)
+	}
+
+	return ssz, nil
+}
+
+func (e *Encoder) EncodeBlobsSSZ(blobs v1.Blobs) ([]byte, error) {
+	if e.customPreset {
+		return e.getDynamicSSZ().MarshalSSZ(&blobs)
+	}
+
+	return blobs.MarshalSSZ()
+}
@@ -0,0 +1,24 @@
+package ssz
+
+import (
+	"context"
+
+	"github.com/attestantio/go-eth2-client/spec"
+	"github.com/ethpandaops/checkpointz/pkg/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// startSpan starts a span for operation on an object of the given fork.
+func startSpan(ctx context.Context, operation string, version spec.DataVersion) trace.Span {
+	_, span := tracing.Start(ctx, "ssz."+operation, trace.WithAttributes(attribute.String("eth.version", version.String())))
+
+	return span
+}
+
+// endSpan ends a span started by startSpan, recording the size of the encoded data.
+func endSpan(span trace.Span, size int, err error) {
+	span.SetAttributes(attribute.Int("ssz.size", size))
+
+	tracing.End(span, err)
+}
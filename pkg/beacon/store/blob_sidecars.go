@@ -1,38 +1,40 @@
 package store
 
 import (
-	"errors"
 	"time"
 
 	"github.com/attestantio/go-eth2-client/spec/deneb"
 	"github.com/attestantio/go-eth2-client/spec/phase0"
 	"github.com/ethpandaops/checkpointz/pkg/cache"
+	"github.com/ethpandaops/checkpointz/pkg/clock"
 	"github.com/ethpandaops/checkpointz/pkg/eth"
 	"github.com/sirupsen/logrus"
 )
 
 type BlobSidecar struct {
-	store *cache.TTLMap
+	store *Indexed[phase0.Slot, []*deneb.BlobSidecar]
 	log   logrus.FieldLogger
 }
 
-func NewBlobSidecar(log logrus.FieldLogger, config Config, namespace string) *BlobSidecar {
+func NewBlobSidecar(log logrus.FieldLogger, config Config, namespace string, budget *cache.Budget, clk clock.Clock) *BlobSidecar {
 	d := &BlobSidecar{
-		log:   log.WithField("component", "beacon/store/blob_sidecar"),
-		store: cache.NewTTLMap(config.MaxItems, "blob_sidecar", namespace),
+		log: log.WithField("component", "beacon/store/blob_sidecar"),
 	}
 
-	d.store.OnItemDeleted(func(key string, value interface{}, expiredAt time.Time) {
+	d.store = NewIndexed[phase0.Slot, []*deneb.BlobSidecar](d.log, config, "blob_sidecar", namespace, budget, clk, eth.SlotAsString)
+
+	d.store.OnItemDeleted(func(key string, _ []*deneb.BlobSidecar, expiredAt time.Time) {
 		d.log.WithField("key", key).WithField("expired_at", expiredAt.String()).Debug("Blob sidecar was deleted from the cache")
 	})
 
-	d.store.EnableMetrics(namespace)
-
 	return d
 }
 
 func (d *BlobSidecar) Add(slot phase0.Slot, sidecars []*deneb.BlobSidecar, expiresAt time.Time) error {
-	d.store.Add(eth.SlotAsString(slot), sidecars, expiresAt, false)
+	// Sidecars are large and can be re-fetched, so they make way for blocks and states first.
+	if err := d.store.Add(slot, sidecars, expiresAt, blobSidecarsSize(sidecars), cache.PriorityLow); err != nil {
+		return err
+	}
 
 	d.log.WithFields(
 		logrus.Fields{
@@ -45,19 +47,20 @@ func (d *BlobSidecar) Add(slot phase0.Slot, sidecars []*deneb.BlobSidecar, expir
 }
 
 func (d *BlobSidecar) GetBySlot(slot phase0.Slot) ([]*deneb.BlobSidecar, error) {
-	data, _, err := d.store.Get(eth.SlotAsString(slot))
-	if err != nil {
-		return nil, err
-	}
+	return d.store.Get(slot)
+}
 
-	return d.parseSidecar(data)
+// Pin prevents the sidecars for the given slot from expiring or being evicted.
+func (d *BlobSidecar) Pin(slot phase0.Slot) error {
+	return d.store.SetPriority(slot, cache.PriorityPinned)
 }
 
-func (d *BlobSidecar) parseSidecar(data interface{}) ([]*deneb.BlobSidecar, error) {
-	sidecar, ok := data.([]*deneb.BlobSidecar)
-	if !ok {
-		return nil, errors.New("invalid blob sidecar type")
-	}
+// Unpin allows the sidecars for the given slot to expire or be evicted again.
+func (d *BlobSidecar) Unpin(slot phase0.Slot) error {
+	return d.store.SetPriority(slot, cache.PriorityLow)
+}
 
-	return sidecar, nil
+// Stop stops the blob sidecar store's background expiry.
+func (c *BlobSidecar) Stop() {
+	c.store.Stop()
 }
@@ -6,6 +6,7 @@ import (
 
 	"github.com/attestantio/go-eth2-client/spec/deneb"
 	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/ethpandaops/checkpointz/pkg/clock"
 	"github.com/sirupsen/logrus/hooks/test"
 	"github.com/stretchr/testify/assert"
 )
@@ -14,7 +15,7 @@ func TestBlobSidecarAddAndGet(t *testing.T) {
 	logger, _ := test.NewNullLogger()
 	config := Config{MaxItems: 10}
 	namespace := "test_a"
-	blobSidecarStore := NewBlobSidecar(logger, config, namespace)
+	blobSidecarStore := NewBlobSidecar(logger, config, namespace, nil, clock.New())
 
 	slot := phase0.Slot(100)
 	expiresAt := time.Now().Add(10 * time.Minute)
@@ -37,7 +38,7 @@ func TestBlobSidecarGetBySlotNotFound(t *testing.T) {
 	logger, _ := test.NewNullLogger()
 	config := Config{MaxItems: 10}
 	namespace := "test_b"
-	blobSidecarStore := NewBlobSidecar(logger, config, namespace)
+	blobSidecarStore := NewBlobSidecar(logger, config, namespace, nil, clock.New())
 
 	slot := phase0.Slot(200)
 
@@ -7,44 +7,66 @@ import (
 
 	"github.com/attestantio/go-eth2-client/spec"
 	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/ethpandaops/checkpointz/pkg/beacon/verify"
 	"github.com/ethpandaops/checkpointz/pkg/cache"
+	"github.com/ethpandaops/checkpointz/pkg/clock"
 	"github.com/ethpandaops/checkpointz/pkg/eth"
 	"github.com/sirupsen/logrus"
 )
 
+const (
+	blockIndexSlot      = "slot"
+	blockIndexStateRoot = "state_root"
+)
+
 type Block struct {
 	log   logrus.FieldLogger
-	store *cache.TTLMap
+	store *Indexed[phase0.Root, *blockEntry]
+}
+
+// blockEntry is a stored block alongside how its signature was verified and where it came from.
+type blockEntry struct {
+	block *spec.VersionedSignedBeaconBlock
 
-	slotToBlockRoot      sync.Map
-	stateRootToBlockRoot sync.Map
+	mu              sync.RWMutex
+	signatureStatus verify.Status
+	provenance      *Provenance
 }
 
-func NewBlock(log logrus.FieldLogger, config Config, namespace string) *Block {
+func NewBlock(log logrus.FieldLogger, config Config, namespace string, budget *cache.Budget, clk clock.Clock) *Block {
 	c := &Block{
-		log:   log.WithField("component", "beacon/store/block"),
-		store: cache.NewTTLMap(config.MaxItems, "block", namespace),
-
-		slotToBlockRoot:      sync.Map{},
-		stateRootToBlockRoot: sync.Map{},
+		log: log.WithField("component", "beacon/store/block"),
 	}
 
-	c.store.OnItemDeleted(func(key string, value interface{}, expiredAt time.Time) {
-		c.log.WithField("block_root", key).WithField("expired_at", expiredAt.String()).Debug("Block was evicted from the cache")
-
-		block, ok := value.(*spec.VersionedSignedBeaconBlock)
-		if !ok {
-			c.log.WithField("block_root", key).Error("Invalid block type when cleaning up block cache")
-			return
-		}
+	c.store = NewIndexed(c.log, config, "block", namespace, budget, clk, eth.RootAsString,
+		Index[*blockEntry]{
+			Name: blockIndexSlot,
+			Key: func(entry *blockEntry) (string, error) {
+				slot, err := entry.block.Slot()
+				if err != nil {
+					return "", err
+				}
+
+				return eth.SlotAsString(slot), nil
+			},
+		},
+		Index[*blockEntry]{
+			Name: blockIndexStateRoot,
+			Key: func(entry *blockEntry) (string, error) {
+				stateRoot, err := entry.block.StateRoot()
+				if err != nil {
+					return "", err
+				}
+
+				return eth.RootAsString(stateRoot), nil
+			},
+		},
+	)
 
-		if err := c.cleanupBlock(block); err != nil {
-			c.log.WithError(err).Error("Failed to cleanup block")
-		}
+	c.store.OnItemDeleted(func(key string, _ *blockEntry, expiredAt time.Time) {
+		c.log.WithField("block_root", key).WithField("expired_at", expiredAt.String()).Debug("Block was evicted from the cache")
 	})
 
-	c.store.EnableMetrics(namespace)
-
 	return c
 }
 
@@ -59,12 +81,14 @@ func (c *Block) Add(root phase0.Root, block *spec.VersionedSignedBeaconBlock, ex
 		return err
 	}
 
-	invincible := slot == 0 // Store the genesis block forever.
-
-	c.store.Add(eth.RootAsString(root), block, expiresAt, invincible)
+	priority := cache.PriorityNormal
+	if slot == 0 {
+		priority = cache.PriorityPinned // Store the genesis block forever.
+	}
 
-	c.slotToBlockRoot.Store(slot, root)
-	c.stateRootToBlockRoot.Store(stateRoot, root)
+	if err := c.store.Add(root, &blockEntry{block: block}, expiresAt, blockSize(block), priority); err != nil {
+		return err
+	}
 
 	c.log.WithFields(
 		logrus.Fields{
@@ -78,74 +102,113 @@ func (c *Block) Add(root phase0.Root, block *spec.VersionedSignedBeaconBlock, ex
 	return nil
 }
 
-func (c *Block) cleanupBlock(block *spec.VersionedSignedBeaconBlock) error {
-	slot, err := block.Slot()
+func (c *Block) GetByRoot(root phase0.Root) (*spec.VersionedSignedBeaconBlock, error) {
+	entry, err := c.store.Get(root)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	stateRoot, err := block.StateRoot()
+	return entry.block, nil
+}
+
+func (c *Block) GetByStateRoot(stateRoot phase0.Root) (*spec.VersionedSignedBeaconBlock, error) {
+	entry, err := c.store.GetBy(blockIndexStateRoot, eth.RootAsString(stateRoot))
 	if err != nil {
-		return err
+		return nil, errors.New("block not found")
 	}
 
-	c.slotToBlockRoot.Delete(eth.SlotAsString(slot))
-	c.stateRootToBlockRoot.Delete(eth.RootAsString(stateRoot))
-
-	return nil
+	return entry.block, nil
 }
 
-func (c *Block) GetByRoot(root phase0.Root) (*spec.VersionedSignedBeaconBlock, error) {
-	data, _, err := c.store.Get(eth.RootAsString(root))
+func (c *Block) GetBySlot(slot phase0.Slot) (*spec.VersionedSignedBeaconBlock, error) {
+	entry, err := c.store.GetBy(blockIndexSlot, eth.SlotAsString(slot))
 	if err != nil {
-		return nil, err
+		return nil, errors.New("block not found")
 	}
 
-	return c.parseBlock(data)
+	return entry.block, nil
 }
 
-func (c *Block) GetByStateRoot(stateRoot phase0.Root) (*spec.VersionedSignedBeaconBlock, error) {
-	data, ok := c.stateRootToBlockRoot.Load(stateRoot)
-	if !ok {
-		return nil, errors.New("block not found")
-	}
+// Pin prevents the block with the given root from expiring or being evicted.
+func (c *Block) Pin(root phase0.Root) error {
+	return c.store.SetPriority(root, cache.PriorityPinned)
+}
+
+// Unpin allows the block with the given root to expire or be evicted again.
+func (c *Block) Unpin(root phase0.Root) error {
+	return c.store.SetPriority(root, cache.PriorityNormal)
+}
+
+// Delete removes the block with the given root from the store.
+func (c *Block) Delete(root phase0.Root) {
+	c.store.Delete(root)
+}
 
-	root, err := c.parseRoot(data)
+// SetSignatureStatus records how the signature of the block with the given root was verified.
+func (c *Block) SetSignatureStatus(root phase0.Root, status verify.Status) error {
+	entry, err := c.store.Get(root)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	return c.GetByRoot(root)
+	entry.mu.Lock()
+	defer entry.mu.Unlock()
+
+	entry.signatureStatus = status
+
+	return nil
 }
 
-func (c *Block) GetBySlot(slot phase0.Slot) (*spec.VersionedSignedBeaconBlock, error) {
-	data, ok := c.slotToBlockRoot.Load(slot)
-	if !ok {
-		return nil, errors.New("block not found")
+// GetSignatureStatus returns how the signature of the block with the given root was verified.
+func (c *Block) GetSignatureStatus(root phase0.Root) (verify.Status, error) {
+	entry, err := c.store.Get(root)
+	if err != nil {
+		return "", err
 	}
 
-	root, err := c.parseRoot(data)
-	if err != nil {
-		return nil, err
+	entry.mu.RLock()
+	defer entry.mu.RUnlock()
+
+	if entry.signatureStatus == "" {
+		return "", errors.New("signature status not found")
 	}
 
-	return c.GetByRoot(root)
+	return entry.signatureStatus, nil
 }
 
-func (c *Block) parseBlock(data interface{}) (*spec.VersionedSignedBeaconBlock, error) {
-	block, ok := data.(*spec.VersionedSignedBeaconBlock)
-	if !ok {
-		return nil, errors.New("invalid block type")
+// SetProvenance records where the block with the given root came from.
+func (c *Block) SetProvenance(root phase0.Root, provenance *Provenance) error {
+	entry, err := c.store.Get(root)
+	if err != nil {
+		return err
 	}
 
-	return block, nil
+	entry.mu.Lock()
+	defer entry.mu.Unlock()
+
+	entry.provenance = provenance.Copy()
+
+	return nil
 }
 
-func (c *Block) parseRoot(data interface{}) (phase0.Root, error) {
-	root, ok := data.(phase0.Root)
-	if !ok {
-		return phase0.Root{}, errors.New("invalid root")
+// GetProvenance returns where the block with the given root came from.
+func (c *Block) GetProvenance(root phase0.Root) (*Provenance, error) {
+	entry, err := c.store.Get(root)
+	if err != nil {
+		return nil, err
 	}
 
-	return root, nil
+	entry.mu.RLock()
+	defer entry.mu.RUnlock()
+
+	if entry.provenance == nil {
+		return nil, errors.New("provenance not found")
+	}
+
+	return entry.provenance.Copy(), nil
+}
+
+// Stop stops the block store's background expiry.
+func (c *Block) Stop() {
+	c.store.Stop()
 }
@@ -0,0 +1,120 @@
+package store
+
+import (
+	"testing"
+	"time"
+
+	"github.com/attestantio/go-eth2-client/spec"
+	"github.com/attestantio/go-eth2-client/spec/deneb"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/ethpandaops/checkpointz/pkg/beacon/verify"
+	"github.com/ethpandaops/checkpointz/pkg/clock"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestBlock(slot phase0.Slot, stateRoot phase0.Root) *spec.VersionedSignedBeaconBlock {
+	return &spec.VersionedSignedBeaconBlock{
+		Version: spec.DataVersionDeneb,
+		Deneb: &deneb.SignedBeaconBlock{
+			Message: &deneb.BeaconBlock{
+				Slot:      slot,
+				StateRoot: stateRoot,
+				Body:      &deneb.BeaconBlockBody{},
+			},
+		},
+	}
+}
+
+func TestBlockAddAndGet(t *testing.T) {
+	logger, _ := test.NewNullLogger()
+	blocks := NewBlock(logger, Config{MaxItems: 10}, "test_block_a", nil, clock.New())
+
+	root := phase0.Root{0x01}
+	stateRoot := phase0.Root{0x02}
+	block := newTestBlock(100, stateRoot)
+
+	require.NoError(t, blocks.Add(root, block, time.Now().Add(time.Hour)))
+
+	got, err := blocks.GetByRoot(root)
+	require.NoError(t, err)
+	assert.Equal(t, block, got)
+
+	got, err = blocks.GetBySlot(100)
+	require.NoError(t, err)
+	assert.Equal(t, block, got)
+
+	got, err = blocks.GetByStateRoot(stateRoot)
+	require.NoError(t, err)
+	assert.Equal(t, block, got)
+
+	_, err = blocks.GetBySlot(101)
+	assert.Error(t, err)
+}
+
+func TestBlockEvictionCleansUpIndexes(t *testing.T) {
+	logger, _ := test.NewNullLogger()
+	blocks := NewBlock(logger, Config{MaxItems: 2}, "test_block_b", nil, clock.New())
+
+	now := time.Now()
+
+	require.NoError(t, blocks.Add(phase0.Root{0x01}, newTestBlock(1, phase0.Root{0x11}), now.Add(time.Hour)))
+	require.NoError(t, blocks.Add(phase0.Root{0x02}, newTestBlock(2, phase0.Root{0x12}), now.Add(2*time.Hour)))
+	require.NoError(t, blocks.Add(phase0.Root{0x03}, newTestBlock(3, phase0.Root{0x13}), now.Add(3*time.Hour)))
+
+	_, err := blocks.GetBySlot(1)
+	assert.Error(t, err)
+
+	_, err = blocks.GetByStateRoot(phase0.Root{0x11})
+	assert.Error(t, err)
+
+	for _, index := range []string{blockIndexSlot, blockIndexStateRoot} {
+		entries := 0
+
+		blocks.store.lookups[index].Range(func(_, _ any) bool {
+			entries++
+
+			return true
+		})
+
+		assert.Equal(t, 2, entries, "index %s should only hold entries for stored blocks", index)
+	}
+}
+
+func TestBlockDeleteCleansUpIndexes(t *testing.T) {
+	logger, _ := test.NewNullLogger()
+	blocks := NewBlock(logger, Config{MaxItems: 10}, "test_block_c", nil, clock.New())
+
+	root := phase0.Root{0x01}
+
+	require.NoError(t, blocks.Add(root, newTestBlock(5, phase0.Root{0x02}), time.Now().Add(time.Hour)))
+
+	blocks.Delete(root)
+
+	_, err := blocks.GetBySlot(5)
+	assert.Error(t, err)
+
+	_, err = blocks.GetByStateRoot(phase0.Root{0x02})
+	assert.Error(t, err)
+}
+
+func TestBlockSignatureStatus(t *testing.T) {
+	logger, _ := test.NewNullLogger()
+	blocks := NewBlock(logger, Config{MaxItems: 10}, "test_block_d", nil, clock.New())
+
+	root := phase0.Root{0x01}
+
+	assert.Error(t, blocks.SetSignatureStatus(root, verify.StatusVerified))
+
+	require.NoError(t, blocks.Add(root, newTestBlock(5, phase0.Root{0x02}), time.Now().Add(time.Hour)))
+
+	_, err := blocks.GetSignatureStatus(root)
+	assert.Error(t, err)
+
+	require.NoError(t, blocks.SetSignatureStatus(root, verify.StatusVerified))
+
+	status, err := blocks.GetSignatureStatus(root)
+	require.NoError(t, err)
+	assert.Equal(t, verify.StatusVerified, status)
+}
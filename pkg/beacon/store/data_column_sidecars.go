@@ -0,0 +1,67 @@
+package store
+
+import (
+	"time"
+
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/ethpandaops/checkpointz/pkg/beacon/fulu"
+	"github.com/ethpandaops/checkpointz/pkg/cache"
+	"github.com/ethpandaops/checkpointz/pkg/clock"
+	"github.com/ethpandaops/checkpointz/pkg/eth"
+	"github.com/sirupsen/logrus"
+)
+
+type DataColumnSidecar struct {
+	store *Indexed[phase0.Slot, []*fulu.DataColumnSidecar]
+	log   logrus.FieldLogger
+}
+
+func NewDataColumnSidecar(log logrus.FieldLogger, config Config, namespace string, budget *cache.Budget, clk clock.Clock) *DataColumnSidecar {
+	d := &DataColumnSidecar{
+		log: log.WithField("component", "beacon/store/data_column_sidecar"),
+	}
+
+	d.store = NewIndexed[phase0.Slot, []*fulu.DataColumnSidecar](d.log, config, "data_column_sidecar", namespace, budget, clk, eth.SlotAsString)
+
+	d.store.OnItemDeleted(func(key string, _ []*fulu.DataColumnSidecar, expiredAt time.Time) {
+		d.log.WithField("key", key).WithField("expired_at", expiredAt.String()).Debug("Data column sidecar was deleted from the cache")
+	})
+
+	return d
+}
+
+func (d *DataColumnSidecar) Add(slot phase0.Slot, sidecars []*fulu.DataColumnSidecar, expiresAt time.Time) error {
+	// Sidecars are large and can be re-fetched, so they make way for blocks and states first.
+	if err := d.store.Add(slot, sidecars, expiresAt, dataColumnSidecarsSize(sidecars), cache.PriorityLow); err != nil {
+		return err
+	}
+
+	d.log.WithFields(
+		logrus.Fields{
+			"slot":       eth.SlotAsString(slot),
+			"columns":    len(sidecars),
+			"expires_at": expiresAt.String(),
+		},
+	).Debug("Added data column sidecars")
+
+	return nil
+}
+
+func (d *DataColumnSidecar) GetBySlot(slot phase0.Slot) ([]*fulu.DataColumnSidecar, error) {
+	return d.store.Get(slot)
+}
+
+// Pin prevents the sidecars for the given slot from expiring or being evicted.
+func (d *DataColumnSidecar) Pin(slot phase0.Slot) error {
+	return d.store.SetPriority(slot, cache.PriorityPinned)
+}
+
+// Unpin allows the sidecars for the given slot to expire or be evicted again.
+func (d *DataColumnSidecar) Unpin(slot phase0.Slot) error {
+	return d.store.SetPriority(slot, cache.PriorityLow)
+}
+
+// Stop stops the data column sidecar store's background expiry.
+func (c *DataColumnSidecar) Stop() {
+	c.store.Stop()
+}
@@ -0,0 +1,34 @@
+package store
+
+import (
+	"testing"
+	"time"
+
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/ethpandaops/checkpointz/pkg/beacon/fulu"
+	"github.com/ethpandaops/checkpointz/pkg/clock"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDataColumnSidecarAddAndGet(t *testing.T) {
+	logger, _ := test.NewNullLogger()
+	store := NewDataColumnSidecar(logger, Config{MaxItems: 10}, "test_c", nil, clock.New())
+
+	slot := phase0.Slot(100)
+	sidecars := []*fulu.DataColumnSidecar{
+		{
+			Index: 3,
+		},
+	}
+
+	err := store.Add(slot, sidecars, time.Now().Add(10*time.Minute))
+	assert.NoError(t, err)
+
+	retrieved, err := store.GetBySlot(slot)
+	assert.NoError(t, err)
+	assert.Equal(t, sidecars, retrieved)
+
+	_, err = store.GetBySlot(phase0.Slot(101))
+	assert.Error(t, err)
+}
@@ -1,38 +1,39 @@
 package store
 
 import (
-	"errors"
 	"time"
 
 	"github.com/attestantio/go-eth2-client/spec/phase0"
 	"github.com/ethpandaops/beacon/pkg/beacon/api/types"
 	"github.com/ethpandaops/checkpointz/pkg/cache"
+	"github.com/ethpandaops/checkpointz/pkg/clock"
 	"github.com/ethpandaops/checkpointz/pkg/eth"
 	"github.com/sirupsen/logrus"
 )
 
 type DepositSnapshot struct {
-	store *cache.TTLMap
+	store *Indexed[phase0.Epoch, *types.DepositSnapshot]
 	log   logrus.FieldLogger
 }
 
-func NewDepositSnapshot(log logrus.FieldLogger, config Config, namespace string) *DepositSnapshot {
+func NewDepositSnapshot(log logrus.FieldLogger, config Config, namespace string, budget *cache.Budget, clk clock.Clock) *DepositSnapshot {
 	d := &DepositSnapshot{
-		log:   log.WithField("component", "beacon/store/deposit_snapshot"),
-		store: cache.NewTTLMap(config.MaxItems, "deposit_snapshot", namespace),
+		log: log.WithField("component", "beacon/store/deposit_snapshot"),
 	}
 
-	d.store.OnItemDeleted(func(key string, value interface{}, expiredAt time.Time) {
+	d.store = NewIndexed[phase0.Epoch, *types.DepositSnapshot](d.log, config, "deposit_snapshot", namespace, budget, clk, eth.EpochAsString)
+
+	d.store.OnItemDeleted(func(key string, _ *types.DepositSnapshot, expiredAt time.Time) {
 		d.log.WithField("key", key).WithField("expired_at", expiredAt.String()).Debug("Deposit snapshot was deleted from the cache")
 	})
 
-	d.store.EnableMetrics(namespace)
-
 	return d
 }
 
 func (d *DepositSnapshot) Add(epoch phase0.Epoch, snapshot *types.DepositSnapshot, expiresAt time.Time) error {
-	d.store.Add(eth.EpochAsString(epoch), snapshot, expiresAt, false)
+	if err := d.store.Add(epoch, snapshot, expiresAt, depositSnapshotSize(snapshot), cache.PriorityNormal); err != nil {
+		return err
+	}
 
 	d.log.WithFields(
 		logrus.Fields{
@@ -45,19 +46,10 @@ func (d *DepositSnapshot) Add(epoch phase0.Epoch, snapshot *types.DepositSnapsho
 }
 
 func (d *DepositSnapshot) GetByEpoch(epoch phase0.Epoch) (*types.DepositSnapshot, error) {
-	data, _, err := d.store.Get(eth.EpochAsString(epoch))
-	if err != nil {
-		return nil, err
-	}
-
-	return d.parseSnapshot(data)
+	return d.store.Get(epoch)
 }
 
-func (d *DepositSnapshot) parseSnapshot(data interface{}) (*types.DepositSnapshot, error) {
-	snapshot, ok := data.(*types.DepositSnapshot)
-	if !ok {
-		return nil, errors.New("invalid deposit snapshot type")
-	}
-
-	return snapshot, nil
+// Stop stops the deposit snapshot store's background expiry.
+func (c *DepositSnapshot) Stop() {
+	c.store.Stop()
 }
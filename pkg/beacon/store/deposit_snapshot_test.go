@@ -0,0 +1,32 @@
+package store
+
+import (
+	"testing"
+	"time"
+
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/ethpandaops/beacon/pkg/beacon/api/types"
+	"github.com/ethpandaops/checkpointz/pkg/clock"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDepositSnapshotAddAndGet(t *testing.T) {
+	logger, _ := test.NewNullLogger()
+	snapshots := NewDepositSnapshot(logger, Config{MaxItems: 10}, "test_deposit_snapshot_a", nil, clock.New())
+
+	snapshot := &types.DepositSnapshot{
+		Finalized:    []phase0.Root{{0x01}},
+		DepositCount: 1,
+	}
+
+	require.NoError(t, snapshots.Add(10, snapshot, time.Now().Add(time.Hour)))
+
+	got, err := snapshots.GetByEpoch(10)
+	require.NoError(t, err)
+	assert.Equal(t, snapshot, got)
+
+	_, err = snapshots.GetByEpoch(11)
+	assert.Error(t, err)
+}
@@ -0,0 +1,55 @@
+package store_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/ethpandaops/checkpointz/pkg/beacon"
+	"github.com/ethpandaops/checkpointz/pkg/checkpointz/checkpointztest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestE2EExposesProvenance(t *testing.T) {
+	chain := checkpointztest.NewChain(t)
+	root := chain.Root(checkpointztest.FinalizedEpoch)
+
+	server := checkpointztest.Start(t, beacon.OperatingModeFull, nil,
+		checkpointztest.Upstream{Node: checkpointztest.NewNode(chain), DataProvider: true},
+		checkpointztest.Upstream{Node: checkpointztest.NewNode(chain), DataProvider: true},
+	)
+
+	server.RequireServes(root)
+
+	rsp, _ := server.Get("/eth/v2/beacon/blocks/finalized", "application/octet-stream")
+	require.Equal(t, http.StatusOK, rsp.StatusCode)
+
+	assert.Equal(t, "upstream-0,upstream-1", rsp.Header.Get("Checkpointz-Agreeing-Upstreams"))
+	assert.Equal(t, "1", rsp.Header.Get("Checkpointz-Agreement"))
+	assert.Equal(t, fmt.Sprintf("%#x", root), rsp.Header.Get("Checkpointz-Finalized-Root"))
+	assert.NotEmpty(t, rsp.Header.Get("Checkpointz-Source"))
+
+	rsp, body := server.Get(fmt.Sprintf("/checkpointz/v1/provenance/%#x", root), "application/json")
+	require.Equal(t, http.StatusOK, rsp.StatusCode)
+
+	var provenance struct {
+		Data struct {
+			Block struct {
+				Source     string  `json:"source"`
+				VerifiedAt *string `json:"verified_at"`
+			} `json:"block"`
+			State struct {
+				Source     string  `json:"source"`
+				VerifiedAt *string `json:"verified_at"`
+			} `json:"state"`
+		} `json:"data"`
+	}
+
+	require.NoError(t, json.Unmarshal(body, &provenance))
+	assert.NotEmpty(t, provenance.Data.Block.Source)
+	assert.NotNil(t, provenance.Data.Block.VerifiedAt)
+	assert.NotEmpty(t, provenance.Data.State.Source)
+	assert.NotNil(t, provenance.Data.State.VerifiedAt)
+}
@@ -0,0 +1,192 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ethpandaops/checkpointz/pkg/cache"
+	"github.com/ethpandaops/checkpointz/pkg/clock"
+	"github.com/sirupsen/logrus"
+)
+
+var ErrNotFound = errors.New("not found")
+
+// Index declares a secondary index over the values of an Indexed store.
+type Index[V any] struct {
+	// Name identifies the index in lookups.
+	Name string
+	// Key derives the secondary key of a value. It must be deterministic.
+	Key func(V) (string, error)
+}
+
+// indexedEntry is what an Indexed store keeps in its TTLMap: the value alongside the secondary keys it was
+// indexed under, so the index entries can be removed when the value is.
+type indexedEntry[V any] struct {
+	value V
+	keys  map[string]string
+}
+
+// Indexed is a typed store keyed by K, with optional secondary indexes that are kept consistent with the
+// underlying cache as items are added, deleted, evicted and expire.
+type Indexed[K comparable, V any] struct {
+	log   logrus.FieldLogger
+	store *cache.TTLMap
+	key   func(K) string
+
+	indexes []Index[V]
+	// lookups holds a secondary key -> primary key map per index.
+	lookups map[string]*sync.Map
+}
+
+// NewIndexed returns a new Indexed store that expires values by the given clock. key converts a primary key to
+// its cache key.
+func NewIndexed[K comparable, V any](log logrus.FieldLogger, config Config, name, namespace string, budget *cache.Budget, clk clock.Clock, key func(K) string, indexes ...Index[V]) *Indexed[K, V] {
+	s := &Indexed[K, V]{
+		log:     log,
+		store:   cache.NewTTLMapWithClock(config.MaxItems, name, namespace, clk),
+		key:     key,
+		indexes: indexes,
+		lookups: make(map[string]*sync.Map, len(indexes)),
+	}
+
+	for _, index := range indexes {
+		s.lookups[index.Name] = &sync.Map{}
+	}
+
+	s.store.OnItemDeletedSync(func(key string, value interface{}) {
+		entry, ok := value.(*indexedEntry[V])
+		if !ok {
+			return
+		}
+
+		s.unindex(key, entry)
+	})
+
+	s.store.UseBudget(budget)
+	s.store.EnableMetrics(namespace)
+
+	return s
+}
+
+// OnItemDeleted registers a callback that runs asynchronously when an item is deleted, evicted or expires.
+func (s *Indexed[K, V]) OnItemDeleted(f func(key string, value V, expiredAt time.Time)) {
+	s.store.OnItemDeleted(func(key string, value interface{}, expiredAt time.Time) {
+		entry, ok := value.(*indexedEntry[V])
+		if !ok {
+			s.log.WithField("key", key).Error("Invalid value type when cleaning up the cache")
+
+			return
+		}
+
+		f(key, entry.value, expiredAt)
+	})
+}
+
+// Add stores the value under the given key and indexes it. If the key already exists, the existing value is kept.
+func (s *Indexed[K, V]) Add(key K, value V, expiresAt time.Time, cost int64, priority cache.Priority) error {
+	primary := s.key(key)
+
+	if s.store.Has(primary) {
+		return nil
+	}
+
+	entry := &indexedEntry[V]{
+		value: value,
+		keys:  make(map[string]string, len(s.indexes)),
+	}
+
+	for _, index := range s.indexes {
+		secondary, err := index.Key(value)
+		if err != nil {
+			return fmt.Errorf("failed to derive %s index key: %w", index.Name, err)
+		}
+
+		entry.keys[index.Name] = secondary
+	}
+
+	s.store.AddWithCost(primary, entry, expiresAt, cost, priority)
+
+	for name, secondary := range entry.keys {
+		s.lookups[name].Store(secondary, primary)
+	}
+
+	// The item may have been evicted before it was indexed, in which case the delete hook has already run
+	// and won't clean up after us.
+	if !s.store.Has(primary) {
+		s.unindex(primary, entry)
+	}
+
+	return nil
+}
+
+func (s *Indexed[K, V]) unindex(primary string, entry *indexedEntry[V]) {
+	for name, secondary := range entry.keys {
+		// Only remove the index entry if it hasn't since been pointed at another item.
+		s.lookups[name].CompareAndDelete(secondary, primary)
+	}
+}
+
+// Get returns the value stored under the given key.
+func (s *Indexed[K, V]) Get(key K) (V, error) {
+	return s.get(s.key(key))
+}
+
+// GetBy returns the value indexed under the given secondary key.
+func (s *Indexed[K, V]) GetBy(index, secondary string) (V, error) {
+	var empty V
+
+	lookup, ok := s.lookups[index]
+	if !ok {
+		return empty, fmt.Errorf("unknown index %s", index)
+	}
+
+	primary, ok := lookup.Load(secondary)
+	if !ok {
+		return empty, ErrNotFound
+	}
+
+	key, ok := primary.(string)
+	if !ok {
+		return empty, errors.New("invalid primary key")
+	}
+
+	return s.get(key)
+}
+
+func (s *Indexed[K, V]) get(key string) (V, error) {
+	var empty V
+
+	data, _, err := s.store.Get(key)
+	if err != nil {
+		return empty, err
+	}
+
+	entry, ok := data.(*indexedEntry[V])
+	if !ok {
+		return empty, errors.New("invalid value type")
+	}
+
+	return entry.value, nil
+}
+
+// Delete removes the value stored under the given key.
+func (s *Indexed[K, V]) Delete(key K) {
+	s.store.Delete(s.key(key))
+}
+
+// SetPriority changes the eviction priority of the value stored under the given key.
+func (s *Indexed[K, V]) SetPriority(key K, priority cache.Priority) error {
+	return s.store.SetPriority(s.key(key), priority)
+}
+
+// Stop stops the store's background expiry.
+func (s *Indexed[K, V]) Stop() {
+	s.store.Stop()
+}
+
+// Len returns the number of values in the store.
+func (s *Indexed[K, V]) Len() int {
+	return s.store.Len()
+}
@@ -0,0 +1,148 @@
+package store
+
+import (
+	"fmt"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/ethpandaops/checkpointz/pkg/cache"
+	"github.com/ethpandaops/checkpointz/pkg/clock"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testValue struct {
+	id    int
+	group string
+}
+
+func newTestIndexed(t *testing.T, maxItems int, namespace string) *Indexed[int, *testValue] {
+	t.Helper()
+
+	logger, _ := test.NewNullLogger()
+
+	return NewIndexed(logger, Config{MaxItems: maxItems}, "test", namespace, nil, clock.New(), strconv.Itoa,
+		Index[*testValue]{
+			Name: "group",
+			Key: func(v *testValue) (string, error) {
+				if v.group == "" {
+					return "", fmt.Errorf("value %d has no group", v.id)
+				}
+
+				return v.group, nil
+			},
+		},
+	)
+}
+
+func TestIndexedAddAndGet(t *testing.T) {
+	s := newTestIndexed(t, 10, "test_indexed_a")
+
+	value := &testValue{id: 1, group: "a"}
+	require.NoError(t, s.Add(1, value, time.Now().Add(time.Hour), 0, cache.PriorityNormal))
+
+	got, err := s.Get(1)
+	require.NoError(t, err)
+	assert.Equal(t, value, got)
+
+	got, err = s.GetBy("group", "a")
+	require.NoError(t, err)
+	assert.Equal(t, value, got)
+
+	_, err = s.GetBy("group", "b")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	_, err = s.GetBy("unknown", "a")
+	assert.Error(t, err)
+}
+
+func TestIndexedAddFailsWhenIndexKeyFails(t *testing.T) {
+	s := newTestIndexed(t, 10, "test_indexed_b")
+
+	assert.Error(t, s.Add(1, &testValue{id: 1}, time.Now().Add(time.Hour), 0, cache.PriorityNormal))
+
+	_, err := s.Get(1)
+	assert.Error(t, err)
+}
+
+func TestIndexedDeleteRemovesIndexes(t *testing.T) {
+	s := newTestIndexed(t, 10, "test_indexed_c")
+
+	require.NoError(t, s.Add(1, &testValue{id: 1, group: "a"}, time.Now().Add(time.Hour), 0, cache.PriorityNormal))
+
+	s.Delete(1)
+
+	_, err := s.GetBy("group", "a")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	lookups := 0
+
+	s.lookups["group"].Range(func(_, _ any) bool {
+		lookups++
+
+		return true
+	})
+
+	assert.Zero(t, lookups)
+}
+
+func TestIndexedEvictionRemovesIndexes(t *testing.T) {
+	s := newTestIndexed(t, 2, "test_indexed_d")
+
+	now := time.Now()
+
+	require.NoError(t, s.Add(1, &testValue{id: 1, group: "a"}, now.Add(time.Hour), 0, cache.PriorityNormal))
+	require.NoError(t, s.Add(2, &testValue{id: 2, group: "b"}, now.Add(2*time.Hour), 0, cache.PriorityNormal))
+	require.NoError(t, s.Add(3, &testValue{id: 3, group: "c"}, now.Add(3*time.Hour), 0, cache.PriorityNormal))
+
+	_, err := s.GetBy("group", "a")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	_, ok := s.lookups["group"].Load("a")
+	assert.False(t, ok, "index entry for an evicted value should be removed")
+
+	got, err := s.GetBy("group", "c")
+	require.NoError(t, err)
+	assert.Equal(t, 3, got.id)
+}
+
+func TestIndexedExpiryRemovesIndexes(t *testing.T) {
+	s := newTestIndexed(t, 10, "test_indexed_e")
+
+	require.NoError(t, s.Add(1, &testValue{id: 1, group: "a"}, time.Now().Add(time.Second), 0, cache.PriorityNormal))
+
+	time.Sleep(3 * time.Second)
+
+	_, ok := s.lookups["group"].Load("a")
+	assert.False(t, ok, "index entry for an expired value should be removed")
+}
+
+func TestIndexedReindexedKeyIsNotRemovedByOldValue(t *testing.T) {
+	s := newTestIndexed(t, 10, "test_indexed_f")
+
+	require.NoError(t, s.Add(1, &testValue{id: 1, group: "a"}, time.Now().Add(time.Hour), 0, cache.PriorityNormal))
+	require.NoError(t, s.Add(2, &testValue{id: 2, group: "a"}, time.Now().Add(time.Hour), 0, cache.PriorityNormal))
+
+	// Removing the value that used to own the index entry must not remove the newer one.
+	s.Delete(1)
+
+	got, err := s.GetBy("group", "a")
+	require.NoError(t, err)
+	assert.Equal(t, 2, got.id)
+}
+
+func TestIndexedAddKeepsExistingValue(t *testing.T) {
+	s := newTestIndexed(t, 10, "test_indexed_g")
+
+	require.NoError(t, s.Add(1, &testValue{id: 1, group: "a"}, time.Now().Add(time.Hour), 0, cache.PriorityNormal))
+	require.NoError(t, s.Add(1, &testValue{id: 10, group: "b"}, time.Now().Add(time.Hour), 0, cache.PriorityNormal))
+
+	got, err := s.Get(1)
+	require.NoError(t, err)
+	assert.Equal(t, 1, got.id)
+
+	_, err = s.GetBy("group", "b")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
@@ -0,0 +1,46 @@
+package store
+
+import (
+	"time"
+
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+)
+
+// Provenance records where a stored block or state came from, and how many upstreams agreed on the finalized
+// checkpoint at the time it was fetched.
+type Provenance struct {
+	// FinalizedEpoch and FinalizedRoot are the finalized checkpoint the entry was fetched under.
+	FinalizedEpoch phase0.Epoch `json:"finalized_epoch"`
+	FinalizedRoot  phase0.Root  `json:"finalized_root"`
+	// AgreeingUpstreams are the upstreams that reported FinalizedRoot.
+	AgreeingUpstreams []string `json:"agreeing_upstreams"`
+	// Agreement is the fraction of upstreams that agreed on the finalized checkpoint.
+	Agreement float64 `json:"agreement"`
+	// Source is the upstream that supplied the entry.
+	Source string `json:"source"`
+	// FetchedAt is when the entry was downloaded.
+	FetchedAt time.Time `json:"fetched_at"`
+	// VerifiedAt is when the entry's root was checked against the root it was requested by. It's nil if the
+	// entry wasn't checked, like blocks downloaded by slot.
+	VerifiedAt *time.Time `json:"verified_at,omitempty"`
+	// RemoteETag is the ETag of the entry's copy in remote storage, if that copy is known to have the entry's
+	// root, either because it was read from there and checked or because it was uploaded from here.
+	RemoteETag string `json:"-"`
+}
+
+// Copy returns a deep copy of the provenance.
+func (p *Provenance) Copy() *Provenance {
+	if p == nil {
+		return nil
+	}
+
+	copied := *p
+	copied.AgreeingUpstreams = append([]string(nil), p.AgreeingUpstreams...)
+
+	if p.VerifiedAt != nil {
+		verifiedAt := *p.VerifiedAt
+		copied.VerifiedAt = &verifiedAt
+	}
+
+	return &copied
+}
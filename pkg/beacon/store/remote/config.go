@@ -0,0 +1,90 @@
+package remote
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// StateDownloads controls how beacon state downloads are answered once a state is in remote storage.
+type StateDownloads string
+
+const (
+	// StateDownloadsServe serves states from memory as usual.
+	StateDownloadsServe StateDownloads = "serve"
+	// StateDownloadsRedirect redirects SSZ state downloads to the object under PublicURL.
+	StateDownloadsRedirect StateDownloads = "redirect"
+	// StateDownloadsPresign redirects SSZ state downloads to a presigned URL for the object.
+	StateDownloadsPresign StateDownloads = "presign"
+)
+
+// Config configures S3 compatible object storage that bundles are shared through.
+type Config struct {
+	// Enabled enables remote storage.
+	Enabled bool `yaml:"enabled" default:"false"`
+	// Endpoint is the URL of the S3 compatible API, e.g. https://s3.amazonaws.com or http://localhost:9000.
+	Endpoint string `yaml:"endpoint"`
+	// Region is the bucket's region.
+	Region string `yaml:"region" default:"us-east-1"`
+	// Bucket is the bucket objects are stored in. It must already exist.
+	Bucket string `yaml:"bucket"`
+	// Prefix is prepended to every object key, so that several networks can share a bucket.
+	Prefix string `yaml:"prefix"`
+	// AccessKeyID and SecretAccessKey are the credentials for the bucket. If they're empty, they're read from
+	// the AWS_ACCESS_KEY_ID/AWS_SECRET_ACCESS_KEY or MINIO_ACCESS_KEY/MINIO_SECRET_KEY environment variables.
+	AccessKeyID     string `yaml:"access_key_id"`
+	SecretAccessKey string `yaml:"secret_access_key"`
+	// PathStyle addresses the bucket in the URL path rather than the host name, as MinIO expects.
+	PathStyle bool `yaml:"path_style" default:"false"`
+	// StateDownloads controls how beacon state downloads are answered once the state is in the bucket.
+	StateDownloads StateDownloads `yaml:"state_downloads" default:"serve"`
+	// PublicURL is the base URL the bucket's objects are publicly readable at, for StateDownloadsRedirect.
+	PublicURL string `yaml:"public_url"`
+	// PresignExpiry is how long presigned URLs are valid for, for StateDownloadsPresign.
+	PresignExpiry time.Duration `yaml:"presign_expiry" default:"15m"`
+}
+
+func (c *Config) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+
+	if c.Endpoint == "" {
+		return errors.New("endpoint is required")
+	}
+
+	endpoint, err := url.Parse(c.Endpoint)
+	if err != nil {
+		return fmt.Errorf("invalid endpoint: %w", err)
+	}
+
+	if endpoint.Scheme != "http" && endpoint.Scheme != "https" {
+		return errors.New("endpoint must be an http or https URL")
+	}
+
+	if c.Bucket == "" {
+		return errors.New("bucket is required")
+	}
+
+	switch c.StateDownloads {
+	case StateDownloadsServe:
+	case StateDownloadsRedirect:
+		if c.PublicURL == "" {
+			return errors.New("public_url is required to redirect state downloads")
+		}
+	case StateDownloadsPresign:
+		if c.PresignExpiry <= 0 {
+			return errors.New("presign_expiry must be positive")
+		}
+
+		// S3 refuses to presign URLs for longer than a week.
+		if c.PresignExpiry > 7*24*time.Hour {
+			return errors.New("presign_expiry cannot be longer than 7 days")
+		}
+	default:
+		return fmt.Errorf("invalid state_downloads: %s", c.StateDownloads)
+	}
+
+	return nil
+}
@@ -0,0 +1,185 @@
+package remote_test
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethpandaops/checkpointz/pkg/beacon"
+	"github.com/ethpandaops/checkpointz/pkg/beacon/beacontest"
+	"github.com/ethpandaops/checkpointz/pkg/beacon/store/remote"
+	"github.com/ethpandaops/checkpointz/pkg/checkpointz"
+	"github.com/ethpandaops/checkpointz/pkg/checkpointz/checkpointztest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestE2ESharesBundlesThroughRemoteStorage(t *testing.T) {
+	chain := checkpointztest.NewChain(t)
+
+	objects := beacontest.NewObjectStore()
+	t.Cleanup(objects.Close)
+
+	withRemoteStorage := func(stateDownloads remote.StateDownloads) func(*checkpointz.Config) {
+		return func(config *checkpointz.Config) {
+			config.Checkpointz.RemoteStorage = remote.Config{
+				Enabled:         true,
+				Endpoint:        objects.URL(),
+				Region:          "us-east-1",
+				Bucket:          "checkpointz",
+				Prefix:          "e2e",
+				AccessKeyID:     "access",
+				SecretAccessKey: "secret",
+				PathStyle:       true,
+				StateDownloads:  stateDownloads,
+				PresignExpiry:   time.Minute,
+			}
+		}
+	}
+
+	finalizedBlock, _, _ := chain.Block(checkpointztest.FinalizedEpoch)
+	stateRoot, err := finalizedBlock.StateRoot()
+	require.NoError(t, err)
+
+	root := chain.Root(checkpointztest.FinalizedEpoch)
+	stateKey := fmt.Sprintf("e2e/states/%#x.ssz", stateRoot)
+	stateSlot := checkpointztest.FinalizedEpoch * beacontest.SlotsPerEpoch
+	bundleKeys := []string{
+		stateKey,
+		fmt.Sprintf("e2e/blocks/%#x.ssz", root),
+		fmt.Sprintf("e2e/blob_sidecars/%#x.ssz", root),
+		fmt.Sprintf("e2e/deposit_snapshots/%d.ssz", checkpointztest.FinalizedEpoch),
+	}
+
+	// The first replica downloads the bundle from its upstream and shares it.
+	first := checkpointztest.Start(t, beacon.OperatingModeFull, withRemoteStorage(remote.StateDownloadsServe),
+		checkpointztest.Upstream{Node: checkpointztest.NewNode(chain), DataProvider: true},
+	)
+
+	first.RequireServes(root)
+
+	first.Eventually(func() bool {
+		for _, key := range bundleKeys {
+			if _, ok := objects.Object("checkpointz", key); !ok {
+				return false
+			}
+		}
+
+		return true
+	})
+
+	expected, _ := chain.State(checkpointztest.FinalizedEpoch)
+	expectedData, err := expected.Deneb.MarshalSSZ()
+	require.NoError(t, err)
+
+	shared, _ := objects.Object("checkpointz", stateKey)
+	assert.Equal(t, expectedData, shared)
+
+	first.Stop()
+
+	// The second replica reads the whole bundle from remote storage rather than its upstream, and redirects
+	// state downloads there.
+	node := checkpointztest.NewNode(chain)
+
+	second := checkpointztest.Start(t, beacon.OperatingModeFull, withRemoteStorage(remote.StateDownloadsPresign),
+		checkpointztest.Upstream{Node: node, DataProvider: true},
+	)
+
+	second.RequireServes(root)
+	assert.Zero(t, node.Requests(fmt.Sprintf("/eth/v2/debug/beacon/states/%d", stateSlot)))
+	assert.Zero(t, node.Requests(fmt.Sprintf("/eth/v2/beacon/blocks/%#x", root)))
+	assert.Zero(t, node.Requests(fmt.Sprintf("/eth/v1/beacon/blob_sidecars/%d", stateSlot)))
+	assert.Zero(t, node.Requests("/eth/v1/beacon/deposit_snapshot"))
+
+	rsp, body := second.Get(fmt.Sprintf("/checkpointz/v1/provenance/%#x", root), "application/json")
+	require.Equal(t, http.StatusOK, rsp.StatusCode)
+	assert.Contains(t, string(body), `"source":"s3://checkpointz/e2e"`)
+
+	redirect := getSSZWithoutRedirects(t, second.URL+"/eth/v2/debug/beacon/states/finalized")
+	require.Equal(t, http.StatusTemporaryRedirect, redirect.StatusCode)
+	assert.True(t, strings.HasPrefix(redirect.Header.Get("Location"), objects.URL()+"/checkpointz/"+stateKey+"?"))
+	assert.Equal(t, "deneb", redirect.Header.Get("Eth-Consensus-Version"), "the redirect carries the fork version")
+
+	// Following the redirect downloads the state from the bucket.
+	rsp, body = second.Get("/eth/v2/debug/beacon/states/finalized", "application/octet-stream")
+	require.Equal(t, http.StatusOK, rsp.StatusCode)
+	assert.Equal(t, expectedData, body)
+
+	// JSON is still served directly.
+	rsp, _ = second.Get("/eth/v2/debug/beacon/states/finalized", "application/json")
+	assert.Equal(t, http.StatusOK, rsp.StatusCode)
+
+	// Once the object is no longer the state that was checked, downloads are served by the replica itself.
+	req, err := http.NewRequest(http.MethodPut, objects.URL()+"/checkpointz/"+stateKey, bytes.NewReader([]byte("not the state")))
+	require.NoError(t, err)
+
+	overwrite, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	overwrite.Body.Close()
+	require.Equal(t, http.StatusOK, overwrite.StatusCode)
+
+	rsp, body = second.Get("/eth/v2/debug/beacon/states/finalized", "application/octet-stream")
+	require.Equal(t, http.StatusOK, rsp.StatusCode)
+	assert.Equal(t, "deneb", rsp.Header.Get("Eth-Consensus-Version"))
+	assert.Equal(t, expectedData, body)
+}
+
+func TestE2ERedirectsStateDownloadsToUploadedStates(t *testing.T) {
+	chain := checkpointztest.NewChain(t)
+
+	objects := beacontest.NewObjectStore()
+	t.Cleanup(objects.Close)
+
+	server := checkpointztest.Start(t, beacon.OperatingModeFull, func(config *checkpointz.Config) {
+		config.Checkpointz.RemoteStorage = remote.Config{
+			Enabled:         true,
+			Endpoint:        objects.URL(),
+			Region:          "us-east-1",
+			Bucket:          "checkpointz",
+			Prefix:          "e2e",
+			AccessKeyID:     "access",
+			SecretAccessKey: "secret",
+			PathStyle:       true,
+			StateDownloads:  remote.StateDownloadsRedirect,
+			PublicURL:       "https://states.example.com",
+		}
+	}, checkpointztest.Upstream{Node: checkpointztest.NewNode(chain), DataProvider: true})
+
+	server.RequireServes(chain.Root(checkpointztest.FinalizedEpoch))
+
+	finalizedBlock, _, _ := chain.Block(checkpointztest.FinalizedEpoch)
+	stateRoot, err := finalizedBlock.StateRoot()
+	require.NoError(t, err)
+
+	// The replica downloaded the state from its upstream, so redirects once its own upload has landed.
+	server.Eventually(func() bool {
+		rsp := getSSZWithoutRedirects(t, server.URL+"/eth/v2/debug/beacon/states/finalized")
+
+		return rsp.StatusCode == http.StatusTemporaryRedirect &&
+			rsp.Header.Get("Location") == fmt.Sprintf("https://states.example.com/e2e/states/%#x.ssz", stateRoot)
+	})
+}
+
+// getSSZWithoutRedirects requests an SSZ state download from url, without following any redirect.
+func getSSZWithoutRedirects(t *testing.T, url string) *http.Response {
+	t.Helper()
+
+	client := &http.Client{
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+
+	req, err := http.NewRequest(http.MethodGet, url, http.NoBody)
+	require.NoError(t, err)
+	req.Header.Set("Accept", "application/octet-stream")
+
+	rsp, err := client.Do(req)
+	require.NoError(t, err)
+	rsp.Body.Close()
+
+	return rsp
+}
@@ -0,0 +1,239 @@
+// Package remote stores SSZ encoded bundles - blocks, beacon states, sidecars and deposit snapshots - in S3
+// compatible object storage, so that a fleet of Checkpointz replicas can share the bundles one of them
+// downloaded instead of each pulling them from their upstreams.
+package remote
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/attestantio/go-eth2-client/spec"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+// ErrNotFound is returned when the bucket doesn't hold the requested object.
+var ErrNotFound = errors.New("not found in remote storage")
+
+// versionMetadata is the user metadata key holding the fork version of a stored object.
+const versionMetadata = "Eth-Consensus-Version"
+
+// Store is a bucket of bundles.
+type Store struct {
+	config Config
+	client *minio.Client
+}
+
+// New returns a new Store for the bucket in config.
+func New(config Config) (*Store, error) {
+	client, err := NewClient(config)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Store{
+		config: config,
+		client: client,
+	}, nil
+}
+
+// NewClient returns a client for the object storage API in config.
+func NewClient(config Config) (*minio.Client, error) {
+	endpoint, err := url.Parse(config.Endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("invalid endpoint: %w", err)
+	}
+
+	creds := credentials.NewChainCredentials([]credentials.Provider{
+		&credentials.EnvAWS{},
+		&credentials.EnvMinio{},
+	})
+
+	if config.AccessKeyID != "" {
+		creds = credentials.NewStaticV4(config.AccessKeyID, config.SecretAccessKey, "")
+	}
+
+	lookup := minio.BucketLookupAuto
+	if config.PathStyle {
+		lookup = minio.BucketLookupPath
+	}
+
+	return minio.New(endpoint.Host, &minio.Options{
+		Creds:  creds,
+		Secure: endpoint.Scheme == "https",
+		// Setting the region up front saves a bucket location lookup before the first request.
+		Region:       config.Region,
+		BucketLookup: lookup,
+	})
+}
+
+// Name identifies the store in provenance and logs.
+func (s *Store) Name() string {
+	return "s3://" + path.Join(s.config.Bucket, s.config.Prefix)
+}
+
+// Object identifies an object in the bucket. Blocks, states and sidecars are keyed by a root, so anything
+// read back can be verified before use.
+type Object struct {
+	kind string
+	name string
+}
+
+// State returns the object holding the SSZ encoded beacon state with the given root.
+func State(root phase0.Root) Object {
+	return Object{kind: "states", name: fmt.Sprintf("%#x", root)}
+}
+
+// Block returns the object holding the SSZ encoded signed beacon block with the given root.
+func Block(root phase0.Root) Object {
+	return Object{kind: "blocks", name: fmt.Sprintf("%#x", root)}
+}
+
+// BlobSidecars returns the object holding the SSZ encoded blob sidecars of the block with the given root.
+func BlobSidecars(blockRoot phase0.Root) Object {
+	return Object{kind: "blob_sidecars", name: fmt.Sprintf("%#x", blockRoot)}
+}
+
+// DataColumnSidecars returns the object holding the SSZ encoded data column sidecars of the block with the
+// given root.
+func DataColumnSidecars(blockRoot phase0.Root) Object {
+	return Object{kind: "data_column_sidecars", name: fmt.Sprintf("%#x", blockRoot)}
+}
+
+// DepositSnapshot returns the object holding the SSZ encoded deposit snapshot for the given epoch.
+func DepositSnapshot(epoch phase0.Epoch) Object {
+	return Object{kind: "deposit_snapshots", name: fmt.Sprintf("%d", epoch)}
+}
+
+// Kind returns the kind of object, e.g. "states".
+func (o Object) Kind() string {
+	return o.kind
+}
+
+// key returns the object key of the object.
+func (s *Store) key(object Object) string {
+	return path.Join(s.config.Prefix, object.kind, object.name+".ssz")
+}
+
+// Info describes a stored object.
+type Info struct {
+	// Version is the fork version recorded for the object, or spec.DataVersionUnknown if none was.
+	Version spec.DataVersion
+	// ETag identifies the object's contents, and changes whenever it's overwritten.
+	ETag string
+}
+
+// Put stores the SSZ encoded object and returns its ETag. The fork version is recorded for objects that are
+// decoded by fork, and may be spec.DataVersionUnknown for those that aren't.
+func (s *Store) Put(ctx context.Context, object Object, version spec.DataVersion, data []byte) (string, error) {
+	opts := minio.PutObjectOptions{ContentType: "application/octet-stream"}
+	if version != spec.DataVersionUnknown {
+		opts.UserMetadata = map[string]string{versionMetadata: version.String()}
+	}
+
+	info, err := s.client.PutObject(ctx, s.config.Bucket, s.key(object), bytes.NewReader(data), int64(len(data)), opts)
+	if err != nil {
+		return "", err
+	}
+
+	return info.ETag, nil
+}
+
+// Get returns the SSZ encoded object along with its fork version and ETag, or ErrNotFound.
+func (s *Store) Get(ctx context.Context, object Object) (Info, []byte, error) {
+	reader, err := s.client.GetObject(ctx, s.config.Bucket, s.key(object), minio.GetObjectOptions{})
+	if err != nil {
+		return Info{}, nil, notFound(err)
+	}
+	defer reader.Close()
+
+	stat, err := reader.Stat()
+	if err != nil {
+		return Info{}, nil, notFound(err)
+	}
+
+	info := Info{Version: spec.DataVersionUnknown, ETag: stat.ETag}
+
+	if raw, ok := stat.UserMetadata[versionMetadata]; ok {
+		info.Version, err = spec.DataVersionFromString(raw)
+		if err != nil {
+			return Info{}, nil, fmt.Errorf("invalid %s metadata: %w", versionMetadata, err)
+		}
+	}
+
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return Info{}, nil, notFound(err)
+	}
+
+	return info, data, nil
+}
+
+// Has returns true if the bucket holds the object.
+func (s *Store) Has(ctx context.Context, object Object) (bool, error) {
+	_, err := s.client.StatObject(ctx, s.config.Bucket, s.key(object), minio.StatObjectOptions{})
+	if err == nil {
+		return true, nil
+	}
+
+	if err := notFound(err); errors.Is(err, ErrNotFound) {
+		return false, nil
+	}
+
+	return false, err
+}
+
+// StateURL returns the URL that downloads of the state with the given root should be redirected to, or an
+// empty string if they should be served as usual. Downloads are only redirected while the bucket holds the
+// object with the given ETag, i.e. the copy that was checked against the root, so that a state overwritten
+// in the bucket is never handed out.
+func (s *Store) StateURL(ctx context.Context, root phase0.Root, etag string) (string, error) {
+	if s.config.StateDownloads == StateDownloadsServe || etag == "" {
+		return "", nil
+	}
+
+	key := s.key(State(root))
+
+	info, err := s.client.StatObject(ctx, s.config.Bucket, key, minio.StatObjectOptions{})
+	if err != nil {
+		if err := notFound(err); errors.Is(err, ErrNotFound) {
+			return "", nil
+		}
+
+		return "", err
+	}
+
+	if info.ETag != etag {
+		return "", nil
+	}
+
+	switch s.config.StateDownloads {
+	case StateDownloadsRedirect:
+		return strings.TrimSuffix(s.config.PublicURL, "/") + "/" + key, nil
+	case StateDownloadsPresign:
+		u, err := s.client.PresignedGetObject(ctx, s.config.Bucket, key, s.config.PresignExpiry, nil)
+		if err != nil {
+			return "", err
+		}
+
+		return u.String(), nil
+	default:
+		return "", nil
+	}
+}
+
+// notFound returns ErrNotFound if err reports a missing object, and err otherwise.
+func notFound(err error) error {
+	if minio.ToErrorResponse(err).Code == minio.NoSuchKey {
+		return ErrNotFound
+	}
+
+	return err
+}
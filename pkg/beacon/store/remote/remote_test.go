@@ -0,0 +1,204 @@
+package remote
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/attestantio/go-eth2-client/spec"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/ethpandaops/checkpointz/pkg/beacon/beacontest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestStore(t *testing.T, objects *beacontest.ObjectStore, configure func(*Config)) *Store {
+	t.Helper()
+
+	config := Config{
+		Enabled:         true,
+		Endpoint:        objects.URL(),
+		Region:          "us-east-1",
+		Bucket:          "checkpointz",
+		Prefix:          "testnet",
+		AccessKeyID:     "access",
+		SecretAccessKey: "secret",
+		PathStyle:       true,
+		StateDownloads:  StateDownloadsServe,
+		PresignExpiry:   15 * time.Minute,
+	}
+
+	if configure != nil {
+		configure(&config)
+	}
+
+	require.NoError(t, config.Validate())
+
+	s, err := New(config)
+	require.NoError(t, err)
+
+	return s
+}
+
+func TestStoreRoundTripsStates(t *testing.T) {
+	objects := beacontest.NewObjectStore()
+	t.Cleanup(objects.Close)
+
+	s := newTestStore(t, objects, nil)
+	ctx := context.Background()
+	root := phase0.Root{0x01, 0x02}
+
+	has, err := s.Has(ctx, State(root))
+	require.NoError(t, err)
+	assert.False(t, has)
+
+	_, _, err = s.Get(ctx, State(root))
+	require.ErrorIs(t, err, ErrNotFound)
+
+	data := []byte("not really a state")
+	etag, err := s.Put(ctx, State(root), spec.DataVersionDeneb, data)
+	require.NoError(t, err)
+	assert.NotEmpty(t, etag)
+
+	stored, ok := objects.Object("checkpointz", "testnet/states/0x0102000000000000000000000000000000000000000000000000000000000000.ssz")
+	require.True(t, ok)
+	assert.Equal(t, data, stored)
+
+	has, err = s.Has(ctx, State(root))
+	require.NoError(t, err)
+	assert.True(t, has)
+
+	has, err = s.Has(ctx, Block(root))
+	require.NoError(t, err)
+	assert.False(t, has, "objects of different kinds with the same root are distinct")
+
+	info, got, err := s.Get(ctx, State(root))
+	require.NoError(t, err)
+	assert.Equal(t, spec.DataVersionDeneb, info.Version)
+	assert.Equal(t, etag, info.ETag)
+	assert.Equal(t, data, got)
+
+	assert.Equal(t, "s3://checkpointz/testnet", s.Name())
+}
+
+func TestStoreRoundTripsUnversionedObjects(t *testing.T) {
+	objects := beacontest.NewObjectStore()
+	t.Cleanup(objects.Close)
+
+	s := newTestStore(t, objects, nil)
+	ctx := context.Background()
+
+	data := []byte("not really a deposit snapshot")
+	_, err := s.Put(ctx, DepositSnapshot(42), spec.DataVersionUnknown, data)
+	require.NoError(t, err)
+
+	stored, ok := objects.Object("checkpointz", "testnet/deposit_snapshots/42.ssz")
+	require.True(t, ok)
+	assert.Equal(t, data, stored)
+
+	info, got, err := s.Get(ctx, DepositSnapshot(42))
+	require.NoError(t, err)
+	assert.Equal(t, spec.DataVersionUnknown, info.Version)
+	assert.Equal(t, data, got)
+}
+
+func TestStoreStateURL(t *testing.T) {
+	objects := beacontest.NewObjectStore()
+	t.Cleanup(objects.Close)
+
+	ctx := context.Background()
+	stored := phase0.Root{0x01}
+	missing := phase0.Root{0x02}
+
+	etag, err := newTestStore(t, objects, nil).Put(ctx, State(stored), spec.DataVersionDeneb, []byte("state"))
+	require.NoError(t, err)
+
+	t.Run("serve", func(t *testing.T) {
+		location, err := newTestStore(t, objects, nil).StateURL(ctx, stored, etag)
+		require.NoError(t, err)
+		assert.Empty(t, location)
+	})
+
+	t.Run("redirect", func(t *testing.T) {
+		s := newTestStore(t, objects, func(c *Config) {
+			c.StateDownloads = StateDownloadsRedirect
+			c.PublicURL = "https://states.example.com/"
+		})
+
+		location, err := s.StateURL(ctx, stored, etag)
+		require.NoError(t, err)
+		assert.Equal(t, "https://states.example.com/testnet/states/0x0100000000000000000000000000000000000000000000000000000000000000.ssz", location)
+
+		location, err = s.StateURL(ctx, missing, etag)
+		require.NoError(t, err)
+		assert.Empty(t, location)
+
+		location, err = s.StateURL(ctx, stored, "")
+		require.NoError(t, err)
+		assert.Empty(t, location, "a copy that wasn't checked isn't redirected to")
+	})
+
+	t.Run("presign", func(t *testing.T) {
+		s := newTestStore(t, objects, func(c *Config) {
+			c.StateDownloads = StateDownloadsPresign
+		})
+
+		location, err := s.StateURL(ctx, stored, etag)
+		require.NoError(t, err)
+
+		u, err := url.Parse(location)
+		require.NoError(t, err)
+		assert.Equal(t, "/checkpointz/testnet/states/0x0100000000000000000000000000000000000000000000000000000000000000.ssz", u.Path)
+		assert.Equal(t, "900", u.Query().Get("X-Amz-Expires"))
+
+		rsp, err := http.Get(location)
+		require.NoError(t, err)
+		rsp.Body.Close()
+		assert.Equal(t, http.StatusOK, rsp.StatusCode)
+	})
+
+	t.Run("overwritten", func(t *testing.T) {
+		s := newTestStore(t, objects, func(c *Config) {
+			c.StateDownloads = StateDownloadsPresign
+		})
+
+		_, err := s.Put(ctx, State(stored), spec.DataVersionDeneb, []byte("something else"))
+		require.NoError(t, err)
+
+		location, err := s.StateURL(ctx, stored, etag)
+		require.NoError(t, err)
+		assert.Empty(t, location, "the object no longer holds the state that was checked")
+	})
+}
+
+func TestConfigValidate(t *testing.T) {
+	valid := Config{
+		Enabled:        true,
+		Endpoint:       "http://localhost:9000",
+		Bucket:         "checkpointz",
+		StateDownloads: StateDownloadsServe,
+		PresignExpiry:  time.Minute,
+	}
+
+	require.NoError(t, valid.Validate())
+	require.NoError(t, (&Config{}).Validate(), "disabled storage isn't validated")
+
+	for name, mutate := range map[string]func(*Config){
+		"no endpoint":            func(c *Config) { c.Endpoint = "" },
+		"no scheme":              func(c *Config) { c.Endpoint = "localhost:9000" },
+		"no bucket":              func(c *Config) { c.Bucket = "" },
+		"redirect without url":   func(c *Config) { c.StateDownloads = StateDownloadsRedirect },
+		"unknown downloads":      func(c *Config) { c.StateDownloads = "proxy" },
+		"presign over a week":    func(c *Config) { c.StateDownloads, c.PresignExpiry = StateDownloadsPresign, 8*24*time.Hour },
+		"presign without expiry": func(c *Config) { c.StateDownloads, c.PresignExpiry = StateDownloadsPresign, 0 },
+	} {
+		t.Run(name, func(t *testing.T) {
+			config := valid
+			mutate(&config)
+
+			assert.Error(t, config.Validate())
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package store
+
+import (
+	"github.com/attestantio/go-eth2-client/spec"
+	"github.com/attestantio/go-eth2-client/spec/deneb"
+	"github.com/ethpandaops/beacon/pkg/beacon/api/types"
+	"github.com/ethpandaops/checkpointz/pkg/beacon/fulu"
+)
+
+// The functions in this file estimate the memory cost of cached items by their SSZ size, which is a close
+// approximation of their in-memory footprint. Sizes are based on the mainnet preset.
+
+type sszSizer interface {
+	SizeSSZ() int
+}
+
+func sizeOf(s sszSizer) int64 {
+	return int64(s.SizeSSZ())
+}
+
+func blockSize(block *spec.VersionedSignedBeaconBlock) int64 {
+	switch {
+	case block == nil:
+		return 0
+	case block.Phase0 != nil:
+		return sizeOf(block.Phase0)
+	case block.Altair != nil:
+		return sizeOf(block.Altair)
+	case block.Bellatrix != nil:
+		return sizeOf(block.Bellatrix)
+	case block.Capella != nil:
+		return sizeOf(block.Capella)
+	case block.Deneb != nil:
+		return sizeOf(block.Deneb)
+	case block.Electra != nil:
+		return sizeOf(block.Electra)
+	case block.Fulu != nil:
+		return sizeOf(block.Fulu)
+	default:
+		return 0
+	}
+}
+
+func stateSize(state *spec.VersionedBeaconState) int64 {
+	switch {
+	case state == nil:
+		return 0
+	case state.Phase0 != nil:
+		return sizeOf(state.Phase0)
+	case state.Altair != nil:
+		return sizeOf(state.Altair)
+	case state.Bellatrix != nil:
+		return sizeOf(state.Bellatrix)
+	case state.Capella != nil:
+		return sizeOf(state.Capella)
+	case state.Deneb != nil:
+		return sizeOf(state.Deneb)
+	case state.Electra != nil:
+		return sizeOf(state.Electra)
+	case state.Fulu != nil:
+		return sizeOf(state.Fulu)
+	default:
+		return 0
+	}
+}
+
+func blobSidecarsSize(sidecars []*deneb.BlobSidecar) int64 {
+	var size int64
+
+	for _, sidecar := range sidecars {
+		if sidecar != nil {
+			size += sizeOf(sidecar)
+		}
+	}
+
+	return size
+}
+
+func dataColumnSidecarsSize(sidecars []*fulu.DataColumnSidecar) int64 {
+	var size int64
+
+	for _, sidecar := range sidecars {
+		if sidecar != nil {
+			size += sizeOf(sidecar)
+		}
+	}
+
+	return size
+}
+
+func depositSnapshotSize(snapshot *types.DepositSnapshot) int64 {
+	if snapshot == nil {
+		return 0
+	}
+
+	// finalized roots, deposit root, deposit count, execution block hash and height.
+	return int64(len(snapshot.Finalized)*32 + 32 + 8 + 32 + 8)
+}
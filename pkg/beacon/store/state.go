@@ -2,39 +2,53 @@ package store
 
 import (
 	"errors"
+	"sync"
 	"time"
 
 	"github.com/attestantio/go-eth2-client/spec"
 	"github.com/attestantio/go-eth2-client/spec/phase0"
 	"github.com/ethpandaops/checkpointz/pkg/cache"
+	"github.com/ethpandaops/checkpointz/pkg/clock"
 	"github.com/ethpandaops/checkpointz/pkg/eth"
 	"github.com/sirupsen/logrus"
 )
 
 type BeaconState struct {
-	store *cache.TTLMap
+	store *Indexed[phase0.Root, *stateEntry]
 	log   logrus.FieldLogger
 }
 
-func NewBeaconState(log logrus.FieldLogger, config Config, namespace string) *BeaconState {
+// stateEntry is a stored beacon state alongside where it came from.
+type stateEntry struct {
+	state *spec.VersionedBeaconState
+
+	mu         sync.RWMutex
+	provenance *Provenance
+}
+
+func NewBeaconState(log logrus.FieldLogger, config Config, namespace string, budget *cache.Budget, clk clock.Clock) *BeaconState {
 	c := &BeaconState{
-		log:   log.WithField("component", "beacon/store/beacon_state"),
-		store: cache.NewTTLMap(config.MaxItems, "state", namespace),
+		log: log.WithField("component", "beacon/store/beacon_state"),
 	}
 
-	c.store.OnItemDeleted(func(key string, value interface{}, expiredAt time.Time) {
+	c.store = NewIndexed[phase0.Root, *stateEntry](c.log, config, "state", namespace, budget, clk, eth.RootAsString)
+
+	c.store.OnItemDeleted(func(key string, _ *stateEntry, expiredAt time.Time) {
 		c.log.WithField("state_root", key).WithField("expired_at", expiredAt.String()).Debug("State was deleted from the cache")
 	})
 
-	c.store.EnableMetrics(namespace)
-
 	return c
 }
 
 func (c *BeaconState) Add(stateRoot phase0.Root, state *spec.VersionedBeaconState, expiresAt time.Time, slot phase0.Slot) error {
-	invincible := slot == 0
+	priority := cache.PriorityNormal
+	if slot == 0 {
+		priority = cache.PriorityPinned
+	}
 
-	c.store.Add(eth.RootAsString(stateRoot), state, expiresAt, invincible)
+	if err := c.store.Add(stateRoot, &stateEntry{state: state}, expiresAt, stateSize(state), priority); err != nil {
+		return err
+	}
 
 	c.log.WithFields(
 		logrus.Fields{
@@ -47,19 +61,57 @@ func (c *BeaconState) Add(stateRoot phase0.Root, state *spec.VersionedBeaconStat
 }
 
 func (c *BeaconState) GetByStateRoot(stateRoot phase0.Root) (*spec.VersionedBeaconState, error) {
-	data, _, err := c.store.Get(eth.RootAsString(stateRoot))
+	entry, err := c.store.Get(stateRoot)
 	if err != nil {
 		return nil, err
 	}
 
-	return c.parseState(data)
+	return entry.state, nil
+}
+
+// SetProvenance records where the state with the given root came from.
+func (c *BeaconState) SetProvenance(stateRoot phase0.Root, provenance *Provenance) error {
+	entry, err := c.store.Get(stateRoot)
+	if err != nil {
+		return err
+	}
+
+	entry.mu.Lock()
+	defer entry.mu.Unlock()
+
+	entry.provenance = provenance.Copy()
+
+	return nil
 }
 
-func (c *BeaconState) parseState(data interface{}) (*spec.VersionedBeaconState, error) {
-	state, ok := data.(*spec.VersionedBeaconState)
-	if !ok {
-		return nil, errors.New("invalid state")
+// GetProvenance returns where the state with the given root came from.
+func (c *BeaconState) GetProvenance(stateRoot phase0.Root) (*Provenance, error) {
+	entry, err := c.store.Get(stateRoot)
+	if err != nil {
+		return nil, err
+	}
+
+	entry.mu.RLock()
+	defer entry.mu.RUnlock()
+
+	if entry.provenance == nil {
+		return nil, errors.New("provenance not found")
 	}
 
-	return state, nil
+	return entry.provenance.Copy(), nil
+}
+
+// Pin prevents the state with the given root from expiring or being evicted.
+func (c *BeaconState) Pin(stateRoot phase0.Root) error {
+	return c.store.SetPriority(stateRoot, cache.PriorityPinned)
+}
+
+// Unpin allows the state with the given root to expire or be evicted again.
+func (c *BeaconState) Unpin(stateRoot phase0.Root) error {
+	return c.store.SetPriority(stateRoot, cache.PriorityNormal)
+}
+
+// Stop stops the state store's background expiry.
+func (c *BeaconState) Stop() {
+	c.store.Stop()
 }
@@ -0,0 +1,93 @@
+package store
+
+import (
+	"testing"
+	"time"
+
+	"github.com/attestantio/go-eth2-client/spec"
+	"github.com/attestantio/go-eth2-client/spec/deneb"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/ethpandaops/checkpointz/pkg/clock"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBeaconStateAddAndGet(t *testing.T) {
+	logger, _ := test.NewNullLogger()
+	states := NewBeaconState(logger, Config{MaxItems: 10}, "test_state_a", nil, clock.New())
+
+	stateRoot := phase0.Root{0x01}
+	state := &spec.VersionedBeaconState{
+		Version: spec.DataVersionDeneb,
+		Deneb:   &deneb.BeaconState{Slot: 64},
+	}
+
+	require.NoError(t, states.Add(stateRoot, state, time.Now().Add(time.Hour), 64))
+
+	got, err := states.GetByStateRoot(stateRoot)
+	require.NoError(t, err)
+	assert.Equal(t, state, got)
+
+	_, err = states.GetByStateRoot(phase0.Root{0x02})
+	assert.Error(t, err)
+}
+
+func TestBeaconStatePinSurvivesExpiry(t *testing.T) {
+	logger, _ := test.NewNullLogger()
+	states := NewBeaconState(logger, Config{MaxItems: 10}, "test_state_b", nil, clock.New())
+
+	stateRoot := phase0.Root{0x01}
+	state := &spec.VersionedBeaconState{
+		Version: spec.DataVersionDeneb,
+		Deneb:   &deneb.BeaconState{Slot: 64},
+	}
+
+	require.NoError(t, states.Add(stateRoot, state, time.Now().Add(time.Second), 64))
+	require.NoError(t, states.Pin(stateRoot))
+
+	time.Sleep(2 * time.Second)
+
+	_, err := states.GetByStateRoot(stateRoot)
+	assert.NoError(t, err)
+}
+
+func TestBeaconStateProvenance(t *testing.T) {
+	logger, _ := test.NewNullLogger()
+	states := NewBeaconState(logger, Config{MaxItems: 10}, "test_state_c", nil, clock.New())
+
+	stateRoot := phase0.Root{0x01}
+	state := &spec.VersionedBeaconState{
+		Version: spec.DataVersionDeneb,
+		Deneb:   &deneb.BeaconState{Slot: 64},
+	}
+
+	assert.Error(t, states.SetProvenance(stateRoot, &Provenance{}), "provenance can't be set for a missing state")
+
+	require.NoError(t, states.Add(stateRoot, state, time.Now().Add(time.Hour), 64))
+
+	_, err := states.GetProvenance(stateRoot)
+	assert.Error(t, err)
+
+	verifiedAt := time.Now()
+	provenance := &Provenance{
+		FinalizedEpoch:    2,
+		FinalizedRoot:     phase0.Root{0x02},
+		AgreeingUpstreams: []string{"a", "b"},
+		Agreement:         2.0 / 3.0,
+		Source:            "a",
+		FetchedAt:         verifiedAt.Add(-time.Second),
+		VerifiedAt:        &verifiedAt,
+	}
+
+	require.NoError(t, states.SetProvenance(stateRoot, provenance))
+
+	// The store keeps its own copy.
+	provenance.AgreeingUpstreams[0] = "c"
+
+	got, err := states.GetProvenance(stateRoot)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "b"}, got.AgreeingUpstreams)
+	assert.Equal(t, "a", got.Source)
+	assert.Equal(t, verifiedAt, *got.VerifiedAt)
+}
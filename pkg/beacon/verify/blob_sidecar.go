@@ -0,0 +1,163 @@
+package verify
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"math/bits"
+
+	"github.com/attestantio/go-eth2-client/spec"
+	"github.com/attestantio/go-eth2-client/spec/deneb"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+)
+
+const (
+	// blobKZGCommitmentsFieldIndex is the index of blob_kzg_commitments in BeaconBlockBody (deneb onwards).
+	blobKZGCommitmentsFieldIndex = 11
+	// beaconBlockBodyDepth is the depth of the BeaconBlockBody container tree (deneb onwards, <= 16 fields).
+	beaconBlockBodyDepth = 4
+	// defaultMaxBlobCommitmentsPerBlock is MAX_BLOB_COMMITMENTS_PER_BLOCK for the mainnet preset.
+	defaultMaxBlobCommitmentsPerBlock = 4096
+)
+
+var (
+	ErrInclusionProofInvalid = errors.New("kzg commitment inclusion proof is invalid")
+	ErrKZGProofInvalid       = errors.New("kzg proof is invalid")
+)
+
+// BlobSidecars verifies a full set of blob sidecars against the block they belong to. Every sidecar must
+// commit to the block's body root via a valid inclusion proof and carry a valid KZG proof for its blob.
+// maxBlobCommitmentsPerBlock is the MAX_BLOB_COMMITMENTS_PER_BLOCK preset value; 0 uses the mainnet preset.
+func BlobSidecars(block *spec.VersionedSignedBeaconBlock, sidecars []*deneb.BlobSidecar, maxBlobCommitmentsPerBlock uint64) error {
+	if block == nil {
+		return errors.New("block is nil")
+	}
+
+	bodyRoot, err := block.BodyRoot()
+	if err != nil {
+		return fmt.Errorf("failed to get block body root: %w", err)
+	}
+
+	commitments, err := block.BlobKZGCommitments()
+	if err != nil {
+		return fmt.Errorf("failed to get blob kzg commitments: %w", err)
+	}
+
+	if len(sidecars) != len(commitments) {
+		return fmt.Errorf("expected %d blob sidecars, got %d", len(commitments), len(sidecars))
+	}
+
+	seen := make(map[deneb.BlobIndex]struct{}, len(sidecars))
+
+	for _, sidecar := range sidecars {
+		if sidecar == nil {
+			return errors.New("blob sidecar is nil")
+		}
+
+		if _, exists := seen[sidecar.Index]; exists {
+			return fmt.Errorf("duplicate blob sidecar index %d", sidecar.Index)
+		}
+
+		seen[sidecar.Index] = struct{}{}
+
+		if uint64(sidecar.Index) >= uint64(len(commitments)) {
+			return fmt.Errorf("blob sidecar index %d out of range", sidecar.Index)
+		}
+
+		if sidecar.KZGCommitment != commitments[sidecar.Index] {
+			return fmt.Errorf("blob sidecar %d commitment does not match the block", sidecar.Index)
+		}
+
+		if sidecar.SignedBlockHeader == nil || sidecar.SignedBlockHeader.Message == nil {
+			return fmt.Errorf("blob sidecar %d has no block header", sidecar.Index)
+		}
+
+		if sidecar.SignedBlockHeader.Message.BodyRoot != bodyRoot {
+			return fmt.Errorf("blob sidecar %d header body root does not match the block", sidecar.Index)
+		}
+
+		if err := BlobSidecarInclusionProof(sidecar, bodyRoot, maxBlobCommitmentsPerBlock); err != nil {
+			return fmt.Errorf("blob sidecar %d: %w", sidecar.Index, err)
+		}
+
+		if err := BlobSidecarKZGProof(sidecar); err != nil {
+			return fmt.Errorf("blob sidecar %d: %w", sidecar.Index, err)
+		}
+	}
+
+	return nil
+}
+
+// BlobSidecarInclusionProof verifies the sidecar's KZG commitment is included in the given block body root.
+func BlobSidecarInclusionProof(sidecar *deneb.BlobSidecar, bodyRoot phase0.Root, maxBlobCommitmentsPerBlock uint64) error {
+	if maxBlobCommitmentsPerBlock == 0 {
+		maxBlobCommitmentsPerBlock = defaultMaxBlobCommitmentsPerBlock
+	}
+
+	// The commitment list is merkleized to a depth of ceil(log2(max)), with the length mixed in one level above.
+	listDepth := bits.Len64(maxBlobCommitmentsPerBlock - 1)
+	depth := beaconBlockBodyDepth + 1 + listDepth
+
+	if depth > len(sidecar.KZGCommitmentInclusionProof) {
+		return fmt.Errorf("inclusion proof depth %d exceeds proof length %d", depth, len(sidecar.KZGCommitmentInclusionProof))
+	}
+
+	// get_subtree_index(get_generalized_index(BeaconBlockBody, 'blob_kzg_commitments', index))
+	subtreeIndex := uint64(blobKZGCommitmentsFieldIndex)<<(1+listDepth) | uint64(sidecar.Index)
+
+	branch := make([][32]byte, depth)
+	for i := range branch {
+		branch[i] = sidecar.KZGCommitmentInclusionProof[i]
+	}
+
+	if !IsValidMerkleBranch(commitmentRoot(sidecar.KZGCommitment), branch, subtreeIndex, bodyRoot) {
+		return ErrInclusionProofInvalid
+	}
+
+	return nil
+}
+
+// BlobSidecarKZGProof verifies the sidecar's KZG proof for its blob and commitment.
+func BlobSidecarKZGProof(sidecar *deneb.BlobSidecar) error {
+	blob := kzg4844.Blob(sidecar.Blob)
+
+	if err := kzg4844.VerifyBlobProof(&blob, kzg4844.Commitment(sidecar.KZGCommitment), kzg4844.Proof(sidecar.KZGProof)); err != nil {
+		return fmt.Errorf("%w: %s", ErrKZGProofInvalid, err.Error())
+	}
+
+	return nil
+}
+
+// IsValidMerkleBranch implements is_valid_merkle_branch from the consensus specs.
+func IsValidMerkleBranch(leaf [32]byte, branch [][32]byte, index uint64, root phase0.Root) bool {
+	value := leaf
+
+	for i, node := range branch {
+		if (index>>uint(i))&1 == 1 { //nolint:gosec // i is bounded by the branch length
+			value = hashPair(node, value)
+		} else {
+			value = hashPair(value, node)
+		}
+	}
+
+	return value == root
+}
+
+// commitmentRoot returns hash_tree_root(KZGCommitment), a Bytes48 packed into two chunks.
+func commitmentRoot(commitment deneb.KZGCommitment) [32]byte {
+	var chunks [64]byte
+
+	copy(chunks[:], commitment[:])
+
+	return sha256.Sum256(chunks[:])
+}
+
+func hashPair(left, right [32]byte) [32]byte {
+	var data [64]byte
+
+	copy(data[:32], left[:])
+	copy(data[32:], right[:])
+
+	return sha256.Sum256(data[:])
+}
@@ -0,0 +1,146 @@
+package verify
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/attestantio/go-eth2-client/spec"
+	"github.com/attestantio/go-eth2-client/spec/altair"
+	"github.com/attestantio/go-eth2-client/spec/bellatrix"
+	"github.com/attestantio/go-eth2-client/spec/deneb"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+	"github.com/holiman/uint256"
+)
+
+// testBlock builds a deneb block carrying one blob, returning the block and a matching sidecar.
+func testBlock(t *testing.T) (*spec.VersionedSignedBeaconBlock, *deneb.BlobSidecar) {
+	t.Helper()
+
+	blob := &kzg4844.Blob{}
+	blob[1] = 0x01
+	blob[32*7+5] = 0xaa
+
+	commitment, err := kzg4844.BlobToCommitment(blob)
+	if err != nil {
+		t.Fatalf("failed to compute commitment: %v", err)
+	}
+
+	proof, err := kzg4844.ComputeBlobProof(blob, commitment)
+	if err != nil {
+		t.Fatalf("failed to compute proof: %v", err)
+	}
+
+	body := &deneb.BeaconBlockBody{
+		ETH1Data:          &phase0.ETH1Data{BlockHash: make([]byte, 32)},
+		ProposerSlashings: []*phase0.ProposerSlashing{},
+		AttesterSlashings: []*phase0.AttesterSlashing{},
+		Attestations:      []*phase0.Attestation{},
+		Deposits:          []*phase0.Deposit{},
+		VoluntaryExits:    []*phase0.SignedVoluntaryExit{},
+		SyncAggregate: &altair.SyncAggregate{
+			SyncCommitteeBits: make([]byte, 64),
+		},
+		ExecutionPayload: &deneb.ExecutionPayload{
+			LogsBloom:     [256]byte{},
+			BaseFeePerGas: uint256.NewInt(0),
+			Transactions:  []bellatrix.Transaction{},
+			Withdrawals:   nil,
+		},
+		BLSToExecutionChanges: nil,
+		BlobKZGCommitments:    []deneb.KZGCommitment{deneb.KZGCommitment(commitment)},
+	}
+
+	bodyRoot, err := body.HashTreeRoot()
+	if err != nil {
+		t.Fatalf("failed to compute body root: %v", err)
+	}
+
+	tree, err := body.GetTree()
+	if err != nil {
+		t.Fatalf("failed to get body tree: %v", err)
+	}
+
+	// Generalized index of blob_kzg_commitments[0] within the body with mainnet presets.
+	gindex := ((16 + blobKZGCommitmentsFieldIndex) * 2) * defaultMaxBlobCommitmentsPerBlock
+
+	merkleProof, err := tree.Prove(gindex)
+	if err != nil {
+		t.Fatalf("failed to prove commitment: %v", err)
+	}
+
+	sidecar := &deneb.BlobSidecar{
+		Index:         0,
+		Blob:          deneb.Blob(*blob),
+		KZGCommitment: deneb.KZGCommitment(commitment),
+		KZGProof:      deneb.KZGProof(proof),
+		SignedBlockHeader: &phase0.SignedBeaconBlockHeader{
+			Message: &phase0.BeaconBlockHeader{BodyRoot: bodyRoot},
+		},
+	}
+
+	if len(merkleProof.Hashes) != len(sidecar.KZGCommitmentInclusionProof) {
+		t.Fatalf("unexpected proof length %d", len(merkleProof.Hashes))
+	}
+
+	for i, hash := range merkleProof.Hashes {
+		copy(sidecar.KZGCommitmentInclusionProof[i][:], hash)
+	}
+
+	block := &spec.VersionedSignedBeaconBlock{
+		Version: spec.DataVersionDeneb,
+		Deneb: &deneb.SignedBeaconBlock{
+			Message: &deneb.BeaconBlock{Body: body},
+		},
+	}
+
+	return block, sidecar
+}
+
+func TestBlobSidecarsValid(t *testing.T) {
+	block, sidecar := testBlock(t)
+
+	if err := BlobSidecars(block, []*deneb.BlobSidecar{sidecar}, 0); err != nil {
+		t.Fatalf("expected sidecars to verify: %v", err)
+	}
+}
+
+func TestBlobSidecarsInvalidInclusionProof(t *testing.T) {
+	block, sidecar := testBlock(t)
+
+	sidecar.KZGCommitmentInclusionProof[3][0] ^= 0xff
+
+	err := BlobSidecars(block, []*deneb.BlobSidecar{sidecar}, 0)
+	if !errors.Is(err, ErrInclusionProofInvalid) {
+		t.Fatalf("expected inclusion proof error, got %v", err)
+	}
+}
+
+func TestBlobSidecarsInvalidKZGProof(t *testing.T) {
+	block, sidecar := testBlock(t)
+
+	sidecar.Blob[33] = 0x02
+
+	err := BlobSidecars(block, []*deneb.BlobSidecar{sidecar}, 0)
+	if !errors.Is(err, ErrKZGProofInvalid) {
+		t.Fatalf("expected kzg proof error, got %v", err)
+	}
+}
+
+func TestBlobSidecarsMismatchedCount(t *testing.T) {
+	block, _ := testBlock(t)
+
+	if err := BlobSidecars(block, []*deneb.BlobSidecar{}, 0); err == nil {
+		t.Fatal("expected error for missing sidecars")
+	}
+}
+
+func TestBlobSidecarsBodyRootMismatch(t *testing.T) {
+	block, sidecar := testBlock(t)
+
+	sidecar.SignedBlockHeader.Message.BodyRoot[0] ^= 0xff
+
+	if err := BlobSidecars(block, []*deneb.BlobSidecar{sidecar}, 0); err == nil {
+		t.Fatal("expected error for mismatched body root")
+	}
+}
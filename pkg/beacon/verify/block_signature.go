@@ -0,0 +1,173 @@
+package verify
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/attestantio/go-eth2-client/spec"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/ethpandaops/checkpointz/pkg/eth"
+	blst "github.com/supranational/blst/bindings/go"
+)
+
+// Status describes whether a block's signature was verified before it was stored.
+type Status string
+
+const (
+	// StatusVerified means the block's proposer signature was checked against a beacon state.
+	StatusVerified Status = "verified"
+	// StatusUnverified means verification is enabled but no state was available to verify against.
+	StatusUnverified Status = "unverified"
+	// StatusDisabled means block signature verification is turned off.
+	StatusDisabled Status = "disabled"
+)
+
+// blsSignatureDST is the ciphersuite used by the consensus layer for BLS signatures.
+var blsSignatureDST = []byte("BLS_SIG_BLS12381G2_XMD:SHA-256_SSWU_RO_POP_")
+
+// domainBeaconProposer is DOMAIN_BEACON_PROPOSER.
+var domainBeaconProposer = phase0.DomainType{0x00, 0x00, 0x00, 0x00}
+
+var ErrBlockSignatureInvalid = errors.New("block signature is invalid")
+
+// BlockSignature verifies the block's signature against its proposer's public key and the
+// DOMAIN_BEACON_PROPOSER domain, both taken from the given state. The state must be from the same fork
+// as the block (typically the block's own post-state). blockRoot is the hash tree root of the block message.
+func BlockSignature(block *spec.VersionedSignedBeaconBlock, blockRoot phase0.Root, beaconState *spec.VersionedBeaconState, slotsPerEpoch uint64) error {
+	if block == nil {
+		return errors.New("block is nil")
+	}
+
+	if slotsPerEpoch == 0 {
+		return errors.New("slots per epoch is zero")
+	}
+
+	slot, err := block.Slot()
+	if err != nil {
+		return fmt.Errorf("failed to get block slot: %w", err)
+	}
+
+	proposerIndex, err := block.ProposerIndex()
+	if err != nil {
+		return fmt.Errorf("failed to get block proposer index: %w", err)
+	}
+
+	signature, err := blockSignature(block)
+	if err != nil {
+		return err
+	}
+
+	proposer, err := beaconState.ValidatorAtIndex(proposerIndex)
+	if err != nil {
+		return fmt.Errorf("failed to get proposer %d from state: %w", proposerIndex, err)
+	}
+
+	fork, genesisValidatorsRoot, err := eth.ForkFromState(beaconState)
+	if err != nil {
+		return fmt.Errorf("failed to get fork from state: %w", err)
+	}
+
+	domain, err := ComputeDomain(domainBeaconProposer, fork, phase0.Epoch(uint64(slot)/slotsPerEpoch), genesisValidatorsRoot)
+	if err != nil {
+		return err
+	}
+
+	signingRoot, err := (&phase0.SigningData{ObjectRoot: blockRoot, Domain: domain}).HashTreeRoot()
+	if err != nil {
+		return fmt.Errorf("failed to compute signing root: %w", err)
+	}
+
+	return VerifySignature(proposer.PublicKey, signingRoot[:], signature)
+}
+
+// ComputeDomain returns the signing domain for the given domain type at the given epoch.
+func ComputeDomain(domainType phase0.DomainType, fork *phase0.Fork, epoch phase0.Epoch, genesisValidatorsRoot phase0.Root) (phase0.Domain, error) {
+	if fork == nil {
+		return phase0.Domain{}, errors.New("fork is nil")
+	}
+
+	version := fork.CurrentVersion
+	if epoch < fork.Epoch {
+		version = fork.PreviousVersion
+	}
+
+	forkDataRoot, err := (&phase0.ForkData{CurrentVersion: version, GenesisValidatorsRoot: genesisValidatorsRoot}).HashTreeRoot()
+	if err != nil {
+		return phase0.Domain{}, fmt.Errorf("failed to compute fork data root: %w", err)
+	}
+
+	var domain phase0.Domain
+
+	copy(domain[:4], domainType[:])
+	copy(domain[4:], forkDataRoot[:28])
+
+	return domain, nil
+}
+
+// VerifySignature verifies a BLS signature over msg by the given public key.
+func VerifySignature(pubkey phase0.BLSPubKey, msg []byte, signature phase0.BLSSignature) error {
+	pk := new(blst.P1Affine).Uncompress(pubkey[:])
+	if pk == nil {
+		return errors.New("invalid public key")
+	}
+
+	sig := new(blst.P2Affine).Uncompress(signature[:])
+	if sig == nil {
+		return errors.New("invalid signature encoding")
+	}
+
+	if !sig.Verify(true, pk, true, msg, blsSignatureDST) {
+		return ErrBlockSignatureInvalid
+	}
+
+	return nil
+}
+
+func blockSignature(block *spec.VersionedSignedBeaconBlock) (phase0.BLSSignature, error) {
+	switch block.Version {
+	case spec.DataVersionPhase0:
+		if block.Phase0 == nil {
+			return phase0.BLSSignature{}, errors.New("no phase0 block")
+		}
+
+		return block.Phase0.Signature, nil
+	case spec.DataVersionAltair:
+		if block.Altair == nil {
+			return phase0.BLSSignature{}, errors.New("no altair block")
+		}
+
+		return block.Altair.Signature, nil
+	case spec.DataVersionBellatrix:
+		if block.Bellatrix == nil {
+			return phase0.BLSSignature{}, errors.New("no bellatrix block")
+		}
+
+		return block.Bellatrix.Signature, nil
+	case spec.DataVersionCapella:
+		if block.Capella == nil {
+			return phase0.BLSSignature{}, errors.New("no capella block")
+		}
+
+		return block.Capella.Signature, nil
+	case spec.DataVersionDeneb:
+		if block.Deneb == nil {
+			return phase0.BLSSignature{}, errors.New("no deneb block")
+		}
+
+		return block.Deneb.Signature, nil
+	case spec.DataVersionElectra:
+		if block.Electra == nil {
+			return phase0.BLSSignature{}, errors.New("no electra block")
+		}
+
+		return block.Electra.Signature, nil
+	case spec.DataVersionFulu:
+		if block.Fulu == nil {
+			return phase0.BLSSignature{}, errors.New("no fulu block")
+		}
+
+		return block.Fulu.Signature, nil
+	default:
+		return phase0.BLSSignature{}, errors.New("unknown block version")
+	}
+}
@@ -0,0 +1,131 @@
+package verify
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/attestantio/go-eth2-client/spec"
+	"github.com/attestantio/go-eth2-client/spec/deneb"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	blst "github.com/supranational/blst/bindings/go"
+)
+
+const testSlotsPerEpoch = 32
+
+func signedTestBlock(t *testing.T, signingFork *phase0.Fork) (*spec.VersionedSignedBeaconBlock, phase0.Root, *spec.VersionedBeaconState) {
+	t.Helper()
+
+	sk := blst.KeyGen(make([]byte, 32))
+	pk := new(blst.P1Affine).From(sk)
+
+	var pubkey phase0.BLSPubKey
+
+	copy(pubkey[:], pk.Compress())
+
+	genesisValidatorsRoot := phase0.Root{0x0a}
+	blockRoot := phase0.Root{0x0b}
+	slot := phase0.Slot(100)
+
+	domain, err := ComputeDomain(domainBeaconProposer, signingFork, phase0.Epoch(slot/testSlotsPerEpoch), genesisValidatorsRoot)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	signingRoot, err := (&phase0.SigningData{ObjectRoot: blockRoot, Domain: domain}).HashTreeRoot()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var signature phase0.BLSSignature
+
+	copy(signature[:], new(blst.P2Affine).Sign(sk, signingRoot[:], blsSignatureDST).Compress())
+
+	block := &spec.VersionedSignedBeaconBlock{
+		Version: spec.DataVersionDeneb,
+		Deneb: &deneb.SignedBeaconBlock{
+			Message:   &deneb.BeaconBlock{Slot: slot, ProposerIndex: 1},
+			Signature: signature,
+		},
+	}
+
+	beaconState := &spec.VersionedBeaconState{
+		Version: spec.DataVersionDeneb,
+		Deneb: &deneb.BeaconState{
+			GenesisValidatorsRoot: genesisValidatorsRoot,
+			Fork: &phase0.Fork{
+				PreviousVersion: phase0.Version{0x03},
+				CurrentVersion:  phase0.Version{0x04},
+				Epoch:           2,
+			},
+			Validators: []*phase0.Validator{
+				{PublicKey: phase0.BLSPubKey{}},
+				{PublicKey: pubkey},
+			},
+		},
+	}
+
+	return block, blockRoot, beaconState
+}
+
+func TestBlockSignatureValid(t *testing.T) {
+	block, root, beaconState := signedTestBlock(t, &phase0.Fork{CurrentVersion: phase0.Version{0x04}, Epoch: 2})
+
+	if err := BlockSignature(block, root, beaconState, testSlotsPerEpoch); err != nil {
+		t.Fatalf("expected signature to verify: %v", err)
+	}
+}
+
+func TestBlockSignatureWrongFork(t *testing.T) {
+	block, root, beaconState := signedTestBlock(t, &phase0.Fork{CurrentVersion: phase0.Version{0x03}, Epoch: 2})
+
+	err := BlockSignature(block, root, beaconState, testSlotsPerEpoch)
+	if !errors.Is(err, ErrBlockSignatureInvalid) {
+		t.Fatalf("expected invalid signature, got %v", err)
+	}
+}
+
+func TestBlockSignatureWrongRoot(t *testing.T) {
+	block, _, beaconState := signedTestBlock(t, &phase0.Fork{CurrentVersion: phase0.Version{0x04}, Epoch: 2})
+
+	err := BlockSignature(block, phase0.Root{0xff}, beaconState, testSlotsPerEpoch)
+	if !errors.Is(err, ErrBlockSignatureInvalid) {
+		t.Fatalf("expected invalid signature, got %v", err)
+	}
+}
+
+func TestBlockSignatureWrongProposer(t *testing.T) {
+	block, root, beaconState := signedTestBlock(t, &phase0.Fork{CurrentVersion: phase0.Version{0x04}, Epoch: 2})
+
+	block.Deneb.Message.ProposerIndex = 0
+
+	if err := BlockSignature(block, root, beaconState, testSlotsPerEpoch); err == nil {
+		t.Fatal("expected an error for the wrong proposer")
+	}
+}
+
+func TestComputeDomainUsesPreviousVersionBeforeForkEpoch(t *testing.T) {
+	fork := &phase0.Fork{PreviousVersion: phase0.Version{0x01}, CurrentVersion: phase0.Version{0x02}, Epoch: 10}
+
+	before, err := ComputeDomain(domainBeaconProposer, fork, 9, phase0.Root{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	previous, err := ComputeDomain(domainBeaconProposer, &phase0.Fork{CurrentVersion: phase0.Version{0x01}}, 9, phase0.Root{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if before != previous {
+		t.Errorf("domain before fork epoch = %#x, want %#x", before, previous)
+	}
+
+	after, err := ComputeDomain(domainBeaconProposer, fork, 10, phase0.Root{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if after == before {
+		t.Error("expected domain to change at the fork epoch")
+	}
+}
@@ -0,0 +1,175 @@
+package verify
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/bits"
+	"sync"
+
+	"github.com/attestantio/go-eth2-client/spec"
+	"github.com/attestantio/go-eth2-client/spec/deneb"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	goethkzg "github.com/crate-crypto/go-eth-kzg"
+	"github.com/ethpandaops/checkpointz/pkg/beacon/fulu"
+)
+
+// kzgContext loads the trusted setup the first time cell proofs are verified, as it takes a while.
+var kzgContext = sync.OnceValues(goethkzg.NewContext4096Secure)
+
+// DataColumnSidecars verifies data column sidecars against the block they belong to. Every column must hold a
+// cell of each blob the block commits to, commit to the block's body root via a valid inclusion proof, and
+// carry valid KZG proofs for its cells. Upstreams only custody some of the columns, so any subset may be given.
+// maxBlobCommitmentsPerBlock is the MAX_BLOB_COMMITMENTS_PER_BLOCK preset value; 0 uses the mainnet preset.
+func DataColumnSidecars(block *spec.VersionedSignedBeaconBlock, sidecars []*fulu.DataColumnSidecar, maxBlobCommitmentsPerBlock uint64) error {
+	if block == nil {
+		return errors.New("block is nil")
+	}
+
+	bodyRoot, err := block.BodyRoot()
+	if err != nil {
+		return fmt.Errorf("failed to get block body root: %w", err)
+	}
+
+	commitments, err := block.BlobKZGCommitments()
+	if err != nil {
+		return fmt.Errorf("failed to get blob kzg commitments: %w", err)
+	}
+
+	if len(commitments) == 0 {
+		if len(sidecars) != 0 {
+			return errors.New("block has no blobs but data column sidecars were given")
+		}
+
+		return nil
+	}
+
+	var (
+		seen        = make(map[uint64]struct{}, len(sidecars))
+		cellIndices = make([]uint64, 0, len(sidecars)*len(commitments))
+		cells       = make([]*goethkzg.Cell, 0, len(sidecars)*len(commitments))
+		cellProofs  = make([]goethkzg.KZGProof, 0, len(sidecars)*len(commitments))
+		cellCommits = make([]goethkzg.KZGCommitment, 0, len(sidecars)*len(commitments))
+	)
+
+	for _, sidecar := range sidecars {
+		if sidecar == nil {
+			return errors.New("data column sidecar is nil")
+		}
+
+		if sidecar.Index >= fulu.NumberOfColumns {
+			return fmt.Errorf("data column sidecar index %d out of range", sidecar.Index)
+		}
+
+		if _, exists := seen[sidecar.Index]; exists {
+			return fmt.Errorf("duplicate data column sidecar index %d", sidecar.Index)
+		}
+
+		seen[sidecar.Index] = struct{}{}
+
+		if len(sidecar.Column) != len(commitments) || len(sidecar.KZGCommitments) != len(commitments) || len(sidecar.KZGProofs) != len(commitments) {
+			return fmt.Errorf("data column sidecar %d has %d cells, %d commitments and %d proofs, expected %d", sidecar.Index, len(sidecar.Column), len(sidecar.KZGCommitments), len(sidecar.KZGProofs), len(commitments))
+		}
+
+		for i, commitment := range sidecar.KZGCommitments {
+			if commitment != commitments[i] {
+				return fmt.Errorf("data column sidecar %d commitment %d does not match the block", sidecar.Index, i)
+			}
+		}
+
+		if sidecar.SignedBlockHeader == nil || sidecar.SignedBlockHeader.Message == nil {
+			return fmt.Errorf("data column sidecar %d has no block header", sidecar.Index)
+		}
+
+		if sidecar.SignedBlockHeader.Message.BodyRoot != bodyRoot {
+			return fmt.Errorf("data column sidecar %d header body root does not match the block", sidecar.Index)
+		}
+
+		if err := DataColumnSidecarInclusionProof(sidecar, bodyRoot, maxBlobCommitmentsPerBlock); err != nil {
+			return fmt.Errorf("data column sidecar %d: %w", sidecar.Index, err)
+		}
+
+		for i := range sidecar.Column {
+			cellIndices = append(cellIndices, sidecar.Index)
+			cells = append(cells, (*goethkzg.Cell)(&sidecar.Column[i]))
+			cellProofs = append(cellProofs, goethkzg.KZGProof(sidecar.KZGProofs[i]))
+			cellCommits = append(cellCommits, goethkzg.KZGCommitment(sidecar.KZGCommitments[i]))
+		}
+	}
+
+	if len(cells) == 0 {
+		return nil
+	}
+
+	ctx, err := kzgContext()
+	if err != nil {
+		return fmt.Errorf("failed to load kzg trusted setup: %w", err)
+	}
+
+	// The cells of every column are verified in one batch.
+	if err := ctx.VerifyCellKZGProofBatch(cellCommits, cellIndices, cells, cellProofs); err != nil {
+		return fmt.Errorf("%w: %s", ErrKZGProofInvalid, err.Error())
+	}
+
+	return nil
+}
+
+// DataColumnSidecarInclusionProof verifies the sidecar's list of KZG commitments is included in the given block
+// body root.
+func DataColumnSidecarInclusionProof(sidecar *fulu.DataColumnSidecar, bodyRoot phase0.Root, maxBlobCommitmentsPerBlock uint64) error {
+	if maxBlobCommitmentsPerBlock == 0 {
+		maxBlobCommitmentsPerBlock = defaultMaxBlobCommitmentsPerBlock
+	}
+
+	branch := make([][32]byte, len(sidecar.KZGCommitmentsInclusionProof))
+	for i := range branch {
+		branch[i] = sidecar.KZGCommitmentsInclusionProof[i]
+	}
+
+	// get_subtree_index(get_generalized_index(BeaconBlockBody, 'blob_kzg_commitments'))
+	if !IsValidMerkleBranch(commitmentsRoot(sidecar.KZGCommitments, maxBlobCommitmentsPerBlock), branch, blobKZGCommitmentsFieldIndex, bodyRoot) {
+		return ErrInclusionProofInvalid
+	}
+
+	return nil
+}
+
+// commitmentsRoot returns hash_tree_root(List[KZGCommitment, limit]).
+func commitmentsRoot(commitments []deneb.KZGCommitment, limit uint64) [32]byte {
+	depth := bits.Len64(limit - 1)
+
+	layer := make([][32]byte, len(commitments))
+	for i, commitment := range commitments {
+		layer[i] = commitmentRoot(commitment)
+	}
+
+	// Missing leaves are zero, so each level is padded with the root of an empty subtree of its height.
+	var zero [32]byte
+
+	for range depth {
+		next := make([][32]byte, (len(layer)+1)/2)
+
+		for i := range next {
+			right := zero
+			if 2*i+1 < len(layer) {
+				right = layer[2*i+1]
+			}
+
+			next[i] = hashPair(layer[2*i], right)
+		}
+
+		layer = next
+		zero = hashPair(zero, zero)
+	}
+
+	root := zero
+	if len(layer) > 0 {
+		root = layer[0]
+	}
+
+	var length [32]byte
+
+	binary.LittleEndian.PutUint64(length[:8], uint64(len(commitments)))
+
+	return hashPair(root, length)
+}
@@ -0,0 +1,115 @@
+package verify
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/attestantio/go-eth2-client/spec"
+	"github.com/attestantio/go-eth2-client/spec/deneb"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	goethkzg "github.com/crate-crypto/go-eth-kzg"
+	"github.com/ethpandaops/checkpointz/pkg/beacon/fulu"
+)
+
+// testColumns builds the columns at indices for the single blob of the block from testBlock.
+func testColumns(t *testing.T, indices ...uint64) (*spec.VersionedSignedBeaconBlock, []*fulu.DataColumnSidecar) {
+	t.Helper()
+
+	block, blobSidecar := testBlock(t)
+
+	ctx, err := kzgContext()
+	if err != nil {
+		t.Fatalf("failed to load trusted setup: %v", err)
+	}
+
+	blob := goethkzg.Blob(blobSidecar.Blob)
+
+	cells, proofs, err := ctx.ComputeCellsAndKZGProofs(&blob, 0)
+	if err != nil {
+		t.Fatalf("failed to compute cells: %v", err)
+	}
+
+	tree, err := block.Deneb.Message.Body.GetTree()
+	if err != nil {
+		t.Fatalf("failed to get body tree: %v", err)
+	}
+
+	// Generalized index of blob_kzg_commitments within the body.
+	merkleProof, err := tree.Prove(16 + blobKZGCommitmentsFieldIndex)
+	if err != nil {
+		t.Fatalf("failed to prove commitments: %v", err)
+	}
+
+	var inclusionProof [fulu.KZGCommitmentsInclusionProofDepth]phase0.Root
+
+	if len(merkleProof.Hashes) != len(inclusionProof) {
+		t.Fatalf("unexpected proof length %d", len(merkleProof.Hashes))
+	}
+
+	for i, hash := range merkleProof.Hashes {
+		copy(inclusionProof[i][:], hash)
+	}
+
+	columns := make([]*fulu.DataColumnSidecar, 0, len(indices))
+
+	for _, index := range indices {
+		columns = append(columns, &fulu.DataColumnSidecar{
+			Index:                        index,
+			Column:                       []fulu.Cell{fulu.Cell(*cells[index])},
+			KZGCommitments:               []deneb.KZGCommitment{blobSidecar.KZGCommitment},
+			KZGProofs:                    []deneb.KZGProof{deneb.KZGProof(proofs[index])},
+			SignedBlockHeader:            blobSidecar.SignedBlockHeader,
+			KZGCommitmentsInclusionProof: inclusionProof,
+		})
+	}
+
+	return block, columns
+}
+
+func TestDataColumnSidecarsValid(t *testing.T) {
+	block, columns := testColumns(t, 0, 5, 127)
+
+	if err := DataColumnSidecars(block, columns, 0); err != nil {
+		t.Fatalf("expected columns to verify: %v", err)
+	}
+}
+
+func TestDataColumnSidecarsInvalidInclusionProof(t *testing.T) {
+	block, columns := testColumns(t, 3)
+
+	columns[0].KZGCommitmentsInclusionProof[2][0] ^= 0xff
+
+	err := DataColumnSidecars(block, columns, 0)
+	if !errors.Is(err, ErrInclusionProofInvalid) {
+		t.Fatalf("expected inclusion proof error, got %v", err)
+	}
+}
+
+func TestDataColumnSidecarsInvalidKZGProof(t *testing.T) {
+	block, columns := testColumns(t, 0, 4)
+
+	columns[0].Column[0][31] ^= 0x01
+
+	err := DataColumnSidecars(block, columns, 0)
+	if !errors.Is(err, ErrKZGProofInvalid) {
+		t.Fatalf("expected kzg proof error, got %v", err)
+	}
+}
+
+func TestDataColumnSidecarsMismatchedCommitments(t *testing.T) {
+	block, columns := testColumns(t, 1)
+
+	columns[0].KZGCommitments[0][0] ^= 0xff
+
+	if err := DataColumnSidecars(block, columns, 0); err == nil {
+		t.Fatal("expected error for commitments not matching the block")
+	}
+}
+
+func TestDataColumnSidecarsDuplicateIndex(t *testing.T) {
+	block, columns := testColumns(t, 1, 1)
+
+	if err := DataColumnSidecars(block, columns, 0); err == nil {
+		t.Fatal("expected error for duplicate columns")
+	}
+}
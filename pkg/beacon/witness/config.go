@@ -0,0 +1,48 @@
+package witness
+
+import (
+	"errors"
+	"time"
+
+	"github.com/creasty/defaults"
+)
+
+// Config configures an external witness: another Checkpointz instance or beacon node whose finalized
+// checkpoints are compared with our own.
+type Config struct {
+	// Name identifies the witness in the status endpoint, logs and metrics.
+	Name string `yaml:"name"`
+	// Address is the base URL of the witness's beacon API.
+	Address string `yaml:"address"`
+	// Headers are sent with every request to the witness.
+	Headers map[string]string `yaml:"headers"`
+	// Timeout bounds every request to the witness.
+	Timeout time.Duration `yaml:"timeout" default:"10s"`
+}
+
+// UnmarshalYAML applies the defaults before decoding, as defaults aren't set on slice elements.
+func (c *Config) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	if err := defaults.Set(c); err != nil {
+		return err
+	}
+
+	type plain Config
+
+	return unmarshal((*plain)(c))
+}
+
+func (c *Config) Validate() error {
+	if c.Name == "" {
+		return errors.New("name is required")
+	}
+
+	if c.Address == "" {
+		return errors.New("address is required")
+	}
+
+	if c.Timeout <= 0 {
+		return errors.New("timeout must be positive")
+	}
+
+	return nil
+}
@@ -0,0 +1,92 @@
+package witness_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/ethpandaops/checkpointz/pkg/beacon"
+	"github.com/ethpandaops/checkpointz/pkg/beacon/beacontest"
+	"github.com/ethpandaops/checkpointz/pkg/beacon/witness"
+	"github.com/ethpandaops/checkpointz/pkg/checkpointz"
+	"github.com/ethpandaops/checkpointz/pkg/checkpointz/checkpointztest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// witnessConfigs returns witness endpoints for the given nodes, which are closed when the test ends.
+func witnessConfigs(t *testing.T, nodes map[string]*beacontest.Node) []witness.Config {
+	configs := make([]witness.Config, 0, len(nodes))
+
+	for name, n := range nodes {
+		t.Cleanup(n.Close)
+
+		configs = append(configs, witness.Config{Name: name, Address: n.URL(), Timeout: 5 * time.Second})
+	}
+
+	return configs
+}
+
+func TestE2EReportsWitnessVerdicts(t *testing.T) {
+	chain := checkpointztest.NewChain(t)
+
+	fork, err := chain.Fork(checkpointztest.FinalizedEpoch - 2)
+	require.NoError(t, err)
+
+	witnesses := witnessConfigs(t, map[string]*beacontest.Node{
+		"honest": checkpointztest.NewNode(chain),
+		"forked": checkpointztest.NewNode(fork),
+	})
+
+	server := checkpointztest.Start(t, beacon.OperatingModeLight, func(config *checkpointz.Config) {
+		config.Checkpointz.Witnesses.Endpoints = witnesses
+	}, checkpointztest.Upstream{Node: checkpointztest.NewNode(chain), DataProvider: true})
+
+	server.RequireServes(chain.Root(checkpointztest.FinalizedEpoch))
+
+	var body struct {
+		Data struct {
+			Witnesses map[string]*witness.Report `json:"witnesses"`
+		} `json:"data"`
+	}
+
+	server.Eventually(func() bool {
+		rsp, data := server.Get("/checkpointz/v1/status", "application/json")
+		if rsp.StatusCode != http.StatusOK {
+			return false
+		}
+
+		require.NoError(t, json.Unmarshal(data, &body))
+
+		return len(body.Data.Witnesses) == 2
+	})
+
+	assert.Equal(t, witness.VerdictAgree, body.Data.Witnesses["honest"].Verdict)
+	assert.Equal(t, witness.VerdictDisagree, body.Data.Witnesses["forked"].Verdict)
+}
+
+func TestE2EWitnessDisagreementBlocksServing(t *testing.T) {
+	chain := checkpointztest.NewChain(t)
+
+	fork, err := chain.Fork(checkpointztest.FinalizedEpoch - 2)
+	require.NoError(t, err)
+
+	forked := checkpointztest.NewNode(fork)
+	witnesses := witnessConfigs(t, map[string]*beacontest.Node{"forked": forked})
+
+	server := checkpointztest.Start(t, beacon.OperatingModeLight, func(config *checkpointz.Config) {
+		config.Checkpointz.Witnesses.Endpoints = witnesses
+		config.Checkpointz.Witnesses.BlockOnDisagreement = true
+	}, checkpointztest.Upstream{Node: checkpointztest.NewNode(chain), DataProvider: true})
+
+	// The witness is only consulted once a new serving checkpoint has been downloaded.
+	server.Eventually(func() bool {
+		return forked.Requests("/eth/v1/beacon/blocks/") > 0 || forked.Requests("/eth/v1/beacon/states/finalized/finality_checkpoints") > 0
+	})
+
+	server.Wait(20 * time.Second)
+
+	_, ok := server.ServedRoot()
+	assert.False(t, ok, "checkpoint should not be served while a witness disagrees")
+}
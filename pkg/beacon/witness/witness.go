@@ -0,0 +1,130 @@
+// Package witness compares our finalized checkpoint with those reported by independent Checkpointz
+// instances and beacon nodes, to catch our whole upstream set following a bad chain.
+package witness
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/ethpandaops/checkpointz/pkg/beaconapi"
+	"github.com/ethpandaops/checkpointz/pkg/clock"
+)
+
+// Verdict is a witness's view of one of our checkpoints.
+type Verdict string
+
+const (
+	// VerdictAgree means the witness has the same block at the checkpoint's epoch.
+	VerdictAgree Verdict = "agree"
+	// VerdictDisagree means the witness has finalized a different block at the checkpoint's epoch.
+	VerdictDisagree Verdict = "disagree"
+	// VerdictBehind means the witness hasn't finalized the checkpoint's epoch yet.
+	VerdictBehind Verdict = "behind"
+	// VerdictUnknown means the witness couldn't be asked.
+	VerdictUnknown Verdict = "unknown"
+)
+
+// Verdicts lists every verdict.
+var Verdicts = []Verdict{VerdictAgree, VerdictDisagree, VerdictBehind, VerdictUnknown}
+
+// Report is the outcome of comparing a checkpoint with a witness.
+type Report struct {
+	Name    string  `json:"name"`
+	Verdict Verdict `json:"verdict"`
+	// Checkpoint is our checkpoint that was compared.
+	Checkpoint *phase0.Checkpoint `json:"checkpoint,omitempty"`
+	// Finalized is the witness's own finalized checkpoint.
+	Finalized *phase0.Checkpoint `json:"finalized,omitempty"`
+	Error     string             `json:"error,omitempty"`
+	CheckedAt time.Time          `json:"checked_at"`
+}
+
+// Witness is an external Checkpointz instance or beacon node.
+type Witness struct {
+	config Config
+	client *beaconapi.Client
+	clock  clock.Clock
+}
+
+// New returns a Witness for the given config.
+func New(config Config, clk clock.Clock) *Witness {
+	return &Witness{
+		config: config,
+		client: beaconapi.NewClientWithHeaders(config.Address, config.Timeout, config.Headers),
+		clock:  clk,
+	}
+}
+
+// Name returns the name of the witness.
+func (w *Witness) Name() string {
+	return w.config.Name
+}
+
+// Check compares checkpoint, whose block is at slot, with the witness. If the witness has finalized a later
+// epoch, its block at slot is compared instead.
+func (w *Witness) Check(ctx context.Context, checkpoint *phase0.Checkpoint, slot phase0.Slot) *Report {
+	report := &Report{
+		Name:       w.config.Name,
+		Verdict:    VerdictUnknown,
+		Checkpoint: checkpoint,
+	}
+
+	defer func() {
+		report.CheckedAt = w.clock.Now()
+	}()
+
+	finalized, err := w.Finalized(ctx)
+	if err != nil {
+		report.Error = err.Error()
+
+		return report
+	}
+
+	report.Finalized = finalized
+
+	root := finalized.Root
+
+	switch {
+	case finalized.Epoch < checkpoint.Epoch:
+		report.Verdict = VerdictBehind
+
+		return report
+	case finalized.Epoch > checkpoint.Epoch:
+		root, err = w.BlockRoot(ctx, slot)
+		if err != nil {
+			report.Error = err.Error()
+
+			return report
+		}
+	}
+
+	if root == checkpoint.Root {
+		report.Verdict = VerdictAgree
+	} else {
+		report.Verdict = VerdictDisagree
+	}
+
+	return report
+}
+
+// Finalized returns the witness's finalized checkpoint.
+func (w *Witness) Finalized(ctx context.Context) (*phase0.Checkpoint, error) {
+	finalized, err := w.client.Finalized(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query witness %s: %w", w.config.Name, err)
+	}
+
+	return finalized, nil
+}
+
+// BlockRoot returns the root of the witness's block at slot.
+func (w *Witness) BlockRoot(ctx context.Context, slot phase0.Slot) (phase0.Root, error) {
+	root, err := w.client.BlockRootAtSlot(ctx, slot)
+	if err != nil {
+		return phase0.Root{}, fmt.Errorf("failed to query witness %s: %w", w.config.Name, err)
+	}
+
+	return root, nil
+}
@@ -0,0 +1,107 @@
+package witness
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/ethpandaops/checkpointz/pkg/beacon/beacontest"
+	"github.com/ethpandaops/checkpointz/pkg/clock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var now = time.Date(2022, 6, 1, 12, 0, 0, 0, time.UTC)
+
+func newWitness(t *testing.T, address string) *Witness {
+	t.Helper()
+
+	return New(Config{Name: "witness", Address: address, Timeout: 5 * time.Second}, clock.NewMock(now))
+}
+
+func checkpointAt(chain *beacontest.Chain, epoch phase0.Epoch) (*phase0.Checkpoint, phase0.Slot) {
+	return &phase0.Checkpoint{Epoch: epoch, Root: chain.Root(epoch)}, phase0.Slot(uint64(epoch) * beacontest.SlotsPerEpoch)
+}
+
+func TestCheck(t *testing.T) {
+	chain, err := beacontest.NewChain(6)
+	require.NoError(t, err)
+
+	fork, err := chain.Fork(2)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name      string
+		chain     *beacontest.Chain
+		finalized phase0.Epoch
+		checked   phase0.Epoch
+		expected  Verdict
+	}{
+		{name: "same epoch, same chain", chain: chain, finalized: 4, checked: 4, expected: VerdictAgree},
+		{name: "same epoch, forked chain", chain: fork, finalized: 4, checked: 4, expected: VerdictDisagree},
+		{name: "witness ahead, same chain", chain: chain, finalized: 5, checked: 4, expected: VerdictAgree},
+		{name: "witness ahead, forked chain", chain: fork, finalized: 5, checked: 4, expected: VerdictDisagree},
+		{name: "witness ahead, before fork", chain: fork, finalized: 5, checked: 1, expected: VerdictAgree},
+		{name: "witness behind", chain: chain, finalized: 3, checked: 4, expected: VerdictBehind},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			node := beacontest.NewNode(tt.chain, beacontest.Scenario{FinalizedEpoch: tt.finalized})
+			defer node.Close()
+
+			checkpoint, slot := checkpointAt(chain, tt.checked)
+
+			report := newWitness(t, node.URL()).Check(context.Background(), checkpoint, slot)
+
+			assert.Equal(t, tt.expected, report.Verdict, report.Error)
+			assert.Equal(t, "witness", report.Name)
+			assert.Equal(t, checkpoint, report.Checkpoint)
+			require.NotNil(t, report.Finalized)
+			assert.Equal(t, tt.finalized, report.Finalized.Epoch)
+			assert.Equal(t, now, report.CheckedAt)
+		})
+	}
+}
+
+func TestCheckUnreachable(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	chain, err := beacontest.NewChain(2)
+	require.NoError(t, err)
+
+	checkpoint, slot := checkpointAt(chain, 1)
+
+	report := newWitness(t, server.URL).Check(context.Background(), checkpoint, slot)
+
+	assert.Equal(t, VerdictUnknown, report.Verdict)
+	assert.Contains(t, report.Error, "503")
+	assert.Nil(t, report.Finalized)
+}
+
+func TestBlockRootAcceptsUnprefixedRoots(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/eth/v1/beacon/blocks/32/root", r.URL.Path)
+		assert.Equal(t, "secret", r.Header.Get("Authorization"))
+
+		_, _ = w.Write([]byte(`{"data":{"root":"0100000000000000000000000000000000000000000000000000000000000000"}}`))
+	}))
+	defer server.Close()
+
+	w := New(Config{
+		Name:    "witness",
+		Address: server.URL + "/",
+		Headers: map[string]string{"Authorization": "secret"},
+		Timeout: 5 * time.Second,
+	}, clock.New())
+
+	root, err := w.BlockRoot(context.Background(), 32)
+	require.NoError(t, err)
+	assert.Equal(t, phase0.Root{0x01}, root)
+}
@@ -0,0 +1,127 @@
+package beacon
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/ethpandaops/checkpointz/pkg/beacon/witness"
+	"github.com/sirupsen/logrus"
+)
+
+// WitnessesStatus returns each witness's latest report: on the serving checkpoint, or on a new checkpoint
+// that was compared before being served.
+func (d *Default) WitnessesStatus(ctx context.Context) (map[string]*witness.Report, error) {
+	d.witnessMutex.Lock()
+	defer d.witnessMutex.Unlock()
+
+	status := make(map[string]*witness.Report, len(d.witnessReports))
+
+	for name, report := range d.witnessReports {
+		status[name] = report
+	}
+
+	return status, nil
+}
+
+// checkWitnesses compares the serving checkpoint with every witness and records their reports.
+func (d *Default) checkWitnesses(ctx context.Context) error {
+	finality, err := d.Finalized(ctx)
+	if err != nil {
+		return err
+	}
+
+	if finality == nil || finality.Finalized == nil {
+		return errors.New("no serving checkpoint to compare")
+	}
+
+	sp, err := d.Spec()
+	if err != nil {
+		return err
+	}
+
+	slot := phase0.Slot(uint64(finality.Finalized.Epoch) * uint64(sp.SlotsPerEpoch))
+
+	d.recordWitnessReports(d.consultWitnesses(ctx, finality.Finalized, slot))
+
+	return nil
+}
+
+// verifyWithWitnesses returns an error if any witness disagrees with checkpoint, whose block is at slot.
+// Witnesses that are behind or unreachable don't count against it. Their reports are recorded either way.
+func (d *Default) verifyWithWitnesses(ctx context.Context, checkpoint *phase0.Checkpoint, slot phase0.Slot) error {
+	reports := d.consultWitnesses(ctx, checkpoint, slot)
+
+	d.recordWitnessReports(reports)
+
+	var disagreeing []string
+
+	for _, report := range reports {
+		if report.Verdict == witness.VerdictDisagree {
+			disagreeing = append(disagreeing, report.Name)
+		}
+	}
+
+	if len(disagreeing) > 0 {
+		d.metrics.ObserveServingCheckpointBlocked()
+
+		return fmt.Errorf("witnesses disagree with checkpoint: %s", strings.Join(disagreeing, ", "))
+	}
+
+	return nil
+}
+
+// recordWitnessReports records reports as the witnesses' latest.
+func (d *Default) recordWitnessReports(reports []*witness.Report) {
+	d.witnessMutex.Lock()
+	defer d.witnessMutex.Unlock()
+
+	for _, report := range reports {
+		d.metrics.ObserveWitnessVerdict(report.Name, report.Verdict)
+
+		d.witnessReports[report.Name] = report
+	}
+}
+
+// consultWitnesses asks every witness about checkpoint concurrently.
+func (d *Default) consultWitnesses(ctx context.Context, checkpoint *phase0.Checkpoint, slot phase0.Slot) []*witness.Report {
+	reports := make([]*witness.Report, len(d.witnesses))
+
+	var wg sync.WaitGroup
+
+	for i, w := range d.witnesses {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			reports[i] = w.Check(ctx, checkpoint, slot)
+		}()
+	}
+
+	wg.Wait()
+
+	for _, report := range reports {
+		logCtx := d.log.WithFields(logrus.Fields{
+			"witness": report.Name,
+			"verdict": report.Verdict,
+			"epoch":   checkpoint.Epoch,
+			"root":    fmt.Sprintf("%#x", checkpoint.Root),
+		})
+
+		switch report.Verdict {
+		case witness.VerdictDisagree:
+			logCtx.WithField("witness_finalized_epoch", report.Finalized.Epoch).
+				Warn("Witness disagrees with our checkpoint")
+		case witness.VerdictUnknown:
+			logCtx.WithField("error", report.Error).Debug("Failed to consult witness")
+		default:
+			logCtx.Debug("Consulted witness")
+		}
+	}
+
+	return reports
+}
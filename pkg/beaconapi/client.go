@@ -0,0 +1,147 @@
+// Package beaconapi is a minimal client for the parts of the beacon API that Checkpointz serves, for use by
+// the command line tools and witnesses.
+package beaconapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/ethpandaops/checkpointz/pkg/eth"
+)
+
+// ErrNotFound is returned when an endpoint doesn't have the requested data.
+var ErrNotFound = errors.New("not found")
+
+// Client queries the beacon API of a beacon node or Checkpointz instance.
+type Client struct {
+	url     string
+	headers map[string]string
+	client  *http.Client
+}
+
+// NewClient returns a Client for the beacon API at url.
+func NewClient(url string, timeout time.Duration) *Client {
+	return NewClientWithHeaders(url, timeout, nil)
+}
+
+// NewClientWithHeaders returns a Client for the beacon API at url that sends headers with every request.
+func NewClientWithHeaders(url string, timeout time.Duration, headers map[string]string) *Client {
+	return &Client{
+		url:     strings.TrimSuffix(url, "/"),
+		headers: headers,
+		client:  &http.Client{Timeout: timeout},
+	}
+}
+
+// URL returns the address the client queries.
+func (c *Client) URL() string {
+	return c.url
+}
+
+// Finalized returns the endpoint's finalized checkpoint.
+func (c *Client) Finalized(ctx context.Context) (*phase0.Checkpoint, error) {
+	var rsp struct {
+		Data struct {
+			Finalized *phase0.Checkpoint `json:"finalized"`
+		} `json:"data"`
+	}
+
+	if err := c.get(ctx, "/eth/v1/beacon/states/finalized/finality_checkpoints", &rsp); err != nil {
+		return nil, err
+	}
+
+	if rsp.Data.Finalized == nil {
+		return nil, errors.New("no finalized checkpoint returned")
+	}
+
+	return rsp.Data.Finalized, nil
+}
+
+// BlockRoot returns the root of the block with the given block ID: head, genesis, finalized, a slot or a root.
+func (c *Client) BlockRoot(ctx context.Context, blockID string) (phase0.Root, error) {
+	var rsp struct {
+		Data struct {
+			Root string `json:"root"`
+		} `json:"data"`
+	}
+
+	if err := c.get(ctx, fmt.Sprintf("/eth/v1/beacon/blocks/%s/root", blockID), &rsp); err != nil {
+		return phase0.Root{}, err
+	}
+
+	// Checkpointz returns the root without a 0x prefix, beacon nodes with one.
+	return eth.RootFromString(rsp.Data.Root)
+}
+
+// BlockRootAtSlot returns the root of the block at slot.
+func (c *Client) BlockRootAtSlot(ctx context.Context, slot phase0.Slot) (phase0.Root, error) {
+	return c.BlockRoot(ctx, eth.SlotAsString(slot))
+}
+
+// SlotsPerEpoch returns the SLOTS_PER_EPOCH of the endpoint's chain spec.
+func (c *Client) SlotsPerEpoch(ctx context.Context) (uint64, error) {
+	var rsp struct {
+		Data map[string]any `json:"data"`
+	}
+
+	if err := c.get(ctx, "/eth/v1/config/spec", &rsp); err != nil {
+		return 0, err
+	}
+
+	value, ok := rsp.Data["SLOTS_PER_EPOCH"].(string)
+	if !ok {
+		return 0, errors.New("spec has no SLOTS_PER_EPOCH")
+	}
+
+	slotsPerEpoch, err := strconv.ParseUint(value, 10, 64)
+	if err != nil || slotsPerEpoch == 0 {
+		return 0, fmt.Errorf("invalid SLOTS_PER_EPOCH: %q", value)
+	}
+
+	return slotsPerEpoch, nil
+}
+
+func (c *Client) get(ctx context.Context, path string, into any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.url+path, http.NoBody)
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Accept", "application/json")
+
+	c.setHeaders(req)
+
+	rsp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer rsp.Body.Close()
+
+	if rsp.StatusCode == http.StatusNotFound {
+		return fmt.Errorf("%s: %w", path, ErrNotFound)
+	}
+
+	if rsp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s: unexpected status %d", path, rsp.StatusCode)
+	}
+
+	if err := json.NewDecoder(rsp.Body).Decode(into); err != nil {
+		return fmt.Errorf("%s: failed to decode response: %w", path, err)
+	}
+
+	return nil
+}
+
+// setHeaders adds the client's headers to req.
+func (c *Client) setHeaders(req *http.Request) {
+	for k, v := range c.headers {
+		req.Header.Set(k, v)
+	}
+}
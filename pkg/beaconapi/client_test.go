@@ -0,0 +1,119 @@
+package beaconapi
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBlockRootAcceptsUnprefixedRoots(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/eth/v1/beacon/blocks/64/root", r.URL.Path)
+
+		_, _ = w.Write([]byte(`{"data":{"root":"0100000000000000000000000000000000000000000000000000000000000000"}}`))
+	}))
+	defer server.Close()
+
+	root, err := NewClient(server.URL+"/", 5*time.Second).BlockRootAtSlot(context.Background(), 64)
+	require.NoError(t, err)
+	assert.Equal(t, phase0.Root{0x01}, root)
+}
+
+func TestDownloadResumes(t *testing.T) {
+	const body = "0123456789"
+
+	var ranges []string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "application/octet-stream", r.Header.Get("Accept"))
+
+		w.Header().Set("Eth-Consensus-Version", "deneb")
+
+		ranges = append(ranges, r.Header.Get("Range"))
+
+		if rng := r.Header.Get("Range"); rng != "" {
+			offset, err := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(rng, "bytes="), "-"))
+			require.NoError(t, err)
+
+			w.WriteHeader(http.StatusPartialContent)
+			_, _ = w.Write([]byte(body[offset:]))
+
+			return
+		}
+
+		_, _ = w.Write([]byte(body))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, 5*time.Second)
+	dst := filepath.Join(t.TempDir(), "block.ssz")
+
+	// Leave behind an interrupted download of this path and a stale one of another.
+	require.NoError(t, os.WriteFile(partialPath(dst, "/block"), []byte(body[:4]), 0o600))
+	require.NoError(t, os.WriteFile(partialPath(dst, "/other"), []byte("xx"), 0o600))
+
+	headers, err := client.Download(context.Background(), "/block", dst)
+	require.NoError(t, err)
+	assert.Equal(t, "deneb", headers.Get("Eth-Consensus-Version"))
+	assert.Equal(t, []string{"bytes=4-"}, ranges)
+
+	data, err := os.ReadFile(dst)
+	require.NoError(t, err)
+	assert.Equal(t, body, string(data))
+
+	leftovers, err := filepath.Glob(dst + ".*.part")
+	require.NoError(t, err)
+	assert.Empty(t, leftovers)
+
+	// A server that ignores the range sends everything again.
+	require.NoError(t, os.WriteFile(partialPath(dst, "/block"), []byte("garbage"), 0o600))
+
+	server.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(body))
+	})
+
+	_, err = client.Download(context.Background(), "/block", dst)
+	require.NoError(t, err)
+
+	data, err = os.ReadFile(dst)
+	require.NoError(t, err)
+	assert.Equal(t, body, string(data))
+}
+
+func TestDownloadNotFound(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+
+	_, err := NewClient(server.URL, 5*time.Second).Download(context.Background(), "/missing", filepath.Join(t.TempDir(), "x"))
+	require.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestDownloadEraUsesTheServersFileName(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/checkpointz/v1/era/3", r.URL.Path)
+
+		w.Header().Set("Content-Disposition", `attachment; filename="../mainnet-00003-01020304.era"`)
+		_, _ = w.Write([]byte("era"))
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+
+	path, err := NewClient(server.URL, 5*time.Second).DownloadEra(context.Background(), 3, dir)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "mainnet-00003-01020304.era"), path)
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "era", string(data))
+}
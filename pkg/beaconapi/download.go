@@ -0,0 +1,96 @@
+package beaconapi
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+)
+
+// Download streams the SSZ response for path to the file dst and returns the response headers. The data is
+// written to a partial file next to dst and only renamed to dst once complete. If a partial file exists from
+// an interrupted download of the same path, only the rest of it is requested; servers that ignore the range
+// are downloaded from scratch. Paths must identify immutable data, e.g. by root, for resuming to be safe.
+func (c *Client) Download(ctx context.Context, path, dst string) (http.Header, error) {
+	partial := partialPath(dst, path)
+
+	// Partial downloads of anything else into dst are stale.
+	stale, err := filepath.Glob(dst + ".*.part")
+	if err != nil {
+		return nil, err
+	}
+
+	for _, f := range stale {
+		if f != partial {
+			if err := os.Remove(f); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	var offset int64
+
+	if info, err := os.Stat(partial); err == nil {
+		offset = info.Size()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.url+path, http.NoBody)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Accept", "application/octet-stream")
+
+	c.setHeaders(req)
+
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
+
+	rsp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer rsp.Body.Close()
+
+	flags := os.O_CREATE | os.O_WRONLY
+
+	switch rsp.StatusCode {
+	case http.StatusPartialContent:
+		flags |= os.O_APPEND
+	case http.StatusOK:
+		flags |= os.O_TRUNC
+	case http.StatusNotFound:
+		return nil, fmt.Errorf("%s: %w", path, ErrNotFound)
+	default:
+		return nil, fmt.Errorf("%s: unexpected status %d", path, rsp.StatusCode)
+	}
+
+	file, err := os.OpenFile(partial, flags, 0o644) //nolint:gosec // dst is chosen by the operator
+	if err != nil {
+		return nil, err
+	}
+
+	_, copyErr := io.Copy(file, rsp.Body)
+
+	if err := errors.Join(copyErr, file.Close()); err != nil {
+		return nil, fmt.Errorf("%s: download interrupted: %w", path, err)
+	}
+
+	if err := os.Rename(partial, dst); err != nil {
+		return nil, err
+	}
+
+	return rsp.Header, nil
+}
+
+// partialPath returns where a download of path into dst is kept until it completes.
+func partialPath(dst, path string) string {
+	sum := sha256.Sum256([]byte(path))
+
+	return fmt.Sprintf("%s.%x.part", dst, sum[:4])
+}
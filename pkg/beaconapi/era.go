@@ -0,0 +1,34 @@
+package beaconapi
+
+import (
+	"context"
+	"fmt"
+	"mime"
+	"os"
+	"path/filepath"
+)
+
+// DownloadEra downloads an era file from a Checkpointz instance into dir and returns its path. The file is
+// named as the server suggests, falling back to era-<number>.era.
+func (c *Client) DownloadEra(ctx context.Context, number uint64, dir string) (string, error) {
+	download := filepath.Join(dir, fmt.Sprintf("era-%05d.era", number))
+
+	headers, err := c.Download(ctx, fmt.Sprintf("/checkpointz/v1/era/%d", number), download)
+	if err != nil {
+		return "", err
+	}
+
+	_, params, err := mime.ParseMediaType(headers.Get("Content-Disposition"))
+	if err != nil || params["filename"] == "" {
+		return download, nil
+	}
+
+	// Never let the server choose where the file goes, only its name.
+	path := filepath.Join(dir, filepath.Base(params["filename"]))
+
+	if err := os.Rename(download, path); err != nil {
+		return "", err
+	}
+
+	return path, nil
+}
@@ -0,0 +1,397 @@
+// Package bundle downloads the finalized checkpoint bundle (block, state and blob sidecars) from Checkpointz
+// instances to disk, verifying it locally.
+package bundle
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/attestantio/go-eth2-client/spec"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/ethpandaops/checkpointz/pkg/beacon/ssz"
+	"github.com/ethpandaops/checkpointz/pkg/beacon/verify"
+	"github.com/ethpandaops/checkpointz/pkg/beaconapi"
+	"github.com/ethpandaops/checkpointz/pkg/eth"
+	serviceeth "github.com/ethpandaops/checkpointz/pkg/service/eth"
+)
+
+const (
+	// ManifestFile is the name of the manifest written alongside the bundle.
+	ManifestFile = "manifest.json"
+	// BlockFile is the name of the SSZ encoded signed block.
+	BlockFile = "block.ssz"
+	// StateFile is the name of the SSZ encoded beacon state.
+	StateFile = "state.ssz"
+	// BlobSidecarsFile is the name of the SSZ encoded list of blob sidecars.
+	BlobSidecarsFile = "blob_sidecars.ssz"
+)
+
+// Options controls what Fetch downloads.
+type Options struct {
+	// Dir is the directory the bundle is written to.
+	Dir string
+	// State downloads the beacon state. Only Checkpointz instances running in full mode serve it.
+	State bool
+	// BlobSidecars downloads the block's blob sidecars, if it has any.
+	BlobSidecars bool
+}
+
+// Manifest describes a downloaded bundle.
+type Manifest struct {
+	Version   string      `json:"version"`
+	Slot      phase0.Slot `json:"slot,string"`
+	BlockRoot phase0.Root `json:"block_root"`
+	StateRoot phase0.Root `json:"state_root"`
+	// Providers are the Checkpointz instances that agreed on the block root.
+	Providers []string `json:"providers"`
+	// Source is the provider the files were downloaded from.
+	Source string `json:"source"`
+	Files  []File `json:"files"`
+	// Complete is set once every requested file has been downloaded and verified.
+	Complete  bool      `json:"complete"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// File is a verified file in the bundle.
+type File struct {
+	Name   string `json:"name"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+func (m *Manifest) file(name string) *File {
+	for i := range m.Files {
+		if m.Files[i].Name == name {
+			return &m.Files[i]
+		}
+	}
+
+	return nil
+}
+
+// Fetch downloads the finalized bundle that every provider agrees on into opts.Dir. Files already downloaded
+// and verified for the same block are kept, and interrupted downloads are resumed.
+func Fetch(ctx context.Context, providers []*beaconapi.Client, encoder *ssz.Encoder, opts Options) (*Manifest, error) {
+	if len(providers) == 0 {
+		return nil, errors.New("at least one provider is required")
+	}
+
+	root, err := agreedFinalizedRoot(ctx, providers)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(opts.Dir, 0o755); err != nil { //nolint:gosec // the bundle isn't secret
+		return nil, err
+	}
+
+	manifest := loadManifest(opts.Dir, root)
+	manifest.Providers = providerURLs(providers)
+	manifest.Complete = false
+
+	source := providers[0]
+	manifest.Source = source.URL()
+
+	block, err := fetchBlock(ctx, source, encoder, opts.Dir, root, manifest)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := saveManifest(opts.Dir, manifest); err != nil {
+		return nil, err
+	}
+
+	if opts.State {
+		if err := fetchState(ctx, source, encoder, opts.Dir, manifest); err != nil {
+			return nil, err
+		}
+
+		if err := saveManifest(opts.Dir, manifest); err != nil {
+			return nil, err
+		}
+	}
+
+	if opts.BlobSidecars {
+		if err := fetchBlobSidecars(ctx, source, encoder, opts.Dir, block, manifest); err != nil {
+			return nil, err
+		}
+	}
+
+	manifest.Complete = true
+
+	return manifest, saveManifest(opts.Dir, manifest)
+}
+
+// agreedFinalizedRoot returns the finalized block root, if every provider reports the same one.
+func agreedFinalizedRoot(ctx context.Context, providers []*beaconapi.Client) (phase0.Root, error) {
+	roots := make(map[phase0.Root][]string)
+
+	for _, provider := range providers {
+		root, err := provider.BlockRoot(ctx, string(serviceeth.IDFinalized))
+		if err != nil {
+			return phase0.Root{}, fmt.Errorf("failed to fetch finalized block root from %s: %w", provider.URL(), err)
+		}
+
+		roots[root] = append(roots[root], provider.URL())
+	}
+
+	if len(roots) > 1 {
+		disagreement := make([]string, 0, len(roots))
+		for root, urls := range roots {
+			disagreement = append(disagreement, fmt.Sprintf("%s (%s)", eth.RootAsString(root), strings.Join(urls, ", ")))
+		}
+
+		return phase0.Root{}, fmt.Errorf("providers disagree on the finalized block: %s", strings.Join(disagreement, "; "))
+	}
+
+	for root := range roots {
+		return root, nil
+	}
+
+	return phase0.Root{}, errors.New("no finalized block root")
+}
+
+func fetchBlock(ctx context.Context, source *beaconapi.Client, encoder *ssz.Encoder, dir string, root phase0.Root, manifest *Manifest) (*spec.VersionedSignedBeaconBlock, error) {
+	path := filepath.Join(dir, BlockFile)
+
+	version, data, err := download(ctx, source, fmt.Sprintf("/eth/v2/beacon/blocks/%s", eth.RootAsString(root)), path, manifest)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download block: %w", err)
+	}
+
+	block, err := encoder.DecodeBlockSSZ(ctx, version, data)
+	if err != nil {
+		return nil, discard(path, manifest, err)
+	}
+
+	blockRoot, err := encoder.GetBlockRoot(block)
+	if err != nil {
+		return nil, discard(path, manifest, err)
+	}
+
+	if blockRoot != root {
+		return nil, discard(path, manifest, fmt.Errorf("block root %s does not match the finalized root %s", eth.RootAsString(blockRoot), eth.RootAsString(root)))
+	}
+
+	slot, err := block.Slot()
+	if err != nil {
+		return nil, err
+	}
+
+	stateRoot, err := block.StateRoot()
+	if err != nil {
+		return nil, err
+	}
+
+	manifest.Version = version.String()
+	manifest.Slot = slot
+	manifest.StateRoot = stateRoot
+
+	return block, record(path, manifest)
+}
+
+func fetchState(ctx context.Context, source *beaconapi.Client, encoder *ssz.Encoder, dir string, manifest *Manifest) error {
+	path := filepath.Join(dir, StateFile)
+
+	version, data, err := download(ctx, source, fmt.Sprintf("/eth/v2/debug/beacon/states/%s", eth.RootAsString(manifest.StateRoot)), path, manifest)
+	if err != nil {
+		return fmt.Errorf("failed to download state: %w", err)
+	}
+
+	beaconState, err := encoder.DecodeStateSSZ(ctx, version, data)
+	if err != nil {
+		return discard(path, manifest, err)
+	}
+
+	stateRoot, err := encoder.GetStateRoot(ctx, beaconState)
+	if err != nil {
+		return discard(path, manifest, err)
+	}
+
+	if stateRoot != manifest.StateRoot {
+		return discard(path, manifest, fmt.Errorf("state root %s does not match the block's state root %s", eth.RootAsString(stateRoot), eth.RootAsString(manifest.StateRoot)))
+	}
+
+	return record(path, manifest)
+}
+
+func fetchBlobSidecars(ctx context.Context, source *beaconapi.Client, encoder *ssz.Encoder, dir string, block *spec.VersionedSignedBeaconBlock, manifest *Manifest) error {
+	// Blob sidecars only exist from deneb until fulu replaced them with data columns.
+	if block.Version < spec.DataVersionDeneb || block.Version >= spec.DataVersionFulu {
+		return nil
+	}
+
+	commitments, err := block.BlobKZGCommitments()
+	if err != nil {
+		return err
+	}
+
+	if len(commitments) == 0 {
+		return nil
+	}
+
+	path := filepath.Join(dir, BlobSidecarsFile)
+
+	_, data, err := download(ctx, source, fmt.Sprintf("/eth/v1/beacon/blob_sidecars/%s", eth.RootAsString(manifest.BlockRoot)), path, manifest)
+	if err != nil {
+		return fmt.Errorf("failed to download blob sidecars: %w", err)
+	}
+
+	sidecars, err := encoder.DecodeBlobSidecarsSSZ(data)
+	if err != nil {
+		return discard(path, manifest, err)
+	}
+
+	if err := verify.BlobSidecars(block, sidecars, 0); err != nil {
+		return discard(path, manifest, err)
+	}
+
+	return record(path, manifest)
+}
+
+// download returns the contents of path and its fork version, downloading it from source unless a verified
+// copy is already recorded in the manifest.
+func download(ctx context.Context, source *beaconapi.Client, url, path string, manifest *Manifest) (spec.DataVersion, []byte, error) {
+	if existing := manifest.file(filepath.Base(path)); existing != nil {
+		data, err := readVerified(path, existing)
+		if err == nil {
+			version, err := spec.DataVersionFromString(manifest.Version)
+
+			return version, data, err
+		}
+
+		// The file has changed since it was verified, so start again.
+		manifest.Files = removeFile(manifest.Files, existing.Name)
+	}
+
+	headers, err := source.Download(ctx, url, path)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	var version spec.DataVersion
+
+	if v := headers.Get("Eth-Consensus-Version"); v != "" {
+		if version, err = spec.DataVersionFromString(strings.ToLower(v)); err != nil {
+			return 0, nil, fmt.Errorf("invalid consensus version %q: %w", v, err)
+		}
+	} else if manifest.Version != "" {
+		if version, err = spec.DataVersionFromString(manifest.Version); err != nil {
+			return 0, nil, err
+		}
+	} else {
+		return 0, nil, errors.New("response has no Eth-Consensus-Version header")
+	}
+
+	data, err := os.ReadFile(path) //nolint:gosec // path is within the operator's bundle directory
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return version, data, nil
+}
+
+// record adds the verified file at path to the manifest.
+func record(path string, manifest *Manifest) error {
+	f, err := os.Open(path) //nolint:gosec // path is within the operator's bundle directory
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+
+	size, err := io.Copy(h, f)
+	if err != nil {
+		return err
+	}
+
+	manifest.Files = append(removeFile(manifest.Files, filepath.Base(path)), File{
+		Name:   filepath.Base(path),
+		Size:   size,
+		SHA256: hex.EncodeToString(h.Sum(nil)),
+	})
+
+	return nil
+}
+
+// discard removes a file that failed verification so that it's downloaded again next time.
+func discard(path string, manifest *Manifest, cause error) error {
+	manifest.Files = removeFile(manifest.Files, filepath.Base(path))
+
+	return errors.Join(fmt.Errorf("%s failed verification: %w", filepath.Base(path), cause), os.Remove(path))
+}
+
+func readVerified(path string, file *File) ([]byte, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // path is within the operator's bundle directory
+	if err != nil {
+		return nil, err
+	}
+
+	sum := sha256.Sum256(data)
+
+	if int64(len(data)) != file.Size || hex.EncodeToString(sum[:]) != file.SHA256 {
+		return nil, fmt.Errorf("%s does not match the manifest", file.Name)
+	}
+
+	return data, nil
+}
+
+func removeFile(files []File, name string) []File {
+	kept := files[:0]
+
+	for _, f := range files {
+		if f.Name != name {
+			kept = append(kept, f)
+		}
+	}
+
+	return kept
+}
+
+// loadManifest returns the manifest in dir if it describes the same block, or a new one otherwise.
+func loadManifest(dir string, root phase0.Root) *Manifest {
+	manifest := &Manifest{}
+
+	data, err := os.ReadFile(filepath.Join(dir, ManifestFile)) //nolint:gosec // path is within the operator's bundle directory
+	if err == nil && json.Unmarshal(data, manifest) == nil && manifest.BlockRoot == root {
+		return manifest
+	}
+
+	return &Manifest{BlockRoot: root, Files: []File{}}
+}
+
+func saveManifest(dir string, manifest *Manifest) error {
+	manifest.UpdatedAt = time.Now().UTC()
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp := filepath.Join(dir, ManifestFile+".tmp")
+
+	if err := os.WriteFile(tmp, data, 0o644); err != nil { //nolint:gosec // the manifest isn't secret
+		return err
+	}
+
+	return os.Rename(tmp, filepath.Join(dir, ManifestFile))
+}
+
+func providerURLs(providers []*beaconapi.Client) []string {
+	urls := make([]string, 0, len(providers))
+	for _, p := range providers {
+		urls = append(urls, p.URL())
+	}
+
+	return urls
+}
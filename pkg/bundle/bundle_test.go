@@ -0,0 +1,123 @@
+package bundle
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/ethpandaops/checkpointz/pkg/beacon/beacontest"
+	"github.com/ethpandaops/checkpointz/pkg/beacon/ssz"
+	"github.com/ethpandaops/checkpointz/pkg/beaconapi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestProvider(t *testing.T, chain *beacontest.Chain, finalized phase0.Epoch) (*beacontest.Node, *beaconapi.Client) {
+	t.Helper()
+
+	node := beacontest.NewNode(chain, beacontest.Scenario{FinalizedEpoch: finalized})
+	t.Cleanup(node.Close)
+
+	return node, beaconapi.NewClient(node.URL(), 5*time.Second)
+}
+
+func TestFetch(t *testing.T) {
+	chain, err := beacontest.NewChain(4)
+	require.NoError(t, err)
+
+	node, provider := newTestProvider(t, chain, 3)
+	_, other := newTestProvider(t, chain, 3)
+
+	dir := t.TempDir()
+	opts := Options{Dir: dir, State: true, BlobSidecars: true}
+
+	manifest, err := Fetch(context.Background(), []*beaconapi.Client{provider, other}, ssz.NewEncoder(false), opts)
+	require.NoError(t, err)
+
+	assert.True(t, manifest.Complete)
+	assert.Equal(t, chain.Root(3), manifest.BlockRoot)
+	assert.Equal(t, phase0.Slot(3*beacontest.SlotsPerEpoch), manifest.Slot)
+	assert.Equal(t, "deneb", manifest.Version)
+	assert.Equal(t, []string{provider.URL(), other.URL()}, manifest.Providers)
+	// The fake chain's blocks carry no blobs, so there are no sidecars to download.
+	require.Len(t, manifest.Files, 2)
+	assert.Equal(t, BlockFile, manifest.Files[0].Name)
+	assert.Equal(t, StateFile, manifest.Files[1].Name)
+
+	block, _, _ := chain.Block(3)
+	stateRoot, err := block.StateRoot()
+	require.NoError(t, err)
+	assert.Equal(t, stateRoot, manifest.StateRoot)
+
+	data, err := os.ReadFile(filepath.Join(dir, ManifestFile))
+	require.NoError(t, err)
+
+	var written Manifest
+	require.NoError(t, json.Unmarshal(data, &written))
+	assert.Equal(t, manifest.BlockRoot, written.BlockRoot)
+	assert.Equal(t, manifest.Files, written.Files)
+
+	// Verified files aren't downloaded again.
+	blocks, states := node.Requests("/eth/v2/beacon/blocks/"), node.Requests("/eth/v2/debug/beacon/states/")
+
+	_, err = Fetch(context.Background(), []*beaconapi.Client{provider}, ssz.NewEncoder(false), opts)
+	require.NoError(t, err)
+	assert.Equal(t, blocks, node.Requests("/eth/v2/beacon/blocks/"))
+	assert.Equal(t, states, node.Requests("/eth/v2/debug/beacon/states/"))
+
+	// Files that no longer match the manifest are.
+	require.NoError(t, os.WriteFile(filepath.Join(dir, StateFile), []byte("corrupt"), 0o600))
+
+	_, err = Fetch(context.Background(), []*beaconapi.Client{provider}, ssz.NewEncoder(false), opts)
+	require.NoError(t, err)
+	assert.Equal(t, states+1, node.Requests("/eth/v2/debug/beacon/states/"))
+}
+
+func TestFetchRequiresAgreement(t *testing.T) {
+	chain, err := beacontest.NewChain(4)
+	require.NoError(t, err)
+
+	fork, err := chain.Fork(2)
+	require.NoError(t, err)
+
+	_, provider := newTestProvider(t, chain, 3)
+	_, forked := newTestProvider(t, fork, 3)
+
+	_, err = Fetch(context.Background(), []*beaconapi.Client{provider, forked}, ssz.NewEncoder(false), Options{Dir: t.TempDir()})
+	require.ErrorContains(t, err, "providers disagree on the finalized block")
+}
+
+func TestFetchRejectsBlocksThatDontMatchTheirRoot(t *testing.T) {
+	chain, err := beacontest.NewChain(4)
+	require.NoError(t, err)
+
+	fork, err := chain.Fork(2)
+	require.NoError(t, err)
+
+	_, provider := newTestProvider(t, chain, 3)
+	dir := t.TempDir()
+
+	// Pretend an earlier download of the finalized block was actually the forked one.
+	forkedBlock, _, _ := fork.Block(3)
+	data, err := forkedBlock.Deneb.MarshalSSZ()
+	require.NoError(t, err)
+
+	manifest := &Manifest{BlockRoot: chain.Root(3), Version: "deneb", Files: []File{}}
+	require.NoError(t, os.WriteFile(filepath.Join(dir, BlockFile), data, 0o600))
+	require.NoError(t, record(filepath.Join(dir, BlockFile), manifest))
+	require.NoError(t, saveManifest(dir, manifest))
+
+	_, err = Fetch(context.Background(), []*beaconapi.Client{provider}, ssz.NewEncoder(false), Options{Dir: dir})
+	require.ErrorContains(t, err, "block.ssz failed verification")
+
+	_, err = os.Stat(filepath.Join(dir, BlockFile))
+	assert.True(t, os.IsNotExist(err))
+
+	// The next attempt downloads it again.
+	_, err = Fetch(context.Background(), []*beaconapi.Client{provider}, ssz.NewEncoder(false), Options{Dir: dir})
+	require.NoError(t, err)
+}
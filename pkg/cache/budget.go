@@ -0,0 +1,150 @@
+package cache
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Budget is a memory budget shared between TTLMaps. When an item added to one of the maps would take the
+// combined cost of all items over the limit, items are evicted across every map sharing the budget - lowest
+// priority first, then closest to expiry - until the new item fits.
+type Budget struct {
+	limit int64
+	used  atomic.Int64
+
+	// mu serialises additions so that room made for an item isn't taken by another.
+	mu sync.Mutex
+
+	mapsMu sync.RWMutex
+	maps   []*TTLMap
+
+	usedGauge  prometheus.Gauge
+	limitGauge prometheus.Gauge
+}
+
+// NewBudget returns a budget of limit bytes. A limit of 0 or less is unlimited.
+func NewBudget(limit int64, namespace string) *Budget {
+	b := &Budget{
+		limit: limit,
+		usedGauge: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace + "_ttlmap",
+			Name:      "budget_used_bytes",
+			Help:      "Combined cost of all items in caches sharing the memory budget",
+		}),
+		limitGauge: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace + "_ttlmap",
+			Name:      "budget_limit_bytes",
+			Help:      "The memory budget shared by the caches",
+		}),
+	}
+
+	b.limitGauge.Set(float64(limit))
+
+	return b
+}
+
+// EnableMetrics registers the budget's gauges. A budget with the same namespace may already be registered (e.g. by a
+// second provider in the same process), in which case its gauges are shared rather than registered twice.
+func (b *Budget) EnableMetrics() {
+	b.usedGauge = registerGauge(b.usedGauge)
+	b.limitGauge = registerGauge(b.limitGauge)
+
+	b.limitGauge.Set(float64(b.limit))
+}
+
+// registerGauge registers the gauge, returning the one already registered under the same name if there is one.
+func registerGauge(gauge prometheus.Gauge) prometheus.Gauge {
+	err := prometheus.Register(gauge)
+	if err == nil {
+		return gauge
+	}
+
+	var registered prometheus.AlreadyRegisteredError
+	if errors.As(err, &registered) {
+		if existing, ok := registered.ExistingCollector.(prometheus.Gauge); ok {
+			return existing
+		}
+	}
+
+	panic(err)
+}
+
+// Limit returns the budget in bytes.
+func (b *Budget) Limit() int64 {
+	return b.limit
+}
+
+// Used returns the combined cost of all items in maps sharing the budget.
+func (b *Budget) Used() int64 {
+	return b.used.Load()
+}
+
+func (b *Budget) register(m *TTLMap) {
+	b.mapsMu.Lock()
+	defer b.mapsMu.Unlock()
+
+	b.maps = append(b.maps, m)
+}
+
+func (b *Budget) consume(cost int64) {
+	b.usedGauge.Set(float64(b.used.Add(cost)))
+}
+
+func (b *Budget) release(cost int64) {
+	b.usedGauge.Set(float64(b.used.Add(-cost)))
+}
+
+// makeRoom evicts items until cost more bytes fit in the budget, or nothing else can be evicted.
+// Callers must hold b.mu and must not hold the lock of any participating map.
+func (b *Budget) makeRoom(cost int64) {
+	if b.limit <= 0 {
+		return
+	}
+
+	b.mapsMu.RLock()
+	defer b.mapsMu.RUnlock()
+
+	for b.used.Load()+cost > b.limit {
+		victim := b.nextVictim()
+		if victim == nil {
+			return
+		}
+
+		victim.l.Lock()
+		evicted := victim.evictNext()
+		victim.l.Unlock()
+
+		if !evicted {
+			return
+		}
+	}
+}
+
+// nextVictim returns the map holding the next item to evict across the budget.
+func (b *Budget) nextVictim() *TTLMap {
+	var (
+		victim    *TTLMap
+		priority  Priority
+		expiresAt time.Time
+	)
+
+	for _, m := range b.maps {
+		m.l.RLock()
+
+		if candidate := m.nextEvictable(); candidate != nil && (victim == nil ||
+			candidate.priority < priority ||
+			(candidate.priority == priority && candidate.expiresAt.Before(expiresAt))) {
+			victim = m
+			priority = candidate.priority
+			expiresAt = candidate.expiresAt
+		}
+
+		m.l.RUnlock()
+	}
+
+	return victim
+}
@@ -0,0 +1,129 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestBudgetEvictsAcrossMaps(t *testing.T) {
+	budget := NewBudget(100, "")
+
+	states := NewTTLMap(10, "", "")
+	states.UseBudget(budget)
+
+	blocks := NewTTLMap(10, "", "")
+	blocks.UseBudget(budget)
+
+	now := time.Now()
+
+	states.AddWithCost("state1", "value", now.Add(time.Hour), 60, PriorityNormal)
+	blocks.AddWithCost("block1", "value", now.Add(2*time.Hour), 30, PriorityNormal)
+
+	if budget.Used() != 90 {
+		t.Fatalf("Expected 90 bytes used, got %d", budget.Used())
+	}
+
+	// Doesn't fit - the item closest to expiry (state1) should make way.
+	blocks.AddWithCost("block2", "value", now.Add(3*time.Hour), 30, PriorityNormal)
+
+	if _, _, err := states.Get("state1"); err == nil {
+		t.Fatalf("Expected state1 to have been evicted")
+	}
+
+	if _, _, err := blocks.Get("block1"); err != nil {
+		t.Fatalf("Expected block1 to not have been evicted")
+	}
+
+	if budget.Used() != 60 {
+		t.Fatalf("Expected 60 bytes used, got %d", budget.Used())
+	}
+}
+
+func TestBudgetEvictsLowPriorityFirst(t *testing.T) {
+	budget := NewBudget(100, "")
+
+	blobs := NewTTLMap(10, "", "")
+	blobs.UseBudget(budget)
+
+	blocks := NewTTLMap(10, "", "")
+	blocks.UseBudget(budget)
+
+	now := time.Now()
+
+	blocks.AddWithCost("block1", "value", now.Add(time.Hour), 50, PriorityNormal)
+	blobs.AddWithCost("blob1", "value", now.Add(2*time.Hour), 50, PriorityLow)
+	blocks.AddWithCost("block2", "value", now.Add(3*time.Hour), 50, PriorityNormal)
+
+	if _, _, err := blobs.Get("blob1"); err == nil {
+		t.Fatalf("Expected low priority blob1 to have been evicted")
+	}
+
+	if _, _, err := blocks.Get("block1"); err != nil {
+		t.Fatalf("Expected block1 to not have been evicted")
+	}
+}
+
+func TestBudgetNeverEvictsPinned(t *testing.T) {
+	budget := NewBudget(100, "")
+
+	states := NewTTLMap(10, "", "")
+	states.UseBudget(budget)
+
+	now := time.Now()
+
+	states.AddWithCost("genesis", "value", now.Add(time.Hour), 80, PriorityPinned)
+	states.AddWithCost("state1", "value", now.Add(time.Hour), 80, PriorityNormal)
+
+	if _, _, err := states.Get("genesis"); err != nil {
+		t.Fatalf("Expected pinned item to not have been evicted")
+	}
+
+	// Nothing evictable remains, so the budget is exceeded rather than dropping the new item.
+	if _, _, err := states.Get("state1"); err != nil {
+		t.Fatalf("Expected state1 to have been added")
+	}
+
+	states.AddWithCost("state2", "value", now.Add(2*time.Hour), 10, PriorityNormal)
+
+	if _, _, err := states.Get("state1"); err == nil {
+		t.Fatalf("Expected state1 to have been evicted")
+	}
+
+	if budget.Used() != 90 {
+		t.Fatalf("Expected 90 bytes used, got %d", budget.Used())
+	}
+}
+
+func TestBudgetUnlimited(t *testing.T) {
+	budget := NewBudget(0, "")
+
+	states := NewTTLMap(10, "", "")
+	states.UseBudget(budget)
+
+	for _, key := range []string{"a", "b", "c"} {
+		states.AddWithCost(key, "value", time.Now().Add(time.Hour), 1<<40, PriorityNormal)
+	}
+
+	if states.Len() != 3 {
+		t.Fatalf("Expected 3 items, got %d", states.Len())
+	}
+}
+
+func TestBudgetEnableMetricsTwice(t *testing.T) {
+	registerer := prometheus.DefaultRegisterer
+	prometheus.DefaultRegisterer = prometheus.NewRegistry()
+
+	t.Cleanup(func() { prometheus.DefaultRegisterer = registerer })
+
+	first := NewBudget(100, "budget_test")
+	first.EnableMetrics()
+
+	second := NewBudget(200, "budget_test")
+	second.EnableMetrics()
+
+	if first.usedGauge != second.usedGauge {
+		t.Fatalf("Expected budgets with the same namespace to share their gauges")
+	}
+}
@@ -0,0 +1,42 @@
+package cache
+
+import "testing"
+
+func TestParseByteSize(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected ByteSize
+		err      bool
+	}{
+		{input: "", expected: 0},
+		{input: "1024", expected: 1024},
+		{input: "100B", expected: 100},
+		{input: "512MiB", expected: 512 << 20},
+		{input: "4GB", expected: 4_000_000_000},
+		{input: "1.5 GiB", expected: 3 << 29},
+		{input: "8gib", expected: 8 << 30},
+		{input: "lots", err: true},
+		{input: "-1GB", err: true},
+	}
+
+	for _, test := range tests {
+		got, err := ParseByteSize(test.input)
+		if test.err {
+			if err == nil {
+				t.Errorf("ParseByteSize(%q) expected error", test.input)
+			}
+
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("ParseByteSize(%q) unexpected error: %v", test.input, err)
+
+			continue
+		}
+
+		if got != test.expected {
+			t.Errorf("ParseByteSize(%q) = %d, want %d", test.input, got, test.expected)
+		}
+	}
+}
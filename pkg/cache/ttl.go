@@ -1,59 +1,154 @@
 package cache
 
 import (
+	"container/heap"
 	"errors"
-	"sort"
 	"sync"
 	"time"
+
+	"github.com/ethpandaops/checkpointz/pkg/clock"
 )
 
-type item struct {
-	value      interface{}
-	expiresAt  time.Time
-	invincible bool
-}
+// Priority determines the order in which items are evicted when a cache is full. Lower priority items are
+// evicted first; within a priority class the item closest to expiry goes first.
+type Priority int
+
+const (
+	// PriorityLow is for items that are cheap to re-fetch and rarely requested.
+	PriorityLow Priority = iota
+	// PriorityNormal is the default priority.
+	PriorityNormal
+	// PriorityPinned items never expire and are never evicted.
+	PriorityPinned
+)
 
-type sortableItem struct {
+// evictableClasses are the priority classes that can expire or be evicted, in eviction order.
+var evictableClasses = []Priority{PriorityLow, PriorityNormal}
+
+type item struct {
 	key       string
+	value     interface{}
 	expiresAt time.Time
+	cost      int64
+	priority  Priority
+	// index is the position of the item in its expiry heap.
+	index int
+}
+
+// expiryHeap is a min-heap of items ordered by expiry.
+type expiryHeap []*item
+
+func (h expiryHeap) Len() int { return len(h) }
+
+func (h expiryHeap) Less(i, j int) bool { return h[i].expiresAt.Before(h[j].expiresAt) }
+
+func (h expiryHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+
+func (h *expiryHeap) Push(x any) {
+	it, ok := x.(*item)
+	if !ok {
+		return
+	}
+
+	it.index = len(*h)
+	*h = append(*h, it)
+}
+
+func (h *expiryHeap) Pop() any {
+	old := *h
+	n := len(old)
+	it := old[n-1]
+	old[n-1] = nil
+	it.index = -1
+	*h = old[:n-1]
+
+	return it
 }
 
 type TTLMap struct {
 	m        map[string]*item
 	l        sync.RWMutex
 	maxItems int
+	bytes    int64
+
+	// heaps holds an expiry heap per evictable priority class.
+	heaps map[Priority]*expiryHeap
+
+	budget *Budget
+
+	clock clock.Clock
 
 	metrics Metrics
 
-	deletedCallbacks []func(string, interface{}, time.Time)
-	addedCallbacks   []func(string, interface{}, time.Time)
+	stop     chan struct{}
+	stopOnce sync.Once
+
+	deletedCallbacks     []func(string, interface{}, time.Time)
+	deletedSyncCallbacks []func(string, interface{})
+	addedCallbacks       []func(string, interface{}, time.Time)
 }
 
-// NewTTLMap returns a new TTLMap.
-func NewTTLMap(maxItems int, name, namespace string) (m *TTLMap) {
+// NewTTLMap returns a new TTLMap that expires items by the wall clock.
+func NewTTLMap(maxItems int, name, namespace string) *TTLMap {
+	return NewTTLMapWithClock(maxItems, name, namespace, clock.New())
+}
+
+// NewTTLMapWithClock returns a new TTLMap that expires items by the given clock.
+func NewTTLMapWithClock(maxItems int, name, namespace string, clk clock.Clock) (m *TTLMap) {
 	m = &TTLMap{
 		m:        make(map[string]*item, maxItems),
 		maxItems: maxItems,
+		heaps:    make(map[Priority]*expiryHeap, len(evictableClasses)),
+		clock:    clk,
 		metrics:  NewMetrics(name, namespace+"_ttlmap"),
+		stop:     make(chan struct{}),
+	}
+
+	for _, class := range evictableClasses {
+		m.heaps[class] = &expiryHeap{}
 	}
 
-	go func() {
-		for now := range time.Tick(time.Second * 1) {
+	go m.run()
+
+	return
+}
+
+func (m *TTLMap) run() {
+	ticker := m.clock.NewTicker(time.Second * 1)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case now := <-ticker.C():
 			m.l.Lock()
-			for k, v := range m.m {
-				if v.invincible {
-					continue
-				}
-
-				if v.expiresAt.Before(now) {
-					m.delete(k, v.value, v.expiresAt)
-				}
-			}
+			m.expire(now)
 			m.l.Unlock()
+		case <-m.stop:
+			return
 		}
-	}()
+	}
+}
 
-	return
+// Stop stops expiring items. The map remains usable, but items will no longer expire on their own.
+func (m *TTLMap) Stop() {
+	m.stopOnce.Do(func() {
+		close(m.stop)
+	})
+}
+
+// UseBudget makes the map share the given memory budget with any other maps using it.
+func (m *TTLMap) UseBudget(b *Budget) {
+	if b == nil {
+		return
+	}
+
+	m.budget = b
+
+	b.register(m)
 }
 
 func (m *TTLMap) EnableMetrics(namespace string) {
@@ -61,10 +156,12 @@ func (m *TTLMap) EnableMetrics(namespace string) {
 
 	m.OnItemAdded(func(k string, v interface{}, e time.Time) {
 		m.metrics.ObserveLen(m.Len())
+		m.metrics.ObserveBytes(m.Bytes())
 	})
 
 	m.OnItemDeleted(func(k string, v interface{}, e time.Time) {
 		m.metrics.ObserveLen(m.Len())
+		m.metrics.ObserveBytes(m.Bytes())
 	})
 }
 
@@ -72,6 +169,13 @@ func (m *TTLMap) OnItemDeleted(f func(string, interface{}, time.Time)) {
 	m.deletedCallbacks = append(m.deletedCallbacks, f)
 }
 
+// OnItemDeletedSync registers a callback that runs synchronously whenever an item is deleted, evicted or
+// expired - before the deletion is visible to other callers. The map is locked while it runs, so the callback
+// must be quick and must not call back into the map.
+func (m *TTLMap) OnItemDeletedSync(f func(string, interface{})) {
+	m.deletedSyncCallbacks = append(m.deletedSyncCallbacks, f)
+}
+
 func (m *TTLMap) OnItemAdded(f func(string, interface{}, time.Time)) {
 	m.addedCallbacks = append(m.addedCallbacks, f)
 }
@@ -80,50 +184,71 @@ func (m *TTLMap) Delete(k string) {
 	m.l.Lock()
 	defer m.l.Unlock()
 
-	val, expiresAt, err := m.get(k)
-	if err != nil {
+	it, ok := m.m[k]
+	if !ok {
 		return
 	}
 
-	m.delete(k, val, expiresAt)
+	m.delete(it)
 }
 
-func (m *TTLMap) delete(k string, val interface{}, expiresAt time.Time) {
-	delete(m.m, k)
+func (m *TTLMap) delete(it *item) {
+	delete(m.m, it.key)
+
+	if h, ok := m.heaps[it.priority]; ok && it.index >= 0 {
+		heap.Remove(h, it.index)
+	}
+
+	m.bytes -= it.cost
+
+	if m.budget != nil {
+		m.budget.release(it.cost)
+	}
 
 	m.metrics.ObserveOperations(OperationDEL, 1)
 
+	for _, f := range m.deletedSyncCallbacks {
+		f(it.key, it.value)
+	}
+
 	for _, f := range m.deletedCallbacks {
-		go f(k, val, expiresAt)
+		go f(it.key, it.value, it.expiresAt)
 	}
 }
 
-func (m *TTLMap) evictItemToClosestToExpiry() {
-	// This is a very naive implementation.
-	evictableItems := make([]sortableItem, 0, len(m.m))
+// expire removes all evictable items that expired before now.
+func (m *TTLMap) expire(now time.Time) {
+	for _, class := range evictableClasses {
+		h := m.heaps[class]
 
-	// Get all non-invincible items.
-	for k, v := range m.m {
-		if v.invincible {
-			continue
+		for h.Len() > 0 && (*h)[0].expiresAt.Before(now) {
+			m.delete((*h)[0])
 		}
-
-		evictableItems = append(evictableItems, sortableItem{
-			key:       k,
-			expiresAt: v.expiresAt,
-		})
 	}
+}
 
-	if len(evictableItems) == 0 {
-		return
+// nextEvictable returns the item that would be evicted next, if any.
+func (m *TTLMap) nextEvictable() *item {
+	for _, class := range evictableClasses {
+		if h := m.heaps[class]; h.Len() > 0 {
+			return (*h)[0]
+		}
 	}
 
-	sort.Slice(evictableItems, func(i, j int) bool {
-		return evictableItems[i].expiresAt.Before(evictableItems[j].expiresAt)
-	})
+	return nil
+}
 
-	m.delete(evictableItems[0].key, evictableItems[0].expiresAt, evictableItems[0].expiresAt)
+// evictNext evicts the lowest priority item closest to expiry. It returns false if nothing could be evicted.
+func (m *TTLMap) evictNext() bool {
+	it := m.nextEvictable()
+	if it == nil {
+		return false
+	}
+
+	m.delete(it)
 	m.metrics.ObserveOperations(OperationEVICT, 1)
+
+	return true
 }
 
 func (m *TTLMap) Len() int {
@@ -137,26 +262,82 @@ func (m *TTLMap) len() int {
 	return len(m.m)
 }
 
+// Bytes returns the combined cost of all items in the map.
+func (m *TTLMap) Bytes() int64 {
+	m.l.RLock()
+	defer m.l.RUnlock()
+
+	return m.bytes
+}
+
+// Add adds an item with no declared cost. Invincible items are pinned.
 func (m *TTLMap) Add(k string, v interface{}, expiresAt time.Time, invincible bool) {
+	priority := PriorityNormal
+	if invincible {
+		priority = PriorityPinned
+	}
+
+	m.AddWithCost(k, v, expiresAt, 0, priority)
+}
+
+// AddWithCost adds an item that occupies cost bytes of the map's memory budget. If the budget would be
+// exceeded, items are evicted from all maps sharing the budget to make room. Pinned items are always added.
+func (m *TTLMap) AddWithCost(k string, v interface{}, expiresAt time.Time, cost int64, priority Priority) {
+	if m.budget != nil {
+		m.budget.mu.Lock()
+		defer m.budget.mu.Unlock()
+
+		if !m.has(k) {
+			m.budget.makeRoom(cost)
+		}
+	}
+
 	m.l.Lock()
 	defer m.l.Unlock()
 
-	m.add(k, v, expiresAt, invincible)
+	m.add(k, v, expiresAt, cost, priority)
 }
 
-func (m *TTLMap) add(k string, v interface{}, expiresAt time.Time, invincible bool) {
-	if m.len() >= m.maxItems {
-		m.evictItemToClosestToExpiry()
-	}
+// Has returns true if the key is in the map.
+func (m *TTLMap) Has(k string) bool {
+	return m.has(k)
+}
+
+func (m *TTLMap) has(k string) bool {
+	m.l.RLock()
+	defer m.l.RUnlock()
 
+	_, ok := m.m[k]
+
+	return ok
+}
+
+func (m *TTLMap) add(k string, v interface{}, expiresAt time.Time, cost int64, priority Priority) {
 	it, ok := m.m[k]
 	if !ok {
+		if m.len() >= m.maxItems {
+			m.evictNext()
+		}
+
 		it = &item{
-			value:      v,
-			expiresAt:  expiresAt,
-			invincible: invincible,
+			key:       k,
+			value:     v,
+			expiresAt: expiresAt,
+			cost:      cost,
+			priority:  priority,
+			index:     -1,
 		}
 		m.m[k] = it
+
+		if h, ok := m.heaps[priority]; ok {
+			heap.Push(h, it)
+		}
+
+		m.bytes += cost
+
+		if m.budget != nil {
+			m.budget.consume(cost)
+		}
 	}
 
 	m.metrics.ObserveOperations(OperationADD, 1)
@@ -166,13 +347,40 @@ func (m *TTLMap) add(k string, v interface{}, expiresAt time.Time, invincible bo
 	}
 }
 
+// SetPriority changes the priority class of an existing item.
+func (m *TTLMap) SetPriority(k string, priority Priority) error {
+	m.l.Lock()
+	defer m.l.Unlock()
+
+	it, ok := m.m[k]
+	if !ok {
+		return errors.New("not found")
+	}
+
+	if it.priority == priority {
+		return nil
+	}
+
+	if h, ok := m.heaps[it.priority]; ok && it.index >= 0 {
+		heap.Remove(h, it.index)
+	}
+
+	it.priority = priority
+
+	if h, ok := m.heaps[priority]; ok {
+		heap.Push(h, it)
+	}
+
+	return nil
+}
+
 func (m *TTLMap) Get(k string) (interface{}, time.Time, error) {
 	m.l.RLock()
 	itv, expires, err := m.get(k)
 	m.l.RUnlock()
 
 	if err != nil {
-		return nil, time.Now(), err
+		return nil, m.clock.Now(), err
 	}
 
 	return itv, expires, err
@@ -184,7 +392,7 @@ func (m *TTLMap) get(k string) (interface{}, time.Time, error) {
 	it, ok := m.m[k]
 	if !ok {
 		m.metrics.ObserveMiss()
-		return nil, time.Now(), errors.New("not found")
+		return nil, m.clock.Now(), errors.New("not found")
 	}
 
 	m.metrics.ObserveHit()
@@ -2,8 +2,11 @@ package cache
 
 import (
 	"fmt"
+	"sync/atomic"
 	"testing"
 	"time"
+
+	"github.com/ethpandaops/checkpointz/pkg/clock"
 )
 
 func TestItemAdds(t *testing.T) {
@@ -96,7 +99,7 @@ func TestMaxItemsEvictsOldest(t *testing.T) {
 func TestCallbacks(t *testing.T) {
 	instance := NewTTLMap(10, "", "")
 
-	evictedCallback := false
+	var evictedCallback atomic.Bool
 
 	instance.OnItemDeleted(func(key string, value interface{}, expiresAt time.Time) {
 		if key != "key4" {
@@ -107,10 +110,10 @@ func TestCallbacks(t *testing.T) {
 			t.Fatalf("Expected value to be value4, got %s", value)
 		}
 
-		evictedCallback = true
+		evictedCallback.Store(true)
 	})
 
-	addedCallback := false
+	var addedCallback atomic.Bool
 
 	instance.OnItemAdded(func(key string, value interface{}, expiresAt time.Time) {
 		if key != "key4" {
@@ -121,7 +124,7 @@ func TestCallbacks(t *testing.T) {
 			t.Fatalf("Expected value to be value4, got %s", value)
 		}
 
-		addedCallback = true
+		addedCallback.Store(true)
 	})
 
 	instance.Add("key4", "value4", time.Now().Add(time.Hour), false)
@@ -129,11 +132,11 @@ func TestCallbacks(t *testing.T) {
 
 	time.Sleep(time.Second * 1)
 
-	if !evictedCallback {
+	if !evictedCallback.Load() {
 		t.Fatalf("Expected evicted callback to have been called")
 	}
 
-	if !addedCallback {
+	if !addedCallback.Load() {
 		t.Fatalf("Expected added callback to have been called")
 	}
 }
@@ -172,3 +175,135 @@ func TestInvincible(t *testing.T) {
 		t.Error("key2 should not be found")
 	}
 }
+
+func TestMaxItemsEvictsLowPriorityFirst(t *testing.T) {
+	instance := NewTTLMap(2, "", "")
+
+	now := time.Now()
+
+	instance.AddWithCost("normal", "value", now.Add(time.Hour), 0, PriorityNormal)
+	instance.AddWithCost("low", "value", now.Add(2*time.Hour), 0, PriorityLow)
+	instance.AddWithCost("new", "value", now.Add(3*time.Hour), 0, PriorityNormal)
+
+	if _, _, err := instance.Get("low"); err == nil {
+		t.Fatalf("Expected low priority item to have been evicted")
+	}
+
+	if _, _, err := instance.Get("normal"); err != nil {
+		t.Fatalf("Expected normal priority item to not have been evicted")
+	}
+}
+
+func TestSetPriorityPinsItem(t *testing.T) {
+	start := time.Date(2020, 12, 1, 12, 0, 23, 0, time.UTC)
+	mock := clock.NewMock(start)
+
+	instance := NewTTLMapWithClock(10, "", "", mock)
+	defer instance.Stop()
+
+	instance.Add("key", "value", start.Add(time.Second), false)
+
+	if err := instance.SetPriority("key", PriorityPinned); err != nil {
+		t.Fatal(err)
+	}
+
+	// An unpinned item with the same expiry shows when the expiry loop has been past it.
+	instance.Add("sentinel", "value", start.Add(time.Second), false)
+
+	// Wait for the expiry loop to start ticking.
+	mock.BlockUntil(1)
+
+	mock.Advance(2 * time.Second)
+
+	waitForExpiry(t, instance, "sentinel")
+
+	if _, _, err := instance.Get("key"); err != nil {
+		t.Fatalf("Expected pinned item to not have expired")
+	}
+
+	if err := instance.SetPriority("key", PriorityNormal); err != nil {
+		t.Fatal(err)
+	}
+
+	mock.Advance(2 * time.Second)
+
+	waitForExpiry(t, instance, "key")
+
+	if err := instance.SetPriority("missing", PriorityPinned); err == nil {
+		t.Fatalf("Expected error for missing item")
+	}
+}
+
+// waitForExpiry waits for the expiry loop to remove the key.
+func waitForExpiry(t *testing.T, instance *TTLMap, key string) {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for instance.Has(key) {
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected %s to have expired", key)
+		}
+
+		time.Sleep(time.Millisecond * 10)
+	}
+}
+
+func TestBytesTracksCost(t *testing.T) {
+	instance := NewTTLMap(10, "", "")
+
+	instance.AddWithCost("key1", "value", time.Now().Add(time.Hour), 100, PriorityNormal)
+	instance.AddWithCost("key2", "value", time.Now().Add(time.Hour), 50, PriorityNormal)
+
+	if instance.Bytes() != 150 {
+		t.Fatalf("Expected 150 bytes, got %d", instance.Bytes())
+	}
+
+	instance.Delete("key1")
+
+	if instance.Bytes() != 50 {
+		t.Fatalf("Expected 50 bytes, got %d", instance.Bytes())
+	}
+}
+
+func TestStopHaltsExpiry(t *testing.T) {
+	instance := NewTTLMap(10, "", "")
+
+	instance.Stop()
+	instance.Stop()
+
+	instance.Add("key", "value", time.Now().Add(time.Millisecond*10), false)
+
+	time.Sleep(time.Millisecond * 1500)
+
+	if _, _, err := instance.Get("key"); err != nil {
+		t.Fatalf("Expected item to remain after the map was stopped: %v", err)
+	}
+}
+
+func TestItemExpiresInSimulatedTime(t *testing.T) {
+	start := time.Date(2020, 12, 1, 12, 0, 23, 0, time.UTC)
+	mock := clock.NewMock(start)
+
+	instance := NewTTLMapWithClock(10, "", "", mock)
+	defer instance.Stop()
+
+	instance.Add("key", "value", start.Add(time.Hour), false)
+	instance.Add("pinned", "value", start.Add(time.Hour), true)
+
+	// Wait for the expiry loop to start ticking.
+	mock.BlockUntil(1)
+
+	mock.Advance(time.Hour - time.Second)
+
+	if _, _, err := instance.Get("key"); err != nil {
+		t.Fatalf("Expected item to remain before its expiry: %v", err)
+	}
+
+	mock.Advance(2 * time.Second)
+
+	waitForExpiry(t, instance, "key")
+
+	if _, _, err := instance.Get("pinned"); err != nil {
+		t.Fatalf("Expected pinned item to remain: %v", err)
+	}
+}
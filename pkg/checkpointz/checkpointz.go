@@ -2,16 +2,26 @@ package checkpointz
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"io/fs"
 	"net/http"
+	"sync"
 	"time"
 
+	"github.com/ethpandaops/checkpointz/pkg/access"
 	"github.com/ethpandaops/checkpointz/pkg/api"
+	"github.com/ethpandaops/checkpointz/pkg/attestation"
 	"github.com/ethpandaops/checkpointz/pkg/beacon"
+	"github.com/ethpandaops/checkpointz/pkg/clientip"
+	"github.com/ethpandaops/checkpointz/pkg/clock"
+	"github.com/ethpandaops/checkpointz/pkg/compression"
+	"github.com/ethpandaops/checkpointz/pkg/ratelimit"
+	"github.com/ethpandaops/checkpointz/pkg/tlsconfig"
+	"github.com/ethpandaops/checkpointz/pkg/tracing"
 	"github.com/ethpandaops/checkpointz/pkg/version"
 	static "github.com/ethpandaops/checkpointz/web"
 	"github.com/julienschmidt/httprouter"
-	"github.com/nanmu42/gzip"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/sirupsen/logrus"
 )
@@ -27,9 +37,24 @@ type Server struct {
 	provider beacon.FinalityProvider
 
 	http *api.Handler
+
+	// certs is nil unless serving over TLS.
+	certs *tlsconfig.Reloader
+	// compressor is nil if compression is disabled.
+	compressor *compression.Compressor
+	// stopTracing flushes and stops exporting traces. It's nil if tracing is disabled.
+	stopTracing func(context.Context) error
+
+	// loops are the server's background loops. Stop cancels them with stopLoops and waits for them.
+	loops     sync.WaitGroup
+	stopLoops context.CancelFunc
+
+	server        *http.Server
+	metricsServer *http.Server
 }
 
-func NewServer(log *logrus.Logger, conf *Config) *Server {
+// NewServer returns a server for the config. clk drives every timer, poll and expiry in it.
+func NewServer(log *logrus.Logger, conf *Config, clk clock.Clock) *Server {
 	if err := conf.Validate(); err != nil {
 		log.Fatalf("invalid config: %s", err)
 	}
@@ -38,14 +63,80 @@ func NewServer(log *logrus.Logger, conf *Config) *Server {
 		namespace,
 		log,
 		conf.BeaconConfig.BeaconUpstreams,
+		conf.BeaconConfig.Archives,
 		&conf.Checkpointz,
+		clk,
 	)
 
+	var attestor *attestation.Attestor
+
+	if conf.GlobalConfig.Attestations.Enabled() {
+		signer, err := attestation.LoadSigner(conf.GlobalConfig.Attestations.KeyFile)
+		if err != nil {
+			log.Fatalf("invalid attestation config: %s", err)
+		}
+
+		attestor = attestation.NewAttestor(signer, conf.GlobalConfig.Attestations.HistorySize)
+
+		log.Infof("Signing served checkpoints with attestation key %#x", []byte(signer.PublicKey()))
+	}
+
+	clientIPs, err := clientip.New(conf.GlobalConfig.TrustedProxies)
+	if err != nil {
+		log.Fatalf("invalid trusted proxies: %s", err)
+	}
+
+	var limiter *ratelimit.Limiter
+
+	if conf.GlobalConfig.RateLimit.Enabled {
+		limiter = ratelimit.New(conf.GlobalConfig.RateLimit, clk)
+	}
+
+	var controller *access.Controller
+
+	if conf.GlobalConfig.Access.Enabled {
+		controller, err = access.New(conf.GlobalConfig.Access, clk)
+		if err != nil {
+			log.Fatalf("invalid access config: %s", err)
+		}
+	}
+
+	var certs *tlsconfig.Reloader
+
+	if conf.GlobalConfig.TLS.Enabled() {
+		certs, err = tlsconfig.NewReloader(log, conf.GlobalConfig.TLS, clk)
+		if err != nil {
+			log.Fatalf("invalid tls config: %s", err)
+		}
+	}
+
+	var compressor *compression.Compressor
+
+	if conf.GlobalConfig.Compression.Enabled {
+		compressor = compression.New(conf.GlobalConfig.Compression, namespace, clk)
+		compressor.EnableMetrics(namespace)
+	}
+
+	var stopTracing func(context.Context) error
+
+	if conf.GlobalConfig.Tracing.Enabled {
+		stopTracing, err = tracing.Setup(context.Background(), conf.GlobalConfig.Tracing)
+		if err != nil {
+			log.Fatalf("invalid tracing config: %s", err)
+		}
+
+		log.Infof("Exporting traces as %s", conf.GlobalConfig.Tracing.ServiceName)
+	}
+
 	s := &Server{
 		Cfg: *conf,
 		log: log,
 
-		http: api.NewHandler(log, provider, &conf.Checkpointz),
+		http:       api.NewHandler(log, provider, &conf.Checkpointz, attestor, clientIPs, limiter, controller, compressor),
+		certs:      certs,
+		compressor: compressor,
+
+		stopTracing: stopTracing,
 
 		provider: provider,
 	}
@@ -56,7 +147,12 @@ func NewServer(log *logrus.Logger, conf *Config) *Server {
 func (s *Server) Start(ctx context.Context) error {
 	s.log.Infof("Starting Checkpointz server (%s)", version.Short())
 
-	s.provider.StartAsync(ctx)
+	// The provider outlives ctx so that requests can still be served while they drain. Stop stops it.
+	s.provider.StartAsync(context.WithoutCancel(ctx))
+
+	// So do the server's own loops, e.g. reloading certificates for connections made while draining.
+	loopsCtx, stopLoops := context.WithCancel(context.WithoutCancel(ctx))
+	s.stopLoops = stopLoops
 
 	router := httprouter.New()
 
@@ -73,51 +169,128 @@ func (s *Server) Start(ctx context.Context) error {
 		router.NotFound = http.FileServer(http.FS(frontend))
 	}
 
-	if err := s.ServeMetrics(ctx); err != nil {
-		return err
-	}
-
-	server := &http.Server{
+	s.server = &http.Server{
 		Addr:              s.Cfg.GlobalConfig.ListenAddr,
 		ReadHeaderTimeout: 3 * time.Minute,
 		WriteTimeout:      15 * time.Minute,
 	}
 
-	// Gzip any content longer than 1024 bytes if requested via the Accept-Encoding header
-	gzipHandler := gzip.NewHandler(gzip.Config{
-		CompressionLevel: 6,
-		MinContentLength: 1024,
-		RequestFilter: []gzip.RequestFilter{
-			gzip.NewCommonRequestFilter(),
-		},
-		ResponseHeaderFilter: []gzip.ResponseHeaderFilter{},
-	})
-	server.Handler = gzipHandler.WrapHandler(router)
+	if s.certs != nil {
+		s.server.TLSConfig = s.certs.TLSConfig()
 
-	s.log.Infof("Serving http at %s", s.Cfg.GlobalConfig.ListenAddr)
+		s.server.Protocols = new(http.Protocols)
+		s.server.Protocols.SetHTTP1(true)
+		s.server.Protocols.SetHTTP2(s.Cfg.GlobalConfig.TLS.HTTP2)
 
-	if err := server.ListenAndServe(); err != nil {
-		s.log.Fatal(err)
+		s.runLoop(loopsCtx, s.certs.Run)
 	}
 
-	return nil
+	s.server.Handler = router
+
+	// Compress content with the encoding requested via the Accept-Encoding header. Immutable payloads are
+	// compressed once by the API handler, and passed through.
+	if s.compressor != nil {
+		s.server.Handler = s.compressor.Handler(router)
+	}
+
+	s.metricsServer = &http.Server{
+		Addr:              s.Cfg.GlobalConfig.MetricsAddr,
+		ReadHeaderTimeout: 15 * time.Second,
+		Handler:           promhttp.Handler(),
+	}
+
+	errs := make(chan error, 2)
+
+	s.log.Infof("Serving metrics at %s", s.Cfg.GlobalConfig.MetricsAddr)
+
+	go serve(s.metricsServer, errs)
+
+	if s.certs != nil {
+		s.log.Infof("Serving https at %s", s.Cfg.GlobalConfig.ListenAddr)
+	} else {
+		s.log.Infof("Serving http at %s", s.Cfg.GlobalConfig.ListenAddr)
+	}
+
+	go serve(s.server, errs)
+
+	var err error
+
+	select {
+	case <-ctx.Done():
+		s.log.Info("Shutting down")
+	case err = <-errs:
+		s.log.WithError(err).Error("Server failed, shutting down")
+	}
+
+	stopCtx, cancel := context.WithTimeout(context.Background(), s.Cfg.GlobalConfig.ShutdownTimeout)
+	defer cancel()
+
+	return errors.Join(err, s.Stop(stopCtx))
 }
 
-func (s *Server) ServeMetrics(ctx context.Context) error {
-	go func() {
-		server := &http.Server{
-			Addr:              s.Cfg.GlobalConfig.MetricsAddr,
-			ReadHeaderTimeout: 15 * time.Second,
+// Stop gracefully shuts down the HTTP servers, waiting for in-flight requests to complete, and then stops
+// the provider. Anything still running once ctx is done is cut off.
+func (s *Server) Stop(ctx context.Context) error {
+	var errs []error
+
+	if s.server != nil {
+		if err := s.server.Shutdown(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("failed to drain http server: %w", err))
+		}
+	}
+
+	if s.metricsServer != nil {
+		if err := s.metricsServer.Shutdown(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("failed to drain metrics server: %w", err))
 		}
+	}
+
+	if s.stopLoops != nil {
+		s.stopLoops()
+	}
+
+	s.loops.Wait()
 
-		server.Handler = promhttp.Handler()
+	if err := s.provider.Stop(ctx); err != nil {
+		errs = append(errs, fmt.Errorf("failed to stop provider: %w", err))
+	}
 
-		s.log.Infof("Serving metrics at %s", s.Cfg.GlobalConfig.MetricsAddr)
+	if s.compressor != nil {
+		s.compressor.Stop()
+	}
 
-		if err := server.ListenAndServe(); err != nil {
-			s.log.Fatal(err)
+	// Traces are flushed last, so that spans ended while draining are exported.
+	if s.stopTracing != nil {
+		if err := s.stopTracing(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("failed to flush traces: %w", err))
 		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// runLoop runs loop in the background until ctx is done. Stop waits for it to return.
+func (s *Server) runLoop(ctx context.Context, loop func(ctx context.Context)) {
+	s.loops.Add(1)
+
+	go func() {
+		defer s.loops.Done()
+
+		loop(ctx)
 	}()
+}
 
-	return nil
+// serve serves over TLS if the server has a TLS config, which supplies the certificate.
+func serve(server *http.Server, errs chan<- error) {
+	var err error
+
+	if server.TLSConfig != nil {
+		err = server.ListenAndServeTLS("", "")
+	} else {
+		err = server.ListenAndServe()
+	}
+
+	if err != nil && !errors.Is(err, http.ErrServerClosed) {
+		errs <- err
+	}
 }
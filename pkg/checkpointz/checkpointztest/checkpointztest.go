@@ -0,0 +1,318 @@
+// Package checkpointztest runs whole Checkpointz servers against fake beacon nodes for end-to-end tests.
+package checkpointztest
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/creasty/defaults"
+	"github.com/ethpandaops/checkpointz/pkg/beacon"
+	"github.com/ethpandaops/checkpointz/pkg/beacon/beacontest"
+	"github.com/ethpandaops/checkpointz/pkg/beacon/node"
+	"github.com/ethpandaops/checkpointz/pkg/checkpointz"
+	"github.com/ethpandaops/checkpointz/pkg/clock"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	// Epochs is how many epochs the chains returned by NewChain have.
+	Epochs = 8
+	// FinalizedEpoch is the epoch the nodes returned by NewNode report as finalized.
+	FinalizedEpoch = 5
+
+	// timeout covers upstream health checks settling and the provider's startup polling.
+	timeout = 90 * time.Second
+	// poll is how often conditions are checked while waiting, and step how far the servers' clock is moved on
+	// each check, so that their polls and timers don't hold the test up.
+	poll = 100 * time.Millisecond
+	step = time.Second
+)
+
+// Upstream is a fake beacon node a server is configured with.
+type Upstream struct {
+	Node         *beacontest.Node
+	DataProvider bool
+}
+
+// Server is a running Checkpointz server.
+type Server struct {
+	t *testing.T
+
+	// URL is the server's base URL.
+	URL string
+	// Clock is the mock clock the server runs on.
+	Clock *clock.Mock
+
+	stop func()
+}
+
+var (
+	clocksMu sync.Mutex
+	clocks   = map[*testing.T]*clock.Mock{}
+)
+
+// Clock returns the mock clock the test's servers run on. Servers started by the same test share it, so
+// that leases and expiries agree between them.
+func Clock(t *testing.T) *clock.Mock {
+	t.Helper()
+
+	clocksMu.Lock()
+	defer clocksMu.Unlock()
+
+	if c, ok := clocks[t]; ok {
+		return c
+	}
+
+	c := clock.NewMock(time.Now())
+	clocks[t] = c
+
+	t.Cleanup(func() {
+		clocksMu.Lock()
+		defer clocksMu.Unlock()
+
+		delete(clocks, t)
+	})
+
+	return c
+}
+
+// NewChain returns a chain of Epochs epochs.
+func NewChain(t *testing.T) *beacontest.Chain {
+	t.Helper()
+
+	chain, err := beacontest.NewChain(Epochs)
+	require.NoError(t, err)
+
+	return chain
+}
+
+// NewNode returns a fake beacon node serving chain with FinalizedEpoch finalized.
+func NewNode(chain *beacontest.Chain) *beacontest.Node {
+	return beacontest.NewNode(chain, beacontest.Scenario{FinalizedEpoch: FinalizedEpoch})
+}
+
+// Start runs a full Checkpointz server against the given fake upstreams until the test ends. configure,
+// if not nil, adjusts the config before the server is created. The upstreams are closed when the test ends.
+func Start(t *testing.T, mode beacon.OperatingMode, configure func(*checkpointz.Config), upstreams ...Upstream) *Server {
+	t.Helper()
+
+	if testing.Short() {
+		t.Skip("skipping end-to-end test in short mode")
+	}
+
+	isolateMetrics(t)
+
+	config := &checkpointz.Config{}
+	require.NoError(t, defaults.Set(config))
+
+	config.GlobalConfig.ListenAddr = freeAddr(t)
+	config.GlobalConfig.MetricsAddr = freeAddr(t)
+	config.Checkpointz.Mode = mode
+	config.Checkpointz.HistoricalEpochCount = 3
+
+	for i, u := range upstreams {
+		upstreamConfig := node.Config{}
+		require.NoError(t, defaults.Set(&upstreamConfig))
+
+		upstreamConfig.Name = fmt.Sprintf("upstream-%d", i)
+		upstreamConfig.Address = u.Node.URL()
+		upstreamConfig.DataProvider = u.DataProvider
+		upstreamConfig.Retry.InitialBackoff = 10 * time.Millisecond
+		upstreamConfig.Retry.MaxBackoff = 10 * time.Millisecond
+
+		config.BeaconConfig.BeaconUpstreams = append(config.BeaconConfig.BeaconUpstreams, upstreamConfig)
+	}
+
+	if configure != nil {
+		configure(config)
+	}
+
+	log := logrus.New()
+	log.SetOutput(io.Discard)
+
+	if testing.Verbose() {
+		log.SetOutput(&testWriter{t: t})
+		log.SetLevel(logrus.InfoLevel)
+	}
+
+	clk := Clock(t)
+	server := checkpointz.NewServer(log, config, clk)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+
+	go func() {
+		done <- server.Start(ctx)
+	}()
+
+	scheme := "http"
+	if config.GlobalConfig.TLS.Enabled() {
+		scheme = "https"
+	}
+
+	s := &Server{
+		t:     t,
+		URL:   scheme + "://" + config.GlobalConfig.ListenAddr,
+		Clock: clk,
+	}
+
+	s.stop = sync.OnceFunc(func() {
+		cancel()
+
+		select {
+		case err := <-done:
+			assert.NoError(t, err)
+		case <-time.After(config.GlobalConfig.ShutdownTimeout + 5*time.Second):
+			t.Error("server did not shut down")
+		}
+	})
+
+	t.Cleanup(s.stop)
+
+	for _, u := range upstreams {
+		t.Cleanup(u.Node.Close)
+	}
+
+	return s
+}
+
+// Stop shuts the server down before the test ends.
+func (s *Server) Stop() {
+	s.stop()
+}
+
+// ServedRoot returns the root of the finalized block Checkpointz is serving, if any.
+func (s *Server) ServedRoot() (phase0.Root, bool) {
+	rsp, err := http.Get(s.URL + "/eth/v1/beacon/blocks/finalized/root")
+	if err != nil {
+		return phase0.Root{}, false
+	}
+	defer rsp.Body.Close()
+
+	if rsp.StatusCode != http.StatusOK {
+		return phase0.Root{}, false
+	}
+
+	var body struct {
+		Data struct {
+			Root string `json:"root"`
+		} `json:"data"`
+	}
+
+	if err := json.NewDecoder(rsp.Body).Decode(&body); err != nil {
+		return phase0.Root{}, false
+	}
+
+	var root phase0.Root
+
+	if n, err := hex.Decode(root[:], []byte(strings.TrimPrefix(body.Data.Root, "0x"))); err != nil || n != len(root) {
+		return phase0.Root{}, false
+	}
+
+	return root, true
+}
+
+// RequireServes waits until Checkpointz serves the given root as its finalized checkpoint.
+func (s *Server) RequireServes(root phase0.Root) {
+	s.t.Helper()
+
+	s.Eventually(func() bool {
+		served, ok := s.ServedRoot()
+
+		return ok && served == root
+	}, "expected checkpointz to serve %s", root)
+}
+
+// Eventually waits for the condition to hold, moving the server's clock on between checks.
+func (s *Server) Eventually(condition func() bool, msgAndArgs ...interface{}) {
+	s.t.Helper()
+
+	require.Eventually(s.t, func() bool {
+		s.Clock.Advance(step)
+
+		return condition()
+	}, timeout, poll, msgAndArgs...)
+}
+
+// Wait lets d of the server's time pass, giving its loops a chance to run on the way.
+func (s *Server) Wait(d time.Duration) {
+	for elapsed := time.Duration(0); elapsed < d; elapsed += step {
+		s.Clock.Advance(step)
+		time.Sleep(poll)
+	}
+}
+
+// Get requests path from the server with the given Accept header and any extra headers, and returns the
+// response along with its body.
+func (s *Server) Get(path, accept string, headers ...map[string]string) (*http.Response, []byte) {
+	s.t.Helper()
+
+	req, err := http.NewRequest(http.MethodGet, s.URL+path, http.NoBody)
+	require.NoError(s.t, err)
+
+	req.Header.Set("Accept", accept)
+
+	for _, h := range headers {
+		for header, value := range h {
+			req.Header.Set(header, value)
+		}
+	}
+
+	rsp, err := http.DefaultClient.Do(req)
+	require.NoError(s.t, err)
+
+	defer rsp.Body.Close()
+
+	body, err := io.ReadAll(rsp.Body)
+	require.NoError(s.t, err)
+
+	return rsp, body
+}
+
+// isolateMetrics gives the test its own prometheus registry, as every server registers the same metrics.
+func isolateMetrics(t *testing.T) {
+	t.Helper()
+
+	registerer, gatherer := prometheus.DefaultRegisterer, prometheus.DefaultGatherer
+	registry := prometheus.NewRegistry()
+
+	prometheus.DefaultRegisterer, prometheus.DefaultGatherer = registry, registry
+
+	t.Cleanup(func() {
+		prometheus.DefaultRegisterer, prometheus.DefaultGatherer = registerer, gatherer
+	})
+}
+
+func freeAddr(t *testing.T) string {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	defer listener.Close()
+
+	return listener.Addr().String()
+}
+
+type testWriter struct {
+	t *testing.T
+}
+
+func (w *testWriter) Write(p []byte) (int, error) {
+	w.t.Log(string(p))
+
+	return len(p), nil
+}
@@ -1,10 +1,19 @@
 package checkpointz
 
 import (
+	"errors"
 	"fmt"
+	"time"
 
+	"github.com/ethpandaops/checkpointz/pkg/access"
 	"github.com/ethpandaops/checkpointz/pkg/beacon"
+	"github.com/ethpandaops/checkpointz/pkg/beacon/archive"
 	"github.com/ethpandaops/checkpointz/pkg/beacon/node"
+	"github.com/ethpandaops/checkpointz/pkg/clientip"
+	"github.com/ethpandaops/checkpointz/pkg/compression"
+	"github.com/ethpandaops/checkpointz/pkg/ratelimit"
+	"github.com/ethpandaops/checkpointz/pkg/tlsconfig"
+	"github.com/ethpandaops/checkpointz/pkg/tracing"
 )
 
 type Config struct {
@@ -17,10 +26,42 @@ type GlobalConfig struct {
 	ListenAddr   string `yaml:"listenAddr" default:":5555"`
 	LoggingLevel string `yaml:"logging" default:"warn"`
 	MetricsAddr  string `yaml:"metricsAddr" default:":9090"`
+	// TLS configures serving the public HTTP API over HTTPS.
+	TLS tlsconfig.Config `yaml:"tls"`
+	// Compression configures compression of the public HTTP API's responses.
+	Compression compression.Config `yaml:"compression"`
+	// ShutdownTimeout is how long in-flight requests are given to complete when shutting down.
+	ShutdownTimeout time.Duration `yaml:"shutdownTimeout" default:"30s"`
+	// Attestations configures signing of the checkpoints being served.
+	Attestations AttestationConfig `yaml:"attestations"`
+	// TrustedProxies are the addresses or CIDRs of proxies whose X-Forwarded-For and X-Real-IP headers are
+	// believed when working out a client's IP.
+	TrustedProxies []string `yaml:"trustedProxies"`
+	// RateLimit configures rate limiting and concurrency control of the public HTTP API.
+	RateLimit ratelimit.Config `yaml:"rateLimit"`
+	// Access configures which clients may access which routes of the public HTTP API.
+	Access access.Config `yaml:"access"`
+	// Tracing configures exporting OpenTelemetry traces of API requests and bundle downloads.
+	Tracing tracing.Config `yaml:"tracing"`
+}
+
+type AttestationConfig struct {
+	// KeyFile is the path to a file holding a hex encoded ed25519 seed that served checkpoints are signed
+	// with. Attestations are disabled if it's empty.
+	KeyFile string `yaml:"keyFile"`
+	// HistorySize is how many signed checkpoints are kept and published.
+	HistorySize int `yaml:"historySize" default:"256"`
+}
+
+func (c *AttestationConfig) Enabled() bool {
+	return c.KeyFile != ""
 }
 
 type BeaconConfig struct {
 	BeaconUpstreams []node.Config `yaml:"upstreams"`
+	// Archives are local directories of era or SSZ files that historical blocks and states are read from
+	// before asking an upstream. They don't take part in the finality vote.
+	Archives []archive.Config `yaml:"archives"`
 }
 
 func (c *Config) Validate() error {
@@ -28,6 +69,10 @@ func (c *Config) Validate() error {
 	duplicates := make(map[string]struct{})
 
 	for _, u := range c.BeaconConfig.BeaconUpstreams {
+		if err := u.Validate(); err != nil {
+			return fmt.Errorf("invalid upstream %s: %s", u.Name, err)
+		}
+
 		if _, ok := duplicates[u.Name]; ok {
 			return fmt.Errorf("there's a duplicate upstream with the same name: %s", u.Name)
 		}
@@ -40,6 +85,50 @@ func (c *Config) Validate() error {
 		duplicates[u.Address] = struct{}{}
 	}
 
+	for _, a := range c.BeaconConfig.Archives {
+		if err := a.Validate(); err != nil {
+			return fmt.Errorf("invalid archive %s: %s", a.Name, err)
+		}
+
+		if _, ok := duplicates[a.Name]; ok {
+			return fmt.Errorf("there's a duplicate upstream or archive with the same name: %s", a.Name)
+		}
+
+		duplicates[a.Name] = struct{}{}
+	}
+
+	if c.GlobalConfig.ShutdownTimeout <= 0 {
+		return errors.New("global.shutdownTimeout must be positive")
+	}
+
+	if c.GlobalConfig.Attestations.Enabled() && c.GlobalConfig.Attestations.HistorySize <= 0 {
+		return errors.New("global.attestations.historySize must be positive")
+	}
+
+	if err := c.GlobalConfig.TLS.Validate(); err != nil {
+		return fmt.Errorf("invalid global.tls config: %s", err)
+	}
+
+	if err := c.GlobalConfig.Compression.Validate(); err != nil {
+		return fmt.Errorf("invalid global.compression config: %s", err)
+	}
+
+	if _, err := clientip.New(c.GlobalConfig.TrustedProxies); err != nil {
+		return fmt.Errorf("invalid global.trustedProxies config: %s", err)
+	}
+
+	if err := c.GlobalConfig.Access.Validate(); err != nil {
+		return fmt.Errorf("invalid global.access config: %s", err)
+	}
+
+	if err := c.GlobalConfig.Tracing.Validate(); err != nil {
+		return fmt.Errorf("invalid global.tracing config: %s", err)
+	}
+
+	if err := c.GlobalConfig.RateLimit.Validate(); err != nil {
+		return fmt.Errorf("invalid global.rateLimit config: %s", err)
+	}
+
 	if err := c.Checkpointz.Validate(); err != nil {
 		return fmt.Errorf("invalid checkpointz config: %s", err)
 	}
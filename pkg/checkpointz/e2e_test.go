@@ -0,0 +1,147 @@
+package checkpointz_test
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/attestantio/go-eth2-client/spec/deneb"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/ethpandaops/checkpointz/pkg/beacon"
+	"github.com/ethpandaops/checkpointz/pkg/beacon/beacontest"
+	"github.com/ethpandaops/checkpointz/pkg/checkpointz/checkpointztest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestE2EServesFinalizedBundle(t *testing.T) {
+	chain := checkpointztest.NewChain(t)
+
+	server := checkpointztest.Start(t, beacon.OperatingModeFull, nil,
+		checkpointztest.Upstream{Node: checkpointztest.NewNode(chain), DataProvider: true},
+		checkpointztest.Upstream{Node: checkpointztest.NewNode(chain), DataProvider: true},
+	)
+
+	server.RequireServes(chain.Root(checkpointztest.FinalizedEpoch))
+
+	rsp, body := server.Get("/eth/v2/debug/beacon/states/finalized", "application/octet-stream")
+	require.Equal(t, http.StatusOK, rsp.StatusCode)
+
+	beaconState := &deneb.BeaconState{}
+	require.NoError(t, beaconState.UnmarshalSSZ(body))
+
+	expected, _ := chain.State(checkpointztest.FinalizedEpoch)
+	assert.Equal(t, expected.Deneb.Slot, beaconState.Slot)
+
+	rsp, body = server.Get("/eth/v2/beacon/blocks/finalized", "application/octet-stream")
+	require.Equal(t, http.StatusOK, rsp.StatusCode)
+
+	block := &deneb.SignedBeaconBlock{}
+	require.NoError(t, block.UnmarshalSSZ(body))
+
+	root, err := block.Message.HashTreeRoot()
+	require.NoError(t, err)
+	assert.Equal(t, chain.Root(checkpointztest.FinalizedEpoch), phase0.Root(root))
+
+	// Historical epoch boundaries and genesis are backfilled.
+	server.Eventually(func() bool {
+		rsp, _ := server.Get(fmt.Sprintf("/eth/v2/beacon/blocks/%d", (checkpointztest.FinalizedEpoch-1)*beacontest.SlotsPerEpoch), "application/json")
+
+		return rsp.StatusCode == http.StatusOK
+	})
+
+	server.Eventually(func() bool {
+		rsp, _ := server.Get("/eth/v2/debug/beacon/states/genesis", "application/octet-stream")
+
+		return rsp.StatusCode == http.StatusOK
+	})
+}
+
+func TestE2EFollowsFinalityEvents(t *testing.T) {
+	chain := checkpointztest.NewChain(t)
+
+	nodes := []*beacontest.Node{
+		checkpointztest.NewNode(chain),
+		checkpointztest.NewNode(chain),
+	}
+
+	server := checkpointztest.Start(t, beacon.OperatingModeLight, nil,
+		checkpointztest.Upstream{Node: nodes[0], DataProvider: true},
+		checkpointztest.Upstream{Node: nodes[1], DataProvider: true},
+	)
+
+	server.RequireServes(chain.Root(checkpointztest.FinalizedEpoch))
+
+	// Give the event subscriptions a moment to open.
+	server.Eventually(func() bool {
+		return nodes[0].Requests("/eth/v1/events") > 0 && nodes[1].Requests("/eth/v1/events") > 0
+	})
+
+	for _, n := range nodes {
+		n.Finalize(checkpointztest.FinalizedEpoch + 1)
+	}
+
+	server.RequireServes(chain.Root(checkpointztest.FinalizedEpoch + 1))
+}
+
+func TestE2EIgnoresLaggingNode(t *testing.T) {
+	chain := checkpointztest.NewChain(t)
+
+	server := checkpointztest.Start(t, beacon.OperatingModeLight, nil,
+		checkpointztest.Upstream{Node: checkpointztest.NewNode(chain), DataProvider: true},
+		checkpointztest.Upstream{Node: checkpointztest.NewNode(chain), DataProvider: true},
+		checkpointztest.Upstream{Node: beacontest.NewNode(chain, beacontest.Scenario{FinalizedEpoch: checkpointztest.FinalizedEpoch - 3}), DataProvider: true},
+	)
+
+	server.RequireServes(chain.Root(checkpointztest.FinalizedEpoch))
+}
+
+func TestE2EConflictingFinalityFollowsMajority(t *testing.T) {
+	chain := checkpointztest.NewChain(t)
+
+	fork, err := chain.Fork(checkpointztest.FinalizedEpoch - 2)
+	require.NoError(t, err)
+
+	server := checkpointztest.Start(t, beacon.OperatingModeLight, nil,
+		checkpointztest.Upstream{Node: checkpointztest.NewNode(chain), DataProvider: true},
+		checkpointztest.Upstream{Node: checkpointztest.NewNode(chain), DataProvider: true},
+		checkpointztest.Upstream{Node: beacontest.NewNode(fork, beacontest.Scenario{FinalizedEpoch: checkpointztest.FinalizedEpoch}), DataProvider: false},
+	)
+
+	server.RequireServes(chain.Root(checkpointztest.FinalizedEpoch))
+}
+
+func TestE2ERejectsWrongRoots(t *testing.T) {
+	chain := checkpointztest.NewChain(t)
+
+	wrong := beacontest.NewNode(chain, beacontest.Scenario{FinalizedEpoch: checkpointztest.FinalizedEpoch, WrongBlocks: true})
+
+	server := checkpointztest.Start(t, beacon.OperatingModeLight, nil,
+		checkpointztest.Upstream{Node: checkpointztest.NewNode(chain), DataProvider: false},
+		checkpointztest.Upstream{Node: wrong, DataProvider: true},
+	)
+
+	// The only data provider serves blocks that don't match the requested roots, so nothing can be served.
+	server.Eventually(func() bool {
+		return wrong.Requests(fmt.Sprintf("/eth/v2/beacon/blocks/%s", chain.Root(checkpointztest.FinalizedEpoch))) > 0
+	})
+
+	_, served := server.ServedRoot()
+	assert.False(t, served, "a block with the wrong root must not be served")
+
+	wrong.SetScenario(beacontest.Scenario{FinalizedEpoch: checkpointztest.FinalizedEpoch})
+
+	server.RequireServes(chain.Root(checkpointztest.FinalizedEpoch))
+}
+
+func TestE2ESlowUpstreams(t *testing.T) {
+	chain := checkpointztest.NewChain(t)
+
+	server := checkpointztest.Start(t, beacon.OperatingModeFull, nil,
+		checkpointztest.Upstream{Node: beacontest.NewNode(chain, beacontest.Scenario{FinalizedEpoch: checkpointztest.FinalizedEpoch, Delay: 300 * time.Millisecond}), DataProvider: true},
+		checkpointztest.Upstream{Node: beacontest.NewNode(chain, beacontest.Scenario{FinalizedEpoch: checkpointztest.FinalizedEpoch, Delay: 300 * time.Millisecond}), DataProvider: true},
+	)
+
+	server.RequireServes(chain.Root(checkpointztest.FinalizedEpoch))
+}
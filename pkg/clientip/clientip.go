@@ -0,0 +1,131 @@
+// Package clientip works out which client made an HTTP request, believing forwarding headers only from
+// trusted proxies.
+package clientip
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// Resolver returns the IP of the client that made a request.
+type Resolver struct {
+	proxies []*net.IPNet
+}
+
+// New returns a Resolver that believes the forwarding headers of the given proxy addresses or CIDRs.
+func New(trustedProxies []string) (*Resolver, error) {
+	proxies, err := ParseNetworks(trustedProxies)
+	if err != nil {
+		return nil, fmt.Errorf("invalid trusted proxy: %w", err)
+	}
+
+	return &Resolver{proxies: proxies}, nil
+}
+
+// ClientIP returns the IP of the client that made r. Forwarding headers are only believed when the request
+// came through a trusted proxy, and X-Forwarded-For is read from the right, skipping trusted proxies, so a
+// client can't spoof its address by prepending to it.
+func (c *Resolver) ClientIP(r *http.Request) string {
+	remote := remoteIP(r.RemoteAddr)
+	if !c.trusted(remote) {
+		return remote
+	}
+
+	if forwarded := r.Header.Values("X-Forwarded-For"); len(forwarded) > 0 {
+		hops := strings.Split(strings.Join(forwarded, ","), ",")
+
+		for i := len(hops) - 1; i >= 0; i-- {
+			hop := strings.TrimSpace(hops[i])
+			if net.ParseIP(hop) == nil {
+				break
+			}
+
+			if !c.trusted(hop) {
+				return hop
+			}
+		}
+	}
+
+	if realIP := strings.TrimSpace(r.Header.Get("X-Real-IP")); net.ParseIP(realIP) != nil {
+		return realIP
+	}
+
+	return remote
+}
+
+// FromTrustedProxy returns true if r came straight from a trusted proxy, so headers it set can be believed.
+func (c *Resolver) FromTrustedProxy(r *http.Request) bool {
+	return c.trusted(remoteIP(r.RemoteAddr))
+}
+
+func (c *Resolver) trusted(ip string) bool {
+	return Contains(c.proxies, ip)
+}
+
+// Contains returns true if ip is in any of networks.
+func Contains(networks []*net.IPNet, ip string) bool {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return false
+	}
+
+	for _, network := range networks {
+		if network.Contains(parsed) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ParseNetwork parses an address or CIDR into a network. An address is a network of just itself.
+func ParseNetwork(s string) (*net.IPNet, error) {
+	if !strings.Contains(s, "/") {
+		ip := net.ParseIP(s)
+		if ip == nil {
+			return nil, fmt.Errorf("invalid address: %q", s)
+		}
+
+		bits := 8 * net.IPv6len
+		if ip.To4() != nil {
+			ip = ip.To4()
+			bits = 8 * net.IPv4len
+		}
+
+		return &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)}, nil
+	}
+
+	_, network, err := net.ParseCIDR(s)
+	if err != nil {
+		return nil, err
+	}
+
+	return network, nil
+}
+
+// ParseNetworks parses addresses or CIDRs into networks.
+func ParseNetworks(s []string) ([]*net.IPNet, error) {
+	networks := make([]*net.IPNet, 0, len(s))
+
+	for _, n := range s {
+		network, err := ParseNetwork(n)
+		if err != nil {
+			return nil, err
+		}
+
+		networks = append(networks, network)
+	}
+
+	return networks, nil
+}
+
+func remoteIP(addr string) string {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return addr
+	}
+
+	return host
+}
@@ -0,0 +1,94 @@
+package clientip
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientIP(t *testing.T) {
+	resolver, err := New([]string{"10.0.0.0/8", "192.168.1.1"})
+	require.NoError(t, err)
+
+	for name, test := range map[string]struct {
+		remote   string
+		headers  map[string]string
+		expected string
+	}{
+		"direct": {
+			remote:   "1.1.1.1:1234",
+			expected: "1.1.1.1",
+		},
+		"untrusted proxy headers are ignored": {
+			remote:   "1.1.1.1:1234",
+			headers:  map[string]string{"X-Forwarded-For": "2.2.2.2", "X-Real-IP": "2.2.2.2"},
+			expected: "1.1.1.1",
+		},
+		"trusted proxy": {
+			remote:   "10.0.0.1:1234",
+			headers:  map[string]string{"X-Forwarded-For": "2.2.2.2"},
+			expected: "2.2.2.2",
+		},
+		"chain of trusted proxies": {
+			remote:   "10.0.0.1:1234",
+			headers:  map[string]string{"X-Forwarded-For": "2.2.2.2, 192.168.1.1, 10.0.0.2"},
+			expected: "2.2.2.2",
+		},
+		"spoofed hops are skipped": {
+			remote:   "10.0.0.1:1234",
+			headers:  map[string]string{"X-Forwarded-For": "9.9.9.9, 2.2.2.2"},
+			expected: "2.2.2.2",
+		},
+		"real ip": {
+			remote:   "192.168.1.1:1234",
+			headers:  map[string]string{"X-Real-IP": "2.2.2.2"},
+			expected: "2.2.2.2",
+		},
+		"ipv6": {
+			remote:   "[2001:db8::1]:1234",
+			expected: "2001:db8::1",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			r, err := http.NewRequest(http.MethodGet, "/", http.NoBody)
+			require.NoError(t, err)
+
+			r.RemoteAddr = test.remote
+
+			for key, value := range test.headers {
+				r.Header.Set(key, value)
+			}
+
+			assert.Equal(t, test.expected, resolver.ClientIP(r))
+		})
+	}
+}
+
+func TestFromTrustedProxy(t *testing.T) {
+	resolver, err := New([]string{"10.0.0.0/8"})
+	require.NoError(t, err)
+
+	r, err := http.NewRequest(http.MethodGet, "/", http.NoBody)
+	require.NoError(t, err)
+
+	r.RemoteAddr = "10.0.0.1:1234"
+	assert.True(t, resolver.FromTrustedProxy(r))
+
+	// Forwarding headers don't make a client a proxy.
+	r.RemoteAddr = "1.1.1.1:1234"
+	r.Header.Set("X-Forwarded-For", "10.0.0.1")
+	assert.False(t, resolver.FromTrustedProxy(r))
+}
+
+func TestNew(t *testing.T) {
+	_, err := New([]string{"10.0.0.0/8", "::1", "2001:db8::/32"})
+	require.NoError(t, err)
+
+	_, err = New([]string{"10.0.0.0/33"})
+	assert.Error(t, err)
+
+	_, err = New([]string{"proxy.local"})
+	assert.Error(t, err)
+}
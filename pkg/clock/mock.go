@@ -0,0 +1,226 @@
+package clock
+
+import (
+	"sync"
+	"time"
+)
+
+// Mock is a Clock whose time only moves when told to. Timers and tickers fire as Advance moves the clock
+// past them, in the order they're due.
+type Mock struct {
+	mu     sync.Mutex
+	cond   *sync.Cond
+	now    time.Time
+	timers []*mockTimer
+}
+
+var _ Clock = (*Mock)(nil)
+
+type mockTimer struct {
+	at     time.Time
+	period time.Duration
+	ch     chan time.Time
+}
+
+// NewMock returns a Mock clock set to the given time.
+func NewMock(now time.Time) *Mock {
+	m := &Mock{now: now}
+	m.cond = sync.NewCond(&m.mu)
+
+	return m
+}
+
+func (m *Mock) Now() time.Time {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.now
+}
+
+func (m *Mock) After(d time.Duration) <-chan time.Time {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	t := &mockTimer{
+		at: m.now.Add(d),
+		ch: make(chan time.Time, 1),
+	}
+
+	if d <= 0 {
+		t.ch <- m.now
+
+		return t.ch
+	}
+
+	m.addTimer(t)
+
+	return t.ch
+}
+
+func (m *Mock) NewTicker(d time.Duration) Ticker {
+	if d <= 0 {
+		panic("clock: non-positive interval for NewTicker")
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	t := &mockTimer{
+		at:     m.now.Add(d),
+		period: d,
+		ch:     make(chan time.Time, 1),
+	}
+
+	m.addTimer(t)
+
+	return &mockTicker{clock: m, timer: t}
+}
+
+// Advance moves the clock forward by d, firing every timer and ticker that falls due on the way.
+func (m *Mock) Advance(d time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.advanceTo(m.now.Add(d))
+}
+
+// Set moves the clock forward to t, firing every timer and ticker that falls due on the way. The clock
+// never moves backwards.
+func (m *Mock) Set(t time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.advanceTo(t)
+}
+
+// Waiters returns the number of pending timers and tickers.
+func (m *Mock) Waiters() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return len(m.timers)
+}
+
+// BlockUntil blocks until at least n timers and tickers are pending. It lets tests wait for goroutines to
+// start waiting on the clock before advancing it.
+func (m *Mock) BlockUntil(n int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for len(m.timers) < n {
+		m.cond.Wait()
+	}
+}
+
+func (m *Mock) advanceTo(target time.Time) {
+	for {
+		next := m.nextDue(target)
+		if next == nil {
+			break
+		}
+
+		if next.period > 0 {
+			// Skip straight to the last tick before anything else falls due, as any earlier ticks would be
+			// replaced by it anyway.
+			next.at = next.at.Add(next.period * (m.dueUntil(next, target).Sub(next.at) / next.period))
+		}
+
+		if next.at.After(m.now) {
+			m.now = next.at
+		}
+
+		send(next.ch, m.now)
+
+		if next.period > 0 {
+			next.at = next.at.Add(next.period)
+		} else {
+			m.removeTimer(next)
+		}
+	}
+
+	if target.After(m.now) {
+		m.now = target
+	}
+}
+
+// send delivers now on ch. A tick that hasn't been received yet is replaced, so that a slow receiver sees the
+// latest time once it catches up rather than a stale one.
+func send(ch chan time.Time, now time.Time) {
+	select {
+	case ch <- now:
+		return
+	default:
+	}
+
+	select {
+	case <-ch:
+	default:
+	}
+
+	select {
+	case ch <- now:
+	default:
+	}
+}
+
+// nextDue returns the earliest timer due at or before target.
+func (m *Mock) nextDue(target time.Time) *mockTimer {
+	var next *mockTimer
+
+	for _, t := range m.timers {
+		if t.at.After(target) {
+			continue
+		}
+
+		if next == nil || t.at.Before(next.at) {
+			next = t
+		}
+	}
+
+	return next
+}
+
+// dueUntil returns the time up to which t is the only timer due, capped at target.
+func (m *Mock) dueUntil(t *mockTimer, target time.Time) time.Time {
+	until := target
+
+	for _, other := range m.timers {
+		if other != t && other.at.Before(until) {
+			until = other.at
+		}
+	}
+
+	return until
+}
+
+func (m *Mock) addTimer(t *mockTimer) {
+	m.timers = append(m.timers, t)
+
+	m.cond.Broadcast()
+}
+
+func (m *Mock) removeTimer(t *mockTimer) {
+	for i, existing := range m.timers {
+		if existing == t {
+			m.timers = append(m.timers[:i], m.timers[i+1:]...)
+
+			return
+		}
+	}
+}
+
+type mockTicker struct {
+	clock *Mock
+	timer *mockTimer
+}
+
+func (t *mockTicker) C() <-chan time.Time {
+	return t.timer.ch
+}
+
+func (t *mockTicker) Stop() {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+
+	t.clock.removeTimer(t.timer)
+}
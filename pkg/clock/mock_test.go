@@ -0,0 +1,130 @@
+package clock
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var epoch = time.Date(2020, 12, 1, 12, 0, 23, 0, time.UTC)
+
+func TestMockAfterFiresOnAdvance(t *testing.T) {
+	m := NewMock(epoch)
+
+	ch := m.After(10 * time.Second)
+
+	m.Advance(9 * time.Second)
+
+	select {
+	case <-ch:
+		t.Fatal("timer fired early")
+	default:
+	}
+
+	m.Advance(time.Second)
+
+	select {
+	case at := <-ch:
+		assert.Equal(t, epoch.Add(10*time.Second), at)
+	default:
+		t.Fatal("timer did not fire")
+	}
+
+	assert.Equal(t, 0, m.Waiters())
+}
+
+func TestMockAfterNonPositiveFiresImmediately(t *testing.T) {
+	m := NewMock(epoch)
+
+	select {
+	case at := <-m.After(0):
+		assert.Equal(t, epoch, at)
+	default:
+		t.Fatal("timer did not fire")
+	}
+}
+
+func TestMockTickerFiresEveryPeriod(t *testing.T) {
+	m := NewMock(epoch)
+
+	ticker := m.NewTicker(time.Minute)
+
+	for i := 1; i <= 3; i++ {
+		m.Advance(time.Minute)
+
+		select {
+		case at := <-ticker.C():
+			assert.Equal(t, epoch.Add(time.Duration(i)*time.Minute), at)
+		default:
+			t.Fatalf("tick %d did not fire", i)
+		}
+	}
+
+	ticker.Stop()
+	m.Advance(time.Hour)
+
+	select {
+	case <-ticker.C():
+		t.Fatal("stopped ticker fired")
+	default:
+	}
+}
+
+func TestMockFiresTimersInOrder(t *testing.T) {
+	m := NewMock(epoch)
+
+	late := m.After(2 * time.Hour)
+	early := m.After(time.Hour)
+
+	m.Advance(3 * time.Hour)
+
+	assert.Equal(t, epoch.Add(time.Hour), <-early)
+	assert.Equal(t, epoch.Add(2*time.Hour), <-late)
+	assert.Equal(t, epoch.Add(3*time.Hour), m.Now())
+}
+
+func TestMockSetNeverMovesBackwards(t *testing.T) {
+	m := NewMock(epoch)
+
+	m.Set(epoch.Add(-time.Hour))
+
+	assert.Equal(t, epoch, m.Now())
+}
+
+func TestMockBlockUntil(t *testing.T) {
+	m := NewMock(epoch)
+
+	done := make(chan time.Time)
+
+	go func() {
+		done <- <-m.After(time.Second)
+	}()
+
+	m.BlockUntil(1)
+	m.Advance(time.Second)
+
+	select {
+	case at := <-done:
+		require.Equal(t, epoch.Add(time.Second), at)
+	case <-time.After(5 * time.Second):
+		t.Fatal("waiter was not woken")
+	}
+}
+
+func TestMockTickerInterleavesWithTimers(t *testing.T) {
+	m := NewMock(epoch)
+
+	ticker := m.NewTicker(time.Second)
+	timer := m.After(time.Hour + 500*time.Millisecond)
+
+	m.Advance(time.Hour + 500*time.Millisecond)
+
+	assert.Equal(t, epoch.Add(time.Hour), <-ticker.C(), "the last tick before the timer should be delivered")
+	assert.Equal(t, epoch.Add(time.Hour+500*time.Millisecond), <-timer)
+
+	m.Advance(24 * time.Hour)
+
+	assert.Equal(t, epoch.Add(25*time.Hour), <-ticker.C())
+}
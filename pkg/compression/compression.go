@@ -0,0 +1,317 @@
+// Package compression compresses HTTP responses with the content coding each client prefers, caching the
+// compressed variants of immutable payloads so that they're only compressed once.
+package compression
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/ethpandaops/checkpointz/pkg/cache"
+	"github.com/ethpandaops/checkpointz/pkg/clock"
+	"github.com/ethpandaops/checkpointz/pkg/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/sync/singleflight"
+)
+
+// Compressor compresses responses.
+type Compressor struct {
+	config   Config
+	clock    clock.Clock
+	encoders encoders
+
+	payloads *cache.TTLMap
+	budget   *cache.Budget
+	inflight singleflight.Group
+}
+
+// New returns a Compressor for config, which must be valid and enabled.
+func New(config Config, namespace string, clk clock.Clock) *Compressor {
+	c := &Compressor{
+		config:   config,
+		clock:    clk,
+		encoders: newEncoders(),
+		payloads: cache.NewTTLMapWithClock(config.Cache.MaxItems, "compressed_payloads", namespace, clk),
+		budget:   cache.NewBudget(int64(config.Cache.Size), namespace+"_compressed_payloads"),
+	}
+
+	c.payloads.UseBudget(c.budget)
+
+	return c
+}
+
+// EnableMetrics registers the cache's metrics.
+func (c *Compressor) EnableMetrics(namespace string) {
+	c.payloads.EnableMetrics(namespace)
+	c.budget.EnableMetrics()
+}
+
+// Stop stops expiring cached payloads.
+func (c *Compressor) Stop() {
+	c.payloads.Stop()
+}
+
+// Negotiate returns the encoding a response to r should be compressed with, or Identity if it shouldn't be.
+// Range requests are never compressed, as the range would apply to the compressed bytes.
+func (c *Compressor) Negotiate(r *http.Request) Encoding {
+	if r.Method == http.MethodHead || r.Header.Get("Range") != "" {
+		return Identity
+	}
+
+	return Negotiate(r.Header.Get("Accept-Encoding"), c.config.Encodings)
+}
+
+// Precompressed returns the immutable payload identified by key compressed with encoding, compressing it
+// only if it isn't cached. payload is only called when it's not. It returns nil if the payload is too small
+// to be worth compressing, in which case it should be served as is.
+func (c *Compressor) Precompressed(ctx context.Context, key string, encoding Encoding, payload func() ([]byte, error)) (_ []byte, err error) {
+	ctx, span := tracing.Start(ctx, "compression.Precompressed", trace.WithAttributes(
+		attribute.String("compression.key", key),
+		attribute.String("compression.encoding", string(encoding)),
+	))
+	defer func() { tracing.End(span, err) }()
+
+	key = key + "/" + string(encoding)
+
+	if cached, _, err := c.payloads.Get(key); err == nil {
+		span.SetAttributes(attribute.Bool("compression.cached", true))
+
+		data, _ := cached.([]byte)
+
+		return data, nil
+	}
+
+	// Concurrent requests for the same payload wait for one compression. It carries on if they all give up,
+	// so that it's cached for the next request.
+	ch := c.inflight.DoChan(key, func() (any, error) {
+		data, err := payload()
+		if err != nil {
+			return nil, err
+		}
+
+		if len(data) < c.config.MinLength {
+			return []byte(nil), nil
+		}
+
+		compressed, err := c.compress(encoding, data)
+		if err != nil {
+			return nil, err
+		}
+
+		if limit := int64(c.config.Cache.Size); limit <= 0 || int64(len(compressed)) <= limit {
+			c.payloads.AddWithCost(key, compressed, c.clock.Now().Add(c.config.Cache.TTL), int64(len(compressed)), cache.PriorityNormal)
+		}
+
+		return compressed, nil
+	})
+
+	select {
+	case result := <-ch:
+		if result.Err != nil {
+			return nil, result.Err
+		}
+
+		data, _ := result.Val.([]byte)
+
+		return data, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (c *Compressor) compress(encoding Encoding, data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+
+	enc := c.encoders.get(encoding, &buf)
+	defer c.encoders.put(encoding, enc)
+
+	if _, err := enc.Write(data); err != nil {
+		return nil, err
+	}
+
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// Handler compresses the responses of next that are long enough and not compressed already.
+func (c *Compressor) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		AddVary(w.Header())
+
+		encoding := c.Negotiate(r)
+		if encoding == Identity {
+			next.ServeHTTP(w, r)
+
+			return
+		}
+
+		rw := &responseWriter{ResponseWriter: w, compressor: c, encoding: encoding, status: http.StatusOK}
+		defer rw.close()
+
+		next.ServeHTTP(rw, r)
+	})
+}
+
+// incompressible are the prefixes of content types that are compressed already.
+var incompressible = []string{"image/", "video/", "audio/", "font/woff", "application/zip", "application/gzip", "application/zstd"}
+
+// compressible returns true if responses of contentType are worth compressing.
+func compressible(contentType string) bool {
+	if strings.HasPrefix(contentType, "image/svg") {
+		return true
+	}
+
+	for _, prefix := range incompressible {
+		if strings.HasPrefix(contentType, prefix) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// responseWriter buffers the start of a response until it knows whether it's long enough to compress.
+type responseWriter struct {
+	http.ResponseWriter
+
+	compressor *Compressor
+	encoding   Encoding
+
+	status  int
+	buf     []byte
+	decided bool
+	encoder encoder
+}
+
+func (w *responseWriter) WriteHeader(status int) {
+	if w.decided {
+		return
+	}
+
+	w.status = status
+
+	// Informational responses are sent straight away, and don't start the response.
+	if status >= 100 && status < 200 {
+		w.ResponseWriter.WriteHeader(status)
+
+		return
+	}
+
+	if status != http.StatusOK {
+		w.decide()
+	}
+}
+
+func (w *responseWriter) Write(p []byte) (int, error) {
+	if !w.decided {
+		w.buf = append(w.buf, p...)
+
+		if len(w.buf) >= w.compressor.config.MinLength {
+			if err := w.flushBuffer(); err != nil {
+				return 0, err
+			}
+		}
+
+		return len(p), nil
+	}
+
+	if w.encoder != nil {
+		return w.encoder.Write(p)
+	}
+
+	return w.ResponseWriter.Write(p)
+}
+
+// Flush sends what's been written so far, compressing it if there's enough of it.
+func (w *responseWriter) Flush() {
+	if err := w.flushBuffer(); err != nil {
+		return
+	}
+
+	if flusher, ok := w.encoder.(interface{ Flush() error }); ok {
+		_ = flusher.Flush()
+	}
+
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+func (w *responseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// decide starts the response, compressing it if it's a success long enough to be worth compressing and
+// isn't compressed already.
+func (w *responseWriter) decide() {
+	if w.decided {
+		return
+	}
+
+	w.decided = true
+
+	header := w.Header()
+
+	if w.status == http.StatusOK &&
+		len(w.buf) >= w.compressor.config.MinLength &&
+		header.Get("Content-Encoding") == "" &&
+		compressible(header.Get("Content-Type")) {
+		if header.Get("Content-Type") == "" {
+			header.Set("Content-Type", http.DetectContentType(w.buf))
+		}
+
+		header.Set("Content-Encoding", string(w.encoding))
+		header.Del("Content-Length")
+
+		w.encoder = w.compressor.encoders.get(w.encoding, w.ResponseWriter)
+	}
+
+	w.ResponseWriter.WriteHeader(w.status)
+}
+
+// flushBuffer decides how to send the response and writes what's been buffered.
+func (w *responseWriter) flushBuffer() error {
+	w.decide()
+
+	if len(w.buf) == 0 {
+		return nil
+	}
+
+	buf := w.buf
+	w.buf = nil
+
+	if w.encoder != nil {
+		_, err := w.encoder.Write(buf)
+
+		return err
+	}
+
+	_, err := w.ResponseWriter.Write(buf)
+
+	return err
+}
+
+// close finishes the response.
+func (w *responseWriter) close() {
+	// Responses without a body don't need headers written for them.
+	if !w.decided && len(w.buf) == 0 && w.status == http.StatusOK {
+		w.decided = true
+
+		return
+	}
+
+	// The client is gone if this fails, but the encoder still needs closing.
+	_ = w.flushBuffer()
+
+	if w.encoder != nil {
+		_ = w.encoder.Close()
+
+		w.compressor.encoders.put(w.encoding, w.encoder)
+		w.encoder = nil
+	}
+}
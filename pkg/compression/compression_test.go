@@ -0,0 +1,253 @@
+package compression
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/andybalholm/brotli"
+	"github.com/creasty/defaults"
+	"github.com/ethpandaops/checkpointz/pkg/clock"
+	"github.com/klauspost/compress/gzip"
+	"github.com/klauspost/compress/zstd"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestCompressor(t *testing.T) *Compressor {
+	t.Helper()
+
+	config := Config{}
+	require.NoError(t, defaults.Set(&config))
+
+	c := New(config, "test", clock.New())
+	t.Cleanup(c.Stop)
+
+	return c
+}
+
+func decode(t *testing.T, encoding Encoding, data []byte) []byte {
+	t.Helper()
+
+	var (
+		r   io.Reader
+		err error
+	)
+
+	switch encoding {
+	case Zstd:
+		var d *zstd.Decoder
+
+		d, err = zstd.NewReader(bytes.NewReader(data))
+		require.NoError(t, err)
+
+		defer d.Close()
+
+		r = d
+	case Brotli:
+		r = brotli.NewReader(bytes.NewReader(data))
+	case Gzip:
+		r, err = gzip.NewReader(bytes.NewReader(data))
+		require.NoError(t, err)
+	default:
+		return data
+	}
+
+	decoded, err := io.ReadAll(r)
+	require.NoError(t, err)
+
+	return decoded
+}
+
+func TestNegotiate(t *testing.T) {
+	offered := []Encoding{Zstd, Brotli, Gzip}
+
+	for acceptEncoding, expected := range map[string]Encoding{
+		"":                         Identity,
+		"gzip":                     Gzip,
+		"gzip, deflate, br":        Brotli,
+		"gzip, br, zstd":           Zstd,
+		"GZIP":                     Gzip,
+		"br;q=0.5, gzip":           Gzip,
+		"zstd;q=0, br;q=0.1":       Brotli,
+		"*":                        Zstd,
+		"*;q=0.5, gzip":            Gzip,
+		"*, zstd;q=0":              Brotli,
+		"deflate":                  Identity,
+		"identity":                 Identity,
+		"gzip;q=0":                 Identity,
+		"gzip;q=nonsense":          Identity,
+		"gzip ; q=0.8 , br ; q=.9": Brotli,
+	} {
+		assert.Equal(t, expected, Negotiate(acceptEncoding, offered), acceptEncoding)
+	}
+
+	assert.Equal(t, Gzip, Negotiate("zstd, br, gzip", []Encoding{Gzip}), "only offered encodings are chosen")
+}
+
+func TestHandlerCompresses(t *testing.T) {
+	c := newTestCompressor(t)
+	body := strings.Repeat("checkpointz ", 1000)
+
+	handler := c.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Content-Length", "12000")
+
+		// Written in small pieces, so that the decision waits for enough of the body.
+		for i := 0; i < len(body); i += 100 {
+			_, _ = w.Write([]byte(body[i : i+100]))
+		}
+	}))
+
+	for _, encoding := range []Encoding{Zstd, Brotli, Gzip} {
+		t.Run(string(encoding), func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", http.NoBody)
+			req.Header.Set("Accept-Encoding", string(encoding))
+
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.Equal(t, string(encoding), rec.Header().Get("Content-Encoding"))
+			assert.Equal(t, "Accept-Encoding", rec.Header().Get("Vary"))
+			assert.Empty(t, rec.Header().Get("Content-Length"))
+			assert.Less(t, rec.Body.Len(), len(body))
+			assert.Equal(t, body, string(decode(t, encoding, rec.Body.Bytes())))
+		})
+	}
+}
+
+func TestHandlerPassesThrough(t *testing.T) {
+	c := newTestCompressor(t)
+	long := strings.Repeat("a", 2048)
+
+	for name, test := range map[string]struct {
+		body        string
+		status      int
+		contentType string
+		encoded     bool
+		request     func(*http.Request)
+	}{
+		"short":                {body: "short", status: http.StatusOK},
+		"empty":                {status: http.StatusOK},
+		"error":                {body: long, status: http.StatusInternalServerError},
+		"image":                {body: long, status: http.StatusOK, contentType: "image/png"},
+		"already encoded":      {body: long, status: http.StatusOK, encoded: true},
+		"range request":        {body: long, status: http.StatusOK, request: func(r *http.Request) { r.Header.Set("Range", "bytes=10-") }},
+		"unsupported encoding": {body: long, status: http.StatusOK, request: func(r *http.Request) { r.Header.Set("Accept-Encoding", "deflate") }},
+	} {
+		t.Run(name, func(t *testing.T) {
+			handler := c.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if test.contentType != "" {
+					w.Header().Set("Content-Type", test.contentType)
+				}
+
+				if test.encoded {
+					w.Header().Set("Content-Encoding", "zstd")
+				}
+
+				w.WriteHeader(test.status)
+				_, _ = w.Write([]byte(test.body))
+			}))
+
+			req := httptest.NewRequest(http.MethodGet, "/", http.NoBody)
+			req.Header.Set("Accept-Encoding", "gzip")
+
+			if test.request != nil {
+				test.request(req)
+			}
+
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			assert.Equal(t, test.status, rec.Code)
+			assert.Equal(t, test.body, rec.Body.String())
+			assert.Equal(t, "Accept-Encoding", rec.Header().Get("Vary"))
+
+			if !test.encoded {
+				assert.Empty(t, rec.Header().Get("Content-Encoding"))
+			}
+		})
+	}
+}
+
+func TestPrecompressedCompressesOnce(t *testing.T) {
+	c := newTestCompressor(t)
+	payload := []byte(strings.Repeat("state ", 1000))
+
+	var calls atomic.Int32
+
+	marshal := func() ([]byte, error) {
+		calls.Add(1)
+
+		// Give concurrent requests time to pile up.
+		time.Sleep(10 * time.Millisecond)
+
+		return payload, nil
+	}
+
+	var wg sync.WaitGroup
+
+	for range 8 {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			compressed, err := c.Precompressed(context.Background(), "state/0x01", Zstd, marshal)
+			assert.NoError(t, err)
+			assert.Equal(t, payload, decode(t, Zstd, compressed))
+		}()
+	}
+
+	wg.Wait()
+
+	compressed, err := c.Precompressed(context.Background(), "state/0x01", Zstd, marshal)
+	require.NoError(t, err)
+	assert.Equal(t, payload, decode(t, Zstd, compressed))
+	assert.Equal(t, int32(1), calls.Load())
+
+	// Each encoding is compressed separately.
+	compressed, err = c.Precompressed(context.Background(), "state/0x01", Gzip, marshal)
+	require.NoError(t, err)
+	assert.Equal(t, payload, decode(t, Gzip, compressed))
+	assert.Equal(t, int32(2), calls.Load())
+
+	// Small payloads aren't compressed.
+	compressed, err = c.Precompressed(context.Background(), "state/0x02", Zstd, func() ([]byte, error) { return []byte("small"), nil })
+	require.NoError(t, err)
+	assert.Nil(t, compressed)
+}
+
+func TestConfigValidate(t *testing.T) {
+	valid := Config{}
+	require.NoError(t, defaults.Set(&valid))
+	require.NoError(t, valid.Validate())
+	require.NoError(t, (&Config{Encodings: []Encoding{"deflate"}}).Validate(), "disabled compression isn't validated")
+
+	for name, mutate := range map[string]func(*Config){
+		"no encodings":         func(c *Config) { c.Encodings = nil },
+		"unknown encoding":     func(c *Config) { c.Encodings = []Encoding{Gzip, "deflate"} },
+		"duplicate encoding":   func(c *Config) { c.Encodings = []Encoding{Gzip, Gzip} },
+		"negative min length":  func(c *Config) { c.MinLength = -1 },
+		"negative cache size":  func(c *Config) { c.Cache.Size = -1 },
+		"no cache items":       func(c *Config) { c.Cache.MaxItems = 0 },
+		"no cache ttl":         func(c *Config) { c.Cache.TTL = 0 },
+		"identity isn't coded": func(c *Config) { c.Encodings = []Encoding{Identity} },
+	} {
+		t.Run(name, func(t *testing.T) {
+			config := valid
+			config.Encodings = append([]Encoding(nil), valid.Encodings...)
+			mutate(&config)
+
+			assert.Error(t, config.Validate())
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package compression
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ethpandaops/checkpointz/pkg/cache"
+)
+
+// Config configures compression of HTTP responses.
+type Config struct {
+	Enabled bool `yaml:"enabled" default:"true"`
+	// Encodings are the content codings offered to clients, in order of preference when a client accepts
+	// several equally.
+	Encodings []Encoding `yaml:"encodings" default:"[\"zstd\",\"br\",\"gzip\"]"`
+	// MinLength is the size in bytes below which responses aren't compressed.
+	MinLength int `yaml:"minLength" default:"1024"`
+	// Cache configures the cache of compressed immutable payloads.
+	Cache CacheConfig `yaml:"cache"`
+}
+
+// CacheConfig configures the cache of compressed immutable payloads, such as blocks and states by root.
+type CacheConfig struct {
+	// Size is the combined size of the compressed payloads that can be cached, e.g. "512MiB".
+	Size cache.ByteSize `yaml:"size" default:"536870912"`
+	// MaxItems is how many compressed payloads can be cached.
+	MaxItems int `yaml:"maxItems" default:"64"`
+	// TTL is how long a compressed payload is cached for.
+	TTL time.Duration `yaml:"ttl" default:"1h"`
+}
+
+func (c *Config) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+
+	if len(c.Encodings) == 0 {
+		return errors.New("at least one encoding is required")
+	}
+
+	seen := make(map[Encoding]struct{}, len(c.Encodings))
+
+	for _, encoding := range c.Encodings {
+		switch encoding {
+		case Zstd, Brotli, Gzip:
+		default:
+			return fmt.Errorf("unsupported encoding: %q", encoding)
+		}
+
+		if _, ok := seen[encoding]; ok {
+			return fmt.Errorf("duplicate encoding: %s", encoding)
+		}
+
+		seen[encoding] = struct{}{}
+	}
+
+	if c.MinLength < 0 {
+		return errors.New("minLength must not be negative")
+	}
+
+	if c.Cache.Size < 0 {
+		return errors.New("cache.size must not be negative")
+	}
+
+	if c.Cache.MaxItems <= 0 {
+		return errors.New("cache.maxItems must be positive")
+	}
+
+	if c.Cache.TTL <= 0 {
+		return errors.New("cache.ttl must be positive")
+	}
+
+	return nil
+}
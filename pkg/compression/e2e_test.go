@@ -0,0 +1,66 @@
+package compression_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+	"github.com/ethpandaops/checkpointz/pkg/beacon"
+	"github.com/ethpandaops/checkpointz/pkg/checkpointz"
+	"github.com/ethpandaops/checkpointz/pkg/checkpointz/checkpointztest"
+	"github.com/klauspost/compress/zstd"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestE2ECompressesResponses(t *testing.T) {
+	chain := checkpointztest.NewChain(t)
+
+	server := checkpointztest.Start(t, beacon.OperatingModeFull, func(config *checkpointz.Config) {
+		// The test chain's spec is short.
+		config.GlobalConfig.Compression.MinLength = 256
+	}, checkpointztest.Upstream{Node: checkpointztest.NewNode(chain), DataProvider: true})
+
+	server.RequireServes(chain.Root(checkpointztest.FinalizedEpoch))
+
+	// Setting Accept-Encoding ourselves stops the transport from decompressing responses.
+	rsp, expected := server.Get("/eth/v2/debug/beacon/states/finalized", "application/octet-stream", map[string]string{"Accept-Encoding": "identity"})
+	require.Equal(t, http.StatusOK, rsp.StatusCode)
+	assert.Empty(t, rsp.Header.Get("Content-Encoding"))
+
+	// States are compressed once and served from the cache after that.
+	for range 2 {
+		rsp, body := server.Get("/eth/v2/debug/beacon/states/finalized", "application/octet-stream", map[string]string{"Accept-Encoding": "gzip, br, zstd"})
+		require.Equal(t, http.StatusOK, rsp.StatusCode)
+		assert.Equal(t, "zstd", rsp.Header.Get("Content-Encoding"))
+		assert.Contains(t, rsp.Header.Values("Vary"), "Accept-Encoding")
+		assert.Less(t, len(body), len(expected))
+
+		decoder, err := zstd.NewReader(bytes.NewReader(body))
+		require.NoError(t, err)
+
+		decoded, err := io.ReadAll(decoder)
+		decoder.Close()
+
+		require.NoError(t, err)
+		assert.Equal(t, expected, decoded)
+	}
+
+	// Ranges aren't compressed.
+	rsp, body := server.Get("/eth/v2/debug/beacon/states/finalized", "application/octet-stream", map[string]string{"Accept-Encoding": "zstd", "Range": "bytes=10-"})
+	require.Equal(t, http.StatusPartialContent, rsp.StatusCode)
+	assert.Empty(t, rsp.Header.Get("Content-Encoding"))
+	assert.Equal(t, expected[10:], body)
+
+	// Other responses are compressed on the fly.
+	rsp, body = server.Get("/eth/v1/config/spec", "application/json", map[string]string{"Accept-Encoding": "br"})
+	require.Equal(t, http.StatusOK, rsp.StatusCode)
+	assert.Equal(t, "br", rsp.Header.Get("Content-Encoding"))
+
+	decoded, err := io.ReadAll(brotli.NewReader(bytes.NewReader(body)))
+	require.NoError(t, err)
+	assert.True(t, json.Valid(decoded))
+}
@@ -0,0 +1,139 @@
+package compression
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/gzip"
+	"github.com/klauspost/compress/zstd"
+)
+
+// Encoding is an HTTP content coding.
+type Encoding string
+
+const (
+	Identity Encoding = "identity"
+	Zstd     Encoding = "zstd"
+	Brotli   Encoding = "br"
+	Gzip     Encoding = "gzip"
+)
+
+const (
+	gzipLevel   = 6
+	brotliLevel = 5
+)
+
+// Negotiate returns the encoding in offered that the Accept-Encoding header value acceptEncoding prefers,
+// breaking ties in the order of offered. It returns Identity if none of them are acceptable.
+func Negotiate(acceptEncoding string, offered []Encoding) Encoding {
+	if acceptEncoding == "" {
+		return Identity
+	}
+
+	qualities := make(map[Encoding]float64)
+	wildcard := -1.0
+
+	for _, entry := range strings.Split(acceptEncoding, ",") {
+		coding, params, _ := strings.Cut(entry, ";")
+		coding = strings.ToLower(strings.TrimSpace(coding))
+
+		q := 1.0
+
+		for _, param := range strings.Split(params, ";") {
+			key, value, ok := strings.Cut(strings.TrimSpace(param), "=")
+			if !ok || !strings.EqualFold(strings.TrimSpace(key), "q") {
+				continue
+			}
+
+			parsed, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+			if err != nil {
+				parsed = 0
+			}
+
+			q = parsed
+		}
+
+		if coding == "*" {
+			wildcard = q
+
+			continue
+		}
+
+		qualities[Encoding(coding)] = q
+	}
+
+	best, bestQ := Identity, 0.0
+
+	for _, encoding := range offered {
+		q, ok := qualities[encoding]
+		if !ok {
+			q = max(wildcard, 0)
+		}
+
+		if q > bestQ {
+			best, bestQ = encoding, q
+		}
+	}
+
+	return best
+}
+
+// AddVary adds Accept-Encoding to the Vary header, unless it's already there.
+func AddVary(header http.Header) {
+	for _, value := range header.Values("Vary") {
+		for _, field := range strings.Split(value, ",") {
+			if strings.EqualFold(strings.TrimSpace(field), "Accept-Encoding") {
+				return
+			}
+		}
+	}
+
+	header.Add("Vary", "Accept-Encoding")
+}
+
+// encoder is a compressing writer that can be reused for another destination.
+type encoder interface {
+	io.WriteCloser
+	Reset(w io.Writer)
+}
+
+// encoders pools the compressing writers of each encoding, as they're costly to create.
+type encoders map[Encoding]*sync.Pool
+
+func newEncoders() encoders {
+	return encoders{
+		Zstd: &sync.Pool{New: func() any {
+			// Options are valid, so this never fails.
+			w, _ := zstd.NewWriter(nil, zstd.WithEncoderLevel(zstd.SpeedDefault), zstd.WithEncoderConcurrency(1))
+
+			return w
+		}},
+		Brotli: &sync.Pool{New: func() any {
+			return brotli.NewWriterLevel(nil, brotliLevel)
+		}},
+		Gzip: &sync.Pool{New: func() any {
+			// The level is valid, so this never fails.
+			w, _ := gzip.NewWriterLevel(nil, gzipLevel)
+
+			return w
+		}},
+	}
+}
+
+// get returns an encoder for encoding that writes to w. It must be handed back with put once closed.
+func (e encoders) get(encoding Encoding, w io.Writer) encoder {
+	enc, _ := e[encoding].Get().(encoder)
+	enc.Reset(w)
+
+	return enc
+}
+
+func (e encoders) put(encoding Encoding, enc encoder) {
+	enc.Reset(nil)
+
+	e[encoding].Put(enc)
+}
@@ -0,0 +1,55 @@
+package era_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/ethpandaops/checkpointz/pkg/beacon"
+	"github.com/ethpandaops/checkpointz/pkg/checkpointz/checkpointztest"
+	"github.com/golang/snappy"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestE2EServesEras(t *testing.T) {
+	chain := checkpointztest.NewChain(t)
+
+	server := checkpointztest.Start(t, beacon.OperatingModeFull, nil,
+		checkpointztest.Upstream{Node: checkpointztest.NewNode(chain), DataProvider: true},
+	)
+
+	server.RequireServes(chain.Root(checkpointztest.FinalizedEpoch))
+
+	server.Eventually(func() bool {
+		rsp, _ := server.Get("/eth/v2/debug/beacon/states/genesis", "application/octet-stream")
+
+		return rsp.StatusCode == http.StatusOK
+	})
+
+	// The genesis era only needs the genesis state.
+	rsp, body := server.Get("/checkpointz/v1/era/0", "")
+	require.Equal(t, http.StatusOK, rsp.StatusCode)
+	assert.Equal(t, `attachment; filename=beacontest-00000-01000000.era`, rsp.Header.Get("Content-Disposition"))
+
+	require.Greater(t, len(body), 16)
+	assert.Equal(t, []byte{0x65, 0x32, 0, 0, 0, 0, 0, 0}, body[:8], "era files start with an empty version entry")
+	assert.Equal(t, []byte{0x02, 0x00}, body[8:10], "followed by the compressed state")
+
+	stateData, err := io.ReadAll(snappy.NewReader(bytes.NewReader(body[16 : 16+binary.LittleEndian.Uint32(body[10:14])])))
+	require.NoError(t, err)
+
+	genesisState, _ := chain.State(0)
+	expectedState, err := genesisState.Deneb.MarshalSSZ()
+	require.NoError(t, err)
+	assert.Equal(t, expectedState, stateData)
+
+	// Later eras need a state that isn't held.
+	rsp, _ = server.Get("/checkpointz/v1/era/1", "")
+	assert.Equal(t, http.StatusNotFound, rsp.StatusCode)
+
+	rsp, _ = server.Get("/checkpointz/v1/era/latest", "")
+	assert.Equal(t, http.StatusBadRequest, rsp.StatusCode)
+}
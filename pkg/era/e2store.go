@@ -0,0 +1,57 @@
+package era
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+)
+
+// EntryType identifies the contents of an e2store entry.
+type EntryType [2]byte
+
+var (
+	// TypeVersion marks the start of an e2store file, or of a group within one.
+	TypeVersion = EntryType{0x65, 0x32}
+	// TypeCompressedSignedBeaconBlock holds a snappy framed SSZ encoded signed beacon block.
+	TypeCompressedSignedBeaconBlock = EntryType{0x01, 0x00}
+	// TypeCompressedBeaconState holds a snappy framed SSZ encoded beacon state.
+	TypeCompressedBeaconState = EntryType{0x02, 0x00}
+	// TypeSlotIndex maps slots to the offsets of the entries holding them.
+	TypeSlotIndex = EntryType{0x69, 0x32}
+)
+
+// headerSize is the size of an e2store entry header: the type, a little endian uint32 length and two reserved
+// bytes.
+const headerSize = 8
+
+// e2storeWriter writes e2store entries, keeping track of where each one starts.
+type e2storeWriter struct {
+	w      io.Writer
+	offset int64
+}
+
+// writeEntry writes an entry and returns its offset from the start of the file.
+func (e *e2storeWriter) writeEntry(typ EntryType, data []byte) (int64, error) {
+	if len(data) > math.MaxUint32 {
+		return 0, fmt.Errorf("entry of %d bytes is too large", len(data))
+	}
+
+	header := make([]byte, headerSize)
+	copy(header, typ[:])
+	binary.LittleEndian.PutUint32(header[2:], uint32(len(data))) //nolint:gosec // checked above
+
+	offset := e.offset
+
+	if _, err := e.w.Write(header); err != nil {
+		return 0, err
+	}
+
+	if _, err := e.w.Write(data); err != nil {
+		return 0, err
+	}
+
+	e.offset += int64(headerSize + len(data))
+
+	return offset, nil
+}
@@ -0,0 +1,212 @@
+// Package era writes finalized history in the era archive format: the blocks of an era followed by the state
+// at its closing boundary, stored as e2store entries with slot indices.
+// https://github.com/status-im/nimbus-eth2/blob/stable/docs/e2store.md
+package era
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/attestantio/go-eth2-client/spec"
+	"github.com/attestantio/go-eth2-client/spec/capella"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/golang/snappy"
+)
+
+// Block is an SSZ encoded signed beacon block.
+type Block struct {
+	Slot phase0.Slot
+	SSZ  []byte
+}
+
+// Era is the content of a single era file.
+type Era struct {
+	// Number is the era number. Era n holds the blocks of slots [(n-1)*SLOTS_PER_HISTORICAL_ROOT,
+	// n*SLOTS_PER_HISTORICAL_ROOT) and the state at n*SLOTS_PER_HISTORICAL_ROOT.
+	Number                 uint64
+	SlotsPerHistoricalRoot uint64
+	// Blocks are the blocks within the era, in slot order. Slots without a block are indexed as empty.
+	Blocks []Block
+	// State is the SSZ encoded beacon state at the era's closing boundary.
+	State []byte
+}
+
+// StartSlot returns the first slot whose block belongs in the era.
+func (e *Era) StartSlot() phase0.Slot {
+	if e.Number == 0 {
+		return 0
+	}
+
+	return phase0.Slot((e.Number - 1) * e.SlotsPerHistoricalRoot)
+}
+
+// StateSlot returns the slot of the era's state.
+func (e *Era) StateSlot() phase0.Slot {
+	return phase0.Slot(e.Number * e.SlotsPerHistoricalRoot)
+}
+
+// Write writes the era as an era file.
+func (e *Era) Write(w io.Writer) error {
+	if e.SlotsPerHistoricalRoot == 0 {
+		return errors.New("slots per historical root is unknown")
+	}
+
+	if e.Number == 0 && len(e.Blocks) > 0 {
+		return errors.New("the genesis era holds no blocks")
+	}
+
+	out := &e2storeWriter{w: w}
+
+	if _, err := out.writeEntry(TypeVersion, nil); err != nil {
+		return err
+	}
+
+	blockOffsets := make([]int64, e.SlotsPerHistoricalRoot)
+
+	previous := -1
+
+	for _, block := range e.Blocks {
+		if block.Slot < e.StartSlot() || block.Slot >= e.StateSlot() {
+			return fmt.Errorf("block at slot %d is outside of era %d", block.Slot, e.Number)
+		}
+
+		index := int(block.Slot - e.StartSlot())
+		if index <= previous {
+			return fmt.Errorf("block at slot %d is out of order", block.Slot)
+		}
+
+		previous = index
+
+		data, err := compress(block.SSZ)
+		if err != nil {
+			return err
+		}
+
+		if blockOffsets[index], err = out.writeEntry(TypeCompressedSignedBeaconBlock, data); err != nil {
+			return err
+		}
+	}
+
+	data, err := compress(e.State)
+	if err != nil {
+		return err
+	}
+
+	stateOffset, err := out.writeEntry(TypeCompressedBeaconState, data)
+	if err != nil {
+		return err
+	}
+
+	if e.Number > 0 {
+		if err := writeSlotIndex(out, e.StartSlot(), blockOffsets); err != nil {
+			return err
+		}
+	}
+
+	return writeSlotIndex(out, e.StateSlot(), []int64{stateOffset})
+}
+
+// writeSlotIndex writes an index of the given entry offsets, one per slot from start. Offsets are stored
+// relative to the index itself, with 0 for slots that have no entry.
+func writeSlotIndex(out *e2storeWriter, start phase0.Slot, offsets []int64) error {
+	data := make([]byte, 8*(len(offsets)+2))
+
+	binary.LittleEndian.PutUint64(data, uint64(start))
+
+	for i, offset := range offsets {
+		if offset == 0 {
+			continue
+		}
+
+		binary.LittleEndian.PutUint64(data[8*(i+1):], uint64(offset-out.offset)) //nolint:gosec // two's complement of a negative offset, as the format requires
+	}
+
+	binary.LittleEndian.PutUint64(data[8*(len(offsets)+1):], uint64(len(offsets)))
+
+	_, err := out.writeEntry(TypeSlotIndex, data)
+
+	return err
+}
+
+func compress(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+
+	w := snappy.NewBufferedWriter(&buf)
+
+	if _, err := w.Write(data); err != nil {
+		return nil, err
+	}
+
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// FileName returns the conventional name of an era file: <config-name>-<era-number>-<short-historical-root>.era.
+func FileName(configName string, number uint64, historicalRoot phase0.Root) string {
+	return fmt.Sprintf("%s-%05d-%x.era", configName, number, historicalRoot[:4])
+}
+
+// HistoricalRoot returns the root that identifies the given era in its state: the genesis validators root for
+// the genesis era, and otherwise the era's entry in the state's historical roots or historical summaries.
+func HistoricalRoot(beaconState *spec.VersionedBeaconState, number uint64) (phase0.Root, error) {
+	genesisValidatorsRoot, roots, summaries, err := historicalAccumulators(beaconState)
+	if err != nil {
+		return phase0.Root{}, err
+	}
+
+	switch {
+	case number == 0:
+		return genesisValidatorsRoot, nil
+	case number <= uint64(len(roots)):
+		return roots[number-1], nil
+	case number <= uint64(len(roots)+len(summaries)):
+		return summaries[number-1-uint64(len(roots))].HashTreeRoot()
+	default:
+		return phase0.Root{}, fmt.Errorf("state has no historical root for era %d", number)
+	}
+}
+
+func historicalAccumulators(beaconState *spec.VersionedBeaconState) (phase0.Root, []phase0.Root, []*capella.HistoricalSummary, error) {
+	if beaconState == nil {
+		return phase0.Root{}, nil, nil, errors.New("state is nil")
+	}
+
+	switch beaconState.Version {
+	case spec.DataVersionPhase0:
+		if beaconState.Phase0 != nil {
+			return beaconState.Phase0.GenesisValidatorsRoot, beaconState.Phase0.HistoricalRoots, nil, nil
+		}
+	case spec.DataVersionAltair:
+		if beaconState.Altair != nil {
+			return beaconState.Altair.GenesisValidatorsRoot, beaconState.Altair.HistoricalRoots, nil, nil
+		}
+	case spec.DataVersionBellatrix:
+		if beaconState.Bellatrix != nil {
+			return beaconState.Bellatrix.GenesisValidatorsRoot, beaconState.Bellatrix.HistoricalRoots, nil, nil
+		}
+	case spec.DataVersionCapella:
+		if beaconState.Capella != nil {
+			return beaconState.Capella.GenesisValidatorsRoot, beaconState.Capella.HistoricalRoots, beaconState.Capella.HistoricalSummaries, nil
+		}
+	case spec.DataVersionDeneb:
+		if beaconState.Deneb != nil {
+			return beaconState.Deneb.GenesisValidatorsRoot, beaconState.Deneb.HistoricalRoots, beaconState.Deneb.HistoricalSummaries, nil
+		}
+	case spec.DataVersionElectra:
+		if beaconState.Electra != nil {
+			return beaconState.Electra.GenesisValidatorsRoot, beaconState.Electra.HistoricalRoots, beaconState.Electra.HistoricalSummaries, nil
+		}
+	case spec.DataVersionFulu:
+		if beaconState.Fulu != nil {
+			return beaconState.Fulu.GenesisValidatorsRoot, beaconState.Fulu.HistoricalRoots, beaconState.Fulu.HistoricalSummaries, nil
+		}
+	}
+
+	return phase0.Root{}, nil, nil, fmt.Errorf("unsupported %s state", beaconState.Version)
+}
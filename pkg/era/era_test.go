@@ -0,0 +1,230 @@
+package era
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"testing"
+
+	"github.com/attestantio/go-eth2-client/spec"
+	"github.com/attestantio/go-eth2-client/spec/capella"
+	"github.com/attestantio/go-eth2-client/spec/deneb"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/golang/snappy"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type entry struct {
+	offset int64
+	typ    EntryType
+	data   []byte
+}
+
+func readEntries(t *testing.T, data []byte) []entry {
+	t.Helper()
+
+	var entries []entry
+
+	for offset := int64(0); offset < int64(len(data)); {
+		require.GreaterOrEqual(t, len(data)-int(offset), headerSize)
+
+		header := data[offset : offset+headerSize]
+		length := int64(binary.LittleEndian.Uint32(header[2:]))
+
+		entries = append(entries, entry{
+			offset: offset,
+			typ:    EntryType{header[0], header[1]},
+			data:   data[offset+headerSize : offset+headerSize+length],
+		})
+
+		offset += headerSize + length
+	}
+
+	return entries
+}
+
+func decompress(t *testing.T, data []byte) []byte {
+	t.Helper()
+
+	out, err := io.ReadAll(snappy.NewReader(bytes.NewReader(data)))
+	require.NoError(t, err)
+
+	return out
+}
+
+// indexedOffsets returns the absolute offsets of the entries a slot index points at, or -1 for empty slots.
+func indexedOffsets(t *testing.T, index entry) (phase0.Slot, []int64) {
+	t.Helper()
+
+	count := binary.LittleEndian.Uint64(index.data[len(index.data)-8:])
+	require.Len(t, index.data, int(8*(count+2)))
+
+	offsets := make([]int64, count)
+
+	for i := range offsets {
+		relative := int64(binary.LittleEndian.Uint64(index.data[8*(i+1):])) //nolint:gosec // offsets are negative
+		if relative == 0 {
+			offsets[i] = -1
+
+			continue
+		}
+
+		offsets[i] = index.offset + relative
+	}
+
+	return phase0.Slot(binary.LittleEndian.Uint64(index.data)), offsets
+}
+
+func TestWrite(t *testing.T) {
+	e := &Era{
+		Number:                 2,
+		SlotsPerHistoricalRoot: 64,
+		Blocks: []Block{
+			{Slot: 64, SSZ: []byte("block 64")},
+			{Slot: 96, SSZ: []byte("block 96")},
+		},
+		State: []byte("state 128"),
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, e.Write(&buf))
+
+	entries := readEntries(t, buf.Bytes())
+	require.Len(t, entries, 6)
+
+	assert.Equal(t, TypeVersion, entries[0].typ)
+	assert.Empty(t, entries[0].data)
+
+	assert.Equal(t, TypeCompressedSignedBeaconBlock, entries[1].typ)
+	assert.Equal(t, []byte("block 64"), decompress(t, entries[1].data))
+	assert.Equal(t, TypeCompressedSignedBeaconBlock, entries[2].typ)
+	assert.Equal(t, []byte("block 96"), decompress(t, entries[2].data))
+
+	assert.Equal(t, TypeCompressedBeaconState, entries[3].typ)
+	assert.Equal(t, []byte("state 128"), decompress(t, entries[3].data))
+
+	assert.Equal(t, TypeSlotIndex, entries[4].typ)
+
+	start, offsets := indexedOffsets(t, entries[4])
+	assert.Equal(t, phase0.Slot(64), start)
+	require.Len(t, offsets, 64)
+	assert.Equal(t, entries[1].offset, offsets[0])
+	assert.Equal(t, entries[2].offset, offsets[32])
+	assert.Equal(t, int64(-1), offsets[1])
+
+	assert.Equal(t, TypeSlotIndex, entries[5].typ)
+
+	start, offsets = indexedOffsets(t, entries[5])
+	assert.Equal(t, phase0.Slot(128), start)
+	assert.Equal(t, []int64{entries[3].offset}, offsets)
+}
+
+func TestWriteGenesisEra(t *testing.T) {
+	e := &Era{Number: 0, SlotsPerHistoricalRoot: 64, State: []byte("genesis")}
+
+	var buf bytes.Buffer
+	require.NoError(t, e.Write(&buf))
+
+	entries := readEntries(t, buf.Bytes())
+	require.Len(t, entries, 3)
+
+	// The genesis era has no block index.
+	assert.Equal(t, TypeCompressedBeaconState, entries[1].typ)
+	assert.Equal(t, TypeSlotIndex, entries[2].typ)
+
+	start, offsets := indexedOffsets(t, entries[2])
+	assert.Equal(t, phase0.Slot(0), start)
+	assert.Equal(t, []int64{entries[1].offset}, offsets)
+}
+
+func TestWriteRejectsBlocksOutsideTheEra(t *testing.T) {
+	for name, blocks := range map[string][]Block{
+		"before":       {{Slot: 63}},
+		"state slot":   {{Slot: 128}},
+		"out of order": {{Slot: 96}, {Slot: 64}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			e := &Era{Number: 2, SlotsPerHistoricalRoot: 64, Blocks: blocks}
+
+			require.Error(t, e.Write(io.Discard))
+		})
+	}
+}
+
+func TestFileName(t *testing.T) {
+	assert.Equal(t, "mainnet-00042-4b363db9.era", FileName("mainnet", 42, phase0.Root{0x4b, 0x36, 0x3d, 0xb9, 0xff}))
+}
+
+func TestHistoricalRoot(t *testing.T) {
+	summary := &capella.HistoricalSummary{BlockSummaryRoot: phase0.Root{0x03}}
+
+	summaryRoot, err := summary.HashTreeRoot()
+	require.NoError(t, err)
+
+	beaconState := &spec.VersionedBeaconState{
+		Version: spec.DataVersionDeneb,
+		Deneb: &deneb.BeaconState{
+			GenesisValidatorsRoot: phase0.Root{0x01},
+			HistoricalRoots:       []phase0.Root{{0x02}},
+			HistoricalSummaries:   []*capella.HistoricalSummary{summary},
+		},
+	}
+
+	for number, expected := range map[uint64]phase0.Root{
+		0: {0x01},
+		1: {0x02},
+		2: summaryRoot,
+	} {
+		root, err := HistoricalRoot(beaconState, number)
+		require.NoError(t, err)
+		assert.Equal(t, expected, root, "era %d", number)
+	}
+
+	_, err = HistoricalRoot(beaconState, 3)
+	require.Error(t, err)
+}
+
+func TestReadIndex(t *testing.T) {
+	for _, e := range []*Era{
+		{
+			Number:                 2,
+			SlotsPerHistoricalRoot: 64,
+			Blocks:                 []Block{{Slot: 64, SSZ: []byte("block 64")}, {Slot: 96, SSZ: []byte("block 96")}},
+			State:                  []byte("state 128"),
+		},
+		{Number: 0, SlotsPerHistoricalRoot: 64, State: []byte("genesis")},
+	} {
+		var buf bytes.Buffer
+		require.NoError(t, e.Write(&buf))
+
+		r := bytes.NewReader(buf.Bytes())
+
+		index, err := ReadIndex(r, r.Size())
+		require.NoError(t, err)
+
+		assert.Equal(t, e.StateSlot(), index.StateSlot)
+
+		stateData, err := ReadCompressed(r, index.State, TypeCompressedBeaconState)
+		require.NoError(t, err)
+		assert.Equal(t, e.State, stateData)
+
+		require.Len(t, index.Blocks, len(e.Blocks))
+
+		for _, block := range e.Blocks {
+			data, err := ReadCompressed(r, index.Blocks[block.Slot], TypeCompressedSignedBeaconBlock)
+			require.NoError(t, err)
+			assert.Equal(t, block.SSZ, data)
+		}
+
+		_, err = ReadCompressed(r, index.State, TypeCompressedSignedBeaconBlock)
+		require.Error(t, err)
+	}
+}
+
+func TestReadIndexRejectsOtherFiles(t *testing.T) {
+	r := bytes.NewReader([]byte("definitely not an era file"))
+
+	_, err := ReadIndex(r, r.Size())
+	require.Error(t, err)
+}
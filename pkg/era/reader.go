@@ -0,0 +1,130 @@
+package era
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/golang/snappy"
+)
+
+// Index locates the entries of an era file.
+type Index struct {
+	// Blocks maps the slot of each block in the era to the offset of its entry.
+	Blocks map[phase0.Slot]int64
+	// StateSlot is the slot of the era's state.
+	StateSlot phase0.Slot
+	// State is the offset of the state's entry.
+	State int64
+}
+
+// ReadIndex reads the slot indices at the end of an era file of the given size.
+func ReadIndex(r io.ReaderAt, size int64) (*Index, error) {
+	stateSlot, stateOffsets, start, err := readSlotIndex(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read state index: %w", err)
+	}
+
+	if len(stateOffsets) != 1 || stateOffsets[0] < 0 {
+		return nil, errors.New("era files must index exactly one state")
+	}
+
+	index := &Index{
+		Blocks:    make(map[phase0.Slot]int64),
+		StateSlot: stateSlot,
+		State:     stateOffsets[0],
+	}
+
+	// Only the genesis era has no block index.
+	if stateSlot == 0 {
+		return index, nil
+	}
+
+	blockStart, blockOffsets, _, err := readSlotIndex(r, start)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read block index: %w", err)
+	}
+
+	for i, offset := range blockOffsets {
+		if offset >= 0 {
+			index.Blocks[blockStart+phase0.Slot(i)] = offset //nolint:gosec // i is never negative
+		}
+	}
+
+	return index, nil
+}
+
+// readSlotIndex reads the slot index entry that ends at end. It returns the index's starting slot, the
+// absolute offset of each slot's entry (-1 for empty slots) and the offset the index entry starts at.
+func readSlotIndex(r io.ReaderAt, end int64) (phase0.Slot, []int64, int64, error) {
+	countData := make([]byte, 8)
+	if _, err := r.ReadAt(countData, end-8); err != nil {
+		return 0, nil, 0, err
+	}
+
+	count := binary.LittleEndian.Uint64(countData)
+
+	length := 8 * (count + 2)
+	if count > uint64(end) || int64(length)+headerSize > end { //nolint:gosec // bounded by the file size
+		return 0, nil, 0, fmt.Errorf("invalid slot index count %d", count)
+	}
+
+	start := end - int64(length) - headerSize //nolint:gosec // bounded by the file size
+
+	typ, data, err := ReadEntry(r, start)
+	if err != nil {
+		return 0, nil, 0, err
+	}
+
+	if typ != TypeSlotIndex || uint64(len(data)) != length {
+		return 0, nil, 0, errors.New("no slot index found")
+	}
+
+	offsets := make([]int64, count)
+
+	for i := range offsets {
+		relative := int64(binary.LittleEndian.Uint64(data[8*(i+1):])) //nolint:gosec // two's complement, offsets precede the index
+		if relative == 0 {
+			offsets[i] = -1
+
+			continue
+		}
+
+		offsets[i] = start + relative
+	}
+
+	return phase0.Slot(binary.LittleEndian.Uint64(data)), offsets, start, nil
+}
+
+// ReadEntry reads the e2store entry at offset.
+func ReadEntry(r io.ReaderAt, offset int64) (EntryType, []byte, error) {
+	header := make([]byte, headerSize)
+	if _, err := r.ReadAt(header, offset); err != nil {
+		return EntryType{}, nil, err
+	}
+
+	data := make([]byte, binary.LittleEndian.Uint32(header[2:]))
+	if _, err := r.ReadAt(data, offset+headerSize); err != nil {
+		return EntryType{}, nil, err
+	}
+
+	return EntryType{header[0], header[1]}, data, nil
+}
+
+// ReadCompressed reads the entry at offset, which must be of the given type, and returns its decompressed
+// contents.
+func ReadCompressed(r io.ReaderAt, offset int64, typ EntryType) ([]byte, error) {
+	entryType, data, err := ReadEntry(r, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	if entryType != typ {
+		return nil, fmt.Errorf("entry at offset %d has type %#x, expected %#x", offset, entryType, typ)
+	}
+
+	return io.ReadAll(snappy.NewReader(bytes.NewReader(data)))
+}
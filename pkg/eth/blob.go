@@ -0,0 +1,18 @@
+package eth
+
+import (
+	"crypto/sha256"
+
+	"github.com/attestantio/go-eth2-client/spec/deneb"
+)
+
+// VersionedHashVersionKZG is VERSIONED_HASH_VERSION_KZG from EIP-4844.
+const VersionedHashVersionKZG = byte(0x01)
+
+// KZGCommitmentToVersionedHash returns the versioned hash of the given KZG commitment.
+func KZGCommitmentToVersionedHash(commitment deneb.KZGCommitment) deneb.VersionedHash {
+	hash := deneb.VersionedHash(sha256.Sum256(commitment[:]))
+	hash[0] = VersionedHashVersionKZG
+
+	return hash
+}
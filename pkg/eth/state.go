@@ -0,0 +1,199 @@
+package eth
+
+import (
+	"errors"
+
+	v1 "github.com/attestantio/go-eth2-client/api/v1"
+	"github.com/attestantio/go-eth2-client/spec"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+)
+
+// FinalityFromState returns the finality checkpoints recorded in the given beacon state.
+func FinalityFromState(beaconState *spec.VersionedBeaconState) (*v1.Finality, error) {
+	if beaconState == nil {
+		return nil, errors.New("beacon state is nil")
+	}
+
+	var finalized, justified, previousJustified *phase0.Checkpoint
+
+	switch beaconState.Version {
+	case spec.DataVersionPhase0:
+		if beaconState.Phase0 == nil {
+			return nil, errors.New("no phase0 state")
+		}
+
+		finalized = beaconState.Phase0.FinalizedCheckpoint
+		justified = beaconState.Phase0.CurrentJustifiedCheckpoint
+		previousJustified = beaconState.Phase0.PreviousJustifiedCheckpoint
+	case spec.DataVersionAltair:
+		if beaconState.Altair == nil {
+			return nil, errors.New("no altair state")
+		}
+
+		finalized = beaconState.Altair.FinalizedCheckpoint
+		justified = beaconState.Altair.CurrentJustifiedCheckpoint
+		previousJustified = beaconState.Altair.PreviousJustifiedCheckpoint
+	case spec.DataVersionBellatrix:
+		if beaconState.Bellatrix == nil {
+			return nil, errors.New("no bellatrix state")
+		}
+
+		finalized = beaconState.Bellatrix.FinalizedCheckpoint
+		justified = beaconState.Bellatrix.CurrentJustifiedCheckpoint
+		previousJustified = beaconState.Bellatrix.PreviousJustifiedCheckpoint
+	case spec.DataVersionCapella:
+		if beaconState.Capella == nil {
+			return nil, errors.New("no capella state")
+		}
+
+		finalized = beaconState.Capella.FinalizedCheckpoint
+		justified = beaconState.Capella.CurrentJustifiedCheckpoint
+		previousJustified = beaconState.Capella.PreviousJustifiedCheckpoint
+	case spec.DataVersionDeneb:
+		if beaconState.Deneb == nil {
+			return nil, errors.New("no deneb state")
+		}
+
+		finalized = beaconState.Deneb.FinalizedCheckpoint
+		justified = beaconState.Deneb.CurrentJustifiedCheckpoint
+		previousJustified = beaconState.Deneb.PreviousJustifiedCheckpoint
+	case spec.DataVersionElectra:
+		if beaconState.Electra == nil {
+			return nil, errors.New("no electra state")
+		}
+
+		finalized = beaconState.Electra.FinalizedCheckpoint
+		justified = beaconState.Electra.CurrentJustifiedCheckpoint
+		previousJustified = beaconState.Electra.PreviousJustifiedCheckpoint
+	case spec.DataVersionFulu:
+		if beaconState.Fulu == nil {
+			return nil, errors.New("no fulu state")
+		}
+
+		finalized = beaconState.Fulu.FinalizedCheckpoint
+		justified = beaconState.Fulu.CurrentJustifiedCheckpoint
+		previousJustified = beaconState.Fulu.PreviousJustifiedCheckpoint
+	default:
+		return nil, errors.New("unknown state version")
+	}
+
+	if finalized == nil || justified == nil || previousJustified == nil {
+		return nil, errors.New("state is missing finality checkpoints")
+	}
+
+	return &v1.Finality{
+		Finalized:         finalized,
+		Justified:         justified,
+		PreviousJustified: previousJustified,
+	}, nil
+}
+
+// ForkFromState returns the fork and genesis validators root recorded in the given beacon state.
+// Together they determine the signing domains for objects at the state's epoch.
+func ForkFromState(beaconState *spec.VersionedBeaconState) (*phase0.Fork, phase0.Root, error) {
+	if beaconState == nil {
+		return nil, phase0.Root{}, errors.New("beacon state is nil")
+	}
+
+	switch beaconState.Version {
+	case spec.DataVersionPhase0:
+		if beaconState.Phase0 == nil {
+			return nil, phase0.Root{}, errors.New("no phase0 state")
+		}
+
+		return beaconState.Phase0.Fork, beaconState.Phase0.GenesisValidatorsRoot, nil
+	case spec.DataVersionAltair:
+		if beaconState.Altair == nil {
+			return nil, phase0.Root{}, errors.New("no altair state")
+		}
+
+		return beaconState.Altair.Fork, beaconState.Altair.GenesisValidatorsRoot, nil
+	case spec.DataVersionBellatrix:
+		if beaconState.Bellatrix == nil {
+			return nil, phase0.Root{}, errors.New("no bellatrix state")
+		}
+
+		return beaconState.Bellatrix.Fork, beaconState.Bellatrix.GenesisValidatorsRoot, nil
+	case spec.DataVersionCapella:
+		if beaconState.Capella == nil {
+			return nil, phase0.Root{}, errors.New("no capella state")
+		}
+
+		return beaconState.Capella.Fork, beaconState.Capella.GenesisValidatorsRoot, nil
+	case spec.DataVersionDeneb:
+		if beaconState.Deneb == nil {
+			return nil, phase0.Root{}, errors.New("no deneb state")
+		}
+
+		return beaconState.Deneb.Fork, beaconState.Deneb.GenesisValidatorsRoot, nil
+	case spec.DataVersionElectra:
+		if beaconState.Electra == nil {
+			return nil, phase0.Root{}, errors.New("no electra state")
+		}
+
+		return beaconState.Electra.Fork, beaconState.Electra.GenesisValidatorsRoot, nil
+	case spec.DataVersionFulu:
+		if beaconState.Fulu == nil {
+			return nil, phase0.Root{}, errors.New("no fulu state")
+		}
+
+		return beaconState.Fulu.Fork, beaconState.Fulu.GenesisValidatorsRoot, nil
+	default:
+		return nil, phase0.Root{}, errors.New("unknown state version")
+	}
+}
+
+// BlockRootsFromState returns the block roots recorded in the given beacon state: the root of the latest block
+// at or before each of the SLOTS_PER_HISTORICAL_ROOT slots before it, indexed by slot modulo their number.
+func BlockRootsFromState(beaconState *spec.VersionedBeaconState) ([]phase0.Root, error) {
+	if beaconState == nil {
+		return nil, errors.New("beacon state is nil")
+	}
+
+	switch beaconState.Version {
+	case spec.DataVersionPhase0:
+		if beaconState.Phase0 == nil {
+			return nil, errors.New("no phase0 state")
+		}
+
+		return beaconState.Phase0.BlockRoots, nil
+	case spec.DataVersionAltair:
+		if beaconState.Altair == nil {
+			return nil, errors.New("no altair state")
+		}
+
+		return beaconState.Altair.BlockRoots, nil
+	case spec.DataVersionBellatrix:
+		if beaconState.Bellatrix == nil {
+			return nil, errors.New("no bellatrix state")
+		}
+
+		return beaconState.Bellatrix.BlockRoots, nil
+	case spec.DataVersionCapella:
+		if beaconState.Capella == nil {
+			return nil, errors.New("no capella state")
+		}
+
+		return beaconState.Capella.BlockRoots, nil
+	case spec.DataVersionDeneb:
+		if beaconState.Deneb == nil {
+			return nil, errors.New("no deneb state")
+		}
+
+		return beaconState.Deneb.BlockRoots, nil
+	case spec.DataVersionElectra:
+		if beaconState.Electra == nil {
+			return nil, errors.New("no electra state")
+		}
+
+		return beaconState.Electra.BlockRoots, nil
+	case spec.DataVersionFulu:
+		if beaconState.Fulu == nil {
+			return nil, errors.New("no fulu state")
+		}
+
+		return beaconState.Fulu.BlockRoots, nil
+	default:
+		return nil, errors.New("unknown state version")
+	}
+}
@@ -0,0 +1,105 @@
+package eth
+
+import (
+	"testing"
+
+	"github.com/attestantio/go-eth2-client/spec"
+	"github.com/attestantio/go-eth2-client/spec/deneb"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+)
+
+func TestFinalityFromState(t *testing.T) {
+	finalized := &phase0.Checkpoint{Epoch: 10, Root: phase0.Root{0x01}}
+	justified := &phase0.Checkpoint{Epoch: 11, Root: phase0.Root{0x02}}
+	previousJustified := &phase0.Checkpoint{Epoch: 10, Root: phase0.Root{0x03}}
+
+	st := &spec.VersionedBeaconState{
+		Version: spec.DataVersionDeneb,
+		Deneb: &deneb.BeaconState{
+			FinalizedCheckpoint:         finalized,
+			CurrentJustifiedCheckpoint:  justified,
+			PreviousJustifiedCheckpoint: previousJustified,
+		},
+	}
+
+	finality, err := FinalityFromState(st)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if finality.Finalized != finalized {
+		t.Errorf("Finalized = %v, want %v", finality.Finalized, finalized)
+	}
+
+	if finality.Justified != justified {
+		t.Errorf("Justified = %v, want %v", finality.Justified, justified)
+	}
+
+	if finality.PreviousJustified != previousJustified {
+		t.Errorf("PreviousJustified = %v, want %v", finality.PreviousJustified, previousJustified)
+	}
+}
+
+func TestFinalityFromStateMissingVersion(t *testing.T) {
+	if _, err := FinalityFromState(&spec.VersionedBeaconState{Version: spec.DataVersionDeneb}); err == nil {
+		t.Fatal("expected an error for a state without a deneb body")
+	}
+
+	if _, err := FinalityFromState(nil); err == nil {
+		t.Fatal("expected an error for a nil state")
+	}
+}
+
+func TestForkFromState(t *testing.T) {
+	fork := &phase0.Fork{CurrentVersion: phase0.Version{0x04}, Epoch: 5}
+	genesisValidatorsRoot := phase0.Root{0x01}
+
+	st := &spec.VersionedBeaconState{
+		Version: spec.DataVersionDeneb,
+		Deneb: &deneb.BeaconState{
+			Fork:                  fork,
+			GenesisValidatorsRoot: genesisValidatorsRoot,
+		},
+	}
+
+	gotFork, gotRoot, err := ForkFromState(st)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if gotFork != fork {
+		t.Errorf("Fork = %v, want %v", gotFork, fork)
+	}
+
+	if gotRoot != genesisValidatorsRoot {
+		t.Errorf("GenesisValidatorsRoot = %#x, want %#x", gotRoot, genesisValidatorsRoot)
+	}
+
+	if _, _, err := ForkFromState(nil); err == nil {
+		t.Fatal("expected an error for a nil state")
+	}
+}
+
+func TestBlockRootsFromState(t *testing.T) {
+	roots := []phase0.Root{{0x01}, {0x02}}
+
+	st := &spec.VersionedBeaconState{
+		Version: spec.DataVersionDeneb,
+		Deneb: &deneb.BeaconState{
+			BlockRoots: roots,
+		},
+	}
+
+	got, err := BlockRootsFromState(st)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(got) != len(roots) || got[1] != roots[1] {
+		t.Errorf("BlockRoots = %v, want %v", got, roots)
+	}
+
+	if _, err := BlockRootsFromState(nil); err == nil {
+		t.Fatal("expected an error for a nil state")
+	}
+}
@@ -1,7 +1,9 @@
 package eth
 
 import (
+	"encoding/hex"
 	"fmt"
+	"strings"
 
 	"github.com/attestantio/go-eth2-client/spec/phase0"
 )
@@ -17,3 +19,21 @@ func SlotAsString(slot phase0.Slot) string {
 func EpochAsString(epoch phase0.Epoch) string {
 	return fmt.Sprintf("%d", epoch)
 }
+
+// RootFromString parses a hex encoded root, with or without a 0x prefix.
+func RootFromString(s string) (phase0.Root, error) {
+	b, err := hex.DecodeString(strings.TrimPrefix(s, "0x"))
+	if err != nil {
+		return phase0.Root{}, fmt.Errorf("invalid value for root: %w", err)
+	}
+
+	root := phase0.Root{}
+
+	if len(b) != len(root) {
+		return phase0.Root{}, fmt.Errorf("incorrect length %d for root", len(b))
+	}
+
+	copy(root[:], b)
+
+	return root, nil
+}
@@ -0,0 +1,97 @@
+package ratelimit
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Config configures rate limiting and concurrency control of the public HTTP API.
+type Config struct {
+	Enabled bool `yaml:"enabled" default:"false"`
+	// Default limits each client's requests to any route without a limit of its own.
+	Default Limit `yaml:"default"`
+	// Routes limit each client's requests to specific routes, separately from the default limit.
+	Routes []RouteLimit `yaml:"routes"`
+	// StateDownloads caps how many beacon states are downloaded at once across every client.
+	StateDownloads ConcurrencyConfig `yaml:"stateDownloads"`
+}
+
+// Limit is a token bucket: a client may make Burst requests at once, refilled at Rate requests per second.
+// A Rate of 0 is unlimited.
+type Limit struct {
+	Rate  float64 `yaml:"rate" default:"20"`
+	Burst int     `yaml:"burst" default:"40"`
+}
+
+// RouteLimit is the limit of a single route.
+type RouteLimit struct {
+	// Path is the route as it's registered, e.g. /eth/v2/debug/beacon/states/:state_id.
+	Path  string `yaml:"path"`
+	Limit `yaml:",inline"`
+}
+
+// ConcurrencyConfig caps how many requests are served at once, queueing the rest.
+type ConcurrencyConfig struct {
+	// MaxConcurrent is how many requests are served at once. 0 is unlimited.
+	MaxConcurrent int `yaml:"maxConcurrent" default:"4"`
+	// MaxQueued is how many requests wait for a slot before more are turned away.
+	MaxQueued int `yaml:"maxQueued" default:"32"`
+	// QueueTimeout is how long a request waits for a slot before it's turned away.
+	QueueTimeout time.Duration `yaml:"queueTimeout" default:"30s"`
+}
+
+func (c *Config) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+
+	if err := c.Default.Validate(); err != nil {
+		return fmt.Errorf("invalid default limit: %w", err)
+	}
+
+	paths := make(map[string]struct{})
+
+	for _, route := range c.Routes {
+		if !strings.HasPrefix(route.Path, "/") {
+			return fmt.Errorf("route path must start with /: %q", route.Path)
+		}
+
+		if _, ok := paths[route.Path]; ok {
+			return fmt.Errorf("duplicate route limit: %s", route.Path)
+		}
+
+		paths[route.Path] = struct{}{}
+
+		if err := route.Validate(); err != nil {
+			return fmt.Errorf("invalid limit for route %s: %w", route.Path, err)
+		}
+	}
+
+	if c.StateDownloads.MaxConcurrent < 0 {
+		return errors.New("stateDownloads.maxConcurrent must not be negative")
+	}
+
+	if c.StateDownloads.MaxQueued < 0 {
+		return errors.New("stateDownloads.maxQueued must not be negative")
+	}
+
+	if c.StateDownloads.MaxConcurrent > 0 && c.StateDownloads.MaxQueued > 0 && c.StateDownloads.QueueTimeout <= 0 {
+		return errors.New("stateDownloads.queueTimeout must be positive")
+	}
+
+	return nil
+}
+
+func (l *Limit) Validate() error {
+	if l.Rate < 0 {
+		return errors.New("rate must not be negative")
+	}
+
+	if l.Rate > 0 && l.Burst < 1 {
+		return errors.New("burst must be at least 1")
+	}
+
+	return nil
+}
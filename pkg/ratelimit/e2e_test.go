@@ -0,0 +1,52 @@
+package ratelimit_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/ethpandaops/checkpointz/pkg/beacon"
+	"github.com/ethpandaops/checkpointz/pkg/checkpointz"
+	"github.com/ethpandaops/checkpointz/pkg/checkpointz/checkpointztest"
+	"github.com/ethpandaops/checkpointz/pkg/ratelimit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestE2ERateLimitsClients(t *testing.T) {
+	chain := checkpointztest.NewChain(t)
+
+	server := checkpointztest.Start(t, beacon.OperatingModeLight, func(config *checkpointz.Config) {
+		config.GlobalConfig.RateLimit = ratelimit.Config{
+			Enabled: true,
+			Default: ratelimit.Limit{Rate: 100, Burst: 100},
+			Routes: []ratelimit.RouteLimit{
+				{Path: "/checkpointz/v1/status", Limit: ratelimit.Limit{Rate: 0.01, Burst: 2}},
+			},
+		}
+	}, checkpointztest.Upstream{Node: checkpointztest.NewNode(chain), DataProvider: true})
+
+	// Wait for the server with a route in the default bucket.
+	server.Eventually(func() bool {
+		rsp, err := http.Get(server.URL + "/eth/v1/node/version")
+		if err != nil {
+			return false
+		}
+
+		rsp.Body.Close()
+
+		return rsp.StatusCode == http.StatusOK
+	})
+
+	for range 2 {
+		rsp, _ := server.Get("/checkpointz/v1/status", "application/json")
+		require.Equal(t, http.StatusOK, rsp.StatusCode)
+	}
+
+	rsp, _ := server.Get("/checkpointz/v1/status", "application/json")
+	require.Equal(t, http.StatusTooManyRequests, rsp.StatusCode)
+	assert.Equal(t, "100", rsp.Header.Get("Retry-After"))
+
+	// Other routes are limited separately.
+	rsp, _ = server.Get("/eth/v1/node/version", "application/json")
+	assert.Equal(t, http.StatusOK, rsp.StatusCode)
+}
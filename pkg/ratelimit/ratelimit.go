@@ -0,0 +1,235 @@
+// Package ratelimit protects the public HTTP API from clients that make too many requests: it limits each
+// client's request rate per route with token buckets, and caps how many expensive requests are served at
+// once across every client.
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/ethpandaops/checkpointz/pkg/clock"
+)
+
+// sweepInterval is how often buckets that have refilled are forgotten, so that memory doesn't grow with
+// every client ever seen.
+const sweepInterval = time.Minute
+
+var (
+	// ErrQueueFull is returned when too many requests are already waiting for a slot.
+	ErrQueueFull = errors.New("too many requests are queued")
+	// ErrQueueTimeout is returned when a request waited too long for a slot.
+	ErrQueueTimeout = errors.New("timed out waiting in the queue")
+)
+
+// Limiter limits each client's request rate per route, and the concurrency of state downloads.
+type Limiter struct {
+	config Config
+	clock  clock.Clock
+	routes map[string]Limit
+
+	mu        sync.Mutex
+	buckets   map[bucketKey]*Bucket
+	lastSweep time.Time
+
+	stateDownloads *Queue
+}
+
+type bucketKey struct {
+	route  string
+	client string
+}
+
+// New returns a Limiter for config.
+func New(config Config, clk clock.Clock) *Limiter {
+	routes := make(map[string]Limit, len(config.Routes))
+	for _, route := range config.Routes {
+		routes[route.Path] = route.Limit
+	}
+
+	return &Limiter{
+		config:         config,
+		clock:          clk,
+		routes:         routes,
+		buckets:        make(map[bucketKey]*Bucket),
+		lastSweep:      clk.Now(),
+		stateDownloads: NewQueue(config.StateDownloads, clk),
+	}
+}
+
+// Allow takes a token from the client's bucket for route. If there's none left, it returns false and how
+// long until there will be.
+func (l *Limiter) Allow(route, client string) (bool, time.Duration) {
+	limit, ok := l.routes[route]
+	if !ok {
+		// Routes without a limit of their own share the client's default bucket.
+		limit = l.config.Default
+		route = ""
+	}
+
+	if limit.Rate <= 0 {
+		return true, 0
+	}
+
+	now := l.clock.Now()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.sweep(now)
+
+	key := bucketKey{route: route, client: client}
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = NewBucket(limit, now)
+		l.buckets[key] = b
+	}
+
+	return b.Take(now)
+}
+
+// StateDownloads returns the queue state downloads wait in.
+func (l *Limiter) StateDownloads() *Queue {
+	return l.stateDownloads
+}
+
+// sweep forgets buckets that have refilled, as they're no different to new ones. l.mu must be held.
+func (l *Limiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < sweepInterval {
+		return
+	}
+
+	l.lastSweep = now
+
+	for key, b := range l.buckets {
+		if b.Full(now) {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+// Bucket is a token bucket for a Limit. It isn't safe for concurrent use.
+type Bucket struct {
+	limit   Limit
+	tokens  float64
+	updated time.Time
+}
+
+// NewBucket returns a full bucket for limit.
+func NewBucket(limit Limit, now time.Time) *Bucket {
+	return &Bucket{limit: limit, tokens: float64(limit.Burst), updated: now}
+}
+
+// Take takes a token from the bucket. If there's none left, it returns false and how long until there
+// will be. Unlimited buckets always have a token.
+func (b *Bucket) Take(now time.Time) (bool, time.Duration) {
+	if b.limit.Rate <= 0 {
+		return true, 0
+	}
+
+	b.refill(now)
+
+	if b.tokens < 1 {
+		return false, time.Duration((1 - b.tokens) / b.limit.Rate * float64(time.Second))
+	}
+
+	b.tokens--
+
+	return true, 0
+}
+
+// Full returns true if the bucket has refilled, so it's no different to a new one.
+func (b *Bucket) Full(now time.Time) bool {
+	b.refill(now)
+
+	return b.tokens >= float64(b.limit.Burst)
+}
+
+func (b *Bucket) refill(now time.Time) {
+	if elapsed := now.Sub(b.updated); elapsed > 0 {
+		b.tokens = math.Min(float64(b.limit.Burst), b.tokens+elapsed.Seconds()*b.limit.Rate)
+		b.updated = now
+	}
+}
+
+// Queue caps how many requests are served at once. Requests over the cap wait for a slot, up to a limit.
+type Queue struct {
+	config ConcurrencyConfig
+	clock  clock.Clock
+	slots  chan struct{}
+
+	mu     sync.Mutex
+	queued int
+}
+
+// NewQueue returns a Queue for config.
+func NewQueue(config ConcurrencyConfig, clk clock.Clock) *Queue {
+	q := &Queue{
+		config: config,
+		clock:  clk,
+	}
+
+	if config.MaxConcurrent > 0 {
+		q.slots = make(chan struct{}, config.MaxConcurrent)
+	}
+
+	return q
+}
+
+// Acquire waits for a slot, returning a func that frees it. It fails with ErrQueueFull if too many
+// requests are already waiting, and ErrQueueTimeout if the wait is too long.
+func (q *Queue) Acquire(ctx context.Context) (func(), error) {
+	if q.slots == nil {
+		return func() {}, nil
+	}
+
+	release := func() { <-q.slots }
+
+	select {
+	case q.slots <- struct{}{}:
+		return release, nil
+	default:
+	}
+
+	q.mu.Lock()
+
+	if q.queued >= q.config.MaxQueued {
+		q.mu.Unlock()
+
+		return nil, ErrQueueFull
+	}
+
+	q.queued++
+	q.mu.Unlock()
+
+	defer func() {
+		q.mu.Lock()
+		q.queued--
+		q.mu.Unlock()
+	}()
+
+	select {
+	case q.slots <- struct{}{}:
+		return release, nil
+	case <-q.clock.After(q.config.QueueTimeout):
+		return nil, ErrQueueTimeout
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// RetryAfter is how long clients turned away from the queue should wait before trying again.
+func (q *Queue) RetryAfter() time.Duration {
+	return q.config.QueueTimeout
+}
+
+// Queued returns how many requests are waiting for a slot.
+func (q *Queue) Queued() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	return q.queued
+}
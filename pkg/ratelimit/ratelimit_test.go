@@ -0,0 +1,191 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ethpandaops/checkpointz/pkg/clock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const statesRoute = "/eth/v2/debug/beacon/states/:state_id"
+
+func newTestLimiter(t *testing.T, clk clock.Clock, mutate func(*Config)) *Limiter {
+	t.Helper()
+
+	config := Config{
+		Enabled: true,
+		Default: Limit{Rate: 10, Burst: 2},
+		Routes: []RouteLimit{
+			{Path: statesRoute, Limit: Limit{Rate: 0.5, Burst: 1}},
+		},
+		StateDownloads: ConcurrencyConfig{MaxConcurrent: 1, MaxQueued: 1, QueueTimeout: 30 * time.Second},
+	}
+
+	if mutate != nil {
+		mutate(&config)
+	}
+
+	require.NoError(t, config.Validate())
+
+	return New(config, clk)
+}
+
+func TestLimiterAllow(t *testing.T) {
+	clk := clock.NewMock(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+	limiter := newTestLimiter(t, clk, nil)
+
+	// A client may use its burst straight away.
+	for range 2 {
+		ok, _ := limiter.Allow("/eth/v1/node/syncing", "1.1.1.1")
+		assert.True(t, ok)
+	}
+
+	// Routes without their own limit share the default bucket.
+	ok, retryAfter := limiter.Allow("/checkpointz/v1/status", "1.1.1.1")
+	assert.False(t, ok)
+	assert.Equal(t, 100*time.Millisecond, retryAfter)
+
+	// Other clients and routes with their own limit have their own buckets.
+	ok, _ = limiter.Allow("/checkpointz/v1/status", "2.2.2.2")
+	assert.True(t, ok)
+
+	ok, _ = limiter.Allow(statesRoute, "1.1.1.1")
+	assert.True(t, ok)
+
+	ok, retryAfter = limiter.Allow(statesRoute, "1.1.1.1")
+	assert.False(t, ok)
+	assert.Equal(t, 2*time.Second, retryAfter)
+
+	// Buckets refill over time.
+	clk.Advance(100 * time.Millisecond)
+
+	ok, _ = limiter.Allow("/checkpointz/v1/status", "1.1.1.1")
+	assert.True(t, ok)
+
+	ok, _ = limiter.Allow(statesRoute, "1.1.1.1")
+	assert.False(t, ok)
+
+	clk.Advance(2 * time.Second)
+
+	ok, _ = limiter.Allow(statesRoute, "1.1.1.1")
+	assert.True(t, ok)
+}
+
+func TestLimiterForgetsRefilledBuckets(t *testing.T) {
+	clk := clock.NewMock(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+	limiter := newTestLimiter(t, clk, nil)
+
+	limiter.Allow("/checkpointz/v1/status", "1.1.1.1")
+	limiter.Allow(statesRoute, "2.2.2.2")
+	assert.Len(t, limiter.buckets, 2)
+
+	clk.Advance(sweepInterval)
+	limiter.Allow("/checkpointz/v1/status", "3.3.3.3")
+
+	assert.Len(t, limiter.buckets, 1)
+}
+
+func TestLimiterUnlimited(t *testing.T) {
+	clk := clock.NewMock(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+	limiter := newTestLimiter(t, clk, func(c *Config) {
+		c.Default = Limit{}
+	})
+
+	for range 100 {
+		ok, _ := limiter.Allow("/checkpointz/v1/status", "1.1.1.1")
+		require.True(t, ok)
+	}
+
+	assert.Empty(t, limiter.buckets)
+}
+
+func TestQueue(t *testing.T) {
+	ctx := context.Background()
+	clk := clock.NewMock(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+	queue := NewQueue(ConcurrencyConfig{MaxConcurrent: 1, MaxQueued: 1, QueueTimeout: 30 * time.Second}, clk)
+
+	release, err := queue.Acquire(ctx)
+	require.NoError(t, err)
+
+	// The next request waits for the slot, and the one after is turned away.
+	acquired := make(chan func())
+
+	go func() {
+		next, err := queue.Acquire(ctx)
+		assert.NoError(t, err)
+
+		acquired <- next
+	}()
+
+	require.Eventually(t, func() bool { return queue.Queued() == 1 }, time.Second, time.Millisecond)
+
+	_, err = queue.Acquire(ctx)
+	require.ErrorIs(t, err, ErrQueueFull)
+
+	release()
+
+	next := <-acquired
+	assert.Zero(t, queue.Queued())
+
+	// A request that waits too long is turned away.
+	timedOut := make(chan error)
+
+	go func() {
+		_, err := queue.Acquire(ctx)
+		timedOut <- err
+	}()
+
+	// The first queued request's timer is still pending too.
+	clk.BlockUntil(2)
+	clk.Advance(30 * time.Second)
+
+	require.ErrorIs(t, <-timedOut, ErrQueueTimeout)
+
+	next()
+
+	release, err = queue.Acquire(ctx)
+	require.NoError(t, err)
+	release()
+}
+
+func TestQueueUnlimited(t *testing.T) {
+	queue := NewQueue(ConcurrencyConfig{}, clock.New())
+
+	for range 100 {
+		_, err := queue.Acquire(context.Background())
+		require.NoError(t, err)
+	}
+}
+
+func TestConfigValidate(t *testing.T) {
+	valid := Config{
+		Enabled:        true,
+		Default:        Limit{Rate: 20, Burst: 40},
+		Routes:         []RouteLimit{{Path: statesRoute, Limit: Limit{Rate: 0.1, Burst: 2}}},
+		StateDownloads: ConcurrencyConfig{MaxConcurrent: 4, MaxQueued: 32, QueueTimeout: 30 * time.Second},
+	}
+
+	require.NoError(t, valid.Validate())
+	require.NoError(t, (&Config{Default: Limit{Rate: -1}}).Validate(), "disabled limits aren't validated")
+
+	for name, mutate := range map[string]func(*Config){
+		"negative rate":        func(c *Config) { c.Default.Rate = -1 },
+		"no burst":             func(c *Config) { c.Default.Burst = 0 },
+		"relative route":       func(c *Config) { c.Routes[0].Path = "eth/v1/node/syncing" },
+		"duplicate route":      func(c *Config) { c.Routes = append(c.Routes, c.Routes[0]) },
+		"route without burst":  func(c *Config) { c.Routes[0].Burst = 0 },
+		"negative concurrency": func(c *Config) { c.StateDownloads.MaxConcurrent = -1 },
+		"no queue timeout":     func(c *Config) { c.StateDownloads.QueueTimeout = 0 },
+	} {
+		t.Run(name, func(t *testing.T) {
+			config := valid
+			config.Routes = append([]RouteLimit(nil), valid.Routes...)
+			mutate(&config)
+
+			assert.Error(t, config.Validate())
+		})
+	}
+}
@@ -1,12 +1,25 @@
 package checkpointz
 
 import (
+	"bytes"
 	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
 
+	v1 "github.com/attestantio/go-eth2-client/api/v1"
+	"github.com/attestantio/go-eth2-client/spec"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/ethpandaops/beacon/pkg/beacon/state"
+	"github.com/ethpandaops/checkpointz/pkg/attestation"
 	"github.com/ethpandaops/checkpointz/pkg/beacon"
+	"github.com/ethpandaops/checkpointz/pkg/era"
 	"github.com/ethpandaops/checkpointz/pkg/eth"
+	serviceeth "github.com/ethpandaops/checkpointz/pkg/service/eth"
 	"github.com/ethpandaops/checkpointz/pkg/version"
 	"github.com/sirupsen/logrus"
+	"golang.org/x/sync/singleflight"
 )
 
 // Handler is the Checkpointz API handler. HTTP-level concerns should NOT be contained in this package,
@@ -14,13 +27,19 @@ import (
 type Handler struct {
 	log      logrus.FieldLogger
 	provider beacon.FinalityProvider
+	attestor *attestation.Attestor
+
+	eras      *eraCache
+	eraBuilds singleflight.Group
 }
 
-// NewHandler returns a new Handler instance.
-func NewHandler(log logrus.FieldLogger, beac beacon.FinalityProvider) *Handler {
+// NewHandler returns a new Handler instance. attestor may be nil if attestations are disabled.
+func NewHandler(log logrus.FieldLogger, beac beacon.FinalityProvider, attestor *attestation.Attestor) *Handler {
 	return &Handler{
 		log:      log.WithField("module", "api/checkpointz"),
 		provider: beac,
+		attestor: attestor,
+		eras:     newEraCache(),
 	}
 }
 
@@ -43,6 +62,20 @@ func (h *Handler) V1Status(ctx context.Context, req *StatusRequest) (*StatusResp
 
 	response.Upstreams = upstreams
 
+	witnesses, err := h.provider.WitnessesStatus(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	response.Witnesses = witnesses
+
+	coordination, err := h.provider.CoordinationStatus(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	response.Coordination = coordination
+
 	finality, err := h.provider.Finalized(ctx)
 	if err != nil {
 		return nil, err
@@ -114,5 +147,296 @@ func (h *Handler) V1BeaconSlot(ctx context.Context, req *BeaconSlotRequest) (*Be
 		response.SlotTime = slotTime
 	}
 
+	if status, err := h.provider.GetBlockSignatureStatusBySlot(ctx, req.slot); err == nil {
+		response.Verification = &BlockVerification{
+			Signature: status,
+		}
+	}
+
 	return response, nil
 }
+
+// V1Provenance returns where the block and state for the given block or state root came from.
+func (h *Handler) V1Provenance(ctx context.Context, req *ProvenanceRequest) (*ProvenanceResponse, error) {
+	block, err := h.provider.GetBlockByRoot(ctx, req.root)
+	if err != nil {
+		// The root may be a state root instead.
+		block, err = h.provider.GetBlockByStateRoot(ctx, req.root)
+		if err != nil {
+			return nil, fmt.Errorf("no block or state found for root %#x", req.root)
+		}
+	}
+
+	blockRoot, err := h.provider.SSZEncoder().GetBlockRoot(block)
+	if err != nil {
+		return nil, err
+	}
+
+	stateRoot, err := block.StateRoot()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &ProvenanceResponse{
+		BlockRoot: blockRoot,
+		StateRoot: stateRoot,
+	}
+
+	if provenance, err := h.provider.GetBlockProvenance(ctx, blockRoot); err == nil {
+		response.Block = provenance
+	}
+
+	if provenance, err := h.provider.GetBeaconStateProvenance(ctx, stateRoot); err == nil {
+		response.State = provenance
+	}
+
+	return response, nil
+}
+
+// Attest signs the given serving checkpoint with the operator key.
+func (h *Handler) Attest(ctx context.Context, checkpoint *v1.Finality) error {
+	if h.attestor == nil {
+		return errors.New("attestations are not enabled")
+	}
+
+	if checkpoint == nil || checkpoint.Finalized == nil {
+		return errors.New("finalized checkpoint is nil")
+	}
+
+	block, err := h.provider.GetBlockByRoot(ctx, checkpoint.Finalized.Root)
+	if err != nil {
+		return fmt.Errorf("failed to get block for checkpoint: %w", err)
+	}
+
+	stateRoot, err := block.StateRoot()
+	if err != nil {
+		return fmt.Errorf("failed to get state root for checkpoint: %w", err)
+	}
+
+	genesis, err := h.provider.Genesis(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get genesis: %w", err)
+	}
+
+	if genesis == nil {
+		return errors.New("genesis is not known yet")
+	}
+
+	signed := h.attestor.Attest(attestation.Checkpoint{
+		Epoch:                 checkpoint.Finalized.Epoch,
+		BlockRoot:             checkpoint.Finalized.Root,
+		StateRoot:             stateRoot,
+		GenesisValidatorsRoot: genesis.GenesisValidatorsRoot,
+	})
+
+	h.log.
+		WithField("epoch", signed.Message.Epoch).
+		WithField("block_root", fmt.Sprintf("%#x", signed.Message.BlockRoot)).
+		Info("Signed serving checkpoint")
+
+	return nil
+}
+
+// V1Attestations returns the signed statements for the checkpoint currently being served and the ones
+// served before it.
+func (h *Handler) V1Attestations(ctx context.Context, req *AttestationsRequest) (*AttestationsResponse, error) {
+	if h.attestor == nil {
+		return nil, errors.New("attestations are not enabled")
+	}
+
+	return &AttestationsResponse{
+		PublicKey: fmt.Sprintf("%#x", []byte(h.attestor.PublicKey())),
+		Current:   h.attestor.Latest(),
+		History:   h.attestor.History(),
+	}, nil
+}
+
+// ErrEraUnavailable is returned when an era can't be built because part of it isn't held.
+var ErrEraUnavailable = errors.New("era is not available")
+
+// V1Era returns the era file for the requested era, built from the blocks within it and the state at its
+// closing boundary. It fails with ErrRequiresFullMode in light mode, and with ErrEraUnavailable unless the
+// state and every block in the era are held. Built eras don't change, so the latest few are kept.
+func (h *Handler) V1Era(ctx context.Context, req *EraRequest) (*EraResponse, error) {
+	if h.provider.OperatingMode() != beacon.OperatingModeFull {
+		return nil, fmt.Errorf("%w: era files", serviceeth.ErrRequiresFullMode)
+	}
+
+	if cached := h.eras.get(req.number); cached != nil {
+		return cached, nil
+	}
+
+	// Concurrent requests for the same era wait for one build, which carries on if the request that started it
+	// goes away.
+	buildCtx := context.WithoutCancel(ctx)
+
+	built, err, _ := h.eraBuilds.Do(strconv.FormatUint(req.number, 10), func() (any, error) {
+		rsp, err := h.buildEra(buildCtx, req.number)
+		if err != nil {
+			return nil, err
+		}
+
+		h.eras.add(req.number, rsp)
+
+		return rsp, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	rsp, _ := built.(*EraResponse)
+
+	return rsp, nil
+}
+
+func (h *Handler) buildEra(ctx context.Context, number uint64) (*EraResponse, error) {
+	sp, err := h.provider.Spec()
+	if err != nil {
+		return nil, err
+	}
+
+	slotsPerHistoricalRoot, err := strconv.ParseUint(fmt.Sprint(sp.FullSpec["SLOTS_PER_HISTORICAL_ROOT"]), 10, 64)
+	if err != nil || slotsPerHistoricalRoot == 0 {
+		return nil, errors.New("SLOTS_PER_HISTORICAL_ROOT is unknown")
+	}
+
+	e := &era.Era{
+		Number:                 number,
+		SlotsPerHistoricalRoot: slotsPerHistoricalRoot,
+	}
+
+	beaconState, err := h.provider.GetBeaconStateBySlot(ctx, e.StateSlot())
+	if err != nil || beaconState == nil {
+		return nil, fmt.Errorf("state at slot %d for era %d is not held: %w", e.StateSlot(), number, ErrEraUnavailable)
+	}
+
+	historicalRoot, err := era.HistoricalRoot(beaconState, number)
+	if err != nil {
+		return nil, err
+	}
+
+	encoder := h.provider.SSZEncoder()
+
+	if e.State, err = encoder.EncodeStateSSZ(ctx, beaconState); err != nil {
+		return nil, err
+	}
+
+	if number > 0 {
+		if e.Blocks, err = h.eraBlocks(ctx, e, beaconState); err != nil {
+			return nil, err
+		}
+	}
+
+	var buf bytes.Buffer
+
+	if err := e.Write(&buf); err != nil {
+		return nil, err
+	}
+
+	return &EraResponse{
+		Name: era.FileName(networkName(sp), number, historicalRoot),
+		Data: buf.Bytes(),
+	}, nil
+}
+
+// eraBlocks returns every block in e, as recorded in the block roots of its closing state. A slot whose root
+// is the same as the slot before it had no block.
+func (h *Handler) eraBlocks(ctx context.Context, e *era.Era, beaconState *spec.VersionedBeaconState) ([]era.Block, error) {
+	blockRoots, err := eth.BlockRootsFromState(beaconState)
+	if err != nil {
+		return nil, err
+	}
+
+	if uint64(len(blockRoots)) != e.SlotsPerHistoricalRoot {
+		return nil, fmt.Errorf("state has %d block roots, expected %d", len(blockRoots), e.SlotsPerHistoricalRoot)
+	}
+
+	encoder := h.provider.SSZEncoder()
+
+	blocks := []era.Block{}
+
+	var previous phase0.Root
+
+	for slot := e.StartSlot(); slot < e.StateSlot(); slot++ {
+		root := blockRoots[uint64(slot)%e.SlotsPerHistoricalRoot]
+		if root == previous {
+			continue
+		}
+
+		previous = root
+
+		block, err := h.provider.GetBlockByRoot(ctx, root)
+		if err != nil || block == nil {
+			return nil, fmt.Errorf("block %#x at slot %d for era %d is not held: %w", root, slot, e.Number, ErrEraUnavailable)
+		}
+
+		blockSlot, err := block.Slot()
+		if err != nil {
+			return nil, err
+		}
+
+		// The era's first slots may have no block, and repeat the root of the previous era's last one.
+		if blockSlot < e.StartSlot() {
+			continue
+		}
+
+		if blockSlot != slot {
+			return nil, fmt.Errorf("block %#x is at slot %d, but recorded at slot %d", root, blockSlot, slot)
+		}
+
+		data, err := encoder.EncodeBlockSSZ(ctx, block)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode block at slot %d: %w", slot, err)
+		}
+
+		blocks = append(blocks, era.Block{Slot: slot, SSZ: data})
+	}
+
+	return blocks, nil
+}
+
+// eraCache holds the most recently built eras.
+type eraCache struct {
+	mu     sync.Mutex
+	eras   map[uint64]*EraResponse
+	recent []uint64
+}
+
+// maxCachedEras is how many built eras are kept. Each holds a full beacon state.
+const maxCachedEras = 2
+
+func newEraCache() *eraCache {
+	return &eraCache{eras: make(map[uint64]*EraResponse, maxCachedEras)}
+}
+
+func (c *eraCache) get(number uint64) *EraResponse {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.eras[number]
+}
+
+func (c *eraCache) add(number uint64, rsp *EraResponse) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, exists := c.eras[number]; exists {
+		return
+	}
+
+	if len(c.recent) == maxCachedEras {
+		delete(c.eras, c.recent[0])
+		c.recent = c.recent[1:]
+	}
+
+	c.eras[number] = rsp
+	c.recent = append(c.recent, number)
+}
+
+func networkName(sp *state.Spec) string {
+	if sp.ConfigName != "" {
+		return sp.ConfigName
+	}
+
+	return eth.GetNetworkName(sp.DepositChainID)
+}
@@ -0,0 +1,134 @@
+package checkpointz
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/attestantio/go-eth2-client/spec"
+	"github.com/attestantio/go-eth2-client/spec/deneb"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/ethpandaops/beacon/pkg/beacon/state"
+	"github.com/ethpandaops/checkpointz/pkg/beacon"
+	"github.com/ethpandaops/checkpointz/pkg/beacon/ssz"
+	"github.com/ethpandaops/checkpointz/pkg/era"
+	serviceeth "github.com/ethpandaops/checkpointz/pkg/service/eth"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// blockProvider holds blocks by root.
+type blockProvider struct {
+	beacon.FinalityProvider
+
+	blocks map[phase0.Root]*spec.VersionedSignedBeaconBlock
+}
+
+func (p *blockProvider) GetBlockByRoot(ctx context.Context, root phase0.Root) (*spec.VersionedSignedBeaconBlock, error) {
+	block, ok := p.blocks[root]
+	if !ok {
+		return nil, errors.New("block not found")
+	}
+
+	return block, nil
+}
+
+func (p *blockProvider) SSZEncoder() *ssz.Encoder {
+	return ssz.NewEncoder(false)
+}
+
+func testBlock(slot phase0.Slot) *spec.VersionedSignedBeaconBlock {
+	return &spec.VersionedSignedBeaconBlock{
+		Version: spec.DataVersionPhase0,
+		Phase0: &phase0.SignedBeaconBlock{
+			Message: &phase0.BeaconBlock{
+				Slot: slot,
+				Body: &phase0.BeaconBlockBody{
+					ETH1Data: &phase0.ETH1Data{BlockHash: make([]byte, 32)},
+				},
+			},
+		},
+	}
+}
+
+func TestEraBlocksRequiresEveryBlock(t *testing.T) {
+	provider := &blockProvider{blocks: map[phase0.Root]*spec.VersionedSignedBeaconBlock{
+		{0x01}: testBlock(3),
+		{0x02}: testBlock(4),
+		{0x03}: testBlock(6),
+	}}
+
+	h := NewHandler(logrus.New(), provider, nil)
+
+	// Era 2 covers slots 4-7. Slot 5 was missed, and so was slot 7, whose block isn't held.
+	e := &era.Era{Number: 2, SlotsPerHistoricalRoot: 4}
+	beaconState := &spec.VersionedBeaconState{
+		Version: spec.DataVersionDeneb,
+		Deneb: &deneb.BeaconState{
+			BlockRoots: []phase0.Root{{0x02}, {0x02}, {0x03}, {0x04}},
+		},
+	}
+
+	_, err := h.eraBlocks(context.Background(), e, beaconState)
+	require.ErrorIs(t, err, ErrEraUnavailable)
+
+	// If slot 4 was missed too, it repeats the previous era's last block, which isn't part of the era.
+	beaconState.Deneb.BlockRoots = []phase0.Root{{0x01}, {0x01}, {0x03}, {0x03}}
+
+	blocks, err := h.eraBlocks(context.Background(), e, beaconState)
+	require.NoError(t, err)
+	require.Len(t, blocks, 1)
+	assert.Equal(t, phase0.Slot(6), blocks[0].Slot)
+
+	// If slot 7 was missed, every block is held.
+	beaconState.Deneb.BlockRoots = []phase0.Root{{0x02}, {0x02}, {0x03}, {0x03}}
+
+	blocks, err = h.eraBlocks(context.Background(), e, beaconState)
+	require.NoError(t, err)
+	require.Len(t, blocks, 2)
+	assert.Equal(t, phase0.Slot(4), blocks[0].Slot)
+	assert.Equal(t, phase0.Slot(6), blocks[1].Slot)
+}
+
+// stateProvider holds no states, and records whether the context it was asked for one with was done.
+type stateProvider struct {
+	beacon.FinalityProvider
+
+	mode    beacon.OperatingMode
+	ctxDone bool
+}
+
+func (p *stateProvider) OperatingMode() beacon.OperatingMode {
+	return p.mode
+}
+
+func (p *stateProvider) Spec() (*state.Spec, error) {
+	return &state.Spec{FullSpec: map[string]interface{}{"SLOTS_PER_HISTORICAL_ROOT": "8192"}}, nil
+}
+
+func (p *stateProvider) GetBeaconStateBySlot(ctx context.Context, slot phase0.Slot) (*spec.VersionedBeaconState, error) {
+	p.ctxDone = ctx.Err() != nil
+
+	return nil, errors.New("state not found")
+}
+
+func TestEraRequiresFullMode(t *testing.T) {
+	h := NewHandler(logrus.New(), &stateProvider{mode: beacon.OperatingModeLight}, nil)
+
+	_, err := h.V1Era(context.Background(), NewEraRequest(1))
+	require.ErrorIs(t, err, serviceeth.ErrRequiresFullMode)
+}
+
+func TestEraBuildOutlivesItsRequest(t *testing.T) {
+	provider := &stateProvider{mode: beacon.OperatingModeFull}
+	h := NewHandler(logrus.New(), provider, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// Other requests may be waiting on the build, so it mustn't stop when the request that started it does.
+	_, err := h.V1Era(ctx, NewEraRequest(1))
+	require.ErrorIs(t, err, ErrEraUnavailable)
+	assert.False(t, provider.ctxDone)
+}
@@ -37,3 +37,42 @@ func NewBeaconSlotRequest(slot phase0.Slot) *BeaconSlotRequest {
 		slot: slot,
 	}
 }
+
+type ProvenanceRequest struct {
+	root phase0.Root
+}
+
+func (r *ProvenanceRequest) Validate() error {
+	return nil
+}
+
+func NewProvenanceRequest(root phase0.Root) *ProvenanceRequest {
+	return &ProvenanceRequest{
+		root: root,
+	}
+}
+
+type AttestationsRequest struct {
+}
+
+func (r *AttestationsRequest) Validate() error {
+	return nil
+}
+
+func NewAttestationsRequest() *AttestationsRequest {
+	return &AttestationsRequest{}
+}
+
+type EraRequest struct {
+	number uint64
+}
+
+func (r *EraRequest) Validate() error {
+	return nil
+}
+
+func NewEraRequest(number uint64) *EraRequest {
+	return &EraRequest{
+		number: number,
+	}
+}
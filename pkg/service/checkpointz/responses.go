@@ -4,12 +4,19 @@ import (
 	v1 "github.com/attestantio/go-eth2-client/api/v1"
 	"github.com/attestantio/go-eth2-client/spec"
 	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/ethpandaops/checkpointz/pkg/attestation"
 	"github.com/ethpandaops/checkpointz/pkg/beacon"
+	"github.com/ethpandaops/checkpointz/pkg/beacon/leader"
+	"github.com/ethpandaops/checkpointz/pkg/beacon/store"
+	"github.com/ethpandaops/checkpointz/pkg/beacon/verify"
+	"github.com/ethpandaops/checkpointz/pkg/beacon/witness"
 	"github.com/ethpandaops/checkpointz/pkg/eth"
 )
 
 type StatusResponse struct {
 	Upstreams     map[string]*beacon.UpstreamStatus `json:"upstreams"`
+	Witnesses     map[string]*witness.Report        `json:"witnesses,omitempty"`
+	Coordination  *leader.Status                    `json:"coordination,omitempty"`
 	Finality      *v1.Finality                      `json:"finality"`
 	PublicURL     string                            `json:"public_url,omitempty"`
 	BrandName     string                            `json:"brand_name,omitempty"`
@@ -38,7 +45,31 @@ type BeaconSlotsResponse struct {
 }
 
 type BeaconSlotResponse struct {
-	Block    *spec.VersionedSignedBeaconBlock `json:"block"`
-	Epoch    phase0.Epoch                     `json:"epoch"`
-	SlotTime eth.SlotTime                     `json:"time"`
+	Block        *spec.VersionedSignedBeaconBlock `json:"block"`
+	Epoch        phase0.Epoch                     `json:"epoch"`
+	SlotTime     eth.SlotTime                     `json:"time"`
+	Verification *BlockVerification               `json:"verification,omitempty"`
+}
+
+type BlockVerification struct {
+	Signature verify.Status `json:"signature"`
+}
+
+type ProvenanceResponse struct {
+	BlockRoot phase0.Root       `json:"block_root"`
+	StateRoot phase0.Root       `json:"state_root"`
+	Block     *store.Provenance `json:"block,omitempty"`
+	State     *store.Provenance `json:"state,omitempty"`
+}
+
+type AttestationsResponse struct {
+	PublicKey string                          `json:"public_key"`
+	Current   *attestation.SignedCheckpoint   `json:"current"`
+	History   []*attestation.SignedCheckpoint `json:"history"`
+}
+
+type EraResponse struct {
+	// Name is the era file's conventional file name.
+	Name string
+	Data []byte
 }
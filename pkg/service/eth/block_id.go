@@ -1,13 +1,12 @@
 package eth
 
 import (
-	"encoding/hex"
 	"fmt"
 	"strconv"
 	"strings"
 
 	"github.com/attestantio/go-eth2-client/spec/phase0"
-	"github.com/pkg/errors"
+	"github.com/ethpandaops/checkpointz/pkg/eth"
 )
 
 type BlockIDType int
@@ -92,20 +91,7 @@ func NewSlotFromString(id string) (phase0.Slot, error) {
 }
 
 func NewRootFromString(id string) (phase0.Root, error) {
-	b, err := hex.DecodeString(strings.TrimPrefix(id, "0x"))
-	if err != nil {
-		return phase0.Root{}, errors.Wrap(err, "invalid value for root")
-	}
-
-	root := phase0.Root{}
-
-	if len(b) != len(root) {
-		return phase0.Root{}, fmt.Errorf("incorrect length %d for root", len(b))
-	}
-
-	copy(root[:], b)
-
-	return root, nil
+	return eth.RootFromString(id)
 }
 
 func (t BlockIDType) String() string {
@@ -2,6 +2,7 @@ package eth
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	v1 "github.com/attestantio/go-eth2-client/api/v1"
@@ -11,10 +12,19 @@ import (
 	"github.com/ethpandaops/beacon/pkg/beacon/api/types"
 	"github.com/ethpandaops/beacon/pkg/beacon/state"
 	"github.com/ethpandaops/checkpointz/pkg/beacon"
+	"github.com/ethpandaops/checkpointz/pkg/beacon/fulu"
+	"github.com/ethpandaops/checkpointz/pkg/beacon/store"
+	"github.com/ethpandaops/checkpointz/pkg/eth"
+	"github.com/ethpandaops/checkpointz/pkg/tracing"
 	"github.com/ethpandaops/checkpointz/pkg/version"
 	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
+// ErrRequiresFullMode is returned for data only held when running in full mode.
+var ErrRequiresFullMode = errors.New("only available in full mode")
+
 // Handler is the Eth Service handler. HTTP-level concerns should NOT be contained in this package,
 // they should be handled and reasoned with at a higher level.
 type Handler struct {
@@ -35,17 +45,18 @@ func NewHandler(log logrus.FieldLogger, beac beacon.FinalityProvider, namespace
 }
 
 // BeaconBlock returns the beacon block for the given block ID.
-func (h *Handler) BeaconBlock(ctx context.Context, blockID BlockIdentifier) (*spec.VersionedSignedBeaconBlock, error) {
-	var err error
-
+func (h *Handler) BeaconBlock(ctx context.Context, blockID BlockIdentifier) (_ *spec.VersionedSignedBeaconBlock, err error) {
 	const call = "beacon_block"
 
 	h.metrics.ObserveCall(call, blockID.Type().String())
 
+	ctx, span := tracing.Start(ctx, "eth.BeaconBlock", trace.WithAttributes(attribute.String("eth.block_id", blockID.String())))
 	defer func() {
 		if err != nil {
 			h.metrics.ObserveErrorCall(call, blockID.Type().String())
 		}
+
+		tracing.End(span, err)
 	}()
 
 	switch blockID.Type() {
@@ -82,51 +93,54 @@ func (h *Handler) BeaconBlock(ctx context.Context, blockID BlockIdentifier) (*sp
 }
 
 // BeaconGenesis returns the details of the chain's genesis.
-func (h *Handler) BeaconGenesis(ctx context.Context) (*v1.Genesis, error) {
-	var err error
-
+func (h *Handler) BeaconGenesis(ctx context.Context) (_ *v1.Genesis, err error) {
 	const call = "beacon_genesis"
 
 	h.metrics.ObserveCall(call, "")
 
+	ctx, span := tracing.Start(ctx, "eth.BeaconGenesis")
 	defer func() {
 		if err != nil {
 			h.metrics.ObserveErrorCall(call, "")
 		}
+
+		tracing.End(span, err)
 	}()
 
 	return h.provider.Genesis(ctx)
 }
 
 // ConfigSpec gets the spec configuration.
-func (h *Handler) ConfigSpec(ctx context.Context) (*state.Spec, error) {
-	var err error
-
+func (h *Handler) ConfigSpec(ctx context.Context) (_ *state.Spec, err error) {
 	const call = "config_spec"
 
 	h.metrics.ObserveCall(call, "")
 
+	_, span := tracing.Start(ctx, "eth.ConfigSpec")
 	defer func() {
 		if err != nil {
 			h.metrics.ObserveErrorCall(call, "")
 		}
+
+		tracing.End(span, err)
 	}()
 
 	return h.provider.Spec()
 }
 
 // ForkSchedule returns the upcoming forks.
-func (h *Handler) ForkSchedule(ctx context.Context) ([]*state.ScheduledFork, error) {
-	var err error
-
+func (h *Handler) ForkSchedule(ctx context.Context) (_ []*state.ScheduledFork, err error) {
 	const call = "fork_schedule"
 
 	h.metrics.ObserveCall(call, "")
 
+	_, span := tracing.Start(ctx, "eth.ForkSchedule")
 	defer func() {
 		if err != nil {
 			h.metrics.ObserveErrorCall(call, "")
 		}
+
+		tracing.End(span, err)
 	}()
 
 	sp, err := h.provider.Spec()
@@ -143,17 +157,18 @@ func (h *Handler) ForkSchedule(ctx context.Context) ([]*state.ScheduledFork, err
 }
 
 // DepositContract gets the Eth1 deposit address and chain ID
-func (h *Handler) DepositContract(ctx context.Context) (*DepositContract, error) {
-	var err error
-
+func (h *Handler) DepositContract(ctx context.Context) (_ *DepositContract, err error) {
 	const call = "config_deposit_contract"
 
 	h.metrics.ObserveCall(call, "")
 
+	_, span := tracing.Start(ctx, "eth.DepositContract")
 	defer func() {
 		if err != nil {
 			h.metrics.ObserveErrorCall(call, "")
 		}
+
+		tracing.End(span, err)
 	}()
 
 	sp, err := h.provider.Spec()
@@ -168,17 +183,18 @@ func (h *Handler) DepositContract(ctx context.Context) (*DepositContract, error)
 }
 
 // DepositContract gets the deposit snapshot at the finalized checkpoint.
-func (h *Handler) DepositSnapshot(ctx context.Context) (*types.DepositSnapshot, error) {
-	var err error
-
+func (h *Handler) DepositSnapshot(ctx context.Context) (_ *types.DepositSnapshot, err error) {
 	const call = "beacon_deposit_snapshot"
 
 	h.metrics.ObserveCall(call, "")
 
+	ctx, span := tracing.Start(ctx, "eth.DepositSnapshot")
 	defer func() {
 		if err != nil {
 			h.metrics.ObserveErrorCall(call, "")
 		}
+
+		tracing.End(span, err)
 	}()
 
 	finality, err := h.provider.Finalized(ctx)
@@ -199,85 +215,90 @@ func (h *Handler) DepositSnapshot(ctx context.Context) (*types.DepositSnapshot,
 }
 
 // NodeSyncing returns the sync state of the beacon node.
-func (h *Handler) NodeSyncing(ctx context.Context) (*v1.SyncState, error) {
-	var err error
-
+func (h *Handler) NodeSyncing(ctx context.Context) (_ *v1.SyncState, err error) {
 	const call = "node_syncing"
 
 	h.metrics.ObserveCall(call, "")
 
+	ctx, span := tracing.Start(ctx, "eth.NodeSyncing")
 	defer func() {
 		if err != nil {
 			h.metrics.ObserveErrorCall(call, "")
 		}
+
+		tracing.End(span, err)
 	}()
 
 	return h.provider.Syncing(ctx)
 }
 
 // NodeVersion returns the version of the beacon node.
-func (h *Handler) NodeVersion(ctx context.Context) (string, error) {
-	var err error
-
+func (h *Handler) NodeVersion(ctx context.Context) (_ string, err error) {
 	const call = "node_version"
 
 	h.metrics.ObserveCall(call, "")
 
+	_, span := tracing.Start(ctx, "eth.NodeVersion")
 	defer func() {
 		if err != nil {
 			h.metrics.ObserveErrorCall(call, "")
 		}
+
+		tracing.End(span, err)
 	}()
 
 	return version.FullVWithGOOS(), nil
 }
 
 // Peers returns the peers connected to the beacon node.
-func (h *Handler) Peers(ctx context.Context) (types.Peers, error) {
-	var err error
-
+func (h *Handler) Peers(ctx context.Context) (_ types.Peers, err error) {
 	const call = "node_peers"
 
 	h.metrics.ObserveCall(call, "")
 
+	ctx, span := tracing.Start(ctx, "eth.Peers")
 	defer func() {
 		if err != nil {
 			h.metrics.ObserveErrorCall(call, "")
 		}
+
+		tracing.End(span, err)
 	}()
 
 	return h.provider.Peers(ctx)
 }
 
 // PeerCount returns the amount of peers connected to the beacon node.
-func (h *Handler) PeerCount(ctx context.Context) (uint64, error) {
-	var err error
-
+func (h *Handler) PeerCount(ctx context.Context) (_ uint64, err error) {
 	const call = "node_peer_count"
 
 	h.metrics.ObserveCall(call, "")
 
+	ctx, span := tracing.Start(ctx, "eth.PeerCount")
 	defer func() {
 		if err != nil {
 			h.metrics.ObserveErrorCall(call, "")
 		}
+
+		tracing.End(span, err)
 	}()
 
 	return h.provider.PeerCount(ctx)
 }
 
 // BeaconState returns the beacon state for the given state id.
-func (h *Handler) BeaconState(ctx context.Context, stateID StateIdentifier) (*spec.VersionedBeaconState, error) {
-	var err error
-
+func (h *Handler) BeaconState(ctx context.Context, stateID StateIdentifier) (_ *spec.VersionedBeaconState, err error) {
 	const call = "beacon_state"
 
 	h.metrics.ObserveCall(call, stateID.Type().String())
 
+	ctx, span := tracing.Start(ctx, "eth.BeaconState", trace.WithAttributes(attribute.String("eth.state_id", stateID.String())))
 	defer func() {
 		if err != nil {
 			h.metrics.ObserveErrorCall(call, stateID.Type().String())
 		}
+
+		tracing.End(span, err)
 	}()
 
 	switch stateID.Type() {
@@ -314,17 +335,18 @@ func (h *Handler) BeaconState(ctx context.Context, stateID StateIdentifier) (*sp
 }
 
 // FinalityCheckpoints returns the finality checkpoints for the given state id.
-func (h *Handler) FinalityCheckpoints(ctx context.Context, stateID StateIdentifier) (*v1.Finality, error) {
-	var err error
-
+func (h *Handler) FinalityCheckpoints(ctx context.Context, stateID StateIdentifier) (_ *v1.Finality, err error) {
 	const call = "finality_checkpoints"
 
 	h.metrics.ObserveCall(call, stateID.Type().String())
 
+	ctx, span := tracing.Start(ctx, "eth.FinalityCheckpoints", trace.WithAttributes(attribute.String("eth.state_id", stateID.String())))
 	defer func() {
 		if err != nil {
 			h.metrics.ObserveErrorCall(call, stateID.Type().String())
 		}
+
+		tracing.End(span, err)
 	}()
 
 	switch stateID.Type() {
@@ -350,23 +372,61 @@ func (h *Handler) FinalityCheckpoints(ctx context.Context, stateID StateIdentifi
 		}
 
 		return finality, nil
+	case StateIDGenesis, StateIDSlot, StateIDRoot:
+		// Historical finality can only be derived from the state itself. Blocks don't carry it, so light mode,
+		// which only stores blocks, can't serve it.
+		if h.provider.OperatingMode() != beacon.OperatingModeFull {
+			return nil, fmt.Errorf("%w: finality checkpoints for state id %v", ErrRequiresFullMode, stateID.String())
+		}
+
+		var st *spec.VersionedBeaconState
+
+		switch stateID.Type() {
+		case StateIDGenesis:
+			st, err = h.provider.GetBeaconStateBySlot(ctx, phase0.Slot(0))
+		case StateIDSlot:
+			slot, errr := NewSlotFromString(stateID.Value())
+			if errr != nil {
+				return nil, errr
+			}
+
+			st, err = h.provider.GetBeaconStateBySlot(ctx, slot)
+		case StateIDRoot:
+			root, errr := stateID.AsRoot()
+			if errr != nil {
+				return nil, errr
+			}
+
+			st, err = h.provider.GetBeaconStateByStateRoot(ctx, root)
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		if st == nil {
+			return nil, fmt.Errorf("no state for state id %v", stateID.String())
+		}
+
+		return eth.FinalityFromState(st)
 	default:
 		return nil, fmt.Errorf("invalid state id: %v", stateID.String())
 	}
 }
 
 // BlockRoot returns the beacon block root for the given block ID.
-func (h *Handler) BlockRoot(ctx context.Context, blockID BlockIdentifier) (phase0.Root, error) {
-	var err error
-
+func (h *Handler) BlockRoot(ctx context.Context, blockID BlockIdentifier) (_ phase0.Root, err error) {
 	const call = "block_root"
 
 	h.metrics.ObserveCall(call, blockID.Type().String())
 
+	ctx, span := tracing.Start(ctx, "eth.BlockRoot", trace.WithAttributes(attribute.String("eth.block_id", blockID.String())))
 	defer func() {
 		if err != nil {
 			h.metrics.ObserveErrorCall(call, blockID.Type().String())
 		}
+
+		tracing.End(span, err)
 	}()
 
 	switch blockID.Type() {
@@ -439,17 +499,18 @@ func (h *Handler) BlockRoot(ctx context.Context, blockID BlockIdentifier) (phase
 }
 
 // BlobSidecars returns the blob sidecars for the given block ID.
-func (h *Handler) BlobSidecars(ctx context.Context, blockID BlockIdentifier, indices []int) ([]*deneb.BlobSidecar, spec.DataVersion, error) {
-	var err error
-
+func (h *Handler) BlobSidecars(ctx context.Context, blockID BlockIdentifier, indices []int) (_ []*deneb.BlobSidecar, _ spec.DataVersion, err error) {
 	const call = "blob_sidecars"
 
 	h.metrics.ObserveCall(call, blockID.Type().String())
 
+	ctx, span := tracing.Start(ctx, "eth.BlobSidecars", trace.WithAttributes(attribute.String("eth.block_id", blockID.String())))
 	defer func() {
 		if err != nil {
 			h.metrics.ObserveErrorCall(call, blockID.Type().String())
 		}
+
+		tracing.End(span, err)
 	}()
 
 	slot := phase0.Slot(0)
@@ -582,3 +643,300 @@ func (h *Handler) BlobSidecars(ctx context.Context, blockID BlockIdentifier, ind
 
 	return filtered, dataVersion, nil
 }
+
+// DataColumnSidecars returns the data column sidecars for the given block ID.
+func (h *Handler) DataColumnSidecars(ctx context.Context, blockID BlockIdentifier, indices []int) (_ []*fulu.DataColumnSidecar, _ spec.DataVersion, err error) {
+	const call = "data_column_sidecars"
+
+	h.metrics.ObserveCall(call, blockID.Type().String())
+
+	ctx, span := tracing.Start(ctx, "eth.DataColumnSidecars", trace.WithAttributes(attribute.String("eth.block_id", blockID.String())))
+	defer func() {
+		if err != nil {
+			h.metrics.ObserveErrorCall(call, blockID.Type().String())
+		}
+
+		tracing.End(span, err)
+	}()
+
+	block, err := h.resolveBlock(ctx, blockID)
+	if err != nil {
+		return nil, spec.DataVersionUnknown, err
+	}
+
+	slot, err := block.Slot()
+	if err != nil {
+		return nil, block.Version, err
+	}
+
+	sidecars, err := h.provider.GetDataColumnSidecarsBySlot(ctx, slot)
+	if err != nil {
+		return nil, block.Version, err
+	}
+
+	if len(indices) == 0 {
+		return sidecars, block.Version, nil
+	}
+
+	filtered := make([]*fulu.DataColumnSidecar, 0, len(indices))
+
+	for _, index := range indices {
+		if index < 0 {
+			err = fmt.Errorf("invalid index %v", index)
+
+			return nil, block.Version, err
+		}
+
+		for i, sidecar := range sidecars {
+			if uint64(index) == sidecar.Index {
+				filtered = append(filtered, sidecars[i])
+
+				break
+			}
+		}
+	}
+
+	return filtered, block.Version, nil
+}
+
+// Blobs returns the blobs for the given block ID, optionally filtered by versioned hash.
+// Post-Fulu the blobs are reconstructed from the stored data column sidecars.
+func (h *Handler) Blobs(ctx context.Context, blockID BlockIdentifier, versionedHashes []deneb.VersionedHash) (_ v1.Blobs, err error) {
+	const call = "blobs"
+
+	h.metrics.ObserveCall(call, blockID.Type().String())
+
+	ctx, span := tracing.Start(ctx, "eth.Blobs", trace.WithAttributes(attribute.String("eth.block_id", blockID.String())))
+	defer func() {
+		if err != nil {
+			h.metrics.ObserveErrorCall(call, blockID.Type().String())
+		}
+
+		tracing.End(span, err)
+	}()
+
+	block, err := h.resolveBlock(ctx, blockID)
+	if err != nil {
+		return nil, err
+	}
+
+	if block.Version < spec.DataVersionDeneb {
+		err = fmt.Errorf("blobs are not available before deneb")
+
+		return nil, err
+	}
+
+	slot, err := block.Slot()
+	if err != nil {
+		return nil, err
+	}
+
+	commitments, err := block.BlobKZGCommitments()
+	if err != nil {
+		return nil, err
+	}
+
+	blobs := v1.Blobs{}
+
+	if len(commitments) > 0 {
+		if block.Version >= spec.DataVersionFulu {
+			var columns []*fulu.DataColumnSidecar
+
+			columns, err = h.provider.GetDataColumnSidecarsBySlot(ctx, slot)
+			if err != nil {
+				if errors.Is(err, store.ErrNotFound) {
+					err = fmt.Errorf("%w: no columns are held for slot %d", fulu.ErrNotEnoughColumns, slot)
+				}
+
+				return nil, err
+			}
+
+			blobs, err = fulu.BlobsFromColumns(columns)
+			if err != nil {
+				return nil, err
+			}
+		} else {
+			var sidecars []*deneb.BlobSidecar
+
+			sidecars, err = h.provider.GetBlobSidecarsBySlot(ctx, slot)
+			if err != nil {
+				return nil, err
+			}
+
+			blobs = make(v1.Blobs, len(commitments))
+
+			for _, sidecar := range sidecars {
+				if int(sidecar.Index) >= len(blobs) { //nolint:gosec // index is bounded by MAX_BLOBS_PER_BLOCK
+					continue
+				}
+
+				blobs[sidecar.Index] = &sidecar.Blob
+			}
+		}
+
+		if len(blobs) != len(commitments) {
+			err = fmt.Errorf("expected %d blobs, got %d", len(commitments), len(blobs))
+
+			return nil, err
+		}
+
+		for i, blob := range blobs {
+			if blob == nil {
+				err = fmt.Errorf("blob %d is missing", i)
+
+				return nil, err
+			}
+		}
+	}
+
+	if len(versionedHashes) == 0 {
+		return blobs, nil
+	}
+
+	filtered := make(v1.Blobs, 0, len(versionedHashes))
+
+	for _, versionedHash := range versionedHashes {
+		for i, commitment := range commitments {
+			if eth.KZGCommitmentToVersionedHash(commitment) == versionedHash {
+				filtered = append(filtered, blobs[i])
+
+				break
+			}
+		}
+	}
+
+	return filtered, nil
+}
+
+// BlockProvenance returns where the block for the given block ID came from.
+func (h *Handler) BlockProvenance(ctx context.Context, blockID BlockIdentifier) (*store.Provenance, error) {
+	block, err := h.resolveBlock(ctx, blockID)
+	if err != nil {
+		return nil, err
+	}
+
+	root, err := h.provider.SSZEncoder().GetBlockRoot(block)
+	if err != nil {
+		return nil, err
+	}
+
+	return h.provider.GetBlockProvenance(ctx, root)
+}
+
+// StateProvenance returns where the beacon state for the given state ID came from.
+func (h *Handler) StateProvenance(ctx context.Context, stateID StateIdentifier) (*store.Provenance, error) {
+	stateRoot, err := h.resolveStateRoot(ctx, stateID)
+	if err != nil {
+		return nil, err
+	}
+
+	return h.provider.GetBeaconStateProvenance(ctx, stateRoot)
+}
+
+// StateURL returns the URL downloads of the beacon state for the given state ID should be redirected to, or
+// an empty string if it's served directly.
+func (h *Handler) StateURL(ctx context.Context, stateID StateIdentifier) (string, error) {
+	stateRoot, err := h.resolveStateRoot(ctx, stateID)
+	if err != nil {
+		return "", err
+	}
+
+	return h.provider.GetBeaconStateURL(ctx, stateRoot)
+}
+
+// StateRoot returns the state root for the given state ID, without hashing the state.
+func (h *Handler) StateRoot(ctx context.Context, stateID StateIdentifier) (phase0.Root, error) {
+	return h.resolveStateRoot(ctx, stateID)
+}
+
+// resolveStateRoot returns the state root for the given state ID, using the state root of the block it
+// resolves to rather than hashing the state.
+func (h *Handler) resolveStateRoot(ctx context.Context, stateID StateIdentifier) (phase0.Root, error) {
+	var (
+		block *spec.VersionedSignedBeaconBlock
+		err   error
+	)
+
+	switch stateID.Type() {
+	case StateIDRoot:
+		return stateID.AsRoot()
+	case StateIDGenesis:
+		block, err = h.resolveBlock(ctx, newBlockIdentifier(BlockIDGenesis, "genesis"))
+	case StateIDSlot:
+		block, err = h.resolveBlock(ctx, newBlockIdentifier(BlockIDSlot, stateID.Value()))
+	case StateIDFinalized:
+		block, err = h.resolveBlock(ctx, newBlockIdentifier(BlockIDFinalized, "finalized"))
+	default:
+		return phase0.Root{}, fmt.Errorf("invalid state id: %v", stateID.String())
+	}
+
+	if err != nil {
+		return phase0.Root{}, err
+	}
+
+	return block.StateRoot()
+}
+
+// FinalityProvenance returns where the finality checkpoints for the given state ID came from. Head and
+// finalized checkpoints are attributed to the finalized block, as they're decided rather than read from a state.
+func (h *Handler) FinalityProvenance(ctx context.Context, stateID StateIdentifier) (*store.Provenance, error) {
+	switch stateID.Type() {
+	case StateIDHead, StateIDFinalized:
+		return h.BlockProvenance(ctx, newBlockIdentifier(BlockIDFinalized, "finalized"))
+	default:
+		return h.StateProvenance(ctx, stateID)
+	}
+}
+
+// resolveBlock returns the block for the given block ID.
+func (h *Handler) resolveBlock(ctx context.Context, blockID BlockIdentifier) (*spec.VersionedSignedBeaconBlock, error) {
+	var (
+		block *spec.VersionedSignedBeaconBlock
+		err   error
+	)
+
+	switch blockID.Type() {
+	case BlockIDGenesis:
+		block, err = h.provider.GetBlockBySlot(ctx, phase0.Slot(0))
+	case BlockIDSlot:
+		slot, errr := NewSlotFromString(blockID.Value())
+		if errr != nil {
+			return nil, errr
+		}
+
+		block, err = h.provider.GetBlockBySlot(ctx, slot)
+	case BlockIDRoot:
+		root, errr := blockID.AsRoot()
+		if errr != nil {
+			return nil, errr
+		}
+
+		block, err = h.provider.GetBlockByRoot(ctx, root)
+	case BlockIDFinalized:
+		finality, errr := h.provider.Finalized(ctx)
+		if errr != nil {
+			return nil, errr
+		}
+
+		if finality == nil || finality.Finalized == nil {
+			return nil, fmt.Errorf("no finality")
+		}
+
+		block, err = h.provider.GetBlockByRoot(ctx, finality.Finalized.Root)
+	case BlockIDHead:
+		// We only hold finalized blocks, so there's no head block to attribute.
+		return nil, fmt.Errorf("block id %v is not supported", blockID.String())
+	default:
+		return nil, fmt.Errorf("invalid block id: %v", blockID.String())
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if block == nil {
+		return nil, fmt.Errorf("no block for block id %v", blockID.String())
+	}
+
+	return block, nil
+}
@@ -0,0 +1,16 @@
+package eth
+
+import (
+	"context"
+	"testing"
+)
+
+func TestBlockProvenanceRejectsHead(t *testing.T) {
+	t.Parallel()
+
+	h := &Handler{}
+
+	if _, err := h.BlockProvenance(context.Background(), newBlockIdentifier(BlockIDHead, "head")); err == nil {
+		t.Error("Expected an error for the head block")
+	}
+}
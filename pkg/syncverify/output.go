@@ -0,0 +1,74 @@
+package syncverify
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/ethpandaops/checkpointz/pkg/eth"
+)
+
+// WriteText writes a human readable summary of the report to w.
+func (r *Report) WriteText(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	out := &errWriter{w: tw}
+
+	out.printf("Beacon node %s has finalized epoch %s (%s)\n\n",
+		r.Node, eth.EpochAsString(r.NodeFinalized.Epoch), eth.RootAsString(r.NodeFinalized.Root))
+
+	for _, p := range r.Providers {
+		out.printf("%s: %s\n", p.URL, p.Verdict)
+
+		if p.Error != "" {
+			out.printf("  error: %s\n\n", p.Error)
+
+			continue
+		}
+
+		out.printf("  finalized epoch %s (%s)\n", eth.EpochAsString(p.Finalized.Epoch), eth.RootAsString(p.Finalized.Root))
+		out.printf("  EPOCH\tSLOT\tNODE ROOT\tPROVIDER ROOT\tRESULT\n")
+
+		for _, c := range p.Comparisons {
+			result := string(c.Result)
+			if c.Error != "" {
+				result = fmt.Sprintf("%s (%s)", result, c.Error)
+			}
+
+			out.printf("  %s\t%s\t%s\t%s\t%s\n",
+				eth.EpochAsString(c.Epoch), eth.SlotAsString(c.Slot), rootOrDash(c.NodeRoot), rootOrDash(c.ProviderRoot), result)
+		}
+
+		out.printf("\n")
+	}
+
+	out.printf("Result: %s\n", r.Verdict)
+
+	if out.err != nil {
+		return out.err
+	}
+
+	return tw.Flush()
+}
+
+func rootOrDash(root *phase0.Root) string {
+	if root == nil {
+		return "-"
+	}
+
+	return eth.RootAsString(*root)
+}
+
+// errWriter remembers the first write error so that a sequence of writes only needs checking once.
+type errWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (e *errWriter) printf(format string, args ...any) {
+	if e.err != nil {
+		return
+	}
+
+	_, e.err = fmt.Fprintf(e.w, format, args...)
+}
@@ -0,0 +1,195 @@
+// Package syncverify checks a checkpoint-synced beacon node's finalized history against one or more
+// Checkpointz providers.
+package syncverify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/ethpandaops/checkpointz/pkg/beaconapi"
+)
+
+// Result is the outcome of comparing the block at one epoch boundary.
+type Result string
+
+const (
+	// ResultMatch means the node and the provider have the same block.
+	ResultMatch Result = "match"
+	// ResultMismatch means the node and the provider have different blocks.
+	ResultMismatch Result = "mismatch"
+	// ResultUnavailable means either side couldn't return the block.
+	ResultUnavailable Result = "unavailable"
+)
+
+// Verdict is the outcome of comparing the node with a provider.
+type Verdict string
+
+const (
+	// VerdictVerified means every compared block matched, and at least one was compared.
+	VerdictVerified Verdict = "verified"
+	// VerdictMismatch means at least one compared block differed.
+	VerdictMismatch Verdict = "mismatch"
+	// VerdictInconclusive means no blocks could be compared.
+	VerdictInconclusive Verdict = "inconclusive"
+)
+
+// Comparison is the comparison of the block at one epoch boundary.
+type Comparison struct {
+	Epoch        phase0.Epoch `json:"epoch,string"`
+	Slot         phase0.Slot  `json:"slot,string"`
+	NodeRoot     *phase0.Root `json:"node_root,omitempty"`
+	ProviderRoot *phase0.Root `json:"provider_root,omitempty"`
+	Result       Result       `json:"result"`
+	Error        string       `json:"error,omitempty"`
+}
+
+// ProviderReport is the comparison of the node with one provider.
+type ProviderReport struct {
+	URL         string             `json:"url"`
+	Finalized   *phase0.Checkpoint `json:"finalized,omitempty"`
+	Comparisons []Comparison       `json:"comparisons"`
+	Verdict     Verdict            `json:"verdict"`
+	Error       string             `json:"error,omitempty"`
+}
+
+// Report is the comparison of the node with every provider.
+type Report struct {
+	Node          string             `json:"node"`
+	NodeFinalized *phase0.Checkpoint `json:"node_finalized"`
+	Providers     []*ProviderReport  `json:"providers"`
+	Verdict       Verdict            `json:"verdict"`
+}
+
+// Verify compares the node's blocks at the last epochs epoch boundaries it shares with each provider. It only
+// returns an error if the node itself can't be queried; provider failures are recorded in the report.
+func Verify(ctx context.Context, node *beaconapi.Client, providers []*beaconapi.Client, epochs int) (*Report, error) {
+	if epochs < 1 {
+		return nil, errors.New("at least one epoch must be compared")
+	}
+
+	slotsPerEpoch, err := node.SlotsPerEpoch(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch spec from beacon node: %w", err)
+	}
+
+	finalized, err := node.Finalized(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch finality from beacon node: %w", err)
+	}
+
+	report := &Report{
+		Node:          node.URL(),
+		NodeFinalized: finalized,
+		Providers:     make([]*ProviderReport, 0, len(providers)),
+	}
+
+	for _, provider := range providers {
+		report.Providers = append(report.Providers, verifyProvider(ctx, node, finalized, provider, slotsPerEpoch, epochs))
+	}
+
+	report.Verdict = overallVerdict(report.Providers)
+
+	return report, nil
+}
+
+func verifyProvider(ctx context.Context, node *beaconapi.Client, nodeFinalized *phase0.Checkpoint, provider *beaconapi.Client, slotsPerEpoch uint64, epochs int) *ProviderReport {
+	report := &ProviderReport{
+		URL:         provider.URL(),
+		Comparisons: []Comparison{},
+		Verdict:     VerdictInconclusive,
+	}
+
+	finalized, err := provider.Finalized(ctx)
+	if err != nil {
+		report.Error = fmt.Sprintf("failed to fetch finality: %s", err)
+
+		return report
+	}
+
+	report.Finalized = finalized
+
+	// Only epochs both sides have finalized can be compared.
+	latest := min(nodeFinalized.Epoch, finalized.Epoch)
+
+	for i := 0; i < epochs && phase0.Epoch(i) <= latest; i++ {
+		epoch := latest - phase0.Epoch(i)
+
+		report.Comparisons = append(report.Comparisons, compare(ctx, node, provider, epoch, phase0.Slot(uint64(epoch)*slotsPerEpoch)))
+	}
+
+	report.Verdict = providerVerdict(report.Comparisons)
+
+	return report
+}
+
+func compare(ctx context.Context, node, provider *beaconapi.Client, epoch phase0.Epoch, slot phase0.Slot) Comparison {
+	comparison := Comparison{
+		Epoch:  epoch,
+		Slot:   slot,
+		Result: ResultUnavailable,
+	}
+
+	nodeRoot, nodeErr := node.BlockRootAtSlot(ctx, slot)
+	if nodeErr == nil {
+		comparison.NodeRoot = &nodeRoot
+	}
+
+	providerRoot, providerErr := provider.BlockRootAtSlot(ctx, slot)
+	if providerErr == nil {
+		comparison.ProviderRoot = &providerRoot
+	}
+
+	switch {
+	case nodeErr != nil:
+		comparison.Error = fmt.Sprintf("beacon node: %s", nodeErr)
+	case providerErr != nil:
+		comparison.Error = fmt.Sprintf("provider: %s", providerErr)
+	case nodeRoot == providerRoot:
+		comparison.Result = ResultMatch
+	default:
+		comparison.Result = ResultMismatch
+	}
+
+	return comparison
+}
+
+func providerVerdict(comparisons []Comparison) Verdict {
+	matched := false
+
+	for _, c := range comparisons {
+		switch c.Result {
+		case ResultMismatch:
+			return VerdictMismatch
+		case ResultMatch:
+			matched = true
+		}
+	}
+
+	if !matched {
+		return VerdictInconclusive
+	}
+
+	return VerdictVerified
+}
+
+// overallVerdict is a mismatch if any provider mismatched, and verified only if every provider verified.
+func overallVerdict(providers []*ProviderReport) Verdict {
+	verdict := VerdictVerified
+
+	if len(providers) == 0 {
+		verdict = VerdictInconclusive
+	}
+
+	for _, p := range providers {
+		switch p.Verdict {
+		case VerdictMismatch:
+			return VerdictMismatch
+		case VerdictInconclusive:
+			verdict = VerdictInconclusive
+		}
+	}
+
+	return verdict
+}
@@ -0,0 +1,100 @@
+package syncverify
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/ethpandaops/checkpointz/pkg/beacon/beacontest"
+	"github.com/ethpandaops/checkpointz/pkg/beaconapi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestClient(t *testing.T, chain *beacontest.Chain, finalized phase0.Epoch) *beaconapi.Client {
+	t.Helper()
+
+	node := beacontest.NewNode(chain, beacontest.Scenario{FinalizedEpoch: finalized})
+	t.Cleanup(node.Close)
+
+	return beaconapi.NewClient(node.URL(), 5*time.Second)
+}
+
+func TestVerify(t *testing.T) {
+	chain, err := beacontest.NewChain(8)
+	require.NoError(t, err)
+
+	fork, err := chain.Fork(4)
+	require.NoError(t, err)
+
+	node := newTestClient(t, chain, 6)
+
+	report, err := Verify(context.Background(), node, []*beaconapi.Client{
+		newTestClient(t, chain, 6),
+		newTestClient(t, chain, 4),
+		newTestClient(t, fork, 6),
+	}, 3)
+	require.NoError(t, err)
+
+	assert.Equal(t, phase0.Epoch(6), report.NodeFinalized.Epoch)
+	require.Len(t, report.Providers, 3)
+
+	same := report.Providers[0]
+	assert.Equal(t, VerdictVerified, same.Verdict)
+	require.Len(t, same.Comparisons, 3)
+	assert.Equal(t, phase0.Epoch(6), same.Comparisons[0].Epoch)
+	assert.Equal(t, phase0.Slot(6*beacontest.SlotsPerEpoch), same.Comparisons[0].Slot)
+	assert.Equal(t, phase0.Epoch(4), same.Comparisons[2].Epoch)
+
+	// Only epochs both sides have finalized are compared.
+	behind := report.Providers[1]
+	assert.Equal(t, VerdictVerified, behind.Verdict)
+	assert.Equal(t, phase0.Epoch(4), behind.Comparisons[0].Epoch)
+
+	forked := report.Providers[2]
+	assert.Equal(t, VerdictMismatch, forked.Verdict)
+	assert.Equal(t, ResultMismatch, forked.Comparisons[0].Result)
+	assert.Equal(t, ResultMatch, forked.Comparisons[2].Result, "blocks before the fork should match")
+
+	assert.Equal(t, VerdictMismatch, report.Verdict)
+
+	var out bytes.Buffer
+	require.NoError(t, report.WriteText(&out))
+	assert.Contains(t, out.String(), "Result: mismatch")
+	assert.Contains(t, out.String(), chain.Root(6).String())
+}
+
+func TestVerifyUnreachableProviderIsInconclusive(t *testing.T) {
+	chain, err := beacontest.NewChain(4)
+	require.NoError(t, err)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	report, err := Verify(context.Background(), newTestClient(t, chain, 2), []*beaconapi.Client{
+		newTestClient(t, chain, 2),
+		beaconapi.NewClient(server.URL, 5*time.Second),
+	}, 3)
+	require.NoError(t, err)
+
+	assert.Equal(t, VerdictVerified, report.Providers[0].Verdict)
+	assert.Equal(t, VerdictInconclusive, report.Providers[1].Verdict)
+	assert.Contains(t, report.Providers[1].Error, "503")
+	assert.Equal(t, VerdictInconclusive, report.Verdict)
+}
+
+func TestVerifyUnreachableNodeFails(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	_, err := Verify(context.Background(), beaconapi.NewClient(server.URL, 5*time.Second), nil, 3)
+	assert.Error(t, err)
+}
@@ -0,0 +1,64 @@
+package tlsconfig
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ClientAuth is how client certificates are handled. Only requiring them is supported, as nothing reads
+// the identity of a client that presents one.
+type ClientAuth string
+
+const (
+	// ClientAuthRequire turns away clients without a certificate signed by a client CA.
+	ClientAuthRequire ClientAuth = "require"
+)
+
+// Config configures serving over TLS.
+type Config struct {
+	// CertFile and KeyFile are the paths to the PEM encoded certificate chain and private key to serve. TLS
+	// is enabled when they're set.
+	CertFile string `yaml:"certFile"`
+	KeyFile  string `yaml:"keyFile"`
+	// ClientCAFile is the path to a PEM bundle of the CAs client certificates are verified against. Setting
+	// it enables mutual TLS.
+	ClientCAFile string `yaml:"clientCAFile"`
+	// ClientAuth is how client certificates are handled when mutual TLS is enabled. It must be
+	// ClientAuthRequire.
+	ClientAuth ClientAuth `yaml:"clientAuth" default:"require"`
+	// HTTP2 enables HTTP/2.
+	HTTP2 bool `yaml:"http2" default:"true"`
+	// ReloadInterval is how often the files are checked for changes, which are loaded without a restart.
+	ReloadInterval time.Duration `yaml:"reloadInterval" default:"30s"`
+}
+
+func (c *Config) Enabled() bool {
+	return c.CertFile != "" || c.KeyFile != ""
+}
+
+func (c *Config) Validate() error {
+	if !c.Enabled() {
+		if c.ClientCAFile != "" {
+			return errors.New("clientCAFile requires certFile and keyFile")
+		}
+
+		return nil
+	}
+
+	if c.CertFile == "" || c.KeyFile == "" {
+		return errors.New("both certFile and keyFile are required")
+	}
+
+	switch c.ClientAuth {
+	case ClientAuthRequire:
+	default:
+		return fmt.Errorf("invalid clientAuth: %q", c.ClientAuth)
+	}
+
+	if c.ReloadInterval <= 0 {
+		return errors.New("reloadInterval must be positive")
+	}
+
+	return nil
+}
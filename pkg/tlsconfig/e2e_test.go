@@ -0,0 +1,72 @@
+package tlsconfig_test
+
+import (
+	"crypto/tls"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/ethpandaops/checkpointz/pkg/beacon"
+	"github.com/ethpandaops/checkpointz/pkg/checkpointz"
+	"github.com/ethpandaops/checkpointz/pkg/checkpointz/checkpointztest"
+	"github.com/ethpandaops/checkpointz/pkg/tlsconfig/tlsconfigtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestE2EServesOverMutualTLS(t *testing.T) {
+	chain := checkpointztest.NewChain(t)
+
+	serverCA := tlsconfigtest.NewCA(t, "server-ca")
+	clientCA := tlsconfigtest.NewCA(t, "client-ca")
+	files := serverCA.WriteServer("checkpointz")
+
+	server := checkpointztest.Start(t, beacon.OperatingModeLight, func(config *checkpointz.Config) {
+		config.GlobalConfig.TLS.CertFile = files.CertFile
+		config.GlobalConfig.TLS.KeyFile = files.KeyFile
+		config.GlobalConfig.TLS.ClientCAFile = clientCA.WriteCert()
+	}, checkpointztest.Upstream{Node: checkpointztest.NewNode(chain), DataProvider: true})
+
+	clientCert, clientKey := clientCA.Client("client")
+
+	client := &http.Client{Transport: &http.Transport{
+		TLSClientConfig: &tls.Config{
+			RootCAs:      serverCA.Pool(),
+			Certificates: []tls.Certificate{{Certificate: [][]byte{clientCert.Raw}, PrivateKey: clientKey}},
+			MinVersion:   tls.VersionTLS12,
+		},
+		ForceAttemptHTTP2: true,
+	}}
+
+	var rsp *http.Response
+
+	server.Eventually(func() bool {
+		var err error
+
+		rsp, err = client.Get(server.URL + "/eth/v1/beacon/blocks/finalized/root")
+		if err != nil {
+			return false
+		}
+
+		rsp.Body.Close()
+
+		return rsp.StatusCode == http.StatusOK
+	})
+
+	assert.Equal(t, "HTTP/2.0", rsp.Proto)
+
+	// Clients without a certificate are turned away.
+	anonymous := &http.Client{Transport: &http.Transport{
+		TLSClientConfig: &tls.Config{RootCAs: serverCA.Pool(), MinVersion: tls.VersionTLS12},
+	}}
+
+	_, err := anonymous.Get(server.URL + "/eth/v1/node/version")
+	assert.Error(t, err)
+
+	// So is plain HTTP.
+	rsp, err = http.Get("http://" + strings.TrimPrefix(server.URL, "https://") + "/eth/v1/node/version")
+	require.NoError(t, err)
+	rsp.Body.Close()
+
+	assert.Equal(t, http.StatusBadRequest, rsp.StatusCode)
+}
@@ -0,0 +1,223 @@
+// Package tlsconfig serves TLS from certificate files, picking up changes to them (e.g. renewals) without a
+// restart.
+package tlsconfig
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/ethpandaops/checkpointz/pkg/clock"
+	"github.com/sirupsen/logrus"
+)
+
+// Reloader holds the certificate and client CAs loaded from the configured files, and reloads them when
+// the files change.
+type Reloader struct {
+	log    logrus.FieldLogger
+	config Config
+	clock  clock.Clock
+
+	mu          sync.RWMutex
+	certificate *tls.Certificate
+	clientCAs   *x509.CertPool
+	stamps      map[string]stamp
+}
+
+// stamp identifies a version of a file.
+type stamp struct {
+	modified time.Time
+	size     int64
+}
+
+// NewReloader returns a Reloader for config, which must be valid and enabled, loading its files.
+func NewReloader(log logrus.FieldLogger, config Config, clk clock.Clock) (*Reloader, error) {
+	r := &Reloader{
+		log:    log.WithField("module", "tls"),
+		config: config,
+		clock:  clk,
+	}
+
+	if err := r.reload(); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// TLSConfig returns a TLS config that serves the latest certificate, and verifies client certificates
+// against the latest client CAs if mutual TLS is enabled.
+func (r *Reloader) TLSConfig() *tls.Config {
+	config := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		GetCertificate: func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+			r.mu.RLock()
+			defer r.mu.RUnlock()
+
+			return r.certificate, nil
+		},
+	}
+
+	if r.config.ClientCAFile != "" {
+		// The handshake asks for a certificate but leaves verifying it to us, as the CAs it's verified
+		// against can change. Unlike VerifyPeerCertificate, VerifyConnection is also called when a session is
+		// resumed, so a client whose CA has been removed can't keep connecting on an old session ticket.
+		config.ClientAuth = tls.RequireAnyClientCert
+
+		config.VerifyConnection = r.verifyClient
+	}
+
+	return config
+}
+
+// Run checks the files for changes every reload interval until ctx is done, loading them if they have.
+func (r *Reloader) Run(ctx context.Context) {
+	ticker := r.clock.NewTicker(r.config.ReloadInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C():
+		case <-ctx.Done():
+			return
+		}
+
+		changed, err := r.changed()
+		if err != nil {
+			r.log.WithError(err).Warn("Failed to check TLS files for changes")
+
+			continue
+		}
+
+		if !changed {
+			continue
+		}
+
+		// A half written file fails to load, and is retried on the next tick. Until then, the previous
+		// certificate is still served.
+		if err := r.reload(); err != nil {
+			r.log.WithError(err).Error("Failed to reload TLS files, serving the previous certificate")
+
+			continue
+		}
+
+		r.log.Info("Reloaded TLS files")
+	}
+}
+
+func (r *Reloader) files() []string {
+	files := []string{r.config.CertFile, r.config.KeyFile}
+
+	if r.config.ClientCAFile != "" {
+		files = append(files, r.config.ClientCAFile)
+	}
+
+	return files
+}
+
+// changed returns true if any file has changed since it was last loaded.
+func (r *Reloader) changed() (bool, error) {
+	stamps, err := r.stat()
+	if err != nil {
+		return false, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for file, s := range stamps {
+		if r.stamps[file] != s {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+func (r *Reloader) stat() (map[string]stamp, error) {
+	stamps := make(map[string]stamp)
+
+	for _, file := range r.files() {
+		info, err := os.Stat(file)
+		if err != nil {
+			return nil, err
+		}
+
+		stamps[file] = stamp{modified: info.ModTime(), size: info.Size()}
+	}
+
+	return stamps, nil
+}
+
+// reload loads the files, replacing what's served only if they're all valid.
+func (r *Reloader) reload() error {
+	// Stat first, so that a change made while loading is picked up on the next check.
+	stamps, err := r.stat()
+	if err != nil {
+		return err
+	}
+
+	certificate, err := tls.LoadX509KeyPair(r.config.CertFile, r.config.KeyFile)
+	if err != nil {
+		return fmt.Errorf("failed to load certificate: %w", err)
+	}
+
+	var clientCAs *x509.CertPool
+
+	if r.config.ClientCAFile != "" {
+		data, err := os.ReadFile(r.config.ClientCAFile)
+		if err != nil {
+			return fmt.Errorf("failed to read client CAs: %w", err)
+		}
+
+		clientCAs = x509.NewCertPool()
+		if !clientCAs.AppendCertsFromPEM(data) {
+			return fmt.Errorf("no certificates found in %s", r.config.ClientCAFile)
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.certificate = &certificate
+	r.clientCAs = clientCAs
+	r.stamps = stamps
+
+	return nil
+}
+
+// verifyClient verifies the certificate chain a client presented, in a new or resumed session, against the
+// latest client CAs.
+func (r *Reloader) verifyClient(state tls.ConnectionState) error {
+	certs := state.PeerCertificates
+	if len(certs) == 0 {
+		return errors.New("client certificate required")
+	}
+
+	intermediates := x509.NewCertPool()
+	for _, cert := range certs[1:] {
+		intermediates.AddCert(cert)
+	}
+
+	r.mu.RLock()
+	roots := r.clientCAs
+	r.mu.RUnlock()
+
+	if roots == nil {
+		return errors.New("no client CAs loaded")
+	}
+
+	_, err := certs[0].Verify(x509.VerifyOptions{
+		Roots:         roots,
+		Intermediates: intermediates,
+		CurrentTime:   r.clock.Now(),
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	})
+
+	return err
+}
@@ -0,0 +1,291 @@
+package tlsconfig
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/tls"
+	"crypto/x509"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/ethpandaops/checkpointz/pkg/clock"
+	"github.com/ethpandaops/checkpointz/pkg/tlsconfig/tlsconfigtest"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// serve serves HTTPS using the reloader's TLS config, the way Checkpointz does, and returns its address.
+func serve(t *testing.T, r *Reloader) string {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	server := &http.Server{
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			_, _ = w.Write([]byte(req.Proto))
+		}),
+		ReadHeaderTimeout: time.Second,
+		TLSConfig:         r.TLSConfig(),
+		ErrorLog:          log.New(io.Discard, "", 0),
+		Protocols:         new(http.Protocols),
+	}
+	server.Protocols.SetHTTP1(true)
+	server.Protocols.SetHTTP2(true)
+
+	go func() {
+		_ = server.ServeTLS(listener, "", "")
+	}()
+
+	t.Cleanup(func() { server.Close() })
+
+	return listener.Addr().String()
+}
+
+// handshake connects to addr trusting roots, presenting clientCert if it isn't nil, and returns the
+// certificate the server presented.
+func handshake(addr string, roots *x509.CertPool, clientCert *tls.Certificate) (*x509.Certificate, error) {
+	config := &tls.Config{RootCAs: roots, MinVersion: tls.VersionTLS12}
+
+	if clientCert != nil {
+		config.Certificates = []tls.Certificate{*clientCert}
+	}
+
+	state, err := connect(addr, config)
+	if err != nil {
+		return nil, err
+	}
+
+	return state.PeerCertificates[0], nil
+}
+
+// connect makes a request to addr with config, and returns the state of the connection it was made on.
+func connect(addr string, config *tls.Config) (*tls.ConnectionState, error) {
+	conn, err := tls.Dial("tcp", addr, config)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	// Client certificates are verified after the client's side of the handshake completes, so make a round
+	// trip to find out whether the server accepted it. It also delivers the server's session tickets.
+	if _, err := conn.Write([]byte("GET / HTTP/1.1\r\nHost: localhost\r\n\r\n")); err != nil {
+		return nil, err
+	}
+
+	if _, err := conn.Read(make([]byte, 1)); err != nil {
+		return nil, err
+	}
+
+	state := conn.ConnectionState()
+
+	return &state, nil
+}
+
+func keyPair(cert *x509.Certificate, key *ecdsa.PrivateKey) *tls.Certificate {
+	return &tls.Certificate{Certificate: [][]byte{cert.Raw}, PrivateKey: key}
+}
+
+func TestReloaderReloadsChangedFiles(t *testing.T) {
+	ca := tlsconfigtest.NewCA(t, "ca")
+	files := tlsconfigtest.TempFiles(t)
+
+	first, firstKey := ca.Server("first")
+	tlsconfigtest.Write(t, files, first, firstKey)
+
+	clk := clock.NewMock(time.Now())
+
+	r, err := NewReloader(logrus.New(), Config{CertFile: files.CertFile, KeyFile: files.KeyFile, ReloadInterval: time.Minute}, clk)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go r.Run(ctx)
+
+	addr := serve(t, r)
+
+	served, err := handshake(addr, ca.Pool(), nil)
+	require.NoError(t, err)
+	assert.Equal(t, "first", served.Subject.CommonName)
+
+	// An invalid file keeps the previous certificate served.
+	require.NoError(t, os.WriteFile(files.KeyFile, []byte("half written"), 0o600))
+
+	clk.BlockUntil(1)
+	clk.Advance(time.Minute)
+
+	served, err = handshake(addr, ca.Pool(), nil)
+	require.NoError(t, err)
+	assert.Equal(t, "first", served.Subject.CommonName)
+
+	second, secondKey := ca.Server("second")
+	tlsconfigtest.Write(t, files, second, secondKey)
+
+	// Make sure the change is noticed on filesystems with coarse modification times.
+	later := time.Now().Add(time.Hour)
+	require.NoError(t, os.Chtimes(files.CertFile, later, later))
+
+	require.Eventually(t, func() bool {
+		clk.Advance(time.Minute)
+
+		served, err := handshake(addr, ca.Pool(), nil)
+
+		return err == nil && served.Subject.CommonName == "second"
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestReloaderVerifiesClients(t *testing.T) {
+	serverCA := tlsconfigtest.NewCA(t, "server-ca")
+	clientCA := tlsconfigtest.NewCA(t, "client-ca")
+	otherCA := tlsconfigtest.NewCA(t, "other-ca")
+
+	files := serverCA.WriteServer("server")
+
+	client := keyPair(clientCA.Client("client"))
+	stranger := keyPair(otherCA.Client("stranger"))
+	notForClients := keyPair(clientCA.Server("not-for-clients"))
+
+	for name, test := range map[string]struct {
+		cert     *tls.Certificate
+		accepted bool
+	}{
+		"presented":          {cert: client, accepted: true},
+		"missing":            {},
+		"from another CA":    {cert: stranger},
+		"without client use": {cert: notForClients},
+	} {
+		t.Run(name, func(t *testing.T) {
+			r, err := NewReloader(logrus.New(), Config{
+				CertFile:       files.CertFile,
+				KeyFile:        files.KeyFile,
+				ClientCAFile:   clientCA.WriteCert(),
+				ClientAuth:     ClientAuthRequire,
+				ReloadInterval: time.Minute,
+			}, clock.New())
+			require.NoError(t, err)
+
+			_, err = handshake(serve(t, r), serverCA.Pool(), test.cert)
+
+			if test.accepted {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+}
+
+func TestReloaderVerifiesClientsOnResumedSessions(t *testing.T) {
+	serverCA := tlsconfigtest.NewCA(t, "server-ca")
+	clientCA := tlsconfigtest.NewCA(t, "client-ca")
+	otherCA := tlsconfigtest.NewCA(t, "other-ca")
+
+	files := serverCA.WriteServer("server")
+	clientCAFile := clientCA.WriteCert()
+
+	r, err := NewReloader(logrus.New(), Config{
+		CertFile:       files.CertFile,
+		KeyFile:        files.KeyFile,
+		ClientCAFile:   clientCAFile,
+		ClientAuth:     ClientAuthRequire,
+		ReloadInterval: time.Minute,
+	}, clock.New())
+	require.NoError(t, err)
+
+	addr := serve(t, r)
+
+	config := &tls.Config{
+		RootCAs:            serverCA.Pool(),
+		MinVersion:         tls.VersionTLS12,
+		Certificates:       []tls.Certificate{*keyPair(clientCA.Client("client"))},
+		ClientSessionCache: tls.NewLRUClientSessionCache(1),
+	}
+
+	_, err = connect(addr, config)
+	require.NoError(t, err)
+
+	state, err := connect(addr, config)
+	require.NoError(t, err)
+	require.True(t, state.DidResume, "the client should resume its session")
+
+	// Once the client's CA is no longer trusted, its session can't be resumed either.
+	data, err := os.ReadFile(otherCA.WriteCert())
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(clientCAFile, data, 0o600))
+	require.NoError(t, r.reload())
+
+	_, err = connect(addr, config)
+	assert.Error(t, err)
+}
+
+func TestReloaderNegotiatesHTTP2(t *testing.T) {
+	ca := tlsconfigtest.NewCA(t, "ca")
+	files := ca.WriteServer("server")
+
+	r, err := NewReloader(logrus.New(), Config{CertFile: files.CertFile, KeyFile: files.KeyFile, ReloadInterval: time.Minute}, clock.New())
+	require.NoError(t, err)
+
+	addr := serve(t, r)
+
+	client := &http.Client{Transport: &http.Transport{
+		TLSClientConfig:   &tls.Config{RootCAs: ca.Pool(), MinVersion: tls.VersionTLS12},
+		ForceAttemptHTTP2: true,
+	}}
+
+	rsp, err := client.Get("https://" + addr)
+	require.NoError(t, err)
+
+	defer rsp.Body.Close()
+
+	assert.Equal(t, "HTTP/2.0", rsp.Proto)
+}
+
+func TestNewReloaderRejectsInvalidFiles(t *testing.T) {
+	ca := tlsconfigtest.NewCA(t, "ca")
+	files := ca.WriteServer("server")
+
+	notPEM := files.CertFile + ".txt"
+	require.NoError(t, os.WriteFile(notPEM, []byte("nonsense"), 0o600))
+
+	for name, config := range map[string]Config{
+		"missing cert":      {CertFile: files.CertFile + ".missing", KeyFile: files.KeyFile},
+		"mismatched key":    {CertFile: files.CertFile, KeyFile: files.CertFile},
+		"missing client CA": {CertFile: files.CertFile, KeyFile: files.KeyFile, ClientCAFile: files.CertFile + ".missing"},
+		"empty client CA":   {CertFile: files.CertFile, KeyFile: files.KeyFile, ClientCAFile: notPEM},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := NewReloader(logrus.New(), config, clock.New())
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestConfigValidate(t *testing.T) {
+	valid := Config{CertFile: "cert.pem", KeyFile: "key.pem", ClientAuth: ClientAuthRequire, ReloadInterval: time.Minute}
+	require.NoError(t, valid.Validate())
+	require.NoError(t, (&Config{}).Validate(), "disabled TLS isn't validated")
+
+	for name, mutate := range map[string]func(*Config){
+		"no key":               func(c *Config) { c.KeyFile = "" },
+		"no cert":              func(c *Config) { c.CertFile = "" },
+		"unknown client auth":  func(c *Config) { c.ClientAuth = "sometimes" },
+		"optional client auth": func(c *Config) { c.ClientAuth = "optional" },
+		"no reload interval":   func(c *Config) { c.ReloadInterval = 0 },
+	} {
+		t.Run(name, func(t *testing.T) {
+			config := valid
+			mutate(&config)
+
+			assert.Error(t, config.Validate())
+		})
+	}
+
+	assert.Error(t, (&Config{ClientCAFile: "ca.pem"}).Validate(), "mutual TLS requires TLS")
+}
@@ -0,0 +1,163 @@
+// Package tlsconfigtest issues certificates for tests.
+package tlsconfigtest
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// CA is a certificate authority that issues certificates valid for an hour.
+type CA struct {
+	t    *testing.T
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pem  []byte
+}
+
+// Files are the paths of a PEM encoded certificate and its private key.
+type Files struct {
+	CertFile string
+	KeyFile  string
+}
+
+// NewCA returns a new self-signed CA called name.
+func NewCA(t *testing.T, name string) *CA {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := template(name)
+	template.IsCA = true
+	template.BasicConstraintsValid = true
+	template.KeyUsage = x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	return &CA{
+		t:    t,
+		cert: cert,
+		key:  key,
+		pem:  pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+	}
+}
+
+// Pool returns a pool holding only the CA.
+func (c *CA) Pool() *x509.CertPool {
+	pool := x509.NewCertPool()
+	pool.AddCert(c.cert)
+
+	return pool
+}
+
+// WriteCert writes the CA's certificate to a new file, returning its path.
+func (c *CA) WriteCert() string {
+	c.t.Helper()
+
+	path := filepath.Join(c.t.TempDir(), "ca.pem")
+	require.NoError(c.t, os.WriteFile(path, c.pem, 0o600))
+
+	return path
+}
+
+// Server issues a certificate for serving localhost.
+func (c *CA) Server(name string) (*x509.Certificate, *ecdsa.PrivateKey) {
+	c.t.Helper()
+
+	template := template(name)
+	template.DNSNames = []string{"localhost"}
+	template.IPAddresses = []net.IP{net.IPv4(127, 0, 0, 1), net.IPv6loopback}
+	template.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth}
+
+	return c.issue(template)
+}
+
+// Client issues a certificate for authenticating a client.
+func (c *CA) Client(name string) (*x509.Certificate, *ecdsa.PrivateKey) {
+	c.t.Helper()
+
+	template := template(name)
+	template.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth}
+
+	return c.issue(template)
+}
+
+// Write writes cert and key to the files, replacing what they hold.
+func Write(t *testing.T, files Files, cert *x509.Certificate, key *ecdsa.PrivateKey) {
+	t.Helper()
+
+	der, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	require.NoError(t, os.WriteFile(files.CertFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw}), 0o600))
+	require.NoError(t, os.WriteFile(files.KeyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der}), 0o600))
+}
+
+// WriteServer issues a certificate for serving localhost and writes it to new files.
+func (c *CA) WriteServer(name string) Files {
+	c.t.Helper()
+
+	files := TempFiles(c.t)
+	cert, key := c.Server(name)
+	Write(c.t, files, cert, key)
+
+	return files
+}
+
+// TempFiles returns paths for a certificate and key in a new temporary directory.
+func TempFiles(t *testing.T) Files {
+	t.Helper()
+
+	dir := t.TempDir()
+
+	return Files{
+		CertFile: filepath.Join(dir, "cert.pem"),
+		KeyFile:  filepath.Join(dir, "key.pem"),
+	}
+}
+
+func (c *CA) issue(template *x509.Certificate) (*x509.Certificate, *ecdsa.PrivateKey) {
+	c.t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(c.t, err)
+
+	der, err := x509.CreateCertificate(rand.Reader, template, c.cert, &key.PublicKey, c.key)
+	require.NoError(c.t, err)
+
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(c.t, err)
+
+	return cert, key
+}
+
+func template(name string) *x509.Certificate {
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 64))
+	if err != nil {
+		panic(err)
+	}
+
+	return &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Minute),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+	}
+}
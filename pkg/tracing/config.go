@@ -0,0 +1,47 @@
+package tracing
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+)
+
+// Config configures exporting traces over OTLP/HTTP.
+type Config struct {
+	Enabled bool `yaml:"enabled" default:"false"`
+	// Endpoint is the collector's OTLP/HTTP endpoint, either as host:port (e.g. "localhost:4318") or as a URL
+	// (e.g. "https://otel.example.com/v1/traces"). If empty, the standard OTEL_EXPORTER_OTLP_* environment
+	// variables apply.
+	Endpoint string `yaml:"endpoint"`
+	// Insecure sends traces to a host:port endpoint over HTTP rather than HTTPS.
+	Insecure bool `yaml:"insecure" default:"false"`
+	// Headers are sent with every export, e.g. for authentication.
+	Headers map[string]string `yaml:"headers"`
+	// ServiceName identifies this instance in traces.
+	ServiceName string `yaml:"serviceName" default:"checkpointz"`
+	// SampleRatio is the fraction of traces started here that are sampled. Traces started by a caller
+	// follow its sampling decision.
+	SampleRatio float64 `yaml:"sampleRatio" default:"1"`
+}
+
+func (c *Config) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+
+	if strings.Contains(c.Endpoint, "://") {
+		if _, err := url.Parse(c.Endpoint); err != nil {
+			return err
+		}
+	}
+
+	if c.ServiceName == "" {
+		return errors.New("serviceName is required")
+	}
+
+	if c.SampleRatio < 0 || c.SampleRatio > 1 {
+		return errors.New("sampleRatio must be between 0 and 1")
+	}
+
+	return nil
+}
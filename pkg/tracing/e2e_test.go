@@ -0,0 +1,69 @@
+package tracing_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/ethpandaops/checkpointz/pkg/beacon"
+	"github.com/ethpandaops/checkpointz/pkg/checkpointz"
+	"github.com/ethpandaops/checkpointz/pkg/checkpointz/checkpointztest"
+	"github.com/ethpandaops/checkpointz/pkg/tracing/tracingtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestE2ETracesRequestsAndDownloads(t *testing.T) {
+	chain := checkpointztest.NewChain(t)
+	recorder := tracingtest.Record(t)
+
+	server := checkpointztest.Start(t, beacon.OperatingModeFull, func(config *checkpointz.Config) {
+		// Trace context is only believed from trusted proxies, which the test client stands in for.
+		config.GlobalConfig.TrustedProxies = []string{"127.0.0.1"}
+	}, checkpointztest.Upstream{Node: checkpointztest.NewNode(chain), DataProvider: true})
+
+	server.RequireServes(chain.Root(checkpointztest.FinalizedEpoch))
+
+	// Bundles are downloaded in their own traces, which follow the state from the upstream into the store.
+	spans := map[trace.SpanID]string{}
+	for _, span := range recorder.Spans() {
+		spans[span.SpanContext.SpanID()] = span.Name
+	}
+
+	parents := map[string][]string{}
+	for _, span := range recorder.Spans() {
+		if span.Parent.IsValid() {
+			parents[span.Name] = append(parents[span.Name], spans[span.Parent.SpanID()])
+		}
+	}
+
+	assert.Contains(t, parents["beacon.downloadAndStoreBeaconState"], "beacon.fetchBundle")
+	assert.Contains(t, parents["upstream.FetchBeaconState"], "beacon.downloadAndStoreBeaconState")
+	assert.Contains(t, parents["upstream.FetchBlock"], "beacon.fetchBundle")
+
+	// Requests join the caller's trace.
+	rsp, _ := server.Get("/eth/v2/debug/beacon/states/finalized", "application/octet-stream", map[string]string{
+		"traceparent": "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+	})
+	require.Equal(t, http.StatusOK, rsp.StatusCode)
+
+	traceID, err := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	require.NoError(t, err)
+
+	// The request's span ends once the response has been written, which can be after the client has read it.
+	server.Eventually(func() bool {
+		return len(recorder.Named("GET /eth/v2/debug/beacon/states/:state_id")) == 1
+	})
+
+	assert.Subset(t, recorder.InTrace(traceID), []string{
+		"GET /eth/v2/debug/beacon/states/:state_id",
+		"eth.BeaconState",
+		"ssz.EncodeStateSSZ",
+	})
+
+	request := recorder.Named("GET /eth/v2/debug/beacon/states/:state_id")[0]
+	assert.Equal(t, "00f067aa0ba902b7", request.Parent.SpanID().String())
+	assert.Equal(t, trace.SpanKindServer, request.SpanKind)
+	assert.Contains(t, request.Attributes, attribute.Int("http.response.status_code", http.StatusOK))
+}
@@ -0,0 +1,89 @@
+// Package tracing traces requests and downloads with OpenTelemetry, exporting spans over OTLP.
+//
+// Spans are started with the global tracer provider, which does nothing until Setup or Install replaces it.
+package tracing
+
+import (
+	"context"
+	"strings"
+
+	"github.com/ethpandaops/checkpointz/pkg/version"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "github.com/ethpandaops/checkpointz"
+
+// Setup installs a tracer provider exporting spans over OTLP/HTTP as configured, and returns a function that
+// flushes and stops it.
+func Setup(ctx context.Context, config Config) (func(context.Context) error, error) {
+	var opts []otlptracehttp.Option
+
+	switch {
+	case strings.Contains(config.Endpoint, "://"):
+		opts = append(opts, otlptracehttp.WithEndpointURL(config.Endpoint))
+	case config.Endpoint != "":
+		opts = append(opts, otlptracehttp.WithEndpoint(config.Endpoint))
+
+		if config.Insecure {
+			opts = append(opts, otlptracehttp.WithInsecure())
+		}
+	}
+
+	if len(config.Headers) > 0 {
+		opts = append(opts, otlptracehttp.WithHeaders(config.Headers))
+	}
+
+	exporter, err := otlptracehttp.New(ctx, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	provider := NewProvider(config, sdktrace.WithBatcher(exporter))
+
+	Install(provider)
+
+	return provider.Shutdown, nil
+}
+
+// NewProvider returns a tracer provider for config that hands its spans to the span processor registered
+// by register, e.g. sdktrace.WithBatcher(exporter), or sdktrace.WithSyncer(tracetest.NewInMemoryExporter())
+// in tests.
+func NewProvider(config Config, register sdktrace.TracerProviderOption) *sdktrace.TracerProvider {
+	return sdktrace.NewTracerProvider(
+		register,
+		sdktrace.WithResource(resource.NewSchemaless(
+			semconv.ServiceName(config.ServiceName),
+			semconv.ServiceVersion(version.Short()),
+		)),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(config.SampleRatio))),
+	)
+}
+
+// Install makes provider the global tracer provider, and propagates trace context through W3C headers.
+func Install(provider trace.TracerProvider) {
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+}
+
+// Start starts a span named name as a child of the span in ctx, if any.
+func Start(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	// The tracer is looked up each time so that spans follow the global provider when it's replaced.
+	return otel.Tracer(instrumentationName, trace.WithInstrumentationVersion(version.Short())).Start(ctx, name, opts...)
+}
+
+// End ends span, marking it as failed with err if err isn't nil.
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	span.End()
+}
@@ -0,0 +1,88 @@
+package tracing_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/creasty/defaults"
+	"github.com/ethpandaops/checkpointz/pkg/tracing"
+	"github.com/ethpandaops/checkpointz/pkg/tracing/tracingtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+func TestStartNestsSpans(t *testing.T) {
+	recorder := tracingtest.Record(t)
+
+	ctx, parent := tracing.Start(context.Background(), "parent")
+	_, child := tracing.Start(ctx, "child")
+
+	tracing.End(child, errors.New("failed"))
+	tracing.End(parent, nil)
+
+	spans := recorder.Spans()
+	require.Len(t, spans, 2)
+
+	assert.Equal(t, "child", spans[0].Name)
+	assert.Equal(t, parent.SpanContext().SpanID(), spans[0].Parent.SpanID())
+	assert.Equal(t, codes.Error, spans[0].Status.Code)
+	assert.Equal(t, "failed", spans[0].Status.Description)
+	require.Len(t, spans[0].Events, 1, "the error is recorded")
+
+	assert.Equal(t, "parent", spans[1].Name)
+	assert.False(t, spans[1].Parent.IsValid())
+	assert.Equal(t, codes.Unset, spans[1].Status.Code)
+
+	for _, span := range spans {
+		name, ok := span.Resource.Set().Value("service.name")
+		require.True(t, ok)
+		assert.Equal(t, "checkpointz", name.AsString(), "spans carry the service name")
+	}
+}
+
+func TestInstallPropagatesTraceContext(t *testing.T) {
+	recorder := tracingtest.Record(t)
+
+	header := http.Header{}
+	header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+
+	ctx := otel.GetTextMapPropagator().Extract(context.Background(), propagation.HeaderCarrier(header))
+
+	_, span := tracing.Start(ctx, "request")
+	tracing.End(span, nil)
+
+	spans := recorder.Named("request")
+	require.Len(t, spans, 1)
+
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", spans[0].SpanContext.TraceID().String())
+	assert.Equal(t, "00f067aa0ba902b7", spans[0].Parent.SpanID().String())
+	assert.True(t, spans[0].Parent.IsRemote())
+}
+
+func TestConfigValidate(t *testing.T) {
+	valid := tracing.Config{}
+	require.NoError(t, defaults.Set(&valid))
+	require.NoError(t, valid.Validate(), "disabled tracing isn't validated")
+
+	valid.Enabled = true
+	require.NoError(t, valid.Validate())
+
+	for name, mutate := range map[string]func(*tracing.Config){
+		"no service name":      func(c *tracing.Config) { c.ServiceName = "" },
+		"negative sample":      func(c *tracing.Config) { c.SampleRatio = -0.1 },
+		"sample more than all": func(c *tracing.Config) { c.SampleRatio = 1.5 },
+		"invalid endpoint url": func(c *tracing.Config) { c.Endpoint = "http://[::1" },
+	} {
+		t.Run(name, func(t *testing.T) {
+			config := valid
+			mutate(&config)
+
+			assert.Error(t, config.Validate())
+		})
+	}
+}
@@ -0,0 +1,74 @@
+// Package tracingtest records the spans started during tests.
+package tracingtest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/creasty/defaults"
+	"github.com/ethpandaops/checkpointz/pkg/tracing"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Recorder holds the spans ended since Record was called.
+type Recorder struct {
+	exporter *tracetest.InMemoryExporter
+}
+
+// Record installs a tracer provider that samples every span and records them in memory, until the test ends.
+func Record(t *testing.T) *Recorder {
+	t.Helper()
+
+	config := tracing.Config{}
+	require.NoError(t, defaults.Set(&config))
+
+	exporter := tracetest.NewInMemoryExporter()
+	provider := tracing.NewProvider(config, sdktrace.WithSyncer(exporter))
+
+	previous := otel.GetTracerProvider()
+
+	tracing.Install(provider)
+
+	t.Cleanup(func() {
+		otel.SetTracerProvider(previous)
+
+		_ = provider.Shutdown(context.Background())
+	})
+
+	return &Recorder{exporter: exporter}
+}
+
+// Spans returns the spans ended so far, in the order they ended.
+func (r *Recorder) Spans() tracetest.SpanStubs {
+	return r.exporter.GetSpans()
+}
+
+// Named returns the ended spans called name.
+func (r *Recorder) Named(name string) tracetest.SpanStubs {
+	var spans tracetest.SpanStubs
+
+	for _, span := range r.Spans() {
+		if span.Name == name {
+			spans = append(spans, span)
+		}
+	}
+
+	return spans
+}
+
+// InTrace returns the names of the ended spans belonging to the trace traceID.
+func (r *Recorder) InTrace(traceID trace.TraceID) []string {
+	var names []string
+
+	for _, span := range r.Spans() {
+		if span.SpanContext.TraceID() == traceID {
+			names = append(names, span.Name)
+		}
+	}
+
+	return names
+}